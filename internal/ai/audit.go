@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"tala/internal/audit"
+	"tala/internal/logging"
+)
+
+// AuditLog records every executed tool in the audit log. It is off until
+// ConfigureTools turns it on, so tests and embedders do not write to the
+// user's log.
+var AuditLog = false
+
+// maxAuditValue bounds how much of a parameter, prompt or error is recorded;
+// the log says what was done, not the full file contents written
+const maxAuditValue = 500
+
+var (
+	auditMu     sync.Mutex
+	auditPrompt string // the prompt of the response being handled
+	auditFailed bool   // a write already failed and was reported
+)
+
+// setAuditPrompt records which prompt the following tool calls answer
+func setAuditPrompt(prompt string) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	auditPrompt = prompt
+}
+
+// currentAuditPrompt returns the prompt the running tool calls answer
+func currentAuditPrompt() string {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	return auditPrompt
+}
+
+// auditTool appends an executed tool call to the audit log. A log that cannot
+// be written is reported once rather than failing the tool.
+func auditTool(prompt, toolName string, args map[string]interface{}, content string, success bool) {
+	if !AuditLog {
+		return
+	}
+	entry := audit.Entry{
+		Tool:    toolName,
+		Params:  auditParams(args),
+		Success: success,
+		Prompt:  clipAudit(prompt),
+	}
+	if !success {
+		entry.Error = clipAudit(logging.Redact(strings.TrimSpace(content)))
+	}
+	if err := audit.Append(entry); err != nil {
+		auditMu.Lock()
+		defer auditMu.Unlock()
+		if !auditFailed {
+			auditFailed = true
+			slog.Warn("cannot write the audit log", "error", err)
+		}
+	}
+}
+
+// auditParams copies tool parameters for the log, shortening long values and
+// hiding anything that looks like a credential
+func auditParams(args map[string]interface{}) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	params := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if text, ok := value.(string); ok {
+			params[key] = clipAudit(logging.Redact(text))
+		} else {
+			params[key] = value
+		}
+	}
+	return params
+}
+
+// clipAudit shortens text to maxAuditValue bytes, noting the original size
+func clipAudit(text string) string {
+	if len(text) <= maxAuditValue {
+		return text
+	}
+	cut := maxAuditValue
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes)", text[:cut], len(text))
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tala/internal/audit"
+)
+
+// useTestAudit turns on the audit log in a temporary file for the duration of a test
+func useTestAudit(t *testing.T) {
+	audit.File = filepath.Join(t.TempDir(), "audit.log")
+	AuditLog = true
+	t.Cleanup(func() {
+		audit.File = ""
+		AuditLog = false
+	})
+}
+
+func TestExecuteToolIsAudited(t *testing.T) {
+	useTestAudit(t)
+	dir := t.TempDir()
+
+	end := beginToolTurn("write my notes")
+	ExecuteTool("create_file", map[string]interface{}{
+		"filename": filepath.Join(dir, "notes.txt"),
+		"content":  strings.Repeat("note ", 200),
+	})
+	ExecuteTool("read_file", map[string]interface{}{"filename": filepath.Join(dir, "missing.txt")})
+	end()
+
+	entries, _, err := audit.Tail(0)
+	if err != nil {
+		t.Fatalf("Reading the audit log failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audited calls, got %+v", entries)
+	}
+
+	created := entries[0]
+	if created.Tool != "create_file" || !created.Success || created.Prompt != "write my notes" {
+		t.Errorf("Unexpected entry for create_file: %+v", created)
+	}
+	if content, _ := created.Params["content"].(string); !strings.HasSuffix(content, "... (1000 bytes)") {
+		t.Errorf("Expected long content to be shortened, got %q", content)
+	}
+
+	failed := entries[1]
+	if failed.Success || failed.Error == "" {
+		t.Errorf("Expected the failed read to be recorded with its error, got %+v", failed)
+	}
+}
+
+func TestAuditLogOff(t *testing.T) {
+	useTestAudit(t)
+	AuditLog = false
+
+	ExecuteTool("list_files", map[string]interface{}{"path": t.TempDir()})
+	if entries, _, _ := audit.Tail(0); len(entries) != 0 {
+		t.Errorf("Expected nothing recorded with the audit log off, got %+v", entries)
+	}
+}
+
+func TestAuditParamsRedactsSecrets(t *testing.T) {
+	params := auditParams(map[string]interface{}{
+		"command": "curl -H 'Authorization: Bearer abcdefghijklmnop' example.com",
+		"timeout": 5.0,
+	})
+	if strings.Contains(params["command"].(string), "abcdefghijklmnop") {
+		t.Errorf("Expected the token to be redacted, got %q", params["command"])
+	}
+	if params["timeout"] != 5.0 {
+		t.Errorf("Expected other values to be kept, got %v", params["timeout"])
+	}
+}
@@ -0,0 +1,46 @@
+package ai
+
+import "strings"
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when the config
+// sets none
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// NewAzureOpenAIProvider returns an OpenAI provider for models hosted on Azure.
+// Azure serves each model from a deployment the user names, so model is the
+// deployment name. resource is the Azure resource name, as in
+// https://{resource}.openai.azure.com, or a full endpoint URL.
+func NewAzureOpenAIProvider(apiKey, resource, model string, temperature float64, maxTokens int) *OpenAIProvider {
+	p := NewOpenAIProvider(apiKey, model, temperature, maxTokens)
+	p.Azure = true
+	p.APIVersion = DefaultAzureAPIVersion
+	p.BaseURL = AzureEndpoint(resource)
+	return p
+}
+
+// AzureEndpoint returns the API root of an Azure OpenAI resource, given its
+// name or its endpoint URL
+func AzureEndpoint(resource string) string {
+	if resource == "" {
+		return ""
+	}
+	if strings.Contains(resource, "://") {
+		return strings.TrimSuffix(resource, "/") + "/openai"
+	}
+	return "https://" + resource + ".openai.azure.com/openai"
+}
+
+// applyAzureOptions points an Azure OpenAI provider at the configured
+// resource and API version
+func applyAzureOptions(provider Provider, cfg interface{}) {
+	p, ok := provider.(*OpenAIProvider)
+	if !ok || !p.Azure {
+		return
+	}
+	if ar, ok := cfg.(interface{ GetAzureResource() string }); ok {
+		p.BaseURL = AzureEndpoint(ar.GetAzureResource())
+	}
+	if av, ok := cfg.(interface{ GetAzureAPIVersion() string }); ok && av.GetAzureAPIVersion() != "" {
+		p.APIVersion = av.GetAzureAPIVersion()
+	}
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type azureConfig struct {
+	testConfig
+	resource, apiVersion string
+}
+
+func (c *azureConfig) GetAzureResource() string   { return c.resource }
+func (c *azureConfig) GetAzureAPIVersion() string { return c.apiVersion }
+
+func TestAzureEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"contoso":                     "https://contoso.openai.azure.com/openai",
+		"https://ai.contoso.example/": "https://ai.contoso.example/openai",
+		"http://localhost:8080":       "http://localhost:8080/openai",
+		"":                            "",
+	}
+	for resource, want := range tests {
+		if got := AzureEndpoint(resource); got != want {
+			t.Errorf("AzureEndpoint(%q) = %q, want %q", resource, got, want)
+		}
+	}
+}
+
+func TestAzureOpenAIProviderFromConfig(t *testing.T) {
+	cfg := &azureConfig{testConfig: testConfig{provider: "azure-openai", model: "chat-prod"}, resource: "contoso", apiVersion: "2024-06-01"}
+	provider, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p, ok := provider.(*OpenAIProvider)
+	if !ok || !p.Azure {
+		t.Fatalf("Expected an Azure OpenAI provider, got %T", provider)
+	}
+	if p.GetName() != "Azure OpenAI" {
+		t.Errorf("Expected name Azure OpenAI, got %s", p.GetName())
+	}
+	want := "https://contoso.openai.azure.com/openai/deployments/chat-prod/chat/completions?api-version=2024-06-01"
+	if got := p.endpoint(p.Model, "chat/completions"); got != want {
+		t.Errorf("Expected chat endpoint %s, got %s", want, got)
+	}
+}
+
+func TestAzureOpenAIProviderEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/embed-prod/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if v := r.URL.Query().Get("api-version"); v != DefaultAzureAPIVersion {
+			t.Errorf("Expected api-version %s, got %q", DefaultAzureAPIVersion, v)
+		}
+		if r.Header.Get("api-key") != "azure-key" || r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected the key in api-key only, got %v", r.Header)
+		}
+		w.Write([]byte(`{"data": [{"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewAzureOpenAIProvider("azure-key", server.URL, "chat-prod", 0.7, 100)
+	provider.EmbeddingModel = "embed-prod"
+	if _, err := provider.Embed(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+}
+
+func TestAzureEmbedderFromConfig(t *testing.T) {
+	cfg := &azureConfig{testConfig: testConfig{provider: "azure-openai", model: "chat-prod"}, resource: "contoso"}
+	embedder, err := NewEmbedderFromConfig(cfg, "embed-prod")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "https://contoso.openai.azure.com/openai/deployments/embed-prod/embeddings?api-version=" + DefaultAzureAPIVersion
+	if p := embedder.(*OpenAIProvider); p.endpoint(p.embeddingModel(), "embeddings") != want {
+		t.Errorf("Expected embeddings at %s, got %s", want, p.endpoint(p.embeddingModel(), "embeddings"))
+	}
+}
+
+func TestAzureOpenAIProviderChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/openai/deployments/chat-prod/chat/completions" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if v := r.URL.Query().Get("api-version"); v != "2024-06-01" {
+			t.Errorf("Expected api-version 2024-06-01, got %q", v)
+		}
+		if r.Header.Get("api-key") != "azure-key" || r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected the key in api-key only, got %v", r.Header)
+		}
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi from Azure"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &azureConfig{testConfig: testConfig{provider: "azure-openai", model: "chat-prod"}, resource: server.URL, apiVersion: "2024-06-01"}
+	provider, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p := provider.(*OpenAIProvider)
+	p.APIKey = "azure-key"
+	response, err := p.GenerateResponse(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if response != "Hi from Azure" {
+		t.Errorf("Expected the deployment's reply, got %q", response)
+	}
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tala/internal/retrieval"
+)
+
+const (
+	// DefaultCacheSimilarity is how similar a prompt must be to a cached one to
+	// reuse its response, as cosine similarity of their embeddings
+	DefaultCacheSimilarity = 0.95
+	// CacheMaxTemperature is the highest temperature whose responses are cached;
+	// above it a repeated question is expected to get a different answer
+	CacheMaxTemperature = 0.5
+	// maxCacheEntries bounds the cache; the oldest entries are dropped first
+	maxCacheEntries = 200
+)
+
+// CacheFile overrides where cached responses are stored, mainly for tests. When
+// empty they live in $XDG_CACHE_HOME/tala/responses.json (~/.cache/tala).
+var CacheFile = ""
+
+// CacheConfig is the part of the configuration that sets up the semantic cache
+type CacheConfig interface {
+	GetSemanticCache() bool
+	GetCacheSimilarity() float64
+}
+
+// responseCache serves similar prompts from earlier responses; nil when disabled
+var responseCache *SemanticCache
+
+// SemanticCache stores responses with the embedding of their prompt, so a
+// paraphrase of an earlier question can be answered without the model
+type SemanticCache struct {
+	Similarity float64
+
+	mu      sync.Mutex
+	path    string
+	entries []cacheEntry
+}
+
+type cacheEntry struct {
+	Key      string    `json:"key"` // provider, model and system prompt the response came from
+	Prompt   string    `json:"prompt"`
+	Vector   []float32 `json:"vector"`
+	Response string    `json:"response"`
+	Time     time.Time `json:"time"`
+}
+
+// ConfigureCache turns the semantic cache on or off from the configuration
+func ConfigureCache(cfg CacheConfig) {
+	responseCache = nil
+	if !cfg.GetSemanticCache() {
+		return
+	}
+	cache, err := OpenSemanticCache(cfg.GetCacheSimilarity())
+	if err != nil {
+		slog.Warn("semantic cache disabled", "error", err)
+		return
+	}
+	responseCache = cache
+}
+
+// OpenSemanticCache loads the stored cache; a similarity of 0 means the default
+func OpenSemanticCache(similarity float64) (*SemanticCache, error) {
+	if similarity <= 0 {
+		similarity = DefaultCacheSimilarity
+	}
+	path := CacheFile
+	if path == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "tala", "responses.json")
+	}
+
+	cache := &SemanticCache{Similarity: similarity, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		// A damaged cache only costs the stored responses
+		slog.Warn("ignoring unreadable cache", "file", path, "error", err)
+	}
+	return cache, nil
+}
+
+// Lookup returns the cached response whose prompt is most similar to vector,
+// if it came from the same setup and is similar enough
+func (c *SemanticCache) Lookup(key string, vector []float32) (string, float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	best, bestScore := -1, 0.0
+	for i, entry := range c.entries {
+		if entry.Key != key {
+			continue
+		}
+		if score := retrieval.Cosine(vector, entry.Vector); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || bestScore < c.Similarity {
+		return "", bestScore, false
+	}
+	return c.entries[best].Response, bestScore, true
+}
+
+// Store adds a response and saves the cache
+func (c *SemanticCache) Store(key, prompt string, vector []float32, response string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, cacheEntry{Key: key, Prompt: prompt, Vector: vector, Response: response, Time: time.Now()})
+	if len(c.entries) > maxCacheEntries {
+		c.entries = c.entries[len(c.entries)-maxCacheEntries:]
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// cacheKey identifies the setup a response came from; a different model or
+// system prompt answers differently, so their responses are never shared
+func cacheKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SemanticCacheMiddleware answers a prompt from the cache when an earlier one
+// was similar enough, and caches new responses. Prompts are embedded with the
+// provider; without embeddings the cache is skipped. Requests that may run
+// tools and follow-ups in a conversation always go to the provider, since the
+// same words can need a different answer there.
+func SemanticCacheMiddleware(cache *SemanticCache, provider Provider, key string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			if usesTools(ctx) || hasConversation(provider) {
+				return next(ctx, prompt)
+			}
+			vectors, err := provider.Embed(ctx, []string{prompt})
+			if err != nil || len(vectors) != 1 {
+				if !errors.Is(err, ErrNotSupported) {
+					slog.Debug("cache skipped", "error", err)
+				}
+				return next(ctx, prompt)
+			}
+
+			if response, score, ok := cache.Lookup(key, vectors[0]); ok {
+				slog.Debug("cache hit", "similarity", score)
+				if Metrics != nil {
+					Metrics.Add("tala_cache_hits_total", "Responses served from the semantic cache.", 1, "provider", provider.GetName())
+				}
+				return response, nil
+			}
+
+			response, err := next(ctx, prompt)
+			if err == nil && response != "" {
+				if _, truncated := StripTruncationNote(response); !truncated {
+					if err := cache.Store(key, prompt, vectors[0], response); err != nil {
+						slog.Warn("caching response", "error", err)
+					}
+				}
+			}
+			return response, err
+		}
+	}
+}
+
+// hasConversation reports whether the provider sends earlier turns with a prompt
+func hasConversation(provider Provider) bool {
+	p, ok := UnwrapProvider(provider).(*OllamaProvider)
+	return ok && len(p.History) > 0
+}
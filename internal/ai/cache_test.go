@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// embeddingProvider embeds prompts by the topic words they mention and counts
+// the requests that reach it
+type embeddingProvider struct {
+	*MockProvider
+	requests int
+}
+
+func (p *embeddingProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	p.requests++
+	return "answer to " + prompt, nil
+}
+
+func (p *embeddingProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	response, err := p.GenerateResponse(ctx, prompt)
+	callback(response)
+	return response, err
+}
+
+func (p *embeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		vectors[i] = []float32{0, 0, 0.1}
+		if strings.Contains(text, "capital") {
+			vectors[i][0] = 1
+		}
+		if strings.Contains(text, "weather") {
+			vectors[i][1] = 1
+		}
+	}
+	return vectors, nil
+}
+
+func TestSemanticCacheMiddleware(t *testing.T) {
+	CacheFile = filepath.Join(t.TempDir(), "responses.json")
+	defer func() { CacheFile = "" }()
+
+	cache, err := OpenSemanticCache(0)
+	if err != nil {
+		t.Fatalf("OpenSemanticCache failed: %v", err)
+	}
+	inner := &embeddingProvider{MockProvider: NewMockProvider("test")}
+	provider := WrapProvider(inner, SemanticCacheMiddleware(cache, inner, cacheKey("mock", "test")))
+	ctx := context.Background()
+
+	first, _ := provider.GenerateResponse(ctx, "What is the capital of France?")
+	second, _ := provider.GenerateResponse(ctx, "Tell me France's capital city")
+	if inner.requests != 1 || second != first {
+		t.Errorf("Expected the paraphrase to be served from the cache, got %d requests and %q", inner.requests, second)
+	}
+
+	provider.GenerateResponse(ctx, "How is the weather?")
+	if inner.requests != 2 {
+		t.Errorf("Expected a different question to reach the provider, got %d requests", inner.requests)
+	}
+
+	// Cached responses reach streaming callbacks too
+	var streamed string
+	provider.GenerateStreamingResponse(ctx, "capital of France, please", func(chunk string) { streamed += chunk })
+	if inner.requests != 2 || streamed != first {
+		t.Errorf("Expected the cached response as one chunk, got %q after %d requests", streamed, inner.requests)
+	}
+
+	// Another model or system prompt does not share the cache
+	other := WrapProvider(inner, SemanticCacheMiddleware(cache, inner, cacheKey("mock", "other")))
+	other.GenerateResponse(ctx, "What is the capital of France?")
+	if inner.requests != 3 {
+		t.Errorf("Expected a different setup to miss the cache, got %d requests", inner.requests)
+	}
+
+	// The cache survives a restart
+	reopened, err := OpenSemanticCache(0)
+	if err != nil {
+		t.Fatalf("Reopening the cache failed: %v", err)
+	}
+	vectors, _ := inner.Embed(ctx, []string{"capital?"})
+	if response, _, ok := reopened.Lookup(cacheKey("mock", "test"), vectors[0]); !ok || response != first {
+		t.Errorf("Expected the stored response after reopening, got %q, %v", response, ok)
+	}
+}
+
+func TestSemanticCacheSkipsToolRequests(t *testing.T) {
+	CacheFile = filepath.Join(t.TempDir(), "responses.json")
+	defer func() { CacheFile = "" }()
+
+	cache, _ := OpenSemanticCache(0)
+	inner := &embeddingProvider{MockProvider: NewMockProvider("test")}
+	var reached int
+	provider := WrapProvider(inner, SemanticCacheMiddleware(cache, inner, "key"), func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			reached++
+			return next(ctx, prompt)
+		}
+	})
+
+	provider.GenerateResponseWithTools(context.Background(), "What is the capital of France?")
+	provider.GenerateResponseWithTools(context.Background(), "What is the capital of France?")
+	if reached != 2 {
+		t.Errorf("Expected both tool requests to reach the provider, got %d", reached)
+	}
+}
+
+func TestSemanticCacheSkipsHighTemperature(t *testing.T) {
+	CacheFile = filepath.Join(t.TempDir(), "responses.json")
+	defer func() { CacheFile = "" }()
+
+	cache, _ := OpenSemanticCache(0)
+	responseCache = cache
+	defer func() { responseCache = nil }()
+
+	// testConfig uses temperature 0.7, above CacheMaxTemperature
+	provider, err := CreateProviderFromConfig(&testConfig{provider: "mock", model: "test"})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig failed: %v", err)
+	}
+	if _, wrapped := provider.(*wrappedProvider); wrapped {
+		t.Error("Expected no cache for a high-temperature provider")
+	}
+}
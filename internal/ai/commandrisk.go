@@ -0,0 +1,402 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// CommandRisk is how execute_command treats a shell command
+type CommandRisk int
+
+const (
+	// RiskSafe commands only read, so they run without asking
+	RiskSafe CommandRisk = iota
+	// RiskRisky commands may write, delete or reach the network, so the user confirms them
+	RiskRisky
+	// RiskBlocked commands never run
+	RiskBlocked
+)
+
+func (r CommandRisk) String() string {
+	switch r {
+	case RiskSafe:
+		return "safe"
+	case RiskRisky:
+		return "risky"
+	}
+	return "blocked"
+}
+
+// CommandPolicy holds the command patterns of each tier. A pattern is a
+// program followed by the options and arguments that make it match: "git push"
+// matches "git push origin main", "rm -r /" matches "rm -fr /" and
+// "find -delete" matches "find . -name '*.tmp' -delete".
+type CommandPolicy struct {
+	Safe    []string
+	Risky   []string
+	Blocked []string
+}
+
+// DefaultCommandPolicy is used for commands the configuration does not mention.
+// Anything matching none of its patterns is risky.
+var DefaultCommandPolicy = CommandPolicy{
+	Safe: []string{
+		"ls", "dir", "pwd", "echo", "cat", "head", "tail", "less", "more",
+		"grep", "find", "which", "where", "type", "file", "stat", "tree",
+		"date", "whoami", "id", "uptime", "uname", "hostname",
+		"ps", "top -b -n 1", "df", "du", "free", "lscpu", "lsblk", "printenv", "env", "xargs",
+		"wc", "sort", "uniq", "cut", "sed", "diff", "cmp", "md5sum", "sha256sum",
+		"git status", "git log", "git branch", "git diff", "git show", "git remote -v",
+		"go version", "go list", "go env", "go vet", "go doc",
+		"npm list", "npm version",
+	},
+	Risky: []string{
+		"rm", "rmdir", "mv", "cp", "mkdir", "touch", "ln", "chmod", "chown", "truncate", "tee",
+		"sed -i", "sort -o", "find -delete", "find -exec", "find -execdir", "find -ok", "find -okdir", "find -fprint", "find -fls",
+		"git branch -d", "git branch -D", "git branch -m", "git tag -d",
+		"git diff --output", "git log --output", "git show --output",
+		"curl", "wget", "ssh", "scp", "sftp", "ftp", "rsync", "nc", "netcat", "telnet", "ping",
+		"git push", "git pull", "git fetch", "git clone", "git commit", "git checkout",
+		"git reset", "git clean", "git rebase", "git merge", "git stash",
+		"npm install", "npm run", "pip install", "go get", "go install", "go build", "go run", "go test", "make",
+		"kill", "pkill", "killall", "systemctl", "service", "crontab", "at",
+		"sh", "bash", "zsh", "python", "python3", "perl", "ruby", "node", "eval",
+	},
+	Blocked: []string{
+		"rm -r /", "rm -r /*", "rm -r ~", "rm -r ~/*", "chmod -R /", "chown -R /",
+		"sudo", "su", "doas", "passwd", "useradd", "userdel", "usermod",
+		"mkfs", "dd", "fdisk", "parted", "format", "mount", "umount",
+		"shutdown", "reboot", "halt", "poweroff",
+		":(){",
+	},
+}
+
+// CommandPolicyConfig is the part of the configuration that extends the tiers
+type CommandPolicyConfig interface {
+	GetSafeCommands() []string
+	GetRiskyCommands() []string
+	GetBlockedCommands() []string
+}
+
+// ConfirmCommand asks the user whether a risky command may run. When nil, as
+// in headless mode, risky commands are refused.
+var ConfirmCommand func(command, reason string) bool
+
+// commandPolicy holds the configured patterns, checked before the defaults
+var commandPolicy CommandPolicy
+
+// ConfigureCommandPolicy sets the user's command patterns
+func ConfigureCommandPolicy(cfg CommandPolicyConfig) {
+	commandPolicy = CommandPolicy{
+		Safe:    cfg.GetSafeCommands(),
+		Risky:   cfg.GetRiskyCommands(),
+		Blocked: cfg.GetBlockedCommands(),
+	}
+}
+
+// ClassifyCommand decides the tier of a shell command and says why. The
+// command is parsed into argv the way the shell would, so quoting, extra
+// spaces or a full program path do not change the verdict. Chained, piped and
+// nested commands take the tier of their riskiest part. Blocked patterns
+// always win; otherwise the configured tiers come before the built-in ones,
+// so a user can allow a command the defaults consider risky.
+func ClassifyCommand(command string) (CommandRisk, string) {
+	if strings.TrimSpace(command) == "" {
+		return RiskBlocked, "empty command"
+	}
+	return classifyLine(command, 0)
+}
+
+// maxShellNesting bounds how deep substitutions and sh -c are followed
+const maxShellNesting = 5
+
+// classifyLine places a command line in the tier of its riskiest command
+func classifyLine(line string, depth int) (CommandRisk, string) {
+	if depth > maxShellNesting {
+		return RiskRisky, "nests commands too deeply to check"
+	}
+	commands, err := parseShell(line)
+	if err != nil {
+		return RiskRisky, "cannot be parsed: " + err.Error()
+	}
+
+	risk, reason := RiskSafe, ""
+	raise := func(r CommandRisk, why string) {
+		if r > risk {
+			risk, reason = r, why
+		}
+	}
+	for _, command := range commands {
+		for _, file := range command.Writes {
+			if file != "/dev/null" && file != "/dev/stdout" && file != "/dev/stderr" {
+				raise(RiskRisky, fmt.Sprintf("writes to %s", file))
+			}
+		}
+		for _, nested := range command.Nested {
+			raise(classifyLine(nested, depth+1))
+		}
+
+		args, wrapped := unwrapCommand(command.Args)
+		for _, line := range wrapped {
+			raise(classifyLine(line, depth+1))
+		}
+		if len(args) == 0 {
+			continue
+		}
+		raise(classifyArgs(args))
+
+		// Shells and eval run their argument as another command line
+		switch args[0] {
+		case "sh", "bash", "zsh", "dash", "ksh":
+			for i, arg := range args[:len(args)-1] {
+				if arg == "-c" {
+					raise(classifyLine(args[i+1], depth+1))
+				}
+			}
+		case "eval":
+			raise(classifyLine(strings.Join(args[1:], " "), depth+1))
+		}
+	}
+	return risk, reason
+}
+
+// shellKeywords may start a simple command without being the program
+var shellKeywords = map[string]bool{
+	"!": true, "{": true, "}": true, "if": true, "then": true, "else": true, "elif": true,
+	"fi": true, "do": true, "done": true, "while": true, "until": true,
+}
+
+// commandWrappers run the command that follows their own options: valueFlags
+// take an argument, commandFlags take a whole command line, like env -S, and
+// positional counts arguments before the command, like timeout's duration
+var commandWrappers = map[string]struct {
+	valueFlags   []string
+	commandFlags []string
+	positional   int
+}{
+	"env":     {valueFlags: []string{"-u", "-C", "--unset", "--chdir"}, commandFlags: []string{"-S", "--split-string"}},
+	"nice":    {valueFlags: []string{"-n"}},
+	"ionice":  {valueFlags: []string{"-c", "-n"}},
+	"nohup":   {},
+	"time":    {},
+	"command": {},
+	"builtin": {},
+	"exec":    {valueFlags: []string{"-a"}},
+	"stdbuf":  {valueFlags: []string{"-i", "-o", "-e"}},
+	"timeout": {valueFlags: []string{"-s", "-k"}, positional: 1},
+	"xargs": {valueFlags: []string{"-I", "-n", "-P", "-d", "-L", "-s", "-E", "-a",
+		"--max-args", "--max-procs", "--delimiter", "--max-lines", "--max-chars", "--arg-file"}},
+}
+
+// unwrapCommand returns the argv of the program that actually runs: leading
+// variable assignments, keywords and wrappers like env or nice are removed,
+// and the program is reduced to its lowercase name without a path. Command
+// lines given to a wrapper's options, as in env -S, are returned in nested.
+func unwrapCommand(args []string) (argv, nested []string) {
+	for len(args) > 0 {
+		if shellKeywords[args[0]] || isAssignment(args[0]) {
+			args = args[1:]
+			continue
+		}
+		args = append([]string{programName(args[0])}, args[1:]...)
+		wrapper, ok := commandWrappers[args[0]]
+		if !ok || len(args) == 1 {
+			return args, nested
+		}
+
+		rest := args[1:]
+		for len(rest) > 0 && (strings.HasPrefix(rest[0], "-") || (args[0] == "env" && isAssignment(rest[0]))) {
+			flag := rest[0]
+			rest = rest[1:]
+			if flag == "--" {
+				break
+			}
+			if name, value, ok := strings.Cut(flag, "="); ok {
+				if containsString(wrapper.commandFlags, name) {
+					nested = append(nested, value)
+				}
+				continue
+			}
+			if len(rest) > 0 && containsString(wrapper.commandFlags, flag) {
+				nested = append(nested, rest[0])
+				rest = rest[1:]
+			} else if len(rest) > 0 && containsString(wrapper.valueFlags, flag) {
+				rest = rest[1:]
+			}
+		}
+		if len(rest) > wrapper.positional {
+			rest = rest[wrapper.positional:]
+		}
+		if len(rest) == 0 {
+			return args[:1], nested // the wrapper alone, like env printing the environment
+		}
+		args = rest
+	}
+	return nil, nested
+}
+
+// programName reduces /bin/rm or RM.EXE to rm
+func programName(program string) string {
+	program = strings.ToLower(path.Base(strings.ReplaceAll(program, "\\", "/")))
+	return strings.TrimSuffix(program, ".exe")
+}
+
+// isAssignment reports whether a word is a NAME=value variable assignment
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyArgs places one program and its arguments in a tier
+func classifyArgs(args []string) (CommandRisk, string) {
+	if pattern, ok := matchCommand(args, false, commandPolicy.Blocked, DefaultCommandPolicy.Blocked); ok {
+		return RiskBlocked, fmt.Sprintf("matches blocked pattern %q", pattern)
+	}
+	if pattern, ok := matchCommand(args, false, commandPolicy.Risky); ok {
+		return RiskRisky, fmt.Sprintf("matches risky pattern %q", pattern)
+	}
+	if _, ok := matchCommand(args, true, commandPolicy.Safe); ok {
+		return RiskSafe, ""
+	}
+	if len(args) == 2 {
+		switch args[1] {
+		case "--version", "-version", "--help", "-h":
+			return RiskSafe, "" // asking for the version or usage
+		}
+	}
+	if args[0] == "sed" {
+		if reason := sedRisk(args); reason != "" {
+			return RiskRisky, reason
+		}
+	}
+	if pattern, ok := matchCommand(args, false, DefaultCommandPolicy.Risky); ok {
+		return RiskRisky, fmt.Sprintf("matches risky pattern %q", pattern)
+	}
+	if _, ok := matchCommand(args, true, DefaultCommandPolicy.Safe); ok {
+		return RiskSafe, ""
+	}
+	return RiskRisky, fmt.Sprintf("%q is not a known safe command", args[0])
+}
+
+// matchCommand returns the first pattern the command is an instance of
+func matchCommand(args []string, strict bool, lists ...[]string) (string, bool) {
+	for _, patterns := range lists {
+		for _, pattern := range patterns {
+			if matchPattern(args, pattern, strict) {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// longFlagLetters are long options with the same meaning as a short one, so
+// rm --recursive --force / matches the pattern "rm -rf /"
+var longFlagLetters = map[string]string{
+	"--recursive": "-r",
+	"--force":     "-f",
+	"--in-place":  "-i",
+	"--output":    "-o",
+}
+
+// matchPattern reports whether argv is an instance of a pattern such as
+// "rm -rf /" or "git push". The program must be the same and every option of
+// the pattern present, in any order or spelling: -rf, -fr, -r -f and
+// --recursive --force are alike. The pattern's other words must appear among
+// the arguments in order; when strict they must be the first ones, so the safe
+// "git status" does not cover "git push status".
+func matchPattern(args []string, pattern string, strict bool) bool {
+	words := strings.Fields(pattern)
+	if len(words) == 0 || programName(words[0]) != args[0] {
+		return false
+	}
+
+	flags := make(map[string]bool)
+	var operands []string
+	endOfFlags := false
+	for _, arg := range args[1:] {
+		if endOfFlags || !isFlag(arg) {
+			operands = append(operands, normalizeOperand(arg))
+			continue
+		}
+		if arg == "--" {
+			endOfFlags = true
+			continue
+		}
+		name, _, _ := strings.Cut(arg, "=")
+		flags[name] = true
+		if short, ok := longFlagLetters[name]; ok {
+			flags[short] = true
+		}
+		if !strings.HasPrefix(arg, "--") {
+			for _, c := range arg[1:] {
+				if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+					break
+				}
+				flags["-"+string(c)] = true
+			}
+		}
+	}
+
+	next := 0
+	for _, word := range words[1:] {
+		if isFlag(word) {
+			if flags[word] {
+				continue
+			}
+			if strings.HasPrefix(word, "--") {
+				return false
+			}
+			for _, c := range word[1:] {
+				if !flags["-"+string(c)] {
+					return false
+				}
+			}
+			continue
+		}
+		word = normalizeOperand(word)
+		if strict {
+			if next >= len(operands) || operands[next] != word {
+				return false
+			}
+			next++
+			continue
+		}
+		for next < len(operands) && operands[next] != word {
+			next++
+		}
+		if next == len(operands) {
+			return false
+		}
+		next++
+	}
+	return true
+}
+
+// isFlag reports whether an argument is an option rather than an operand
+func isFlag(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// normalizeOperand makes paths that name the same place compare equal:
+// //, /tmp/.. and / are all /, and $HOME is ~
+func normalizeOperand(operand string) string {
+	for _, home := range []string{"${HOME}", "$HOME"} {
+		if operand == home || strings.HasPrefix(operand, home+"/") {
+			operand = "~" + strings.TrimPrefix(operand, home)
+		}
+	}
+	if strings.HasPrefix(operand, "/") || strings.HasPrefix(operand, "~") {
+		return path.Clean(operand)
+	}
+	return operand
+}
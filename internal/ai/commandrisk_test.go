@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type commandPolicyConfig struct {
+	safe, risky, blocked []string
+}
+
+func (c commandPolicyConfig) GetSafeCommands() []string    { return c.safe }
+func (c commandPolicyConfig) GetRiskyCommands() []string   { return c.risky }
+func (c commandPolicyConfig) GetBlockedCommands() []string { return c.blocked }
+
+func TestClassifyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    CommandRisk
+	}{
+		{"ls -la", RiskSafe},
+		{"git status --short", RiskSafe},
+		{"/bin/cat README.md", RiskSafe},
+		{"ls | grep go", RiskSafe},
+		{"go build ./... 2>&1", RiskRisky},
+		{"ls 2>/dev/null", RiskSafe},
+		{"python3 --version", RiskSafe},
+		{"rm notes.txt", RiskRisky},
+		{"git push origin main", RiskRisky},
+		{"curl https://example.com", RiskRisky},
+		{"echo hi > notes.txt", RiskRisky},
+		{"ls && rm notes.txt", RiskRisky},
+		{"echo $(whoami)", RiskSafe},
+		{"echo $(rm notes.txt)", RiskRisky},
+		{"frobnicate --all", RiskRisky},
+		{"rm -rf /", RiskBlocked},
+		{"sudo ls", RiskBlocked},
+		{"ls; shutdown now", RiskBlocked},
+		{"", RiskBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got, reason := ClassifyCommand(tt.command)
+			if got != tt.want {
+				t.Errorf("ClassifyCommand(%q) = %v (%s), want %v", tt.command, got, reason, tt.want)
+			}
+			if got != RiskSafe && reason == "" {
+				t.Errorf("Expected a reason for a %v command", got)
+			}
+		})
+	}
+}
+
+func TestClassifyCommandEvasions(t *testing.T) {
+	blocked := []string{
+		"rm  -rf   /",
+		"/bin/rm -rf /",
+		"rm -r -f /",
+		"rm -fr /",
+		"rm --recursive --force /",
+		"rm -rf -- /",
+		`"rm" -rf /`,
+		"r''m -rf /",
+		`\rm -rf /`,
+		"rm -rf //",
+		"rm -rf /tmp/..",
+		`rm -rf "$HOME"`,
+		"rm -rf ~/",
+		"RM.EXE -rf /",
+		"env rm -rf /",
+		"FOO=1 rm -rf /",
+		"nice -n 5 rm -rf /",
+		"timeout 10 sudo ls",
+		"ls; sudo reboot",
+		"ls\nsudo reboot",
+		"ls | xargs sudo rm",
+		"echo $(sudo id)",
+		"echo `sudo id`",
+		`echo "$(sudo id)"`,
+		"cat <(sudo cat /etc/shadow)",
+		`bash -c "rm -rf /"`,
+		"sh -c 'sudo ls'",
+		`eval "sudo ls"`,
+		"(sudo ls)",
+		"ls && { sudo ls; }",
+		"ls & sudo ls",
+		"env -S 'sudo ls'",
+	}
+	for _, command := range blocked {
+		if got, reason := ClassifyCommand(command); got != RiskBlocked {
+			t.Errorf("ClassifyCommand(%q) = %v (%s), want blocked", command, got, reason)
+		}
+	}
+
+	risky := []string{
+		"find . -name '*.tmp' -delete",
+		"find . -exec rm {} ;",
+		"sed -e s/a/b/ -i notes.txt",
+		"sort -o sorted.txt notes.txt",
+		"git branch -D feature",
+		"echo hi >notes.txt",
+		"echo hi 1>>notes.txt",
+		"ls &>listing.txt",
+		"git push status",
+		"$CMD notes.txt",
+		`echo "unterminated`,
+		"timeout 5 rm notes.txt",
+		"xargs rm -rf x",
+		"xargs --max-args 1 rm -rf x",
+		"env -S 'rm -rf x'",
+		"env --split-string='rm -rf x'",
+		"top",
+		"sed -n '1e rm -rf ~' go.mod",
+		"sed 's/a/b/w /tmp/out' go.mod",
+		"sed 's/a/b/e' go.mod",
+		"sed -n 'w /tmp/out' go.mod",
+		"sed -n '/x/W /tmp/out' go.mod",
+		"sed -e p -e '$e id' go.mod",
+		"sed -ne '1e id' go.mod",
+		"sed --expression='s|a|b|gw out' go.mod",
+		"sed '/a/{s/a/b/;e id' go.mod",
+		"sed -f script.sed go.mod",
+		"git diff --output=/tmp/x",
+		"git log -p --output /tmp/x",
+		"git show --output=/tmp/x HEAD",
+	}
+	for _, command := range risky {
+		if got, reason := ClassifyCommand(command); got != RiskRisky {
+			t.Errorf("ClassifyCommand(%q) = %v (%s), want risky", command, got, reason)
+		}
+	}
+}
+
+func TestClassifyCommandLookalikes(t *testing.T) {
+	// Commands that only mention dangerous words are safe
+	safe := []string{
+		"cat mount",
+		"ls formatting/",
+		`grep "rm -rf /" notes.txt`,
+		"grep -r sudo .",
+		`echo "sudo is blocked; rm -rf / too"`,
+		"echo 'a > b'",
+		"git log --grep=push",
+		"find . -name '*.go'",
+		"ls 2>&1 | grep go",
+		"ls >/dev/null 2>/dev/null",
+		"ls # rm -rf /",
+		"env",
+		"env -S 'ls -la'",
+		"top -b -n 1",
+		"sed s/a/b/ notes.txt",
+		"sed -n '1,10p' go.mod",
+		"sed 's/hello/world/g' notes.txt",
+		"sed -E 's/(we)+/\\1/2' notes.txt",
+		"sed -n '/^func /p' main.go",
+		"sed '$a the end' notes.txt",
+		"sed -e 's/a/b/' -e '/x/d' notes.txt",
+		"sed 'y/abc/xyz/' notes.txt",
+		"sed 's|/usr/bin|/opt/bin|' paths.txt",
+		"git diff --stat",
+		"ls -la /tmp",
+	}
+	for _, command := range safe {
+		if got, reason := ClassifyCommand(command); got != RiskSafe {
+			t.Errorf("ClassifyCommand(%q) = %v (%s), want safe", command, got, reason)
+		}
+	}
+
+	// rm on an ordinary path is risky, not blocked
+	for _, command := range []string{"rm -rf /tmp/build", "rm -rf ./dist", "rm -r ~/old-notes"} {
+		if got, reason := ClassifyCommand(command); got != RiskRisky {
+			t.Errorf("ClassifyCommand(%q) = %v (%s), want risky", command, got, reason)
+		}
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	commands, err := parseShell(`FOO="a b" grep -n 'x y' "a\"b" 2>&1 >out.txt | wc -l && echo $(date)`)
+	if err != nil {
+		t.Fatalf("parseShell failed: %v", err)
+	}
+	if len(commands) != 3 {
+		t.Fatalf("Expected 3 commands, got %+v", commands)
+	}
+	want := []string{"FOO=a b", "grep", "-n", "x y", `a"b`}
+	if strings.Join(commands[0].Args, "|") != strings.Join(want, "|") {
+		t.Errorf("Args = %q, want %q", commands[0].Args, want)
+	}
+	if len(commands[0].Writes) != 1 || commands[0].Writes[0] != "out.txt" {
+		t.Errorf("Writes = %q, want [out.txt]", commands[0].Writes)
+	}
+	if len(commands[2].Nested) != 1 || commands[2].Nested[0] != "date" {
+		t.Errorf("Nested = %q, want [date]", commands[2].Nested)
+	}
+
+	if _, err := parseShell("echo 'open"); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestConfiguredCommandPolicy(t *testing.T) {
+	ConfigureCommandPolicy(commandPolicyConfig{
+		safe:    []string{"make", "sudo"},
+		risky:   []string{"git log"},
+		blocked: []string{"git push --force"},
+	})
+	defer ConfigureCommandPolicy(commandPolicyConfig{})
+
+	tests := []struct {
+		command string
+		want    CommandRisk
+	}{
+		{"make test", RiskSafe},                  // the user allows what the defaults consider risky
+		{"git log -p", RiskRisky},                // and can make a safe command ask first
+		{"git push --force origin", RiskBlocked}, // or block one
+		{"git push origin", RiskRisky},
+		{"sudo ls", RiskBlocked}, // built-in blocked patterns cannot be allowed
+	}
+	for _, tt := range tests {
+		if got, reason := ClassifyCommand(tt.command); got != tt.want {
+			t.Errorf("ClassifyCommand(%q) = %v (%s), want %v", tt.command, got, reason, tt.want)
+		}
+	}
+}
+
+func TestExecuteShellCommandConfirmsRiskyCommands(t *testing.T) {
+	defer func() { ConfirmCommand = nil }()
+
+	ConfirmCommand = nil
+	if result := ExecuteShellCommand("printf ok", 5*time.Second); !strings.Contains(result, "needs confirmation") {
+		t.Errorf("Expected a risky command to be refused without a way to confirm, got %q", result)
+	}
+
+	var asked string
+	ConfirmCommand = func(command, reason string) bool {
+		asked = command
+		return false
+	}
+	if result := ExecuteShellCommand("printf ok", 5*time.Second); !strings.Contains(result, "declined") {
+		t.Errorf("Expected a declined command not to run, got %q", result)
+	}
+	if asked != "printf ok" {
+		t.Errorf("Expected the user to be asked about the command, got %q", asked)
+	}
+
+	ConfirmCommand = func(command, reason string) bool { return true }
+	if result := ExecuteShellCommand("printf ok", 5*time.Second); result != "ok" {
+		t.Errorf("Expected a confirmed command to run, got %q", result)
+	}
+
+	asked = ""
+	ConfirmCommand = func(command, reason string) bool {
+		asked = command
+		return true
+	}
+	if result := ExecuteShellCommand("sudo printf ok", 5*time.Second); !strings.Contains(result, "blocked") || asked != "" {
+		t.Errorf("Expected a blocked command to be refused without asking, got %q", result)
+	}
+	if result := ExecuteShellCommand("echo hello", 5*time.Second); strings.TrimSpace(result) != "hello" || asked != "" {
+		t.Errorf("Expected a safe command to run without asking, got %q", result)
+	}
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CompareTarget is one provider taking part in a comparison
+type CompareTarget struct {
+	Name     string // as the user wrote it, e.g. "ollama:llama3.2" or a profile name
+	Provider Provider
+}
+
+// CompareResult is one target's answer to a compared prompt
+type CompareResult struct {
+	Target   string
+	Response string
+	Duration time.Duration
+	Err      error
+}
+
+// Compare sends the prompt to every target at once and returns the answers in
+// the order of targets. Tools are not used, so every model answers the same
+// question rather than acting on it.
+func Compare(ctx context.Context, targets []CompareTarget, prompt string) []CompareResult {
+	results := make([]CompareResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target CompareTarget) {
+			defer wg.Done()
+			start := time.Now()
+			response, err := target.Provider.GenerateResponse(ctx, prompt)
+			results[i] = CompareResult{Target: target.Name, Response: response, Duration: time.Since(start), Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
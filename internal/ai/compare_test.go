@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowProvider answers after a delay, or fails
+type slowProvider struct {
+	scriptedProvider
+	delay time.Duration
+	err   error
+}
+
+func (p *slowProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return "", p.err
+	}
+	return p.scriptedProvider.GenerateResponse(ctx, prompt)
+}
+
+func TestCompare(t *testing.T) {
+	targets := []CompareTarget{
+		{Name: "slow", Provider: &slowProvider{scriptedProvider: scriptedProvider{responses: []string{"slow answer"}}, delay: 50 * time.Millisecond}},
+		{Name: "fast", Provider: &slowProvider{scriptedProvider: scriptedProvider{responses: []string{"fast answer"}}}},
+		{Name: "broken", Provider: &slowProvider{err: errors.New("connection refused")}},
+	}
+
+	start := time.Now()
+	results := Compare(context.Background(), targets, "question")
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("Expected targets to run concurrently, took %v", elapsed)
+	}
+
+	if len(results) != 3 || results[0].Target != "slow" || results[1].Target != "fast" || results[2].Target != "broken" {
+		t.Fatalf("Expected results in target order, got %+v", results)
+	}
+	if results[0].Response != "slow answer" || results[0].Duration < 50*time.Millisecond {
+		t.Errorf("Unexpected slow result: %+v", results[0])
+	}
+	if results[1].Response != "fast answer" || results[1].Err != nil {
+		t.Errorf("Unexpected fast result: %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("Expected the failing target to report its error")
+	}
+}
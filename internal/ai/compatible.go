@@ -0,0 +1,31 @@
+package ai
+
+import "strings"
+
+// NewOpenAICompatibleProvider returns an OpenAI provider for another server
+// speaking the OpenAI API, such as LM Studio, vLLM, LocalAI or OpenRouter.
+// baseURL is its API root, usually ending in /v1. Local servers often need no
+// key, so an empty apiKey sends no Authorization header.
+func NewOpenAICompatibleProvider(apiKey, baseURL, model string, temperature float64, maxTokens int) *OpenAIProvider {
+	p := NewOpenAIProvider(apiKey, model, temperature, maxTokens)
+	p.Compatible = true
+	p.BaseURL = strings.TrimSuffix(baseURL, "/")
+	return p
+}
+
+// applyEndpointOptions points a provider at the configured server: base_url
+// for OpenAI, OpenAI-compatible servers and Ollama, or the Azure resource
+func applyEndpointOptions(provider Provider, cfg interface{}) {
+	if bu, ok := cfg.(interface{ GetBaseURL() string }); ok && bu.GetBaseURL() != "" {
+		baseURL := strings.TrimSuffix(bu.GetBaseURL(), "/")
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			if !p.Azure {
+				p.BaseURL = baseURL
+			}
+		case *OllamaProvider:
+			p.BaseURL = baseURL
+		}
+	}
+	applyAzureOptions(provider, cfg)
+}
@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type endpointConfig struct {
+	testConfig
+	baseURL string
+}
+
+func (c *endpointConfig) GetBaseURL() string { return c.baseURL }
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header without a key, got %q", auth)
+		}
+		w.Write([]byte(`{"data": [{"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := CreateProviderFromConfig(&endpointConfig{testConfig: testConfig{provider: "openai-compatible", model: "qwen2.5-7b-instruct"}, baseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p := provider.(*OpenAIProvider)
+	p.APIKey = "" // LM Studio and most local servers take no key
+	if p.GetName() != "OpenAI-compatible" {
+		t.Errorf("Expected name OpenAI-compatible, got %s", p.GetName())
+	}
+	if got := p.endpoint(p.Model, "chat/completions"); got != server.URL+"/v1/chat/completions" {
+		t.Errorf("Expected the chat endpoint under base_url, got %s", got)
+	}
+	if _, err := p.Embed(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("Embed without a key failed: %v", err)
+	}
+}
+
+func TestBaseURLOverridesDefaults(t *testing.T) {
+	for _, providerType := range []string{"openai", "ollama"} {
+		provider, err := CreateProviderFromConfig(&endpointConfig{testConfig: testConfig{provider: providerType, model: "m"}, baseURL: "http://gpu-box:8000/"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got string
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			got = p.BaseURL
+		case *OllamaProvider:
+			got = p.BaseURL
+		}
+		if got != "http://gpu-box:8000" {
+			t.Errorf("Expected %s to use base_url, got %q", providerType, got)
+		}
+	}
+}
+
+func TestOpenAICompatibleProviderChat(t *testing.T) {
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, "Hello from LM Studio", &captured)
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider("", server.URL+"/v1", "qwen2.5-7b-instruct", 0.7, 0)
+	response, err := provider.GenerateResponse(context.Background(), "hello")
+	if err != nil || response != "Hello from LM Studio" {
+		t.Fatalf("Expected the server's reply, got %q (%v)", response, err)
+	}
+	if captured.Model != "qwen2.5-7b-instruct" {
+		t.Errorf("Expected the configured model, got %q", captured.Model)
+	}
+
+	var streamed string
+	response, err = provider.GenerateStreamingResponse(context.Background(), "hello", func(chunk string) { streamed += chunk })
+	if err != nil || response != "Hello from LM Studio" || streamed != response {
+		t.Errorf("Expected the streamed reply, got %q and %q (%v)", response, streamed, err)
+	}
+}
@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultContextBudget bounds how much of the context files is sent with each
+// request, in tokens (approximated by words)
+const DefaultContextBudget = 4000
+
+// ContextConfig is the part of the configuration that sets up context files
+type ContextConfig interface {
+	GetContextFiles() []string
+	GetContextTokenBudget() int
+}
+
+// ContextFile is a file whose contents are sent as system context with every request
+type ContextFile struct {
+	Name   string // as the user gave it
+	Path   string // absolute, so changing directory does not lose it
+	Tokens int    // approximate size when last read
+	Err    error  // why the file could not be read, if it could not
+
+	modTime time.Time
+	content string
+}
+
+var (
+	contextMu     sync.Mutex
+	contextFiles  []*ContextFile
+	contextBudget = DefaultContextBudget
+)
+
+// ConfigureContext replaces the context files with the configured ones. Files
+// that cannot be read are kept, with a warning, in case they appear later.
+func ConfigureContext(cfg ContextConfig) {
+	contextMu.Lock()
+	contextFiles = nil
+	contextBudget = cfg.GetContextTokenBudget()
+	if contextBudget <= 0 {
+		contextBudget = DefaultContextBudget
+	}
+	contextMu.Unlock()
+
+	for _, name := range cfg.GetContextFiles() {
+		if err := AddContextFile(name); err != nil {
+			slog.Warn("context file not readable", "file", name, "error", err)
+		}
+	}
+}
+
+// AddContextFile adds a file to the context sent with every request. It fails
+// if the file cannot be read, but a file that is already present is not an error.
+func AddContextFile(name string) error {
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	for _, file := range contextFiles {
+		if file.Path == path {
+			return file.refresh()
+		}
+	}
+	file := &ContextFile{Name: name, Path: path}
+	contextFiles = append(contextFiles, file)
+	return file.refresh()
+}
+
+// RemoveContextFile drops a context file by the name it was added with or its path
+func RemoveContextFile(name string) bool {
+	path, _ := filepath.Abs(name)
+
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	for i, file := range contextFiles {
+		if file.Name == name || file.Path == path {
+			contextFiles = append(contextFiles[:i], contextFiles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearContextFiles removes every context file
+func ClearContextFiles() {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	contextFiles = nil
+}
+
+// ContextFiles returns the context files, re-read if they changed on disk
+func ContextFiles() []ContextFile {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	files := make([]ContextFile, len(contextFiles))
+	for i, file := range contextFiles {
+		file.refresh()
+		files[i] = *file
+	}
+	return files
+}
+
+// ContextBudget returns the most tokens of context files sent with a request
+func ContextBudget() int {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	return contextBudget
+}
+
+// refresh re-reads the file when its modification time changed
+func (f *ContextFile) refresh() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		f.Err, f.content, f.Tokens = err, "", 0
+		return err
+	}
+	if info.IsDir() {
+		f.Err = fmt.Errorf("%s is a directory", f.Name)
+		return f.Err
+	}
+	if f.Err == nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		f.Err, f.content, f.Tokens = err, "", 0
+		return err
+	}
+	f.Err = nil
+	f.modTime = info.ModTime()
+	f.content = string(data)
+	f.Tokens = len(strings.Fields(f.content))
+	return nil
+}
+
+// withContext adds the context files to a system prompt, up to the token budget.
+// Files are re-read when they change, so edits apply to the next request.
+func withContext(systemPrompt string) string {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	if len(contextFiles) == 0 {
+		return systemPrompt
+	}
+
+	var b strings.Builder
+	remaining := contextBudget
+	for _, file := range contextFiles {
+		if file.refresh() != nil || file.content == "" {
+			continue
+		}
+		if remaining <= 0 {
+			break
+		}
+		content := file.content
+		if file.Tokens > remaining {
+			content = truncateWords(content, remaining) + "\n[... truncated to fit the context budget]"
+		}
+		remaining -= file.Tokens
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", file.Name, strings.TrimRight(content, "\n"))
+	}
+
+	if b.Len() == 0 {
+		return systemPrompt
+	}
+	context := "The user has shared these files as context:\n" + b.String()
+	if systemPrompt == "" {
+		return context
+	}
+	return systemPrompt + "\n\n" + context
+}
+
+// truncateWords keeps the first n words of text with their original spacing
+func truncateWords(text string, n int) string {
+	words := 0
+	inWord := false
+	for i, r := range text {
+		space := r == ' ' || r == '\n' || r == '\t' || r == '\r'
+		if !space && !inWord {
+			if words == n {
+				return strings.TrimRight(text[:i], " \n\t\r")
+			}
+			words++
+		}
+		inWord = !space
+	}
+	return text
+}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type contextTestConfig struct {
+	files  []string
+	budget int
+}
+
+func (c contextTestConfig) GetContextFiles() []string  { return c.files }
+func (c contextTestConfig) GetContextTokenBudget() int { return c.budget }
+
+func TestContextFiles(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.md")
+	os.WriteFile(notes, []byte("Use tabs for indentation.\n"), 0644)
+	defer ClearContextFiles()
+
+	ConfigureContext(contextTestConfig{files: []string{notes, filepath.Join(dir, "missing.md")}})
+	files := ContextFiles()
+	if len(files) != 2 || files[0].Tokens != 4 || files[1].Err == nil {
+		t.Fatalf("Expected one readable and one missing file, got %+v", files)
+	}
+	if ContextBudget() != DefaultContextBudget {
+		t.Errorf("Expected the default budget, got %d", ContextBudget())
+	}
+
+	if err := AddContextFile(notes); err != nil || len(ContextFiles()) != 2 {
+		t.Errorf("Expected adding a file twice to keep one entry, got %v", err)
+	}
+
+	system := withContext("Be brief.")
+	if !strings.HasPrefix(system, "Be brief.\n\n") || !strings.Contains(system, "--- "+notes+" ---\nUse tabs for indentation.") {
+		t.Errorf("Unexpected system prompt: %q", system)
+	}
+
+	// Edits are picked up by the next request
+	os.WriteFile(notes, []byte("Use spaces.\n"), 0644)
+	os.Chtimes(notes, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if system := withContext(""); !strings.Contains(system, "Use spaces.") || strings.Contains(system, "tabs") {
+		t.Errorf("Expected the changed file to be re-read, got %q", system)
+	}
+
+	if !RemoveContextFile(notes) || RemoveContextFile(notes) {
+		t.Error("Expected the file to be removed once")
+	}
+	ClearContextFiles()
+	if system := withContext("Be brief."); system != "Be brief." {
+		t.Errorf("Expected no context after clearing, got %q", system)
+	}
+}
+
+func TestContextFilesBudget(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	third := filepath.Join(dir, "third.txt")
+	os.WriteFile(first, []byte("one two three"), 0644)
+	os.WriteFile(second, []byte("four five\nsix seven"), 0644)
+	os.WriteFile(third, []byte("eight"), 0644)
+	defer ClearContextFiles()
+
+	ConfigureContext(contextTestConfig{files: []string{first, second, third}, budget: 5})
+	system := withContext("")
+	if !strings.Contains(system, "one two three") || !strings.Contains(system, "four five\n[... truncated") {
+		t.Errorf("Expected the second file to be cut at the budget, got %q", system)
+	}
+	if strings.Contains(system, "six") || strings.Contains(system, "eight") {
+		t.Errorf("Expected nothing past the budget, got %q", system)
+	}
+}
+
+func TestOllamaProviderSendsContextFiles(t *testing.T) {
+	notes := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(notes, []byte("The project is written in Go."), 0644)
+	ConfigureContext(contextTestConfig{files: []string{notes}})
+	defer ClearContextFiles()
+
+	var system string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) > 0 && req.Messages[0].Role == "system" {
+			system = req.Messages[0].Content
+		}
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: "ok"}, Done: true})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 100, server.URL)
+	if _, err := provider.GenerateResponse(context.Background(), "What language?"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if !strings.Contains(system, "The project is written in Go.") {
+		t.Errorf("Expected the context file in the system message, got %q", system)
+	}
+}
+
+func TestOpenAIProviderSendsContextFiles(t *testing.T) {
+	notes := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(notes, []byte("The project is written in Go."), 0644)
+	ConfigureContext(contextTestConfig{files: []string{notes}})
+	defer ClearContextFiles()
+
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, "ok", &captured)
+	defer server.Close()
+
+	provider := NewOpenAIProvider("sk-key", "gpt-4o", 0.7, 100)
+	provider.BaseURL = server.URL
+	provider.SystemPrompt = "Be brief."
+	if _, err := provider.GenerateResponse(context.Background(), "What language?"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if len(captured.Messages) != 2 || captured.Messages[0].Role != "system" ||
+		!strings.HasPrefix(captured.Messages[0].Content, "Be brief.") ||
+		!strings.Contains(captured.Messages[0].Content, "The project is written in Go.") {
+		t.Errorf("Expected the context file in the system message, got %+v", captured.Messages)
+	}
+}
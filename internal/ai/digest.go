@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"tala/internal/fileops"
+)
+
+// DefaultMaxToolOutput is how many bytes of a tool's output are passed on in
+// full; longer output is replaced by a digest
+const DefaultMaxToolOutput = 16000
+
+// MaxToolOutput is the configured limit, 0 for none
+var MaxToolOutput = DefaultMaxToolOutput
+
+// digestLines is how many lines a digest keeps from each end of the output
+const digestLines = 20
+
+// digestOutput returns tool output within MaxToolOutput unchanged. Longer
+// output is written to a temporary file and replaced by its size, its first
+// and last lines and the file's path, so neither the screen nor the model's
+// context is flooded and the user can still read all of it.
+func digestOutput(toolName, content string) string {
+	if MaxToolOutput <= 0 || len(content) <= MaxToolOutput {
+		return content
+	}
+
+	saved := ""
+	if f, err := os.CreateTemp("", "tala-"+toolName+"-*.txt"); err != nil {
+		slog.Warn("could not save large tool output", "tool", toolName, "error", err)
+	} else {
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			slog.Warn("could not save large tool output", "tool", toolName, "error", err)
+			os.Remove(f.Name())
+		} else {
+			saved = f.Name()
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s output is too long to show: %d lines, %s.", toolName, len(lines), fileops.FormatSize(uint64(len(content))))
+	if saved != "" {
+		fmt.Fprintf(&b, " The full output is in %s", saved)
+	}
+	b.WriteString("]\n")
+
+	// Each end gets half the limit, so a few very long lines cannot flood it either
+	budget := MaxToolOutput / 2
+	if len(lines) <= 2*digestLines {
+		b.WriteString(clip(content, budget) + "\n" + clipStart(content, budget))
+		return b.String()
+	}
+	b.WriteString(clip(strings.Join(lines[:digestLines], "\n"), budget))
+	fmt.Fprintf(&b, "\n[... %d lines omitted ...]\n", len(lines)-2*digestLines)
+	b.WriteString(clipStart(strings.Join(lines[len(lines)-digestLines:], "\n"), budget))
+	return b.String()
+}
+
+// clip keeps at most max bytes from the start of text, on a rune boundary
+func clip(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + " [...]"
+}
+
+// clipStart keeps at most max bytes from the end of text, on a rune boundary
+func clipStart(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := len(text) - max
+	for cut < len(text) && !utf8.RuneStart(text[cut]) {
+		cut++
+	}
+	return "[...] " + text[cut:]
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDigestOutputKeepsShortOutput(t *testing.T) {
+	if got := digestOutput("read_file", "short"); got != "short" {
+		t.Errorf("Expected short output unchanged, got %q", got)
+	}
+}
+
+func TestDigestOutputSavesLargeOutput(t *testing.T) {
+	defer func(limit int) { MaxToolOutput = limit }(MaxToolOutput)
+	MaxToolOutput = 1000
+
+	var lines []string
+	for i := 1; i <= 500; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	content := strings.Join(lines, "\n")
+
+	digest := digestOutput("list_files", content)
+	path := regexp.MustCompile(`The full output is in (\S+)\]`).FindStringSubmatch(digest)
+	if path == nil {
+		t.Fatalf("Expected the digest to name the saved file:\n%s", digest)
+	}
+	defer os.Remove(path[1])
+	if saved, err := os.ReadFile(path[1]); err != nil || string(saved) != content {
+		t.Errorf("Expected the full output in %s, got %d bytes (%v)", path[1], len(saved), err)
+	}
+
+	for _, want := range []string{"500 lines", "line 1\n", "line 20\n", "[... 460 lines omitted ...]", "line 481", "line 500"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("Expected %q in the digest:\n%s", want, digest)
+		}
+	}
+	if strings.Contains(digest, "line 21\n") {
+		t.Errorf("Expected the middle to be left out:\n%s", digest)
+	}
+
+	// A few enormous lines are clipped too
+	long := strings.Repeat("é", 5000)
+	digest = digestOutput("read_file", long)
+	if len(digest) > 1300 || !strings.Contains(digest, "[...]") || !utf8.ValidString(digest) {
+		t.Errorf("Expected a short, valid digest of one long line, got %d bytes", len(digest))
+	}
+	if path := regexp.MustCompile(`in (\S+)\]`).FindStringSubmatch(digest); path != nil {
+		os.Remove(path[1])
+	}
+
+	MaxToolOutput = 0
+	if digestOutput("read_file", content) != content {
+		t.Error("Expected no digest without a limit")
+	}
+}
@@ -0,0 +1,216 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Embedding models used when embedding_model is not set
+const (
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+)
+
+// Embedder turns texts into vectors whose closeness reflects similar meaning.
+// Every Provider is one; only Ollama and OpenAI support it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OllamaEmbeddingRequest is the body of an /api/embeddings request
+type OllamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// OllamaEmbeddingResponse is an /api/embeddings response
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// embeddingModel returns the model used for embeddings
+func (p *OllamaProvider) embeddingModel() string {
+	if p.EmbeddingModel != "" {
+		return p.EmbeddingModel
+	}
+	return DefaultOllamaEmbeddingModel
+}
+
+// Embed returns one vector per text from /api/embeddings, which takes a single
+// text per request
+func (p *OllamaProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		jsonBody, err := json.Marshal(OllamaEmbeddingRequest{Model: p.embeddingModel(), Prompt: text})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/api/embeddings", bytes.NewBuffer(jsonBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, p.sendError(err)
+		}
+		var result OllamaEmbeddingResponse
+		if resp.StatusCode == http.StatusNotFound {
+			err = fmt.Errorf("%w: %s (run `ollama pull %s`)", ErrModelNotFound, p.embeddingModel(), p.embeddingModel())
+		} else if resp.StatusCode != http.StatusOK {
+			err = p.statusError(resp)
+		} else if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			err = fmt.Errorf("failed to decode embedding: %w", err)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if result.Error != "" {
+			return nil, fmt.Errorf("ollama embeddings: %s", result.Error)
+		}
+		if len(result.Embedding) == 0 {
+			return nil, fmt.Errorf("ollama returned no embedding; is %s an embedding model? (`ollama pull %s`)", p.embeddingModel(), DefaultOllamaEmbeddingModel)
+		}
+		vectors = append(vectors, result.Embedding)
+	}
+	return vectors, nil
+}
+
+// OpenAIEmbeddingRequest is the body of a /v1/embeddings request
+type OpenAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// OpenAIEmbeddingResponse is a /v1/embeddings response
+type OpenAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// embeddingModel returns the model used for embeddings, which on Azure names
+// its deployment
+func (p *OpenAIProvider) embeddingModel() string {
+	if p.EmbeddingModel != "" {
+		return p.EmbeddingModel
+	}
+	return DefaultOpenAIEmbeddingModel
+}
+
+// Embed returns one vector per text from /v1/embeddings in a single request
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if p.APIKey == "" && !p.Compatible {
+		return nil, fmt.Errorf("openai embeddings need an API key")
+	}
+	jsonBody, err := json.Marshal(OpenAIEmbeddingRequest{Model: p.embeddingModel(), Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint(p.embeddingModel(), "embeddings"), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.authorize(req)
+
+	client := p.client
+	if client == nil {
+		client = NewHTTPClient(RequestTimeout)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	var result OpenAIEmbeddingResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, withRequestID(resp, fmt.Errorf("openai embeddings returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body))))
+	}
+	if result.Error != nil {
+		return nil, withRequestID(resp, fmt.Errorf("openai embeddings: %s", result.Error.Message))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, withRequestID(resp, fmt.Errorf("openai embeddings returned status %d", resp.StatusCode))
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("openai returned %d embeddings for %d texts", len(result.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("openai returned an embedding for unknown input %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// EmbeddingModelFor returns the embedding model a provider uses, filling in its
+// default when model is empty
+func EmbeddingModelFor(providerType, model string) string {
+	if model != "" {
+		return model
+	}
+	switch providerType {
+	case "openai", "azure-openai", "openai-compatible":
+		return DefaultOpenAIEmbeddingModel
+	case "ollama":
+		return DefaultOllamaEmbeddingModel
+	}
+	return ""
+}
+
+// NewEmbedder returns a provider for embedding texts; an empty model uses the
+// provider's default embedding model. Providers without embeddings are an
+// error wrapping ErrNotSupported.
+func NewEmbedder(providerType, apiKey, model string) (Embedder, error) {
+	provider, err := CreateProvider(providerType, apiKey, "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	switch p := provider.(type) {
+	case *OpenAIProvider:
+		p.EmbeddingModel = model
+	case *OllamaProvider:
+		p.EmbeddingModel = model
+	default:
+		return nil, fmt.Errorf("%s embeddings: %w (use ollama or openai)", providerType, ErrNotSupported)
+	}
+	return provider, nil
+}
+
+// NewEmbedderFromConfig is NewEmbedder for the configured provider and key,
+// with the provider's connection settings, such as base_url or the Azure
+// resource
+func NewEmbedderFromConfig(cfg interface {
+	GetProvider() string
+	GetAPIKey() string
+}, model string) (Embedder, error) {
+	embedder, err := NewEmbedder(cfg.GetProvider(), cfg.GetAPIKey(), model)
+	if err != nil {
+		return nil, err
+	}
+	if provider, ok := embedder.(Provider); ok {
+		applyEndpointOptions(provider, cfg)
+	}
+	return embedder, nil
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOllamaProviderEmbed(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var req OllamaEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		json.NewEncoder(w).Encode(OllamaEmbeddingResponse{Embedding: []float32{float32(len(req.Prompt)), 1}})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama3", 0.7, 100, server.URL)
+	vectors, err := provider.Embed(context.Background(), []string{"a", "abc"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][0] != 3 {
+		t.Errorf("Expected one vector per text in order, got %v", vectors)
+	}
+	if len(models) != 2 || models[0] != DefaultOllamaEmbeddingModel {
+		t.Errorf("Expected the default embedding model, not the chat model, got %v", models)
+	}
+
+	provider.EmbeddingModel = "mxbai-embed-large"
+	provider.Embed(context.Background(), []string{"a"})
+	if models[len(models)-1] != "mxbai-embed-large" {
+		t.Errorf("Expected the configured embedding model, got %s", models[len(models)-1])
+	}
+}
+
+func TestOllamaProviderEmbedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model \"nomic-embed-text\" not found, try pulling it first"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama3", 0.7, 100, server.URL)
+	_, err := provider.Embed(context.Background(), []string{"a"})
+	if !errors.Is(err, ErrModelNotFound) || !strings.Contains(err.Error(), "ollama pull nomic-embed-text") {
+		t.Errorf("Expected a missing embedding model error, got %v", err)
+	}
+}
+
+func TestOpenAIProviderEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("Expected the API key as a bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		var req OpenAIEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != DefaultOpenAIEmbeddingModel || len(req.Input) != 2 {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		// Results may come back out of order; index says which input they belong to
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider("sk-test", "gpt-4o", 0.7, 100)
+	provider.BaseURL = server.URL + "/v1"
+	vectors, err := provider.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+}
+
+func TestOpenAIProviderEmbedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider("sk-wrong", "gpt-4o", 0.7, 100)
+	provider.BaseURL = server.URL
+	if _, err := provider.Embed(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "Incorrect API key") {
+		t.Errorf("Expected the API error message, got %v", err)
+	}
+
+	provider.APIKey = ""
+	if _, err := provider.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
+
+func TestEmbedNotSupported(t *testing.T) {
+	providers := []Provider{
+		NewAnthropicProvider("key", "claude", 0.7, 100),
+		NewMockProvider("test"),
+		WrapProvider(NewMockProvider("test"), TimingMiddleware(func(string, time.Duration, error) {})),
+	}
+	for _, provider := range providers {
+		if _, err := provider.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrNotSupported) {
+			t.Errorf("%s: expected ErrNotSupported, got %v", provider.GetName(), err)
+		}
+	}
+
+	if _, err := NewEmbedder("anthropic", "key", ""); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected NewEmbedder to reject anthropic with ErrNotSupported, got %v", err)
+	}
+	if _, err := NewEmbedder("ollama", "", ""); err != nil {
+		t.Errorf("Expected an Ollama embedder, got %v", err)
+	}
+}
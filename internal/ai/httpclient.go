@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRequestTimeout bounds a provider request when the config sets no timeout
+const DefaultRequestTimeout = 120 * time.Second
+
+// HTTPConfig is the part of the configuration that tunes the HTTP client
+// shared by providers
+type HTTPConfig interface {
+	GetRequestTimeout() time.Duration
+	GetHTTPProxy() string
+	GetCACertFile() string
+	GetExtraHeaders() map[string]string
+}
+
+// RequestTimeout bounds each provider request made through NewHTTPClient
+var RequestTimeout = DefaultRequestTimeout
+
+var (
+	transportMu     sync.Mutex
+	sharedTransport *http.Transport
+	proxyURL        *url.URL       // configured proxy, nil = from the environment
+	rootCAs         *x509.CertPool // system roots plus the configured CA, nil = system roots
+	extraHeaders    http.Header    // added to provider requests that do not set them
+)
+
+// ConfigureHTTP applies the HTTP settings and starts a fresh connection pool,
+// so proxy variables are read again. Providers created afterwards use them.
+// Nothing changes when the proxy or CA certificate cannot be used.
+func ConfigureHTTP(cfg HTTPConfig) error {
+	proxy, err := ParseProxy(cfg.GetHTTPProxy())
+	if err != nil {
+		return err
+	}
+	pool, err := loadCACert(cfg.GetCACertFile())
+	if err != nil {
+		return err
+	}
+
+	RequestTimeout = cfg.GetRequestTimeout()
+	if RequestTimeout <= 0 {
+		RequestTimeout = DefaultRequestTimeout
+	}
+	headers := http.Header{}
+	for name, value := range cfg.GetExtraHeaders() {
+		headers.Set(name, value)
+	}
+	transportMu.Lock()
+	sharedTransport, proxyURL, rootCAs, extraHeaders = nil, proxy, pool, headers
+	transportMu.Unlock()
+	return nil
+}
+
+// ParseProxy checks a proxy address, adding http:// when it has no scheme.
+// An empty address gives nil.
+func ParseProxy(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %q", proxy, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return u, nil
+}
+
+// loadCACert returns the system roots with the PEM certificates in file
+// added, or nil when no file is set
+func loadCACert(file string) (*x509.CertPool, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// NewHTTPClient returns a client for provider requests. Every client shares
+// one transport, so connections are kept alive and reused across providers
+// and requests, and each request carries the extra headers and an
+// X-Request-ID. A timeout of 0 means no limit, for long downloads.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	transport, headers := providerTransport()
+	return &http.Client{Transport: &providerRoundTripper{next: transport, headers: headers}, Timeout: timeout}
+}
+
+// providerTransport returns the shared transport, building it on first use,
+// and the extra headers that go with it
+func providerTransport() (*http.Transport, http.Header) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if sharedTransport == nil {
+		sharedTransport = &http.Transport{
+			Proxy:           environmentProxy(proxyURL),
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}
+	}
+	return sharedTransport, extraHeaders
+}
+
+// environmentProxy picks the proxy for a request: configured when set, else
+// HTTPS_PROXY or HTTP_PROXY, or their lower-case forms, as they are set now.
+// Hosts in NO_PROXY bypass either. Like curl and Go's own default, requests
+// to the local machine, such as a local Ollama, never go through the proxy.
+func environmentProxy(configured *url.URL) func(*http.Request) (*url.URL, error) {
+	httpsProxy := getenvAny("HTTPS_PROXY", "https_proxy")
+	httpProxy := getenvAny("HTTP_PROXY", "http_proxy")
+	noProxy := getenvAny("NO_PROXY", "no_proxy")
+
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+		if isLocalHost(host) || bypassesProxy(host, noProxy) {
+			return nil, nil
+		}
+		if configured != nil {
+			return http.ProxyURL(configured)(req)
+		}
+		proxy := httpProxy
+		if req.URL.Scheme == "https" {
+			proxy = httpsProxy
+		}
+		return ParseProxy(proxy)
+	}
+}
+
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// isLocalHost reports whether host is the local machine
+func isLocalHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// bypassesProxy reports whether NO_PROXY lists host: "*" for every host, a
+// domain for it and its subdomains, or an exact address
+func bypassesProxy(host, noProxy string) bool {
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		if entry == "*" {
+			return true
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		case host == entry || strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type httpConfig struct {
+	timeout time.Duration
+	proxy   string
+	caFile  string
+	headers map[string]string
+}
+
+func (c httpConfig) GetRequestTimeout() time.Duration   { return c.timeout }
+func (c httpConfig) GetHTTPProxy() string               { return c.proxy }
+func (c httpConfig) GetCACertFile() string              { return c.caFile }
+func (c httpConfig) GetExtraHeaders() map[string]string { return c.headers }
+
+// useProxy points the proxy variables at proxy and rebuilds the shared
+// transport so it reads them
+func useProxy(t *testing.T, proxy, noProxy string) {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "http_proxy", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("HTTP_PROXY", proxy)
+	t.Setenv("NO_PROXY", noProxy)
+	if err := ConfigureHTTP(httpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ConfigureHTTP(httpConfig{}) })
+}
+
+func TestProvidersUseHTTPProxy(t *testing.T) {
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of the real destination
+		hosts = append(hosts, r.URL.Host)
+		json.NewEncoder(w).Encode(OllamaEmbeddingResponse{Embedding: []float32{1, 2}})
+	}))
+	defer proxy.Close()
+	useProxy(t, proxy.URL, "")
+
+	provider := NewOllamaProvider("llama3", 0.7, 100, "http://ollama.example.test:11434")
+	if _, err := provider.Embed(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("Embed through the proxy failed: %v", err)
+	}
+	if len(hosts) != 1 || hosts[0] != "ollama.example.test:11434" {
+		t.Errorf("Expected the proxy to forward to ollama.example.test:11434, got %v", hosts)
+	}
+}
+
+func TestProxySchemeIsOptional(t *testing.T) {
+	var proxied bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+	}))
+	defer proxy.Close()
+	useProxy(t, proxy.Listener.Addr().String(), "")
+
+	resp, err := NewHTTPClient(time.Second).Get("http://api.example.test/")
+	if err != nil {
+		t.Fatalf("Request through a proxy given as host:port failed: %v", err)
+	}
+	resp.Body.Close()
+	if !proxied {
+		t.Error("Expected the request to go through the proxy")
+	}
+}
+
+func TestProxyBypass(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Request for %s should not use the proxy", r.URL)
+	}))
+	defer proxy.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaEmbeddingResponse{Embedding: []float32{1}})
+	}))
+	defer server.Close()
+	useProxy(t, proxy.URL, "internal.example.test")
+
+	// A local Ollama is reached directly even with a proxy set
+	provider := NewOllamaProvider("llama3", 0.7, 100, server.URL)
+	if _, err := provider.Embed(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("Embed from a local server failed: %v", err)
+	}
+
+	tests := []struct {
+		host    string
+		noProxy string
+		want    bool
+	}{
+		{"internal.example.test", "internal.example.test", true},
+		{"api.internal.example.test", "internal.example.test", true},
+		{"api.internal.example.test", ".internal.example.test", true},
+		{"api.example.test", "*.example.test", true},
+		{"notexample.test", "example.test", false},
+		{"api.example.test", "*", true},
+		{"api.example.test", "other.test, api.example.test:443", true},
+		{"api.example.test", "", false},
+	}
+	for _, tt := range tests {
+		if got := bypassesProxy(tt.host, tt.noProxy); got != tt.want {
+			t.Errorf("bypassesProxy(%q, %q) = %v, want %v", tt.host, tt.noProxy, got, tt.want)
+		}
+	}
+}
+
+func TestConfigureHTTPTimeout(t *testing.T) {
+	defer ConfigureHTTP(httpConfig{})
+
+	ConfigureHTTP(httpConfig{timeout: 5 * time.Second})
+	if got := NewOpenAIProvider("key", "gpt-4o", 0.7, 100).client.Timeout; got != 5*time.Second {
+		t.Errorf("Expected a 5s request timeout, got %v", got)
+	}
+	ConfigureHTTP(httpConfig{})
+	if RequestTimeout != DefaultRequestTimeout {
+		t.Errorf("Expected an unset timeout to fall back to %v, got %v", DefaultRequestTimeout, RequestTimeout)
+	}
+}
+
+func TestConfiguredProxy(t *testing.T) {
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.URL.Host)
+	}))
+	defer proxy.Close()
+	useProxy(t, "", "internal.example.test")
+
+	// http_proxy applies without any proxy variable, and NO_PROXY still counts
+	if err := ConfigureHTTP(httpConfig{proxy: proxy.Listener.Addr().String()}); err != nil {
+		t.Fatalf("ConfigureHTTP failed: %v", err)
+	}
+	client := NewHTTPClient(time.Second)
+	for _, target := range []string{"http://api.example.test/", "http://internal.example.test/"} {
+		if resp, err := client.Get(target); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if len(hosts) != 1 || hosts[0] != "api.example.test" {
+		t.Errorf("Expected only api.example.test to go through the proxy, got %v", hosts)
+	}
+
+	for _, bad := range []string{"ftp://proxy.example.test", "http://"} {
+		if err := ConfigureHTTP(httpConfig{proxy: bad}); err == nil {
+			t.Errorf("Expected proxy %q to be rejected", bad)
+		}
+	}
+}
+
+func TestCACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	useProxy(t, "", "")
+
+	if resp, err := NewHTTPClient(time.Second).Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected a certificate error before the CA is trusted")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureHTTP(httpConfig{caFile: caFile}); err != nil {
+		t.Fatalf("ConfigureHTTP failed: %v", err)
+	}
+	resp, err := NewHTTPClient(time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the trusted CA to verify the server: %v", err)
+	}
+	resp.Body.Close()
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+	for _, bad := range []string{notPEM, filepath.Join(t.TempDir(), "missing.pem")} {
+		if err := ConfigureHTTP(httpConfig{caFile: bad}); err == nil {
+			t.Errorf("Expected CA file %s to be rejected", bad)
+		}
+	}
+}
+
+func TestExtraHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"data": [{"index": 0, "embedding": [1]}]}`))
+	}))
+	defer server.Close()
+	useProxy(t, "", "")
+
+	if err := ConfigureHTTP(httpConfig{headers: map[string]string{
+		"helicone-auth": "Bearer helicone",
+		"Authorization": "Bearer gateway",
+	}}); err != nil {
+		t.Fatalf("ConfigureHTTP failed: %v", err)
+	}
+	provider := NewOpenAIProvider("sk-key", "gpt-4o", 0.7, 100)
+	provider.BaseURL = server.URL
+	if _, err := provider.Embed(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if got.Get("Helicone-Auth") != "Bearer helicone" {
+		t.Errorf("Expected the extra header to be sent, got %v", got)
+	}
+	if got.Get("Authorization") != "Bearer sk-key" {
+		t.Errorf("Expected the provider's own Authorization to win, got %q", got.Get("Authorization"))
+	}
+}
@@ -3,7 +3,7 @@ package ai
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -15,6 +15,28 @@ type Intent struct {
 	Confidence float64                `json:"confidence"`
 }
 
+// IntentConfidenceThreshold is the confidence an intent needs for its tool to run
+const IntentConfidenceThreshold = 0.8
+
+// ReportIntent, when set, is told about every detected intent and whether it
+// cleared IntentConfidenceThreshold, before any tool runs
+var ReportIntent func(intent Intent, accepted bool)
+
+// acceptIntents reports each intent and returns the ones confident enough to execute
+func acceptIntents(intents []Intent) []Intent {
+	var accepted []Intent
+	for _, intent := range intents {
+		ok := intent.Confidence > IntentConfidenceThreshold
+		if ReportIntent != nil {
+			ReportIntent(intent, ok)
+		}
+		if ok {
+			accepted = append(accepted, intent)
+		}
+	}
+	return accepted
+}
+
 // IntentDetector uses AI to detect user intentions
 type IntentDetector struct {
 	provider Provider
@@ -29,8 +51,15 @@ func NewIntentDetector(provider Provider) *IntentDetector {
 
 // DetectIntent analyzes user input and returns detected intentions
 func (detector *IntentDetector) DetectIntent(ctx context.Context, userInput string) ([]Intent, error) {
-	// Create a prompt for intent detection
-	prompt := detector.createIntentDetectionPrompt(userInput)
+	// Create a prompt for intent detection. Providers with a JSON mode are held
+	// to valid JSON, which then has to be an object.
+	jsonMode := supportsJSONMode(detector.provider)
+	prompt := detector.createIntentDetectionPrompt(userInput, jsonMode)
+	// Earlier turns are conversation, not requests, so they stay out
+	ctx = withoutHistory(ctx)
+	if jsonMode {
+		ctx = withResponseFormat(ctx, ResponseFormatJSON)
+	}
 	
 	// Get AI response
 	response, err := detector.provider.GenerateResponse(ctx, prompt)
@@ -39,113 +68,184 @@ func (detector *IntentDetector) DetectIntent(ctx context.Context, userInput stri
 		return detector.fallbackPatternMatching(userInput), nil
 	}
 	
-	// Parse the AI response to extract intents
-	intents := detector.parseIntentResponse(response)
-	
-	// If no intents detected by AI, use fallback
-	if len(intents) == 0 {
-		return detector.fallbackPatternMatching(userInput), nil
-	}
-	
-	return intents, nil
+	// Parse the AI response to extract intents. Finding none is the model's
+	// answer, so the patterns are not asked to second-guess it.
+	return detector.parseIntentResponse(response), nil
 }
 
-// createIntentDetectionPrompt creates a prompt for AI intent detection
-func (detector *IntentDetector) createIntentDetectionPrompt(userInput string) string {
-	availableTools := GetAvailableTools()
-	
-	prompt := `You are a conservative intent detection system. Only detect tool usage when the user explicitly requests file operations, commands, or system actions.
-
-DO NOT detect intents for:
-- Greetings (hi, hello, hey)
-- General questions
-- Casual conversation
-- Abstract requests
+// createIntentDetectionPrompt creates a prompt for AI intent detection from
+// the configured template
+func (detector *IntentDetector) createIntentDetectionPrompt(userInput string, jsonMode bool) string {
+	return renderIntentPrompt(userInput, jsonMode)
+}
 
-Available tools and their purposes:
-`
-	
-	for _, tool := range availableTools {
-		prompt += fmt.Sprintf("- %s: %s\n", tool.Name, tool.Description)
+// supportsJSONMode reports whether a provider can be made to reply with valid
+// JSON: Ollama's format and OpenAI's response_format
+func supportsJSONMode(provider Provider) bool {
+	switch UnwrapProvider(provider).(type) {
+	case *OllamaProvider, *OpenAIProvider:
+		return true
 	}
-	
-	prompt += `
-Respond with JSON containing an array of detected intents. Each intent should have:
-- "action": brief description of what the user wants to do
-- "tool": the tool name to use (from the list above)
-- "parameters": object with the parameters for the tool
-- "confidence": number between 0-1 indicating confidence
-
-Only detect intents with confidence > 0.8 when user explicitly mentions:
-- File operations (create, read, write, delete specific files)
-- Directory operations (list, create, delete directories)
-- System commands (run specific commands)
+	return false
+}
 
-For general conversation, greetings, or questions, respond with: []
+// parseIntentResponse parses AI response to extract intents
+func (detector *IntentDetector) parseIntentResponse(response string) []Intent {
+	// Reasoning models think before answering, often mentioning tools
+	answer, _ := SplitThinking(response)
+	if intents, ok := decodeIntents(answer); ok {
+		return intents
+	}
 
-User input: "` + userInput + `"
+	// Try to extract individual intent objects
+	return detector.extractIntentsFromText(response)
+}
 
-JSON response:`
-	
-	return prompt
+// decodeIntents finds the intents in a reply: a JSON array of intents, an
+// object holding the array under "intents", or a single intent object. JSON in
+// a code fence is preferred, and prose around the JSON is skipped; brackets in
+// the prose or inside JSON strings do not confuse it.
+func decodeIntents(text string) ([]Intent, bool) {
+	for _, candidate := range append(fencedBlocks(text), text) {
+		for i := 0; i < len(candidate); i++ {
+			if candidate[i] != '[' && candidate[i] != '{' {
+				continue
+			}
+			var value json.RawMessage
+			decoder := json.NewDecoder(strings.NewReader(candidate[i:]))
+			if err := decoder.Decode(&value); err != nil {
+				continue
+			}
+			if intents, ok := intentsFromJSON(value); ok {
+				return intents, true
+			}
+			// Other JSON is skipped whole rather than searched for intents
+			i += int(decoder.InputOffset()) - 1
+		}
+	}
+	return nil, false
 }
 
-// parseIntentResponse parses AI response to extract intents
-func (detector *IntentDetector) parseIntentResponse(response string) []Intent {
+// intentsFromJSON decodes one JSON value as intents; every intent must name a tool
+func intentsFromJSON(value json.RawMessage) ([]Intent, bool) {
 	var intents []Intent
-	
-	// Find JSON in the response
-	jsonStart := strings.Index(response, "[")
-	jsonEnd := strings.LastIndex(response, "]")
-	
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		return intents
+	switch value[0] {
+	case '[':
+		if err := json.Unmarshal(value, &intents); err != nil {
+			return nil, false
+		}
+	case '{':
+		var wrapped struct {
+			Intents *[]Intent `json:"intents"`
+		}
+		if err := json.Unmarshal(value, &wrapped); err == nil && wrapped.Intents != nil {
+			intents = *wrapped.Intents
+			break
+		}
+		var intent Intent
+		if err := json.Unmarshal(value, &intent); err != nil || intent.Tool == "" {
+			return nil, false
+		}
+		intents = []Intent{intent}
 	}
-	
-	jsonStr := response[jsonStart : jsonEnd+1]
-	
-	// Try to parse JSON
-	err := json.Unmarshal([]byte(jsonStr), &intents)
-	if err != nil {
-		// Try to extract individual intent objects
-		return detector.extractIntentsFromText(response)
+	for _, intent := range intents {
+		if intent.Tool == "" {
+			return nil, false
+		}
+	}
+	if intents == nil {
+		intents = []Intent{}
+	}
+	return intents, true
+}
+
+// fencedBlocks returns the contents of the ``` code fences in text
+func fencedBlocks(text string) []string {
+	var blocks []string
+	for {
+		start := strings.Index(text, "```")
+		if start == -1 {
+			return blocks
+		}
+		rest := text[start+3:]
+		end := strings.Index(rest, "```")
+		if end == -1 {
+			return blocks
+		}
+		blocks = append(blocks, stripCodeFence("```"+rest[:end+3]))
+		text = rest[end+3:]
 	}
-	
-	return intents
 }
 
-// extractIntentsFromText tries to extract intents from non-JSON text
+// toolInstruction matches an instruction to use a tool at the start of a
+// sentence or line, like "Use list_files" or "I'll call the create file tool".
+// The tool name follows the match.
+var toolInstruction = regexp.MustCompile(`(?m)(?:^|[.!?:;]\s+)\s*(?:[-*]\s+|\d+\.\s+)?(?:(?:i will|i'll|let me|now|first|then)\s+)?(?:use|call|run|execute|invoke)\s+(?:the\s+)?$`)
+
+// extractIntentsFromText tries to extract intents from non-JSON text. A tool
+// counts only when the text tells to use it, so a reply that merely explains
+// or mentions a tool triggers nothing.
 func (detector *IntentDetector) extractIntentsFromText(text string) []Intent {
 	var intents []Intent
 	
-	// Look for mentions of tool names in the response
+	// Look for instructions to use a tool in the response
 	availableTools := GetAvailableTools()
 	text = strings.ToLower(text)
 	
 	for _, tool := range availableTools {
-		if strings.Contains(text, tool.Name) || strings.Contains(text, strings.ReplaceAll(tool.Name, "_", " ")) {
-			intent := Intent{
-				Action:     "detected from text",
-				Tool:       tool.Name,
-				Parameters: make(map[string]interface{}),
-				Confidence: 0.6,
-			}
-			
-			// Try to extract simple parameters
-			if tool.Name == "execute_command" {
-				if command := detector.extractCommand(text); command != "" {
-					intent.Parameters["command"] = command
-					intent.Confidence = 0.8
-				}
+		after, ok := instructedTool(text, tool.Name)
+		if !ok {
+			continue
+		}
+		intent := Intent{
+			Action:     "detected from text",
+			Tool:       tool.Name,
+			Parameters: make(map[string]interface{}),
+			Confidence: 0.6,
+		}
+		
+		// Try to extract simple parameters
+		if tool.Name == "execute_command" {
+			if command := detector.extractCommand(after); command != "" {
+				intent.Parameters["command"] = command
+				intent.Confidence = 0.8
 			}
-			
-			intents = append(intents, intent)
 		}
+		
+		intents = append(intents, intent)
 	}
 	
 	return intents
 }
 
+// instructedTool finds an instruction to use the tool in lowercase text, with
+// its name written as is or with spaces, and returns the text after the name
+func instructedTool(text, name string) (string, bool) {
+	for _, spelled := range []string{name, strings.ReplaceAll(name, "_", " ")} {
+		for offset := 0; ; {
+			idx := strings.Index(text[offset:], spelled)
+			if idx == -1 {
+				break
+			}
+			start, end := offset+idx, offset+idx+len(spelled)
+			offset = end
+			// A longer word, like list_files_recursive, is another tool
+			if (end < len(text) && isWordByte(text[end])) || (start > 0 && isWordByte(text[start-1])) {
+				continue
+			}
+			if toolInstruction.MatchString(text[:start]) {
+				return text[end:], true
+			}
+		}
+	}
+	return "", false
+}
+
+// isWordByte reports whether b can be part of a tool name
+func isWordByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
 // extractCommand tries to extract a command from text
 func (detector *IntentDetector) extractCommand(text string) string {
 	// Look for common command patterns
@@ -176,7 +276,7 @@ func (detector *IntentDetector) fallbackPatternMatching(userInput string) []Inte
 	var intents []Intent
 	input := strings.ToLower(userInput)
 	
-	// File operations
+	// File operations; a file without content is only touched
 	if (strings.Contains(input, "create") || strings.Contains(input, "make")) && strings.Contains(input, "file") {
 		intent := Intent{
 			Action:     "create file",
@@ -184,6 +284,10 @@ func (detector *IntentDetector) fallbackPatternMatching(userInput string) []Inte
 			Parameters: detector.extractFileParams(userInput),
 			Confidence: 0.7,
 		}
+		if intent.Parameters["content"] == "" {
+			intent.Action, intent.Tool = "touch file", "touch"
+			delete(intent.Parameters, "content")
+		}
 		intents = append(intents, intent)
 	}
 	
@@ -199,8 +303,12 @@ func (detector *IntentDetector) fallbackPatternMatching(userInput string) []Inte
 		intents = append(intents, intent)
 	}
 	
-	// List files
-	if (strings.Contains(input, "list") || strings.Contains(input, "show")) && 
+	// Reading, deleting, copying and moving named files
+	fileIntents := detector.fileOperationIntents(userInput)
+	intents = append(intents, fileIntents...)
+	
+	// List files, unless the request was about a particular file
+	if len(fileIntents) == 0 && (strings.Contains(input, "list") || strings.Contains(input, "show")) && 
 		(strings.Contains(input, "file") || strings.Contains(input, "directory")) {
 		intent := Intent{
 			Action:     "list files",
@@ -263,19 +371,227 @@ func (detector *IntentDetector) fallbackPatternMatching(userInput string) []Inte
 	return intents
 }
 
+// fileOperation is a verb that acts on named files, for the fallback patterns
+type fileOperation struct {
+	verbs       []string
+	tool        string
+	action      string
+	paths       int  // files it needs: 1 filename, 2 source and destination
+	destructive bool // loses data, so the patterns alone never run it
+}
+
+var fileOperations = []fileOperation{
+	{[]string{"read", "cat", "open", "view", "display", "show", "print"}, "read_file", "read file", 1, false},
+	{[]string{"touch"}, "touch", "touch file", 1, false},
+	{[]string{"delete", "remove", "rm", "erase"}, "delete_file", "delete file", 1, true},
+	{[]string{"copy", "cp", "duplicate"}, "copy_file", "copy file", 2, false},
+	{[]string{"move", "mv", "rename"}, "move_file", "move file", 2, true},
+}
+
+// objectFillers may stand between a verb and the file it acts on, as in
+// "delete the file temp.txt"
+var objectFillers = []string{"me", "the", "a", "an", "this", "that", "my", "file", "called", "named"}
+
+// fileOperationIntents matches requests like "read config.json", "delete
+// temp.txt", "copy a.txt to b.txt" or "rename a.txt to b.txt". The file must
+// be the verb's direct object, so "delete the second paragraph of README.md"
+// is not a delete. Confidence is high only when the request starts with the
+// verb, as a command does, and destructive operations stay at
+// IntentConfidenceThreshold, which is reported but never run.
+func (detector *IntentDetector) fileOperationIntents(userInput string) []Intent {
+	words := strings.Fields(userInput)
+	first := 0
+	for first < len(words) && (strings.EqualFold(words[first], "please") || strings.EqualFold(words[first], "now")) {
+		first++
+	}
+	
+	for _, op := range fileOperations {
+		at := -1
+		for i, word := range words {
+			if containsString(op.verbs, strings.ToLower(strings.Trim(word, ",.!?:;"))) {
+				at = i
+				break
+			}
+		}
+		if at == -1 {
+			continue
+		}
+		object, rest := directObject(words[at+1:])
+		if object == "" {
+			continue
+		}
+		
+		params := make(map[string]interface{})
+		if op.paths == 1 {
+			params["filename"] = object
+		} else {
+			destination := destinationOf(object, rest)
+			if destination == "" {
+				continue
+			}
+			params["source"] = object
+			params["destination"] = destination
+		}
+		
+		confidence := 0.7
+		if at == first {
+			confidence = 0.9
+		}
+		if op.destructive && confidence > IntentConfidenceThreshold {
+			confidence = IntentConfidenceThreshold
+		}
+		return []Intent{{
+			Action:     op.action,
+			Tool:       op.tool,
+			Parameters: params,
+			Confidence: confidence,
+		}}
+	}
+	return nil
+}
+
+// directObject returns the filename that follows a verb, past any
+// objectFillers, and the words after it; "" when the next word is not a file
+func directObject(words []string) (string, []string) {
+	for i, word := range words {
+		if containsString(objectFillers, strings.ToLower(word)) {
+			continue
+		}
+		name := trimFilename(word)
+		if !looksLikeFilename(name) {
+			return "", nil
+		}
+		return name, words[i+1:]
+	}
+	return "", nil
+}
+
+// destinationOf picks where a copy or move of source goes: the next filename,
+// or the word after "to", "into" or "as", which may be a directory
+func destinationOf(source string, words []string) string {
+	for i, word := range words {
+		switch strings.ToLower(word) {
+		case "to", "into", "as":
+			if i+1 < len(words) {
+				if destination := trimFilename(words[i+1]); destination != "" && destination != source {
+					return destination
+				}
+			}
+		}
+		if name := trimFilename(word); looksLikeFilename(name) && name != source {
+			return name
+		}
+	}
+	return ""
+}
+
+// extractFilenames returns the words of the input that look like file paths:
+// names with an extension, such as notes.txt, or paths such as src/main.go,
+// without surrounding quotes or punctuation
+func extractFilenames(userInput string) []string {
+	var filenames []string
+	for _, word := range strings.Fields(userInput) {
+		name := trimFilename(word)
+		if looksLikeFilename(name) {
+			filenames = append(filenames, name)
+		}
+	}
+	return filenames
+}
+
+// trimFilename removes quotes and sentence punctuation around a word
+func trimFilename(word string) string {
+	word = strings.Trim(word, "\"'`")
+	word = strings.TrimRight(word, ",;:!?")
+	word = strings.TrimSuffix(word, ".")
+	return strings.Trim(word, "\"'`")
+}
+
+// looksLikeFilename reports whether a word names a file: it has an extension
+// or a directory part, and is not a number like 3.5
+func looksLikeFilename(name string) bool {
+	if name == "" || strings.Trim(name, "0123456789.") == "" {
+		return false
+	}
+	base := name[strings.LastIndex(name, "/")+1:]
+	if base == "" {
+		return false
+	}
+	return strings.Contains(name, "/") || (strings.Contains(base, ".") && !strings.HasSuffix(base, "."))
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultFileExtension is given to files the user names without an extension
+// when the request mentions no language
+var DefaultFileExtension = ".txt"
+
+// languageExtensions maps languages and formats a request may mention, as in
+// "create a python file called foo", to the extension of their files
+var languageExtensions = map[string]string{
+	"python":     ".py",
+	"go":         ".go",
+	"golang":     ".go",
+	"javascript": ".js",
+	"js":         ".js",
+	"node":       ".js",
+	"typescript": ".ts",
+	"ts":         ".ts",
+	"rust":       ".rs",
+	"java":       ".java",
+	"kotlin":     ".kt",
+	"swift":      ".swift",
+	"c":          ".c",
+	"c++":        ".cpp",
+	"cpp":        ".cpp",
+	"c#":         ".cs",
+	"csharp":     ".cs",
+	"ruby":       ".rb",
+	"php":        ".php",
+	"shell":      ".sh",
+	"bash":       ".sh",
+	"sql":        ".sql",
+	"html":       ".html",
+	"css":        ".css",
+	"markdown":   ".md",
+	"json":       ".json",
+	"yaml":       ".yaml",
+	"toml":       ".toml",
+	"text":       ".txt",
+}
+
+// fileExtensionFor picks the extension for a file named without one: that of a
+// language mentioned just before "file", else DefaultFileExtension
+func fileExtensionFor(userInput string) string {
+	words := strings.Fields(strings.ToLower(userInput))
+	for i := 1; i < len(words); i++ {
+		if strings.Trim(words[i], ",.!?:;") != "file" {
+			continue
+		}
+		if ext, ok := languageExtensions[strings.Trim(words[i-1], ",.!?:;")]; ok {
+			return ext
+		}
+	}
+	return DefaultFileExtension
+}
+
 // extractFileParams extracts filename and content from user input
 func (detector *IntentDetector) extractFileParams(userInput string) map[string]interface{} {
 	params := make(map[string]interface{})
 	words := strings.Fields(userInput)
 	
 	// Look for filename - prioritize specific patterns
-	for _, word := range words {
+	if filenames := extractFilenames(userInput); len(filenames) > 0 {
 		// First check for file with extension
-		if strings.Contains(word, ".") {
-			filename := strings.Trim(word, "\"'")
-			params["filename"] = filename
-			break
-		}
+		params["filename"] = filenames[0]
 	}
 	
 	// If no filename found with extension, look for patterns
@@ -285,7 +601,7 @@ func (detector *IntentDetector) extractFileParams(userInput string) map[string]i
 			if i > 1 && words[i-1] == "called" && words[i-2] == "file" {
 				filename := strings.Trim(word, "\"'")
 				if !strings.Contains(filename, ".") {
-					filename += ".txt"
+					filename += fileExtensionFor(userInput)
 				}
 				params["filename"] = filename
 				break
@@ -294,7 +610,7 @@ func (detector *IntentDetector) extractFileParams(userInput string) map[string]i
 			if i > 1 && words[i-1] == "named" && words[i-2] == "file" {
 				filename := strings.Trim(word, "\"'")
 				if !strings.Contains(filename, ".") {
-					filename += ".txt"
+					filename += fileExtensionFor(userInput)
 				}
 				params["filename"] = filename
 				break
@@ -302,8 +618,8 @@ func (detector *IntentDetector) extractFileParams(userInput string) map[string]i
 		}
 	}
 	
-	// Look for content
-	content := "Hello World!" // Default
+	// Look for content; without any the file is created empty, like touch
+	content := ""
 	for i, word := range words {
 		if word == "with" || word == "containing" {
 			if i+1 < len(words) {
@@ -2,7 +2,11 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -17,10 +21,16 @@ func TestIntentDetector_FallbackPatternMatching(t *testing.T) {
 	}{
 		{
 			name:           "create file intent",
-			input:          "create a test.txt file",
+			input:          "create a test.txt file with hello",
 			expectedIntent: "create file",
 			expectedTool:   "create_file",
 		},
+		{
+			name:           "create empty file intent",
+			input:          "create a test.txt file",
+			expectedIntent: "touch file",
+			expectedTool:   "touch",
+		},
 		{
 			name:           "execute command intent",
 			input:          "run ls command",
@@ -111,6 +121,12 @@ func TestIntentDetector_ExtractFileParams(t *testing.T) {
 			expectedFilename: "myfile.txt",
 			expectedContent:  "test data",
 		},
+		{
+			name:             "no content defaults to empty",
+			input:            "create notes.txt",
+			expectedFilename: "notes.txt",
+			expectedContent:  "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,6 +237,43 @@ func TestIntentDetector_ParseIntentResponse(t *testing.T) {
 			Let me execute this for you.`,
 			expected: 1,
 		},
+		{
+			name: "fenced JSON",
+			response: "```json\n" + `[{"action": "list files", "tool": "list_files", "parameters": {"path": "src"}, "confidence": 0.9}]` + "\n```",
+			expected: 1,
+		},
+		{
+			name: "fenced JSON after prose with brackets",
+			response: "I'll use [list_files] and [read_file] for this [1]:\n```\n" +
+				`[{"tool": "list_files", "confidence": 0.9}, {"tool": "read_file", "parameters": {"filename": "a]b.txt"}, "confidence": 0.9}]` +
+				"\n```\nDone [ok].",
+			expected: 2,
+		},
+		{
+			name:     "brackets inside strings",
+			response: `Sure: [{"action": "write [notes]", "tool": "create_file", "parameters": {"filename": "x.txt", "content": "a [b] ]"}, "confidence": 0.9}] (see [docs])`,
+			expected: 1,
+		},
+		{
+			name:     "JSON mode object",
+			response: `{"intents": [{"tool": "list_files", "confidence": 0.9}]}`,
+			expected: 1,
+		},
+		{
+			name:     "JSON mode object without intents",
+			response: `{"intents": []}`,
+			expected: 0,
+		},
+		{
+			name:     "single intent object",
+			response: `{"action": "list files", "tool": "list_files", "parameters": {}, "confidence": 0.9}`,
+			expected: 1,
+		},
+		{
+			name:     "reasoning before the JSON",
+			response: `<think>maybe [{"tool": "delete_file"}]?</think>[{"tool": "list_files", "confidence": 0.9}]`,
+			expected: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -277,6 +330,10 @@ func (m *mockProvider) SupportsStreaming() bool {
 	return true
 }
 
+func (m *mockProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrNotSupported
+}
+
 func TestIntentDetector_DetectIntent(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -334,4 +391,235 @@ func TestIntentDetector_DetectIntent(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAcceptIntentsReportsEveryIntent(t *testing.T) {
+	type report struct {
+		tool     string
+		accepted bool
+	}
+	var reports []report
+	ReportIntent = func(intent Intent, accepted bool) {
+		reports = append(reports, report{intent.Tool, accepted})
+	}
+	defer func() { ReportIntent = nil }()
+
+	accepted := acceptIntents([]Intent{
+		{Tool: "create_file", Confidence: 0.95},
+		{Tool: "execute_command", Confidence: 0.6},
+		{Tool: "list_files", Confidence: IntentConfidenceThreshold},
+	})
+
+	if len(accepted) != 1 || accepted[0].Tool != "create_file" {
+		t.Errorf("Expected only create_file to be accepted, got %v", accepted)
+	}
+	want := []report{{"create_file", true}, {"execute_command", false}, {"list_files", false}}
+	if len(reports) != len(want) {
+		t.Fatalf("Expected %d reports, got %d", len(want), len(reports))
+	}
+	for i := range want {
+		if reports[i] != want[i] {
+			t.Errorf("Report %d: expected %v, got %v", i, want[i], reports[i])
+		}
+	}
+}
+
+func TestIntentDetectionUsesJSONMode(t *testing.T) {
+	var captured OllamaChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&captured)
+		reply := `{"intents": [{"action": "list files", "tool": "list_files", "parameters": {"path": "."}, "confidence": 0.95}]}`
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: reply}, Done: true})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	intents, err := NewIntentDetector(provider).DetectIntent(context.Background(), "list the files here")
+	if err != nil {
+		t.Fatalf("DetectIntent failed: %v", err)
+	}
+	if captured.Format != ResponseFormatJSON {
+		t.Errorf("Expected the intent request to use JSON mode, got format %q", captured.Format)
+	}
+	if prompt := captured.Messages[len(captured.Messages)-1].Content; !strings.Contains(prompt, `{"intents": []}`) {
+		t.Errorf("Expected the prompt to ask for an object, got:\n%s", prompt)
+	}
+	if len(intents) != 1 || intents[0].Tool != "list_files" {
+		t.Errorf("Expected the list_files intent, got %+v", intents)
+	}
+	if provider.Format != "" {
+		t.Errorf("Expected the provider's own format to be unchanged, got %q", provider.Format)
+	}
+}
+
+func TestOpenAIIntentDetectionUsesJSONMode(t *testing.T) {
+	var captured OpenAIChatRequest
+	reply := `{"intents": [{"action": "list files", "tool": "list_files", "parameters": {"path": "."}, "confidence": 0.95}]}`
+	server := newOpenAITestServer(t, reply, &captured)
+	defer server.Close()
+
+	provider := NewOpenAIProvider("test-key", "gpt-4o-mini", 0.7, 0)
+	provider.BaseURL = server.URL
+	intents, err := NewIntentDetector(provider).DetectIntent(context.Background(), "list the files here")
+	if err != nil {
+		t.Fatalf("DetectIntent failed: %v", err)
+	}
+	if captured.ResponseFormat == nil || captured.ResponseFormat.Type != "json_object" {
+		t.Errorf("Expected the intent request to set response_format json_object, got %+v", captured.ResponseFormat)
+	}
+	// json_object replies cannot be a bare array, so the examples are objects too
+	prompt := captured.Messages[len(captured.Messages)-1].Content
+	if !strings.Contains(prompt, `JSON response: {"intents": []}`) || strings.Contains(prompt, "JSON response: [") {
+		t.Errorf("Expected the prompt to ask for an object, got:\n%s", prompt)
+	}
+	if len(intents) != 1 || intents[0].Tool != "list_files" {
+		t.Errorf("Expected the list_files intent, got %+v", intents)
+	}
+	if provider.ResponseFormat != "" {
+		t.Errorf("Expected the provider's own response format to be unchanged, got %q", provider.ResponseFormat)
+	}
+}
+
+func TestExtractIntentsFromTextNeedsAnInstruction(t *testing.T) {
+	detector := &IntentDetector{}
+
+	// Replies that explain or mention tools trigger nothing
+	explanations := []string{
+		"The create_file tool creates a new file with the given content.",
+		"You could use create_file or write_file for that, but I don't think it's needed.",
+		"Don't use delete_file here; it removes files permanently.",
+		"Tools like list_files and read_file let me look at your project.",
+		"To create a file, I would normally call create_file, but greetings need no tools.",
+		"There is no reason to run execute_command for a question about history.",
+	}
+	for _, text := range explanations {
+		if intents := detector.extractIntentsFromText(text); len(intents) != 0 {
+			t.Errorf("Expected no intents from %q, got %+v", text, intents)
+		}
+	}
+
+	instructions := []struct {
+		text string
+		tool string
+	}{
+		{"Use list_files to see the directory.", "list_files"},
+		{"Sure. I'll call the read file tool on config.json.", "read_file"},
+		{"Plan:\n1. Use create_file for notes.txt", "create_file"},
+		{"Execute execute_command to run ls -la.", "execute_command"},
+	}
+	for _, tt := range instructions {
+		intents := detector.extractIntentsFromText(tt.text)
+		if len(intents) != 1 || intents[0].Tool != tt.tool {
+			t.Errorf("Expected %s from %q, got %+v", tt.tool, tt.text, intents)
+			continue
+		}
+		if intents[0].Confidence > IntentConfidenceThreshold {
+			t.Errorf("Expected intents from prose to stay below the threshold, got %v", intents[0].Confidence)
+		}
+	}
+
+	intents := detector.extractIntentsFromText("Execute execute_command to run ls -la.")
+	if len(intents) == 1 && intents[0].Parameters["command"] != "ls -la" {
+		t.Errorf("Expected the command after the instruction, got %v", intents[0].Parameters["command"])
+	}
+}
+
+func TestFallbackFileOperations(t *testing.T) {
+	detector := &IntentDetector{}
+
+	tests := []struct {
+		input  string
+		tool   string
+		params map[string]interface{}
+	}{
+		// Destructive operations are reported, but stay at the threshold so they never run
+		{"read config.json", "read_file", map[string]interface{}{"filename": "config.json"}},
+		{"please show me 'src/main.go'.", "read_file", map[string]interface{}{"filename": "src/main.go"}},
+		{"cat notes.txt", "read_file", map[string]interface{}{"filename": "notes.txt"}},
+		{"touch build/.stamp", "touch", map[string]interface{}{"filename": "build/.stamp"}},
+		{"delete temp.txt", "delete_file", map[string]interface{}{"filename": "temp.txt"}},
+		{"remove the file old/build.log", "delete_file", map[string]interface{}{"filename": "old/build.log"}},
+		{"copy a.txt to b.txt", "copy_file", map[string]interface{}{"source": "a.txt", "destination": "b.txt"}},
+		{"copy report.pdf into backup/", "copy_file", map[string]interface{}{"source": "report.pdf", "destination": "backup/"}},
+		{"move draft.md to posts/draft.md", "move_file", map[string]interface{}{"source": "draft.md", "destination": "posts/draft.md"}},
+		{"rename notes.txt as todo.txt", "move_file", map[string]interface{}{"source": "notes.txt", "destination": "todo.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			intents := detector.fallbackPatternMatching(tt.input)
+			if len(intents) != 1 || intents[0].Tool != tt.tool {
+				t.Fatalf("Expected a single %s intent, got %+v", tt.tool, intents)
+			}
+			if fmt.Sprint(intents[0].Parameters) != fmt.Sprint(tt.params) {
+				t.Errorf("Parameters = %v, want %v", intents[0].Parameters, tt.params)
+			}
+			destructive := tt.tool == "delete_file" || tt.tool == "move_file"
+			if !destructive && intents[0].Confidence <= IntentConfidenceThreshold {
+				t.Errorf("Expected a command-like request to clear the threshold, got %v", intents[0].Confidence)
+			}
+			if destructive && intents[0].Confidence > IntentConfidenceThreshold {
+				t.Errorf("Expected %s from the patterns never to clear the threshold, got %v", tt.tool, intents[0].Confidence)
+			}
+		})
+	}
+
+	// Without a file to act on, when the file is not the verb's object, or
+	// when the verb is not the request, nothing runs
+	for _, input := range []string{"delete everything", "copy that", "how do I read version 3.5 notes", "what does main.go read",
+		"delete the second paragraph of README.md", "remove unused imports from main.go"} {
+		for _, intent := range detector.fallbackPatternMatching(input) {
+			if intent.Confidence > IntentConfidenceThreshold {
+				t.Errorf("Expected nothing to run for %q, got %+v", input, intent)
+			}
+		}
+	}
+}
+
+func TestDetectIntentTrustsAnEmptyAnswer(t *testing.T) {
+	provider := NewMockProvider("test")
+	provider.Responses["delete the second paragraph of README.md"] = "[]"
+
+	intents, err := NewIntentDetector(provider).DetectIntent(context.Background(), "delete the second paragraph of README.md")
+	if err != nil {
+		t.Fatalf("DetectIntent failed: %v", err)
+	}
+	if len(intents) != 0 {
+		t.Errorf("Expected the model's empty answer to stand, got %+v", intents)
+	}
+}
+
+func TestExtractFilenames(t *testing.T) {
+	got := extractFilenames(`open "notes.txt", then README.md and src/app/main.go. Version 1.2 is out.`)
+	want := []string{"notes.txt", "README.md", "src/app/main.go"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("extractFilenames = %q, want %q", got, want)
+	}
+}
+
+func TestExtractFileParamsLanguageExtension(t *testing.T) {
+	detector := &IntentDetector{}
+	defer func() { DefaultFileExtension = ".txt" }()
+
+	tests := []struct {
+		input    string
+		filename string
+	}{
+		{"create a python file called foo", "foo.py"},
+		{"make a Go file named server", "server.go"},
+		{"create a rust file called main with fn main() {}", "main.rs"},
+		{"create a c++ file called engine", "engine.cpp"},
+		{"create a python file called notes.md", "notes.md"}, // an extension given is kept
+		{"create file called todo", "todo.txt"},
+		{"create a file called go", "go.txt"}, // a language named as the file is no hint
+	}
+	for _, tt := range tests {
+		if got := detector.extractFileParams(tt.input)["filename"]; got != tt.filename {
+			t.Errorf("extractFileParams(%q) filename = %v, want %s", tt.input, got, tt.filename)
+		}
+	}
+
+	DefaultFileExtension = ".md"
+	if got := detector.extractFileParams("create file called todo")["filename"]; got != "todo.md" {
+		t.Errorf("Expected the configured default extension, got %v", got)
+	}
+}
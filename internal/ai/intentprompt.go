@@ -0,0 +1,157 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// IntentPromptConfig supplies a custom intent detection prompt and examples
+type IntentPromptConfig interface {
+	GetIntentPromptFile() string                   // template file, empty = DefaultIntentPrompt
+	GetIntentExamples() map[string]json.RawMessage // input -> intents array, nil = DefaultIntentExamples
+}
+
+// DefaultIntentPrompt is the text/template asking a model which tools a
+// request needs. It is executed with .Tools (each with .Name and
+// .Description), .Examples (each with .Input and .Output, the intents as
+// JSON, already wrapped in an object in JSON mode), .Input, the user's request,
+// and .JSONMode, set when the provider can only reply with a JSON object.
+const DefaultIntentPrompt = `You are a conservative intent detection system. Only detect tool usage when the user explicitly requests file operations, commands, or system actions.
+
+DO NOT detect intents for:
+- Greetings (hi, hello, hey)
+- General questions
+- Casual conversation
+- Abstract requests
+
+Available tools and their purposes:
+{{range .Tools}}- {{.Name}}: {{.Description}}
+{{end}}
+Respond with JSON containing an array of detected intents. Each intent should have:
+- "action": brief description of what the user wants to do
+- "tool": the tool name to use (from the list above)
+- "parameters": object with the parameters for the tool
+- "confidence": number between 0-1 indicating confidence
+
+Only detect intents with confidence > 0.8 when user explicitly mentions:
+- File operations (create, read, write, delete specific files)
+- Directory operations (list, create, delete directories)
+- System commands (run specific commands)
+
+For general conversation, greetings, or questions, respond with: {{if .JSONMode}}{"intents": []}{{else}}[]{{end}}
+{{if .JSONMode}}
+The response must be a JSON object, so put the array in it under "intents", e.g. {"intents": []}.
+{{end}}{{if .Examples}}
+Examples:
+{{range .Examples}}
+User input: "{{.Input}}"
+JSON response: {{.Output}}
+{{end}}{{end}}
+User input: "{{.Input}}"
+
+JSON response:`
+
+// DefaultIntentExamples are shown to the detector unless the config has its own
+var DefaultIntentExamples = map[string]json.RawMessage{
+	"hi there":                    json.RawMessage(`[]`),
+	"what is a goroutine?":        json.RawMessage(`[]`),
+	"list the files in src":       json.RawMessage(`[{"action":"list files","tool":"list_files","parameters":{"path":"src"},"confidence":0.95}]`),
+	"create notes.txt with hello": json.RawMessage(`[{"action":"create file","tool":"create_file","parameters":{"filename":"notes.txt","content":"hello"},"confidence":0.95}]`),
+}
+
+// intentExample is one example as the prompt template sees it
+type intentExample struct {
+	Input  string
+	Output string
+}
+
+// intentPromptData is what the intent prompt template is executed with
+type intentPromptData struct {
+	Tools    []Tool
+	Examples []intentExample
+	Input    string
+	JSONMode bool
+}
+
+var (
+	intentPrompt   = template.Must(template.New("intent").Parse(DefaultIntentPrompt))
+	intentExamples = decodeIntentExamples(DefaultIntentExamples)
+)
+
+// ConfigureIntentPrompt loads the intent prompt template and examples from the
+// config. On an error the built-in prompt stays in use.
+func ConfigureIntentPrompt(cfg IntentPromptConfig) error {
+	examples := cfg.GetIntentExamples()
+	if examples == nil {
+		examples = DefaultIntentExamples
+	}
+	intentExamples = decodeIntentExamples(examples)
+
+	intentPrompt = template.Must(template.New("intent").Parse(DefaultIntentPrompt))
+	file := cfg.GetIntentPromptFile()
+	if file == "" {
+		return nil
+	}
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("reading intent prompt: %w", err)
+	}
+	tmpl, err := template.New("intent").Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid intent prompt template: %w", err)
+	}
+	// Catch references to fields the template will never get
+	if err := tmpl.Execute(&bytes.Buffer{}, intentPromptData{}); err != nil {
+		return fmt.Errorf("invalid intent prompt template: %w", err)
+	}
+	intentPrompt = tmpl
+	return nil
+}
+
+// decodeIntentExamples sorts the examples by input, dropping those whose
+// intents are not a JSON array of intents
+func decodeIntentExamples(raw map[string]json.RawMessage) []intentExample {
+	examples := make([]intentExample, 0, len(raw))
+	for input, intents := range raw {
+		var decoded []Intent
+		if err := json.Unmarshal(intents, &decoded); err != nil {
+			slog.Warn("intent example skipped", "input", input, "error", err)
+			continue
+		}
+		var compact bytes.Buffer
+		json.Compact(&compact, intents)
+		examples = append(examples, intentExample{Input: input, Output: compact.String()})
+	}
+	sort.Slice(examples, func(i, j int) bool { return examples[i].Input < examples[j].Input })
+	return examples
+}
+
+// renderIntentPrompt fills the intent prompt template for a request
+func renderIntentPrompt(userInput string, jsonMode bool) string {
+	data := intentPromptData{
+		Tools:    GetAvailableTools(),
+		Examples: intentExamples,
+		Input:    userInput,
+		JSONMode: jsonMode,
+	}
+	if jsonMode {
+		// Show the examples the way the reply has to look
+		data.Examples = make([]intentExample, len(intentExamples))
+		for i, example := range intentExamples {
+			data.Examples[i] = intentExample{Input: example.Input, Output: `{"intents": ` + example.Output + `}`}
+		}
+	}
+	var out strings.Builder
+	if err := intentPrompt.Execute(&out, data); err != nil {
+		slog.Warn("intent prompt failed, using the built-in one", "error", err)
+		out.Reset()
+		template.Must(template.New("intent").Parse(DefaultIntentPrompt)).Execute(&out, data)
+	}
+	return out.String()
+}
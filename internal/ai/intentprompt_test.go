@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type intentPromptConfig struct {
+	file     string
+	examples map[string]json.RawMessage
+}
+
+func (c intentPromptConfig) GetIntentPromptFile() string                   { return c.file }
+func (c intentPromptConfig) GetIntentExamples() map[string]json.RawMessage { return c.examples }
+
+func TestDefaultIntentPrompt(t *testing.T) {
+	if err := ConfigureIntentPrompt(intentPromptConfig{}); err != nil {
+		t.Fatalf("ConfigureIntentPrompt failed: %v", err)
+	}
+	prompt := renderIntentPrompt("delete old.log", false)
+
+	for _, want := range []string{
+		"- create_file: ",
+		`User input: "list the files in src"`,
+		`"tool":"list_files"`,
+		`User input: "delete old.log"`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q:\n%s", want, prompt)
+		}
+	}
+	if !strings.HasSuffix(prompt, "JSON response:") {
+		t.Errorf("Expected the prompt to end asking for JSON, got %q", prompt[len(prompt)-40:])
+	}
+}
+
+func TestConfiguredIntentPrompt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "intent.tmpl")
+	template := `Herramientas:{{range .Tools}} {{.Name}}{{end}}
+{{range .Examples}}{{.Input}} => {{.Output}}
+{{end}}Petición: {{.Input}}`
+	if err := os.WriteFile(file, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer ConfigureIntentPrompt(intentPromptConfig{})
+
+	err := ConfigureIntentPrompt(intentPromptConfig{
+		file: file,
+		examples: map[string]json.RawMessage{
+			"hola":           json.RawMessage(`[]`),
+			"lista src":      json.RawMessage(`[ {"tool": "list_files", "parameters": {"path": "src"}, "confidence": 0.9} ]`),
+			"not an example": json.RawMessage(`{"tool": "list_files"}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConfigureIntentPrompt failed: %v", err)
+	}
+
+	prompt := renderIntentPrompt("borra old.log", false)
+	if !strings.HasPrefix(prompt, "Herramientas: ") || !strings.HasSuffix(prompt, "Petición: borra old.log") {
+		t.Errorf("Expected the configured template, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "hola => []\nlista src => [{\"tool\":\"list_files\",\"parameters\":{\"path\":\"src\"},\"confidence\":0.9}]\n") {
+		t.Errorf("Expected the configured examples in order, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "not an example") || strings.Contains(prompt, "goroutine") {
+		t.Errorf("Expected only valid configured examples, got:\n%s", prompt)
+	}
+}
+
+func TestInvalidIntentPromptKeepsDefault(t *testing.T) {
+	dir := t.TempDir()
+	defer ConfigureIntentPrompt(intentPromptConfig{})
+
+	for name, template := range map[string]string{
+		"syntax.tmpl":  "{{range .Tools}}",
+		"unknown.tmpl": "{{.Request}}",
+	} {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte(template), 0644)
+		if err := ConfigureIntentPrompt(intentPromptConfig{file: file}); err == nil {
+			t.Errorf("Expected %q to be rejected", template)
+		}
+		if prompt := renderIntentPrompt("hi", false); !strings.Contains(prompt, "conservative intent detection") {
+			t.Errorf("Expected the built-in prompt after %q was rejected", template)
+		}
+	}
+
+	if err := ConfigureIntentPrompt(intentPromptConfig{file: filepath.Join(dir, "missing.tmpl")}); err == nil {
+		t.Error("Expected a missing template file to be an error")
+	}
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tala/internal/metrics"
+)
+
+// Metrics receives request, token and tool-call metrics when set. While it is
+// nil nothing is recorded. Set it before creating providers.
+var Metrics *metrics.Registry
+
+// StartMetrics turns on metrics and serves them at http://addr/metrics in the
+// background. A server that fails to start only logs a warning.
+func StartMetrics(addr string) {
+	Metrics = metrics.NewRegistry()
+	go func() {
+		if err := Metrics.Serve(addr); err != nil {
+			slog.Warn("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+}
+
+// MetricsMiddleware records request counts, errors, latency, words and token
+// usage for one provider. Words are counted for every request; tokens only
+// when the provider reports them, as Ollama does.
+func MetricsMiddleware(registry *metrics.Registry, provider Provider) Middleware {
+	name := provider.GetName()
+	return func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			start := time.Now()
+			response, err := next(ctx, prompt)
+
+			status := "ok"
+			if err != nil {
+				status = "error"
+			}
+			registry.Add("tala_requests_total", "Requests sent to AI providers.", 1, "provider", name, "status", status)
+			registry.Observe("tala_request_duration_seconds", "Time taken by AI provider requests.", time.Since(start).Seconds(), "provider", name)
+			registry.Add("tala_words_total", "Words sent to and received from AI providers.", float64(len(strings.Fields(prompt))), "provider", name, "direction", "input")
+			if err != nil {
+				return response, err
+			}
+			registry.Add("tala_words_total", "Words sent to and received from AI providers.", float64(len(strings.Fields(response))), "provider", name, "direction", "output")
+			if usage, ok := LastUsage(provider); ok && !usage.At.Before(start) {
+				registry.Add("tala_tokens_total", "Tokens AI providers reported reading and generating.", float64(usage.PromptTokens), "provider", name, "direction", "input")
+				registry.Add("tala_tokens_total", "Tokens AI providers reported reading and generating.", float64(usage.CompletionTokens), "provider", name, "direction", "output")
+			}
+			return response, err
+		}
+	}
+}
+
+// recordToolCall counts a tool execution and how long it took
+func recordToolCall(name string, success bool, elapsed time.Duration) {
+	if Metrics == nil {
+		return
+	}
+	status := "ok"
+	if !success {
+		status = "error"
+	}
+	Metrics.Add("tala_tool_calls_total", "Tool calls executed for the AI.", 1, "tool", name, "status", status)
+	Metrics.Observe("tala_tool_duration_seconds", "Time taken by tool calls.", elapsed.Seconds(), "tool", name)
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tala/internal/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	registry := metrics.NewRegistry()
+	scripted := &scriptedProvider{responses: []string{"three word reply"}}
+	provider := WrapProvider(scripted, MetricsMiddleware(registry, scripted))
+
+	if _, err := provider.GenerateResponse(context.Background(), "hello there"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, want := range []string{
+		`tala_requests_total{provider="Scripted",status="ok"} 1`,
+		`tala_words_total{provider="Scripted",direction="input"} 2`,
+		`tala_words_total{provider="Scripted",direction="output"} 3`,
+		`tala_request_duration_seconds_count{provider="Scripted"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, out.String())
+		}
+	}
+	// Words are not passed off as tokens when the provider reports none
+	if strings.Contains(out.String(), "tala_tokens_total") {
+		t.Errorf("Expected no token counts from a provider that does not report them:\n%s", out.String())
+	}
+}
+
+func TestMetricsMiddlewareReportsProviderTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaChatResponse{
+			Message:     OllamaMessage{Role: "assistant", Content: "three word reply"},
+			Done:        true,
+			OllamaStats: OllamaStats{PromptEvalCount: 7, EvalCount: 4, EvalDuration: int64(time.Second)},
+		})
+	}))
+	defer server.Close()
+	registry := metrics.NewRegistry()
+	ollama := NewOllamaProvider("llama3", 0.7, 100, server.URL)
+	provider := WrapProvider(ollama, MetricsMiddleware(registry, ollama))
+
+	if _, err := provider.GenerateResponse(context.Background(), "hello there"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out strings.Builder
+	registry.WriteText(&out)
+	for _, want := range []string{
+		`tala_tokens_total{provider="Ollama",direction="input"} 7`,
+		`tala_tokens_total{provider="Ollama",direction="output"} 4`,
+		`tala_words_total{provider="Ollama",direction="output"} 3`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRecordToolCall(t *testing.T) {
+	Metrics = metrics.NewRegistry()
+	defer func() { Metrics = nil }()
+
+	recordToolCall("read_file", true, time.Millisecond)
+	recordToolCall("read_file", false, time.Millisecond)
+
+	var out strings.Builder
+	Metrics.WriteText(&out)
+	for _, want := range []string{
+		`tala_tool_calls_total{tool="read_file",status="ok"} 1`,
+		`tala_tool_calls_total{tool="read_file",status="error"} 1`,
+		`tala_tool_duration_seconds_count{tool="read_file"} 2`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Handler produces a response for a prompt. It is the unit that middleware wraps.
+type Handler func(ctx context.Context, prompt string) (string, error)
+
+// Middleware observes or modifies a request on its way to the provider and the
+// response on its way back. A custom middleware looks like:
+//
+//	func Uppercase(next ai.Handler) ai.Handler {
+//		return func(ctx context.Context, prompt string) (string, error) {
+//			response, err := next(ctx, prompt)
+//			return strings.ToUpper(response), err
+//		}
+//	}
+//
+// Returning without calling next short-circuits the provider, which is how a
+// cache would serve a stored response.
+type Middleware func(next Handler) Handler
+
+// WrapProvider returns a Provider that runs every request through the given
+// middleware. The first middleware is the outermost one.
+func WrapProvider(p Provider, middleware ...Middleware) Provider {
+	if len(middleware) == 0 {
+		return p
+	}
+	return &wrappedProvider{inner: p, middleware: middleware}
+}
+
+// UnwrapProvider returns the innermost provider beneath any WrapProvider layers
+func UnwrapProvider(p Provider) Provider {
+	for {
+		wrapped, ok := p.(*wrappedProvider)
+		if !ok {
+			return p
+		}
+		p = wrapped.inner
+	}
+}
+
+type wrappedProvider struct {
+	inner      Provider
+	middleware []Middleware
+}
+
+// chain applies the middleware around the final handler
+func (w *wrappedProvider) chain(final Handler) Handler {
+	h := final
+	for i := len(w.middleware) - 1; i >= 0; i-- {
+		h = w.middleware[i](h)
+	}
+	return h
+}
+
+func (w *wrappedProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return w.chain(w.inner.GenerateResponse)(ctx, prompt)
+}
+
+// toolsKey marks the context of a request that may run tools
+type toolsKey struct{}
+
+// usesTools reports whether a request may run tools, so a middleware can avoid
+// answering it without the provider
+func usesTools(ctx context.Context) bool {
+	return ctx.Value(toolsKey{}) != nil
+}
+
+func (w *wrappedProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
+	ctx = context.WithValue(ctx, toolsKey{}, true)
+	var toolResults []ToolResult
+	h := w.chain(func(ctx context.Context, prompt string) (string, error) {
+		response, results, err := w.inner.GenerateResponseWithTools(ctx, prompt)
+		toolResults = results
+		return response, err
+	})
+	response, err := h(ctx, prompt)
+	return response, toolResults, err
+}
+
+func (w *wrappedProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	// Chunks go straight to the callback; middleware sees the complete response.
+	// A response served by the middleware itself arrives as one chunk.
+	streamed := false
+	response, err := w.chain(func(ctx context.Context, prompt string) (string, error) {
+		streamed = true
+		return w.inner.GenerateStreamingResponse(ctx, prompt, callback)
+	})(ctx, prompt)
+	if !streamed && err == nil && callback != nil {
+		callback(response)
+	}
+	return response, err
+}
+
+func (w *wrappedProvider) GetName() string {
+	return w.inner.GetName()
+}
+
+func (w *wrappedProvider) SupportsTools() bool {
+	return w.inner.SupportsTools()
+}
+
+func (w *wrappedProvider) SupportsStreaming() bool {
+	return w.inner.SupportsStreaming()
+}
+
+// Embed goes straight to the provider; middleware only sees generation requests
+func (w *wrappedProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return w.inner.Embed(ctx, texts)
+}
+
+// LoggingMiddleware logs each prompt and the size of the response or the error
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			logger.Printf("request: %d chars", len(prompt))
+			response, err := next(ctx, prompt)
+			if err != nil {
+				logger.Printf("error: %v", err)
+			} else {
+				logger.Printf("response: %d chars", len(response))
+			}
+			return response, err
+		}
+	}
+}
+
+// TimingMiddleware reports how long each request took, including failed ones
+func TimingMiddleware(report func(prompt string, elapsed time.Duration, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			start := time.Now()
+			response, err := next(ctx, prompt)
+			report(prompt, time.Since(start), err)
+			return response, err
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapProviderRunsMiddlewareInOrder(t *testing.T) {
+	inner := &scriptedProvider{responses: []string{"response"}}
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, prompt string) (string, error) {
+				order = append(order, name+" in")
+				response, err := next(ctx, prompt+" "+name)
+				order = append(order, name+" out")
+				return response + " " + name, err
+			}
+		}
+	}
+
+	provider := WrapProvider(inner, tag("a"), tag("b"))
+	response, err := provider.GenerateResponse(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "a in,b in,b out,a out" {
+		t.Errorf("Unexpected middleware order: %s", got)
+	}
+	if inner.prompts[0] != "prompt a b" {
+		t.Errorf("Expected middleware to modify the prompt, got %q", inner.prompts[0])
+	}
+	if response != "response b a" {
+		t.Errorf("Expected middleware to modify the response, got %q", response)
+	}
+	if UnwrapProvider(provider) != inner {
+		t.Error("Expected UnwrapProvider to return the inner provider")
+	}
+	if provider.GetName() != "Scripted" {
+		t.Errorf("Expected wrapped provider to keep the inner name, got %q", provider.GetName())
+	}
+}
+
+func TestWrapProviderShortCircuit(t *testing.T) {
+	inner := &scriptedProvider{responses: []string{"from provider"}}
+	cached := func(next Handler) Handler {
+		return func(ctx context.Context, prompt string) (string, error) {
+			return "from cache", nil
+		}
+	}
+
+	response, _, err := WrapProvider(inner, cached).GenerateResponseWithTools(context.Background(), "hi")
+	if err != nil || response != "from cache" {
+		t.Errorf("Expected cached response, got %q (%v)", response, err)
+	}
+	if len(inner.prompts) != 0 {
+		t.Error("Expected the provider not to be called")
+	}
+}
+
+func TestBuiltinMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	var elapsed time.Duration
+	timed := false
+
+	provider := WrapProvider(&scriptedProvider{responses: []string{"hello"}},
+		LoggingMiddleware(log.New(&logs, "", 0)),
+		TimingMiddleware(func(prompt string, d time.Duration, err error) {
+			timed = true
+			elapsed = d
+		}))
+
+	if _, err := provider.GenerateStreamingResponse(context.Background(), "hi", func(string) {}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(logs.String(), "request: 2 chars") || !strings.Contains(logs.String(), "response: 5 chars") {
+		t.Errorf("Unexpected log output: %q", logs.String())
+	}
+	if !timed || elapsed < 0 {
+		t.Errorf("Expected timing to be reported, got %v", elapsed)
+	}
+}
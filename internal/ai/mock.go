@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tala/internal/glyphs"
+)
+
+// MockProvider answers without any network access, for demos, CI and bug reproduction.
+// Prompts containing a key of Responses (case-insensitive) get that canned response;
+// anything else is echoed back. Prompts matching a key of ToolCalls run those tools.
+type MockProvider struct {
+	Model         string
+	SystemPrompt  string
+	Responses     map[string]string
+	ToolCalls     map[string][]ToolCall
+	StopSequences []string // replies end before the first of these, as a real model's would
+}
+
+func NewMockProvider(model string) *MockProvider {
+	return &MockProvider{
+		Model:     model,
+		Responses: make(map[string]string),
+		ToolCalls: make(map[string][]ToolCall),
+	}
+}
+
+func (p *MockProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	if key, ok := matchScriptKey(prompt, p.Responses); ok {
+		return p.stop(p.Responses[key]), nil
+	}
+	return p.stop(fmt.Sprintf("Echo: %s", prompt)), nil
+}
+
+// stop cuts a reply before its first stop sequence
+func (p *MockProvider) stop(response string) string {
+	for _, seq := range p.StopSequences {
+		if i := strings.Index(response, seq); seq != "" && i >= 0 {
+			response = response[:i]
+		}
+	}
+	return response
+}
+
+func (p *MockProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
+	defer beginToolTurn(prompt)()
+	key, ok := matchScriptKey(prompt, p.ToolCalls)
+	if !ok {
+		response, err := p.GenerateResponse(ctx, prompt)
+		return response, []ToolResult{}, err
+	}
+
+	var toolResults []ToolResult
+	summary := "I have completed the following operations:\n"
+	for _, call := range p.ToolCalls[key] {
+		var result ToolResult
+		if call.Name == SaveResponseTool {
+			// Scripted saves write the scripted (or echoed) reply
+			response, _ := p.GenerateResponse(ctx, prompt)
+			result = saveResponse([]Intent{{Tool: SaveResponseTool, Parameters: call.Arguments}}, response)[0]
+		} else {
+			result = ExecuteTool(call.Name, call.Arguments)
+		}
+		toolResults = append(toolResults, result)
+		if result.Success {
+			summary += fmt.Sprintf("%s %s\n", glyphs.Check, result.Content)
+		} else {
+			summary += fmt.Sprintf("%s %s failed: %s\n", glyphs.Cross, result.Name, result.Content)
+		}
+	}
+	return summary, toolResults, nil
+}
+
+func (p *MockProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	response, err := p.GenerateResponse(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	// An interrupted stream returns what was sent so far
+	var sent strings.Builder
+	for i, word := range strings.Split(response, " ") {
+		if ctx.Err() != nil {
+			return sent.String(), ctx.Err()
+		}
+		if i > 0 {
+			word = " " + word
+		}
+		sent.WriteString(word)
+		callback(word)
+	}
+	return response, nil
+}
+
+func (p *MockProvider) GetName() string {
+	return "Mock"
+}
+
+func (p *MockProvider) SupportsTools() bool {
+	return true
+}
+
+func (p *MockProvider) SupportsStreaming() bool {
+	return true
+}
+
+// Embed is not scripted by the mock provider
+func (p *MockProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, fmt.Errorf("mock embeddings: %w", ErrNotSupported)
+}
+
+// matchScriptKey finds the longest key contained in prompt, ignoring case,
+// so more specific scripts win over general ones
+func matchScriptKey[V any](prompt string, script map[string]V) (string, bool) {
+	keys := make([]string, 0, len(script))
+	for key := range script {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	lower := strings.ToLower(prompt)
+	for _, key := range keys {
+		if key != "" && strings.Contains(lower, strings.ToLower(key)) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// decodeMockToolCalls parses the mock_tool_calls config value, skipping malformed entries
+func decodeMockToolCalls(raw map[string]json.RawMessage) map[string][]ToolCall {
+	calls := make(map[string][]ToolCall, len(raw))
+	for key, value := range raw {
+		var script []ToolCall
+		if err := json.Unmarshal(value, &script); err == nil {
+			calls[key] = script
+		}
+	}
+	return calls
+}
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"log/slog"
+	"regexp"
+)
+
+// Model name patterns of the cloud providers. Ollama serves models under any
+// name, so only these are known not to be Ollama models.
+var (
+	openAIModelPattern    = regexp.MustCompile(`^(gpt-[0-9]|o[0-9]|chatgpt-|ft:gpt-)`)
+	anthropicModelPattern = regexp.MustCompile(`^claude-`)
+)
+
+// DefaultModel returns the model suggested for a provider, or "" for one it
+// does not know
+func DefaultModel(providerType string) string {
+	for _, info := range SupportedProviders() {
+		if info.Name == providerType {
+			return info.DefaultModel
+		}
+	}
+	return ""
+}
+
+// ModelFits reports whether a model name looks like one the provider serves.
+// Azure OpenAI, whose deployments the user names, unknown providers and the
+// mock provider accept any name.
+func ModelFits(providerType, model string) bool {
+	switch providerType {
+	case "openai":
+		return openAIModelPattern.MatchString(model)
+	case "anthropic":
+		return anthropicModelPattern.MatchString(model)
+	case "ollama":
+		return model != "" && !openAIModelPattern.MatchString(model) && !anthropicModelPattern.MatchString(model)
+	}
+	return true
+}
+
+// warnModelMismatch logs a warning with a suggested model when the configured
+// model does not look like one the provider serves, the usual reason requests
+// fail after switching provider
+func warnModelMismatch(providerType, model string) {
+	if model == "" || ModelFits(providerType, model) {
+		return
+	}
+	slog.Warn("the model does not look like a "+providerType+" model; requests may fail",
+		"model", model, "suggested", DefaultModel(providerType))
+}
@@ -0,0 +1,45 @@
+package ai
+
+import "testing"
+
+func TestModelFits(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		fits            bool
+	}{
+		{"openai", "gpt-4o-mini", true},
+		{"openai", "o3-mini", true},
+		{"openai", "llama3.2:1b", false},
+		{"azure-openai", "chat-prod", true},
+		{"anthropic", "claude-3-5-sonnet-latest", true},
+		{"anthropic", "gpt-4o", false},
+		{"ollama", "llama3.2:1b", true},
+		{"ollama", "gpt-oss:20b", true},
+		{"ollama", "claude-3-5-sonnet-latest", false},
+		{"ollama", "gpt-4o-mini", false},
+		{"mock", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := ModelFits(tt.provider, tt.model); got != tt.fits {
+			t.Errorf("ModelFits(%q, %q) = %v, expected %v", tt.provider, tt.model, got, tt.fits)
+		}
+	}
+}
+
+func TestDefaultModelsFit(t *testing.T) {
+	for _, info := range SupportedProviders() {
+		if info.DefaultModel == "" {
+			// Only providers serving models under names the user picks, like Azure deployments, go without
+			if !ModelFits(info.Name, "my-deployment") {
+				t.Errorf("Expected %s to have a default model or to accept any model name", info.Name)
+			}
+			continue
+		}
+		if !ModelFits(info.Name, info.DefaultModel) {
+			t.Errorf("Expected %s to have a default model that fits it, got %q", info.Name, info.DefaultModel)
+		}
+	}
+	if DefaultModel("unknown") != "" {
+		t.Error("Expected no default model for an unknown provider")
+	}
+}
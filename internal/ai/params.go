@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// applySampling copies top_p and the presence and frequency penalties from
+// the config onto the providers that send them, OpenAI and Ollama; unset
+// values stay nil so the server's defaults apply. Other providers warn when
+// they are set.
+func applySampling(provider Provider, cfg interface{}) {
+	var topP, presence, frequency *float64
+	if tp, ok := cfg.(interface{ GetTopP() *float64 }); ok {
+		topP = tp.GetTopP()
+	}
+	if pp, ok := cfg.(interface{ GetPresencePenalty() *float64 }); ok {
+		presence = pp.GetPresencePenalty()
+	}
+	if fp, ok := cfg.(interface{ GetFrequencyPenalty() *float64 }); ok {
+		frequency = fp.GetFrequencyPenalty()
+	}
+
+	switch p := provider.(type) {
+	case *OpenAIProvider:
+		p.TopP, p.PresencePenalty, p.FrequencyPenalty = topP, presence, frequency
+	case *OllamaProvider:
+		p.TopP, p.PresencePenalty, p.FrequencyPenalty = topP, presence, frequency
+	case *AnthropicProvider:
+		var ignored []string
+		for name, value := range map[string]*float64{"top_p": topP, "presence_penalty": presence, "frequency_penalty": frequency} {
+			if value != nil {
+				ignored = append(ignored, name)
+			}
+		}
+		sort.Strings(ignored)
+		warnIgnored(p, ignored...)
+	}
+}
+
+// warnIgnored tells the user that the named settings are set but never sent
+// by the provider, so they have no effect
+func warnIgnored(provider Provider, names ...string) {
+	if len(names) > 0 {
+		slog.Warn("settings are not sent to this provider and have no effect",
+			"provider", provider.GetName(), "settings", strings.Join(names, ", "))
+	}
+}
+
+// mergeParams marshals v, a JSON object, and adds the extra parameters to it
+// as they are. Fields tala sets itself keep their values, so extra parameters
+// only reach what the settings leave out.
+func mergeParams(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("merging extra parameters: %w", err)
+	}
+	for key, value := range extra {
+		if _, set := fields[key]; !set {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// MarshalJSON adds the extra parameters to the options Ollama receives
+func (o OllamaOptions) MarshalJSON() ([]byte, error) {
+	type options OllamaOptions // without this method, so it marshals normally
+	return mergeParams(options(o), o.Extra)
+}
+
+// OpenAIChatRequest is the body of a /chat/completions request
+type OpenAIChatRequest struct {
+	Model            string          `json:"model"`
+	Messages         []Message       `json:"messages"`
+	Temperature      float64         `json:"temperature"`
+	MaxTokens        int             `json:"max_tokens,omitempty"` // omitted when 0 (unlimited)
+	Stop             []string        `json:"stop,omitempty"`
+	Seed             *int            `json:"seed,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+}
+
+// ResponseFormat asks OpenAI for a kind of output, e.g. {"type": "json_object"}
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// OpenAIChatResponse is a /chat/completions response, or one event of a
+// streamed one, whose choices carry a delta instead of a message
+type OpenAIChatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		Delta        Message `json:"delta"`
+		FinishReason string  `json:"finish_reason"` // "length" when max_tokens cut the reply short
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// chatRequestBody returns the /chat/completions body for prompt, carrying the
+// provider's settings and extra parameters at the top level and the context
+// files in the system message
+func (p *OpenAIProvider) chatRequestBody(ctx context.Context, prompt string, stream bool) ([]byte, error) {
+	req := OpenAIChatRequest{
+		Model:            p.Model,
+		Temperature:      p.Temperature,
+		MaxTokens:        p.MaxTokens,
+		Stop:             p.StopSequences,
+		Seed:             p.Seed,
+		TopP:             p.TopP,
+		PresencePenalty:  p.PresencePenalty,
+		FrequencyPenalty: p.FrequencyPenalty,
+		Stream:           stream,
+	}
+	if system := withContext(p.SystemPrompt); system != "" {
+		req.Messages = append(req.Messages, Message{Role: "system", Content: system})
+	}
+	req.Messages = append(req.Messages, Message{Role: "user", Content: prompt})
+	if responseFormat(ctx, p.ResponseFormat) == ResponseFormatJSON {
+		req.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+	return mergeParams(req, p.ExtraParams)
+}
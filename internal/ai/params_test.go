@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newBodyServer returns a server that decodes each request body into body and
+// answers every chat and generate endpoint with reply
+func newBodyServer(t *testing.T, body *map[string]interface{}, reply string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		*body = nil
+		if err := json.Unmarshal(data, body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		switch r.URL.Path {
+		case "/api/chat":
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: reply}, Done: true})
+		case "/api/generate":
+			json.NewEncoder(w).Encode(OllamaResponse{Response: reply, Done: true})
+		default:
+			fmt.Fprintf(w, `{"choices": [{"message": {"role": "assistant", "content": %q}}]}`, reply)
+		}
+	}))
+}
+
+func TestExtraParamsInOllamaOptions(t *testing.T) {
+	var body map[string]interface{}
+	server := newBodyServer(t, &body, "ok")
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama3", 0.2, 100, server.URL)
+	provider.ExtraParams = map[string]interface{}{"mirostat": 2, "repeat_penalty": 1.1, "temperature": 1.5}
+	check := func(endpoint string) {
+		t.Helper()
+		options, _ := body["options"].(map[string]interface{})
+		if options["mirostat"] != 2.0 || options["repeat_penalty"] != 1.1 {
+			t.Errorf("Expected the extra parameters in the %s options, got %v", endpoint, body)
+		}
+		if options["temperature"] != 0.2 || options["num_predict"] != 100.0 {
+			t.Errorf("Expected the configured temperature and max tokens to win on %s, got %v", endpoint, options)
+		}
+	}
+
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	check("/api/chat")
+
+	provider.chatUnsupported = true // models without chat use /api/generate
+	if _, err := provider.GenerateStreamingResponse(context.Background(), "hi", func(string) {}); err != nil {
+		t.Fatalf("GenerateStreamingResponse failed: %v", err)
+	}
+	check("/api/generate")
+}
+
+func TestExtraParamsInOpenAIRequest(t *testing.T) {
+	var body map[string]interface{}
+	server := newBodyServer(t, &body, "ok")
+	defer server.Close()
+
+	provider := NewOpenAIProvider("sk-test", "gpt-4o", 0.7, 0)
+	provider.BaseURL = server.URL
+	provider.SystemPrompt = "Be brief."
+	provider.ExtraParams = map[string]interface{}{
+		"top_p":      0.9,
+		"logit_bias": map[string]interface{}{"50256": -100},
+		"model":      "ignored",
+	}
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+
+	if body["top_p"] != 0.9 || body["logit_bias"] == nil {
+		t.Errorf("Expected the extra parameters at the top level, got %v", body)
+	}
+	if body["model"] != "gpt-4o" {
+		t.Errorf("Expected the configured model to win, got %v", body["model"])
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Errorf("Expected no max_tokens when unlimited, got %v", body)
+	}
+	if messages, _ := body["messages"].([]interface{}); len(messages) != 2 {
+		t.Errorf("Expected the system prompt and the prompt as messages, got %v", body)
+	}
+
+	if _, err := provider.GenerateStreamingResponse(context.Background(), "hi", func(string) {}); err != nil {
+		t.Fatalf("GenerateStreamingResponse failed: %v", err)
+	}
+	if body["logit_bias"] == nil || body["stream"] != true {
+		t.Errorf("Expected the extra parameters in the streaming request too, got %v", body)
+	}
+}
+
+type samplingConfig struct {
+	testConfig
+	topP, presence, frequency *float64
+}
+
+func (c *samplingConfig) GetTopP() *float64             { return c.topP }
+func (c *samplingConfig) GetPresencePenalty() *float64  { return c.presence }
+func (c *samplingConfig) GetFrequencyPenalty() *float64 { return c.frequency }
+
+func TestSamplingParameters(t *testing.T) {
+	var body map[string]interface{}
+	server := newBodyServer(t, &body, "ok")
+	defer server.Close()
+	topP, presence, frequency := 0.9, 0.5, -0.5
+	cfg := &samplingConfig{testConfig: testConfig{provider: "openai", model: "gpt-4o"}, topP: &topP, presence: &presence, frequency: &frequency}
+
+	provider, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	provider.(*OpenAIProvider).BaseURL = server.URL
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if body["top_p"] != 0.9 || body["presence_penalty"] != 0.5 || body["frequency_penalty"] != -0.5 {
+		t.Errorf("Expected the sampling parameters at the top level, got %v", body)
+	}
+
+	cfg.provider = "ollama"
+	provider, err = CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	provider.(*OllamaProvider).BaseURL = server.URL
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	options, _ := body["options"].(map[string]interface{})
+	if options["top_p"] != 0.9 || options["presence_penalty"] != 0.5 || options["frequency_penalty"] != -0.5 {
+		t.Errorf("Expected the sampling parameters in options, got %v", body)
+	}
+
+	// Unset parameters are left out so the server's defaults apply
+	openai := NewOpenAIProvider("sk-test", "gpt-4o", 0.7, 0)
+	openai.BaseURL = server.URL
+	if _, err := openai.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	for _, name := range []string{"top_p", "presence_penalty", "frequency_penalty"} {
+		if _, ok := body[name]; ok {
+			t.Errorf("Expected no %s when unset, got %v", name, body)
+		}
+	}
+}
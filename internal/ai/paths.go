@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+
+	"tala/internal/fileops"
+)
+
+// pathParameters are the tool parameters that name a file or directory
+var pathParameters = []string{"filename", "dirname", "path", "source", "destination", "cwd"}
+
+// ConfirmOutsideCwd makes tools ask through ConfirmOutsidePath before acting on
+// a path outside the current directory
+var ConfirmOutsideCwd bool
+
+// ConfirmOutsidePath asks the user whether a tool may act on a path outside the
+// current directory. When it is nil, as in headless mode, such calls are refused
+// while ConfirmOutsideCwd is on.
+var ConfirmOutsidePath func(tool, path string) bool
+
+// resolveToolPaths returns the arguments with their paths made absolute, so a
+// tool acts on, reports and audits the path that is really used. It returns
+// an error when a path outside the current directory was not allowed.
+func resolveToolPaths(toolName string, args map[string]interface{}) (map[string]interface{}, error) {
+	var resolved map[string]interface{}
+	for _, param := range pathParameters {
+		path, ok := args[param].(string)
+		if !ok || path == "" {
+			continue
+		}
+		abs, err := fileops.AbsPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve %s '%s': %w", param, path, err)
+		}
+		if abs != path {
+			slog.Info("tool path resolved", "tool", toolName, "param", param, "path", path, "resolved", abs)
+		}
+
+		if ConfirmOutsideCwd && !fileops.InCurrentDir(abs) {
+			if ConfirmOutsidePath == nil {
+				return nil, fmt.Errorf("'%s' is outside the current directory and needs confirmation, which is not possible here", abs)
+			}
+			if !ConfirmOutsidePath(toolName, abs) {
+				return nil, fmt.Errorf("the user declined %s on '%s', outside the current directory", toolName, abs)
+			}
+		}
+
+		if resolved == nil {
+			resolved = make(map[string]interface{}, len(args))
+			for k, v := range args {
+				resolved[k] = v
+			}
+		}
+		resolved[param] = abs
+	}
+	if resolved == nil {
+		return args, nil
+	}
+	return resolved, nil
+}
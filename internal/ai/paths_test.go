@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolPathsAreResolved(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+
+	result := ExecuteTool("create_file", map[string]interface{}{"filename": "notes.txt", "content": "hi"})
+	if !result.Success {
+		t.Fatalf("create_file failed: %s", result.Content)
+	}
+	cwd, _ := os.Getwd()
+	if want := filepath.Join(cwd, "notes.txt"); !strings.Contains(result.Content, want) {
+		t.Errorf("Expected the result to name %s, got %q", want, result.Content)
+	}
+
+	args := map[string]interface{}{"source": "a.txt", "destination": "~/b.txt", "content": "a.txt"}
+	resolved, err := resolveToolPaths("copy_file", args)
+	if err != nil {
+		t.Fatalf("resolveToolPaths failed: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+	if !filepath.IsAbs(resolved["source"].(string)) || resolved["destination"] != filepath.Join(home, "b.txt") {
+		t.Errorf("Expected absolute paths, got %v", resolved)
+	}
+	if resolved["content"] != "a.txt" || args["source"] != "a.txt" {
+		t.Errorf("Expected other arguments and the caller's map to be left alone, got %v and %v", resolved, args)
+	}
+}
+
+func TestConfirmOutsideCwd(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+	ConfirmOutsideCwd = true
+	defer func() {
+		ConfirmOutsideCwd = false
+		ConfirmOutsidePath = nil
+	}()
+
+	outside := filepath.Join(t.TempDir(), "elsewhere.txt")
+	var asked []string
+	ConfirmOutsidePath = func(tool, path string) bool {
+		asked = append(asked, path)
+		return false
+	}
+
+	if _, err := resolveToolPaths("read_file", map[string]interface{}{"filename": "inside.txt"}); err != nil || len(asked) != 0 {
+		t.Errorf("Expected a path in the current directory to need no confirmation, got %v, asked %v", err, asked)
+	}
+	result := ExecuteTool("create_file", map[string]interface{}{"filename": outside, "content": "x"})
+	if result.Success || len(asked) != 1 || asked[0] != outside {
+		t.Errorf("Expected the user to be asked and the call refused, got %+v, asked %v", result, asked)
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Error("Expected the declined file not to be created")
+	}
+
+	ConfirmOutsidePath = func(tool, path string) bool { return true }
+	if result := ExecuteTool("create_file", map[string]interface{}{"filename": outside, "content": "x"}); !result.Success {
+		t.Errorf("Expected an allowed path to be used, got %q", result.Content)
+	}
+
+	ConfirmOutsidePath = nil
+	if _, err := resolveToolPaths("read_file", map[string]interface{}{"filename": outside}); err == nil {
+		t.Error("Expected a path outside the current directory to be refused without a way to confirm")
+	}
+}
@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"fmt"
+	"runtime"
+
+	"tala/internal/fileops"
+)
+
+// setPermissions changes the mode of path when the command policy allows the
+// equivalent chmod: risky, as by default, asks through ConfirmCommand, and
+// blocked never runs. Windows has no such permissions, so nothing is asked.
+func setPermissions(path, mode string) *fileops.FileOperation {
+	if _, err := fileops.ParseMode(mode); err != nil {
+		return toolFailed(fmt.Sprintf("Error: %v", err))
+	}
+	if runtime.GOOS != "windows" {
+		command := fmt.Sprintf("chmod %s %s", mode, path)
+		switch risk, reason := classifyArgs([]string{"chmod", mode, path}); risk {
+		case RiskBlocked:
+			return toolFailed(fmt.Sprintf("Error: %s blocked for security reasons: %s", command, reason))
+		case RiskRisky:
+			if ConfirmCommand == nil {
+				return toolFailed(fmt.Sprintf("Error: changing permissions needs confirmation (%s), which is not available here. Add \"chmod\" to safe_commands in the config to allow it", reason))
+			}
+			if !ConfirmCommand(command, reason) {
+				return toolFailed("Error: permissions not changed: the user declined it")
+			}
+		}
+	}
+	return fileops.Chmod(path, mode)
+}
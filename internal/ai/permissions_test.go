@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSetPermissionsFollowsCommandPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permissions")
+	}
+	defer func() { ConfirmCommand = nil }()
+	defer ConfigureCommandPolicy(commandPolicyConfig{})
+	script := filepath.Join(t.TempDir(), "build.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\n"), 0644)
+	mode := func() os.FileMode {
+		info, _ := os.Stat(script)
+		return info.Mode().Perm()
+	}
+
+	ConfirmCommand = nil
+	if result := ExecuteTool("set_permissions", map[string]interface{}{"path": script, "mode": "755"}); result.Success {
+		t.Errorf("Expected chmod to need confirmation, got %q", result.Content)
+	}
+
+	var asked string
+	ConfirmCommand = func(command, reason string) bool {
+		asked = command
+		return true
+	}
+	result := ExecuteTool("set_permissions", map[string]interface{}{"path": script, "mode": float64(755)})
+	if !result.Success || mode() != 0755 {
+		t.Fatalf("Expected the confirmed change to apply, got %q and %o", result.Content, mode())
+	}
+	if asked != "chmod 755 "+script {
+		t.Errorf("Expected to be asked about the chmod, got %q", asked)
+	}
+
+	asked = ""
+	ConfigureCommandPolicy(commandPolicyConfig{safe: []string{"chmod"}})
+	if result := ExecuteTool("set_permissions", map[string]interface{}{"path": script, "mode": "700"}); !result.Success || asked != "" || mode() != 0700 {
+		t.Errorf("Expected a safe chmod to run without asking, got %q (asked %q)", result.Content, asked)
+	}
+
+	result = ExecuteTool("set_permissions", map[string]interface{}{"path": script, "mode": "4755"})
+	if result.Success || !strings.Contains(result.Content, "setuid") {
+		t.Errorf("Expected a setuid mode to be rejected, got %q", result.Content)
+	}
+}
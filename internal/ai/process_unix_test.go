@@ -0,0 +1,114 @@
+//go:build !windows
+// +build !windows
+
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunShellCommandKillsChildProcesses(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	
+	pidFile := filepath.Join(tmpDir, "child.pid")
+	
+	// The shell forks a long-running child and then waits on it
+	result := runShellCommand(context.Background(), "sleep 30 & echo $! > "+pidFile+"; wait", "", 500*time.Millisecond)
+	if !strings.Contains(result, "timed out") {
+		t.Fatalf("Expected command to time out, got: %s", result)
+	}
+	
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("Failed to read child pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("Invalid child pid %q: %v", data, err)
+	}
+	
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("Child process %d is still running after timeout kill", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestRunShellCommandKeepWaiting(t *testing.T) {
+	defer func() { ConfirmCommandTimeout, KeepWaiting = false, nil }()
+	ConfirmCommandTimeout = true
+	
+	asked := 0
+	KeepWaiting = func(command string, elapsed time.Duration) bool {
+		asked++
+		return asked == 1
+	}
+	result := runShellCommand(context.Background(), "sleep 0.5; echo finished", "", 300*time.Millisecond)
+	if asked != 1 || !strings.Contains(result, "finished") {
+		t.Errorf("Expected one extension to let the command finish, asked %d times, got: %s", asked, result)
+	}
+	
+	asked = 0
+	result = runShellCommand(context.Background(), "sleep 30", "", 200*time.Millisecond)
+	if asked != 2 || !strings.Contains(result, "timed out after 400ms") {
+		t.Errorf("Expected the command to be killed once declined, asked %d times, got: %s", asked, result)
+	}
+}
+
+func TestRunShellCommandCancel(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	
+	pidFile := filepath.Join(tmpDir, "child.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+	
+	// Cancelling stops the whole process tree and keeps what was printed
+	start := time.Now()
+	result := runShellCommand(ctx, "echo started; sleep 30 & echo $! > "+pidFile+"; wait", "", 10*time.Second)
+	if !strings.Contains(result, "interrupted") || !strings.Contains(result, "started") {
+		t.Errorf("Expected the partial output of an interrupted command, got: %s", result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancelling to stop the command at once, took %v", elapsed)
+	}
+	
+	data, _ := os.ReadFile(pidFile)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("Invalid child pid %q: %v", data, err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("Child process %d is still running after cancel", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// processAlive reports whether pid refers to a running (non-zombie) process
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	// Killed children may linger as zombies until reaped by init
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
@@ -5,11 +5,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"syscall"
 	"time"
+
+	"tala/internal/glyphs"
 )
 
 type Provider interface {
@@ -19,18 +25,38 @@ type Provider interface {
 	GetName() string
 	SupportsTools() bool
 	SupportsStreaming() bool
+	// Embed returns one vector per text for similarity search. Providers
+	// without an embeddings API return an error wrapping ErrNotSupported.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
 }
 
+// ErrNotSupported reports that a provider lacks a capability, such as embeddings
+var ErrNotSupported = errors.New("not supported by this provider")
+
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 }
 
 type OpenAIProvider struct {
-	APIKey      string
-	Model       string
-	Temperature float64
-	MaxTokens   int
+	APIKey           string
+	Model            string
+	Temperature      float64
+	MaxTokens        int
+	SystemPrompt     string
+	ResponseFormat   string                 // "json" maps to response_format {"type": "json_object"}
+	StopSequences    []string               // sent as "stop"
+	Seed             *int                   // sent as "seed"; nil lets the server pick
+	TopP             *float64               // sent as "top_p"; nil keeps the server default
+	PresencePenalty  *float64               // sent as "presence_penalty"; nil keeps the server default
+	FrequencyPenalty *float64               // sent as "frequency_penalty"; nil keeps the server default
+	ExtraParams      map[string]interface{} // added to the request body as they are
+	EmbeddingModel   string                 // model for Embed, empty = DefaultOpenAIEmbeddingModel
+	BaseURL          string                 // API root, e.g. "https://api.openai.com/v1"
+	Azure            bool                   // Azure OpenAI: Model names the deployment and the key goes in "api-key"
+	Compatible       bool                   // another server speaking the OpenAI API, for which the key is optional
+	APIVersion       string                 // Azure only, sent as the api-version query parameter
+	client           *http.Client
 }
 
 func NewOpenAIProvider(apiKey, model string, temperature float64, maxTokens int) *OpenAIProvider {
@@ -39,47 +65,148 @@ func NewOpenAIProvider(apiKey, model string, temperature float64, maxTokens int)
 		Model:       model,
 		Temperature: temperature,
 		MaxTokens:   maxTokens,
+		BaseURL:     "https://api.openai.com/v1",
+		client:      NewHTTPClient(RequestTimeout),
 	}
 }
 
+// endpoint returns the URL of an API path such as "chat/completions". On
+// Azure the path belongs to a deployment and the API version is a query
+// parameter.
+func (p *OpenAIProvider) endpoint(deployment, path string) string {
+	root := strings.TrimSuffix(p.BaseURL, "/")
+	if !p.Azure {
+		return root + "/" + path
+	}
+	return root + "/deployments/" + url.PathEscape(deployment) + "/" + path + "?api-version=" + url.QueryEscape(p.APIVersion)
+}
+
+// authorize adds the API key, which Azure takes in an api-key header. An
+// OpenAI-compatible server without a key gets no header.
+func (p *OpenAIProvider) authorize(req *http.Request) {
+	if p.Azure {
+		req.Header.Set("api-key", p.APIKey)
+		return
+	}
+	if p.APIKey != "" || !p.Compatible {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+}
+
+// postChat sends a /chat/completions request and returns the response once it
+// has a 200 status
+func (p *OpenAIProvider) postChat(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
+	if p.APIKey == "" && !p.Compatible {
+		return nil, fmt.Errorf("%s needs an API key", p.GetName())
+	}
+	jsonBody, err := p.chatRequestBody(ctx, prompt, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint(p.Model, "chat/completions"), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.authorize(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, p.statusError(resp)
+	}
+	return resp, nil
+}
+
+// statusError converts a non-200 response into an error, preferring the
+// message of the API's error object to the raw body
+func (p *OpenAIProvider) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	message := strings.TrimSpace(string(body))
+	var apiResp OpenAIChatResponse
+	if json.Unmarshal(body, &apiResp) == nil && apiResp.Error != nil {
+		message = apiResp.Error.Message
+	}
+	return withRequestID(resp, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, message))
+}
+
 func (p *OpenAIProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
-	return fmt.Sprintf("OpenAI response to: %s", prompt), nil
+	resp, err := p.postChat(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var chatResp OpenAIChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if chatResp.Error != nil {
+		return "", withRequestID(resp, fmt.Errorf("%s error: %s", p.GetName(), chatResp.Error.Message))
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", withRequestID(resp, fmt.Errorf("%s returned no choices", p.GetName()))
+	}
+
+	choice := chatResp.Choices[0]
+	if isTruncatedFinish(choice.FinishReason) {
+		return markTruncated(choice.Message.Content), nil
+	}
+	return choice.Message.Content, nil
 }
 
 func (p *OpenAIProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
-	// Use AI-based intent detection (simulated for OpenAI)
+	defer beginToolTurn(prompt)()
+	// Use AI-based intent detection
 	detector := NewIntentDetector(p)
 	intents, err := detector.DetectIntent(ctx, prompt)
 	if err != nil {
 		response, err := p.GenerateResponse(ctx, prompt)
 		return response, []ToolResult{}, err
 	}
-	
-	// Execute detected tools with high confidence threshold
+
+	// Execute detected tools; saving the reply waits until there is one
 	var toolResults []ToolResult
-	for _, intent := range intents {
-		if intent.Confidence > 0.8 { // Increased threshold for more conservative execution
-			result := ExecuteTool(intent.Tool, intent.Parameters)
-			toolResults = append(toolResults, result)
-		}
+	accepted, saves := deferSaves(acceptIntents(intents))
+	for _, intent := range accepted {
+		result := ExecuteTool(intent.Tool, intent.Parameters)
+		toolResults = append(toolResults, result)
 	}
-	
-	// Generate appropriate response
-	if len(toolResults) > 0 {
-		summary := "I have successfully completed the following operations:\n"
-		for _, result := range toolResults {
-			if result.Success {
-				summary += fmt.Sprintf("✓ %s\n", result.Content)
-			} else {
-				summary += fmt.Sprintf("✗ %s failed: %s\n", result.Name, result.Content)
+
+	// Enhance the prompt with tool information and results
+	enhancedPrompt := toolResultsPrompt(toolResults)
+	enhancedPrompt += retrievedDocuments(ctx)
+	enhancedPrompt += saveInstruction(saves)
+	enhancedPrompt += "User: " + prompt
+
+	response, err := p.GenerateResponse(ctx, enhancedPrompt)
+	if err != nil {
+		// If AI response fails, provide a clear summary of what was accomplished
+		if len(toolResults) > 0 {
+			summary := "I have successfully completed the following operations:\n"
+			for _, result := range toolResults {
+				if result.Success {
+					summary += fmt.Sprintf("%s %s\n", glyphs.Check, result.Content)
+				} else {
+					summary += fmt.Sprintf("%s %s failed: %s\n", glyphs.Cross, result.Name, result.Content)
+				}
 			}
+			summary += "\nAll requested operations have been executed."
+			return summary, toolResults, nil
 		}
-		summary += "\nAll requested operations have been executed."
-		return summary, toolResults, nil
+		return "", toolResults, err
 	}
-	
-	response := fmt.Sprintf("OpenAI response to: %s", prompt)
-	return response, toolResults, nil
+
+	return response, append(toolResults, saveResponse(saves, response)...), nil
 }
 
 func (p *OpenAIProvider) SupportsTools() bool {
@@ -87,30 +214,69 @@ func (p *OpenAIProvider) SupportsTools() bool {
 }
 
 func (p *OpenAIProvider) GetName() string {
+	if p.Azure {
+		return "Azure OpenAI"
+	}
+	if p.Compatible {
+		return "OpenAI-compatible"
+	}
 	return "OpenAI"
 }
 
+// GenerateStreamingResponse streams the reply as server-sent events, each a
+// "data:" line holding a chunk, until "data: [DONE]"
 func (p *OpenAIProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
-	// Simulate streaming by sending chunks
-	response := fmt.Sprintf("OpenAI streaming response to: %s", prompt)
-	words := strings.Split(response, " ")
-	
+	resp, err := p.postChat(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
 	var fullResponse strings.Builder
-	for i, word := range words {
-		if i > 0 {
-			word = " " + word
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // Blank lines between events, comments and other fields
 		}
-		fullResponse.WriteString(word)
-		callback(word)
-		
-		// Small delay to simulate streaming
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var event OpenAIChatResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue // Skip malformed events
+		}
+
+		if event.Error != nil {
+			return fullResponse.String(), withRequestID(resp, fmt.Errorf("%s error: %s", p.GetName(), event.Error.Message))
+		}
+
+		if len(event.Choices) > 0 {
+			choice := event.Choices[0]
+			if choice.Delta.Content != "" {
+				fullResponse.WriteString(choice.Delta.Content)
+				callback(choice.Delta.Content)
+			}
+			if isTruncatedFinish(choice.FinishReason) {
+				fullResponse.WriteString(truncationSuffix)
+				callback(truncationSuffix)
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			return fullResponse.String(), ctx.Err()
-		case <-time.After(50 * time.Millisecond):
+		default:
 		}
 	}
-	
+
+	if err := scanner.Err(); err != nil {
+		return fullResponse.String(), fmt.Errorf("error reading stream: %w", err)
+	}
+
 	return fullResponse.String(), nil
 }
 
@@ -118,12 +284,12 @@ func (p *OpenAIProvider) SupportsStreaming() bool {
 	return true
 }
 
-
 type AnthropicProvider struct {
-	APIKey      string
-	Model       string
-	Temperature float64
-	MaxTokens   int
+	APIKey       string
+	Model        string
+	Temperature  float64
+	MaxTokens    int
+	SystemPrompt string
 }
 
 func NewAnthropicProvider(apiKey, model string, temperature float64, maxTokens int) *AnthropicProvider {
@@ -140,6 +306,7 @@ func (p *AnthropicProvider) GenerateResponse(ctx context.Context, prompt string)
 }
 
 func (p *AnthropicProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
+	defer beginToolTurn(prompt)()
 	// Use AI-based intent detection (simulated for Anthropic)
 	detector := NewIntentDetector(p)
 	intents, err := detector.DetectIntent(ctx, prompt)
@@ -147,30 +314,28 @@ func (p *AnthropicProvider) GenerateResponseWithTools(ctx context.Context, promp
 		response, err := p.GenerateResponse(ctx, prompt)
 		return response, []ToolResult{}, err
 	}
-	
+
 	// Execute detected tools with high confidence threshold
 	var toolResults []ToolResult
-	for _, intent := range intents {
-		if intent.Confidence > 0.8 { // Increased threshold for more conservative execution
-			result := ExecuteTool(intent.Tool, intent.Parameters)
-			toolResults = append(toolResults, result)
-		}
+	for _, intent := range acceptIntents(intents) {
+		result := ExecuteTool(intent.Tool, intent.Parameters)
+		toolResults = append(toolResults, result)
 	}
-	
+
 	// Generate appropriate response
 	if len(toolResults) > 0 {
 		summary := "I have successfully completed the following operations:\n"
 		for _, result := range toolResults {
 			if result.Success {
-				summary += fmt.Sprintf("✓ %s\n", result.Content)
+				summary += fmt.Sprintf("%s %s\n", glyphs.Check, result.Content)
 			} else {
-				summary += fmt.Sprintf("✗ %s failed: %s\n", result.Name, result.Content)
+				summary += fmt.Sprintf("%s %s failed: %s\n", glyphs.Cross, result.Name, result.Content)
 			}
 		}
 		summary += "\nAll requested operations have been executed."
 		return summary, toolResults, nil
 	}
-	
+
 	response := fmt.Sprintf("Anthropic response to: %s", prompt)
 	return response, toolResults, nil
 }
@@ -187,7 +352,7 @@ func (p *AnthropicProvider) GenerateStreamingResponse(ctx context.Context, promp
 	// Simulate streaming by sending chunks
 	response := fmt.Sprintf("Anthropic streaming response to: %s", prompt)
 	words := strings.Split(response, " ")
-	
+
 	var fullResponse strings.Builder
 	for i, word := range words {
 		if i > 0 {
@@ -195,7 +360,7 @@ func (p *AnthropicProvider) GenerateStreamingResponse(ctx context.Context, promp
 		}
 		fullResponse.WriteString(word)
 		callback(word)
-		
+
 		// Small delay to simulate streaming
 		select {
 		case <-ctx.Done():
@@ -203,7 +368,7 @@ func (p *AnthropicProvider) GenerateStreamingResponse(ctx context.Context, promp
 		case <-time.After(50 * time.Millisecond):
 		}
 	}
-	
+
 	return fullResponse.String(), nil
 }
 
@@ -211,25 +376,88 @@ func (p *AnthropicProvider) SupportsStreaming() bool {
 	return true
 }
 
+// Embed is not available: Anthropic has no embeddings API
+func (p *AnthropicProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, fmt.Errorf("anthropic embeddings: %w", ErrNotSupported)
+}
 
 type OllamaProvider struct {
-	Model       string
-	Temperature float64
-	MaxTokens   int
-	BaseURL     string
-	client      *http.Client
+	Model            string
+	Temperature      float64
+	MaxTokens        int
+	BaseURL          string
+	SystemPrompt     string
+	AutoPull         bool                   // pull missing models via /api/pull and retry
+	KeepAlive        string                 // how long Ollama keeps the model loaded, e.g. "30m"; empty = server default
+	Format           string                 // "json" constrains output to valid JSON
+	StopSequences    []string               // sent as options.stop
+	Seed             *int                   // sent as options.seed; nil lets the server pick
+	TopP             *float64               // sent as options.top_p; nil keeps the model default
+	PresencePenalty  *float64               // sent as options.presence_penalty
+	FrequencyPenalty *float64               // sent as options.frequency_penalty
+	ExtraParams      map[string]interface{} // added to options as they are, e.g. mirostat or repeat_penalty
+	History          []OllamaMessage        // earlier conversation turns sent with chat requests
+	HistoryLimit     int                    // most messages History keeps, oldest dropped first; 0 = no limit
+	EmbeddingModel   string                 // model for Embed, empty = DefaultOllamaEmbeddingModel
+	client           *http.Client
+
+	chatUnsupported bool  // set once /api/chat is unavailable so later requests go straight to /api/generate
+	lastUsage       Usage // token counts and timings of the latest response
 }
 
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model     string         `json:"model"`
+	Prompt    string         `json:"prompt"`
+	System    string         `json:"system,omitempty"`
+	Stream    bool           `json:"stream"`
+	KeepAlive string         `json:"keep_alive,omitempty"`
+	Format    string         `json:"format,omitempty"`
+	Options   *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaOptions carries sampling parameters; num_predict is Ollama's max tokens
+type OllamaOptions struct {
+	Temperature      float64                `json:"temperature"`
+	NumPredict       int                    `json:"num_predict,omitempty"` // omitted when 0 (unlimited)
+	Stop             []string               `json:"stop,omitempty"`        // generation ends before any of these
+	Seed             *int                   `json:"seed,omitempty"`        // same seed and prompt give the same reply
+	TopP             *float64               `json:"top_p,omitempty"`
+	PresencePenalty  *float64               `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64               `json:"frequency_penalty,omitempty"`
+	Extra            map[string]interface{} `json:"-"` // extra_params, added by MarshalJSON
 }
 
 type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-	Error    string `json:"error,omitempty"`
+	Response   string `json:"response"`
+	Done       bool   `json:"done"`
+	DoneReason string `json:"done_reason,omitempty"` // "length" when num_predict cut the reply short
+	Error      string `json:"error,omitempty"`
+	OllamaStats
+}
+
+// OllamaMessage is a single role-tagged message in a chat conversation
+type OllamaMessage struct {
+	Role    string `json:"role"` // "system", "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// OllamaChatRequest is the body of an /api/chat request
+type OllamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []OllamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Format    string          `json:"format,omitempty"`
+	Options   *OllamaOptions  `json:"options,omitempty"`
+}
+
+// OllamaChatResponse is an /api/chat response, or one line of a streamed one
+type OllamaChatResponse struct {
+	Message    OllamaMessage `json:"message"`
+	Done       bool          `json:"done"`
+	DoneReason string        `json:"done_reason,omitempty"` // "length" when num_predict cut the reply short
+	Error      string        `json:"error,omitempty"`
+	OllamaStats
 }
 
 func NewOllamaProvider(model string, temperature float64, maxTokens int, baseURL string) *OllamaProvider {
@@ -241,17 +469,233 @@ func NewOllamaProvider(model string, temperature float64, maxTokens int, baseURL
 		Temperature: temperature,
 		MaxTokens:   maxTokens,
 		BaseURL:     baseURL,
-		client: &http.Client{
-			Timeout: 120 * time.Second, // Increased timeout for large models
-		},
+		client:      NewHTTPClient(RequestTimeout),
+	}
+}
+
+// ErrOllamaNotRunning is returned when nothing is listening at the Ollama URL
+var ErrOllamaNotRunning = errors.New("Ollama doesn't appear to be running")
+
+// Ping checks that the Ollama server is reachable by listing its local models
+func (p *OllamaProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.BaseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return p.sendError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return withRequestID(resp, fmt.Errorf("ollama at %s responded with status %d", p.BaseURL, resp.StatusCode))
+	}
+	return nil
+}
+
+// sendError turns a transport failure into an actionable error when Ollama is not running
+func (p *OllamaProvider) sendError(err error) error {
+	if isConnectionRefused(err) {
+		return fmt.Errorf("%w at %s. Start it with `ollama serve`.", ErrOllamaNotRunning, p.BaseURL)
+	}
+	return fmt.Errorf("failed to send request: %w", err)
+}
+
+// options returns the sampling parameters sent with every generation request
+func (p *OllamaProvider) options() *OllamaOptions {
+	return &OllamaOptions{
+		Temperature:      p.Temperature,
+		NumPredict:       p.MaxTokens,
+		Stop:             p.StopSequences,
+		Seed:             p.Seed,
+		TopP:             p.TopP,
+		PresencePenalty:  p.PresencePenalty,
+		FrequencyPenalty: p.FrequencyPenalty,
+		Extra:            p.ExtraParams,
+	}
+}
+
+// Preload asks Ollama to load the model into memory without generating anything,
+// so the first real request does not pay the cold-start cost
+func (p *OllamaProvider) Preload(ctx context.Context) error {
+	jsonBody, err := json.Marshal(OllamaRequest{Model: p.Model, KeepAlive: p.KeepAlive})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/api/generate", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return p.sendError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return p.statusError(resp)
+	}
+	return nil
+}
+
+// ErrModelNotFound is returned when Ollama does not have the requested model locally
+var ErrModelNotFound = errors.New("model not found")
+
+// statusError converts a non-200 Ollama response into an error
+func (p *OllamaProvider) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	return withRequestID(resp, p.statusErrorFromBody(resp.StatusCode, string(body)))
+}
+
+func (p *OllamaProvider) statusErrorFromBody(status int, body string) error {
+	if status == http.StatusNotFound && strings.Contains(body, "model") && strings.Contains(body, "not found") {
+		return fmt.Errorf("%w: %s (run `ollama pull %s` or enable auto_pull_models)", ErrModelNotFound, p.Model, p.Model)
+	}
+	return fmt.Errorf("API request failed with status %d: %s", status, body)
+}
+
+// errChatUnsupported means the server or model cannot serve /api/chat
+var errChatUnsupported = errors.New("chat endpoint not supported")
+
+// chatStatusError is statusError for /api/chat, recognizing servers and models without chat support
+func (p *OllamaProvider) chatStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	err := withRequestID(resp, p.statusErrorFromBody(resp.StatusCode, string(body)))
+	if errors.Is(err, ErrModelNotFound) {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusNotImplemented:
+		return errChatUnsupported // Ollama before /api/chat existed
+	case resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(string(body)), "not support"):
+		return errChatUnsupported // model without a chat template
+	}
+	return err
+}
+
+// ConfirmModelPull is asked before a missing model is downloaded. When nil the
+// pull proceeds, since auto_pull_models is already an explicit opt-in.
+var ConfirmModelPull func(model string) bool
+
+// PullProgress receives download progress while a model is being pulled.
+// total and completed are in bytes and are zero for status-only updates.
+var PullProgress func(model, status string, completed, total int64)
+
+// OllamaPullResponse is one line of the streamed /api/pull response
+type OllamaPullResponse struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// pullMissingModel pulls the model when err reports it missing and auto-pull is allowed.
+// It returns true when the request should be retried.
+func (p *OllamaProvider) pullMissingModel(ctx context.Context, err error) bool {
+	if !p.AutoPull || !errors.Is(err, ErrModelNotFound) {
+		return false
+	}
+	if ConfirmModelPull != nil && !ConfirmModelPull(p.Model) {
+		return false
+	}
+	return p.PullModel(ctx) == nil
+}
+
+// PullModel downloads the provider's model through Ollama's /api/pull endpoint,
+// reporting progress to PullProgress
+func (p *OllamaProvider) PullModel(ctx context.Context) error {
+	jsonBody, err := json.Marshal(map[string]interface{}{"name": p.Model, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/api/pull", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Large models take far longer than the generation timeout to download
+	client := NewHTTPClient(0)
+	resp, err := client.Do(req)
+	if err != nil {
+		return p.sendError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return withRequestID(resp, fmt.Errorf("pull failed with status %d: %s", resp.StatusCode, string(body)))
 	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var update OllamaPullResponse
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			continue // Skip malformed lines
+		}
+		if update.Error != "" {
+			return withRequestID(resp, fmt.Errorf("ollama pull error: %s", update.Error))
+		}
+		if PullProgress != nil {
+			PullProgress(p.Model, update.Status, update.Completed, update.Total)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	return fmt.Errorf("pull of %s ended before completing", p.Model)
+}
+
+// isConnectionRefused reports whether err means nothing is listening at the target address
+func isConnectionRefused(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	// Windows reports WSAECONNREFUSED, which does not match syscall.ECONNREFUSED
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "actively refused")
 }
 
 func (p *OllamaProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	response, err := p.generate(ctx, prompt)
+	if p.pullMissingModel(ctx, err) {
+		return p.generate(ctx, prompt)
+	}
+	return response, err
+}
+
+// generate prefers /api/chat and falls back to /api/generate when chat is unavailable
+func (p *OllamaProvider) generate(ctx context.Context, prompt string) (string, error) {
+	if !p.chatUnsupported {
+		response, err := p.chat(ctx, prompt)
+		if !errors.Is(err, errChatUnsupported) {
+			return response, err
+		}
+		p.chatUnsupported = true
+	}
+	return p.generateCompletion(ctx, prompt)
+}
+
+// generateCompletion sends a flat prompt to the legacy /api/generate endpoint
+func (p *OllamaProvider) generateCompletion(ctx context.Context, prompt string) (string, error) {
 	reqBody := OllamaRequest{
-		Model:  p.Model,
-		Prompt: prompt,
-		Stream: false,
+		Model:     p.Model,
+		Prompt:    prompt,
+		System:    withContext(p.SystemPrompt),
+		Stream:    false,
+		KeepAlive: p.KeepAlive,
+		Format:    responseFormat(ctx, p.Format),
+		Options:   p.options(),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -268,13 +712,12 @@ func (p *OllamaProvider) GenerateResponse(ctx context.Context, prompt string) (s
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", p.sendError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", p.statusError(resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -288,13 +731,180 @@ func (p *OllamaProvider) GenerateResponse(ctx context.Context, prompt string) (s
 	}
 
 	if ollamaResp.Error != "" {
-		return "", fmt.Errorf("ollama error: %s", ollamaResp.Error)
+		return "", withRequestID(resp, fmt.Errorf("ollama error: %s", ollamaResp.Error))
 	}
 
+	p.lastUsage = ollamaResp.usage()
+	if isTruncatedFinish(ollamaResp.DoneReason) {
+		return markTruncated(ollamaResp.Response), nil
+	}
 	return ollamaResp.Response, nil
 }
 
+// RememberTurn keeps an exchange as chat context for the next prompt, for
+// providers that send earlier turns
+func RememberTurn(provider Provider, prompt, response string) {
+	if p, ok := UnwrapProvider(provider).(*OllamaProvider); ok {
+		p.remember(
+			OllamaMessage{Role: "user", Content: prompt},
+			OllamaMessage{Role: "assistant", Content: response})
+	}
+}
+
+// remember adds messages to the history, dropping the oldest beyond HistoryLimit
+func (p *OllamaProvider) remember(messages ...OllamaMessage) {
+	p.History = append(p.History, messages...)
+	if p.HistoryLimit > 0 && len(p.History) > p.HistoryLimit {
+		p.History = append([]OllamaMessage(nil), p.History[len(p.History)-p.HistoryLimit:]...)
+	}
+}
+
+// historyKey marks a request that must not carry the conversation so far
+type historyKey struct{}
+
+// withoutHistory leaves the earlier turns out of the requests made with ctx,
+// for one-off requests such as intent detection
+func withoutHistory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, historyKey{}, true)
+}
+
+// chatMessages builds the role-separated conversation for an /api/chat request
+func (p *OllamaProvider) chatMessages(ctx context.Context, prompt string) []OllamaMessage {
+	var messages []OllamaMessage
+	if system := withContext(p.SystemPrompt); system != "" {
+		messages = append(messages, OllamaMessage{Role: "system", Content: system})
+	}
+	if ctx.Value(historyKey{}) == nil {
+		messages = append(messages, p.History...)
+	}
+	return append(messages, OllamaMessage{Role: "user", Content: prompt})
+}
+
+// postChat sends an /api/chat request and returns the response once it has a 200 status
+func (p *OllamaProvider) postChat(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
+	reqBody := OllamaChatRequest{
+		Model:     p.Model,
+		Messages:  p.chatMessages(ctx, prompt),
+		Stream:    stream,
+		KeepAlive: p.KeepAlive,
+		Format:    responseFormat(ctx, p.Format),
+		Options:   p.options(),
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/api/chat", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, p.sendError(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, p.chatStatusError(resp)
+	}
+	return resp, nil
+}
+
+// chat sends the prompt to /api/chat as the latest user message
+func (p *OllamaProvider) chat(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.postChat(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var chatResp OllamaChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if chatResp.Error != "" {
+		return "", withRequestID(resp, fmt.Errorf("ollama error: %s", chatResp.Error))
+	}
+
+	p.lastUsage = chatResp.usage()
+	if isTruncatedFinish(chatResp.DoneReason) {
+		return markTruncated(chatResp.Message.Content), nil
+	}
+	return chatResp.Message.Content, nil
+}
+
+// chatStream streams the assistant reply from /api/chat
+func (p *OllamaProvider) chatStream(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	start := time.Now()
+	var firstToken time.Duration
+	resp, err := p.postChat(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var fullResponse strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var chatResp OllamaChatResponse
+		if err := json.Unmarshal([]byte(line), &chatResp); err != nil {
+			continue // Skip malformed lines
+		}
+
+		if chatResp.Error != "" {
+			return fullResponse.String(), withRequestID(resp, fmt.Errorf("ollama error: %s", chatResp.Error))
+		}
+
+		if chatResp.Message.Content != "" {
+			if firstToken == 0 {
+				firstToken = time.Since(start)
+			}
+			fullResponse.WriteString(chatResp.Message.Content)
+			callback(chatResp.Message.Content)
+		}
+
+		if chatResp.Done {
+			p.lastUsage = streamedUsage(chatResp.OllamaStats, firstToken)
+			if isTruncatedFinish(chatResp.DoneReason) {
+				fullResponse.WriteString(truncationSuffix)
+				callback(truncationSuffix)
+			}
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fullResponse.String(), ctx.Err()
+		default:
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fullResponse.String(), fmt.Errorf("error reading stream: %w", err)
+	}
+
+	return fullResponse.String(), nil
+}
+
 func (p *OllamaProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
+	defer beginToolTurn(prompt)()
 	// Use AI-based intent detection
 	detector := NewIntentDetector(p)
 	intents, err := detector.DetectIntent(ctx, prompt)
@@ -303,28 +913,21 @@ func (p *OllamaProvider) GenerateResponseWithTools(ctx context.Context, prompt s
 		response, err := p.GenerateResponse(ctx, prompt)
 		return response, []ToolResult{}, err
 	}
-	
-	// Execute detected tools
+
+	// Execute detected tools; saving the reply waits until there is one
 	var toolResults []ToolResult
-	for _, intent := range intents {
-		if intent.Confidence > 0.8 { // Only execute very high-confidence intents
-			result := ExecuteTool(intent.Tool, intent.Parameters)
-			toolResults = append(toolResults, result)
-		}
+	accepted, saves := deferSaves(acceptIntents(intents))
+	for _, intent := range accepted {
+		result := ExecuteTool(intent.Tool, intent.Parameters)
+		toolResults = append(toolResults, result)
 	}
-	
+
 	// Enhance the prompt with tool information and results
-	enhancedPrompt := ""
-	if len(toolResults) > 0 {
-		enhancedPrompt += "I have executed the following operations for you:\n"
-		for _, result := range toolResults {
-			enhancedPrompt += fmt.Sprintf("- %s: %s\n", result.Name, result.Content)
-		}
-		enhancedPrompt += "\nNow, please provide a helpful response about what was accomplished.\n"
-	}
-	
+	enhancedPrompt := toolResultsPrompt(toolResults)
+	enhancedPrompt += retrievedDocuments(ctx)
+	enhancedPrompt += saveInstruction(saves)
 	enhancedPrompt += "User: " + prompt
-	
+
 	// Get AI response with the enhanced prompt
 	response, err := p.GenerateResponse(ctx, enhancedPrompt)
 	if err != nil {
@@ -333,9 +936,9 @@ func (p *OllamaProvider) GenerateResponseWithTools(ctx context.Context, prompt s
 			summary := "I have successfully completed the following operations:\n"
 			for _, result := range toolResults {
 				if result.Success {
-					summary += fmt.Sprintf("✓ %s\n", result.Content)
+					summary += fmt.Sprintf("%s %s\n", glyphs.Check, result.Content)
 				} else {
-					summary += fmt.Sprintf("✗ %s failed: %s\n", result.Name, result.Content)
+					summary += fmt.Sprintf("%s %s failed: %s\n", glyphs.Cross, result.Name, result.Content)
 				}
 			}
 			summary += "\nAll file operations have been executed successfully."
@@ -343,8 +946,8 @@ func (p *OllamaProvider) GenerateResponseWithTools(ctx context.Context, prompt s
 		}
 		return "", toolResults, err
 	}
-	
-	return response, toolResults, nil
+
+	return response, append(toolResults, saveResponse(saves, response)...), nil
 }
 
 func (p *OllamaProvider) SupportsTools() bool {
@@ -356,10 +959,37 @@ func (p *OllamaProvider) GetName() string {
 }
 
 func (p *OllamaProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	response, err := p.generateStream(ctx, prompt, callback)
+	if p.pullMissingModel(ctx, err) {
+		return p.generateStream(ctx, prompt, callback)
+	}
+	return response, err
+}
+
+// generateStream prefers /api/chat and falls back to /api/generate when chat is unavailable
+func (p *OllamaProvider) generateStream(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	if !p.chatUnsupported {
+		response, err := p.chatStream(ctx, prompt, callback)
+		if !errors.Is(err, errChatUnsupported) {
+			return response, err
+		}
+		p.chatUnsupported = true
+	}
+	return p.generateCompletionStream(ctx, prompt, callback)
+}
+
+// generateCompletionStream streams a flat prompt from the legacy /api/generate endpoint
+func (p *OllamaProvider) generateCompletionStream(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	start := time.Now()
+	var firstToken time.Duration
 	reqBody := OllamaRequest{
-		Model:  p.Model,
-		Prompt: prompt,
-		Stream: true, // Enable streaming
+		Model:     p.Model,
+		Prompt:    prompt,
+		System:    withContext(p.SystemPrompt),
+		Stream:    true, // Enable streaming
+		KeepAlive: p.KeepAlive,
+		Format:    responseFormat(ctx, p.Format),
+		Options:   p.options(),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -376,42 +1006,49 @@ func (p *OllamaProvider) GenerateStreamingResponse(ctx context.Context, prompt s
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", p.sendError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", p.statusError(resp)
 	}
 
 	var fullResponse strings.Builder
 	scanner := bufio.NewScanner(resp.Body)
-	
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line == "" {
 			continue
 		}
-		
+
 		var ollamaResp OllamaResponse
 		if err := json.Unmarshal([]byte(line), &ollamaResp); err != nil {
 			continue // Skip malformed lines
 		}
-		
+
 		if ollamaResp.Error != "" {
-			return fullResponse.String(), fmt.Errorf("ollama error: %s", ollamaResp.Error)
+			return fullResponse.String(), withRequestID(resp, fmt.Errorf("ollama error: %s", ollamaResp.Error))
 		}
-		
+
 		if ollamaResp.Response != "" {
+			if firstToken == 0 {
+				firstToken = time.Since(start)
+			}
 			fullResponse.WriteString(ollamaResp.Response)
 			callback(ollamaResp.Response)
 		}
-		
+
 		if ollamaResp.Done {
+			p.lastUsage = streamedUsage(ollamaResp.OllamaStats, firstToken)
+			if isTruncatedFinish(ollamaResp.DoneReason) {
+				fullResponse.WriteString(truncationSuffix)
+				callback(truncationSuffix)
+			}
 			break
 		}
-		
+
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
@@ -419,11 +1056,11 @@ func (p *OllamaProvider) GenerateStreamingResponse(ctx context.Context, prompt s
 		default:
 		}
 	}
-	
+
 	if err := scanner.Err(); err != nil {
 		return fullResponse.String(), fmt.Errorf("error reading stream: %w", err)
 	}
-	
+
 	return fullResponse.String(), nil
 }
 
@@ -431,15 +1068,40 @@ func (p *OllamaProvider) SupportsStreaming() bool {
 	return true
 }
 
+// ProviderInfo describes a provider accepted by CreateProvider
+type ProviderInfo struct {
+	Name           string `json:"name"`
+	RequiresAPIKey bool   `json:"requires_api_key"`
+	Description    string `json:"description"`
+	DefaultModel   string `json:"default_model"` // suggested when the configured model does not fit
+}
+
+// SupportedProviders returns the providers accepted by CreateProvider
+func SupportedProviders() []ProviderInfo {
+	return []ProviderInfo{
+		{Name: "openai", RequiresAPIKey: true, Description: "OpenAI GPT models", DefaultModel: "gpt-4o-mini"},
+		{Name: "azure-openai", RequiresAPIKey: true, Description: "OpenAI models deployed on Azure"},
+		{Name: "openai-compatible", RequiresAPIKey: false, Description: "Servers speaking the OpenAI API"},
+		{Name: "anthropic", RequiresAPIKey: true, Description: "Anthropic Claude models", DefaultModel: "claude-3-5-sonnet-latest"},
+		{Name: "ollama", RequiresAPIKey: false, Description: "Local models served by Ollama", DefaultModel: "llama3.2:1b"},
+		{Name: "mock", RequiresAPIKey: false, Description: "Offline canned or echo replies", DefaultModel: "mock"},
+	}
+}
 
 func CreateProvider(providerType, apiKey, model string, temperature float64, maxTokens int) (Provider, error) {
 	switch providerType {
 	case "openai":
 		return NewOpenAIProvider(apiKey, model, temperature, maxTokens), nil
+	case "azure-openai":
+		return NewAzureOpenAIProvider(apiKey, "", model, temperature, maxTokens), nil // the resource comes from the config
+	case "openai-compatible":
+		return NewOpenAICompatibleProvider(apiKey, "", model, temperature, maxTokens), nil // base_url comes from the config
 	case "anthropic":
 		return NewAnthropicProvider(apiKey, model, temperature, maxTokens), nil
 	case "ollama":
 		return NewOllamaProvider(model, temperature, maxTokens, ""), nil
+	case "mock":
+		return NewMockProvider(model), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerType)
 	}
@@ -456,10 +1118,171 @@ func CreateProviderFromConfig(cfg interface{}) (Provider, error) {
 		GetTemperature() float64
 		GetMaxTokens() int
 	}
-	
-	if config, ok := cfg.(ConfigLike); ok {
-		return CreateProvider(config.GetProvider(), config.GetAPIKey(), config.GetModel(), config.GetTemperature(), config.GetMaxTokens())
+
+	config, ok := cfg.(ConfigLike)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type")
+	}
+
+	slog.Debug("creating provider", "provider", config.GetProvider(), "model", config.GetModel(),
+		"temperature", config.GetTemperature(), "max_tokens", config.GetMaxTokens())
+	provider, err := CreateProvider(config.GetProvider(), config.GetAPIKey(), config.GetModel(), config.GetTemperature(), config.GetMaxTokens())
+	if err != nil {
+		return nil, err
+	}
+	warnModelMismatch(config.GetProvider(), config.GetModel())
+
+	applyProviderOptions(provider, cfg)
+	preloadModel(provider, cfg)
+	if retrievalMiddleware != nil {
+		provider = WrapProvider(provider, retrievalMiddleware)
+	}
+	if Metrics != nil {
+		provider = WrapProvider(provider, MetricsMiddleware(Metrics, provider))
 	}
-	
-	return nil, fmt.Errorf("invalid config type")
-}
\ No newline at end of file
+	if responseCache != nil && config.GetTemperature() <= CacheMaxTemperature {
+		systemPrompt, format := "", ""
+		if sp, ok := cfg.(interface{ GetSystemPrompt() string }); ok {
+			systemPrompt = sp.GetSystemPrompt()
+		}
+		if rf, ok := cfg.(interface{ GetResponseFormat() string }); ok {
+			format = rf.GetResponseFormat()
+		}
+		key := cacheKey(config.GetProvider(), config.GetModel(), systemPrompt, format)
+		provider = WrapProvider(provider, SemanticCacheMiddleware(responseCache, provider, key))
+	}
+	return provider, nil
+}
+
+// preloadModel warms the model up in the background when the config asks for
+// it, so the TUI, the GUI and -p all start loading it as soon as the provider
+// exists. A failed warm-up resurfaces on the first request.
+func preloadModel(provider Provider, cfg interface{}) {
+	pm, ok := cfg.(interface{ GetPreloadModel() bool })
+	if !ok || !pm.GetPreloadModel() {
+		return
+	}
+	if preloader, ok := provider.(interface{ Preload(context.Context) error }); ok {
+		go func() {
+			if err := preloader.Preload(context.Background()); err != nil {
+				slog.Debug("model preload failed", "provider", provider.GetName(), "error", err)
+			}
+		}()
+	}
+}
+
+// SetStopSequences makes the provider end generation before any of the given
+// strings. It reaches through middleware, so it can change a running provider.
+// The anthropic provider does not send them and warns instead.
+func SetStopSequences(provider Provider, stop []string) {
+	switch p := UnwrapProvider(provider).(type) {
+	case *OpenAIProvider:
+		p.StopSequences = stop
+	case *AnthropicProvider:
+		if len(stop) > 0 {
+			warnIgnored(p, "stop_sequences")
+		}
+	case *OllamaProvider:
+		p.StopSequences = stop
+	case *MockProvider:
+		p.StopSequences = stop
+	}
+}
+
+// applyProviderOptions copies optional settings from the config onto the provider.
+// Each setting is read through its own getter so partial configs remain valid.
+func applyProviderOptions(provider Provider, cfg interface{}) {
+	if sp, ok := cfg.(interface{ GetSystemPrompt() string }); ok {
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			p.SystemPrompt = sp.GetSystemPrompt()
+		case *AnthropicProvider:
+			p.SystemPrompt = sp.GetSystemPrompt()
+		case *OllamaProvider:
+			p.SystemPrompt = sp.GetSystemPrompt()
+		case *MockProvider:
+			p.SystemPrompt = sp.GetSystemPrompt()
+		}
+	}
+
+	if mock, isMock := provider.(*MockProvider); isMock {
+		if mr, ok := cfg.(interface{ GetMockResponses() map[string]string }); ok {
+			for key, response := range mr.GetMockResponses() {
+				mock.Responses[key] = response
+			}
+		}
+		if mt, ok := cfg.(interface {
+			GetMockToolCalls() map[string]json.RawMessage
+		}); ok {
+			mock.ToolCalls = decodeMockToolCalls(mt.GetMockToolCalls())
+		}
+	}
+
+	if p, isOllama := provider.(*OllamaProvider); isOllama {
+		if ap, ok := cfg.(interface{ GetAutoPullModels() bool }); ok {
+			p.AutoPull = ap.GetAutoPullModels()
+		}
+		if ka, ok := cfg.(interface{ GetKeepAlive() string }); ok {
+			p.KeepAlive = ka.GetKeepAlive()
+		}
+		if hl, ok := cfg.(interface{ GetHistoryLimit() int }); ok {
+			p.HistoryLimit = hl.GetHistoryLimit()
+		}
+	}
+
+	if em, ok := cfg.(interface{ GetEmbeddingModel() string }); ok {
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			p.EmbeddingModel = em.GetEmbeddingModel()
+		case *OllamaProvider:
+			p.EmbeddingModel = em.GetEmbeddingModel()
+		}
+	}
+
+	if ss, ok := cfg.(interface{ GetStopSequences() []string }); ok {
+		SetStopSequences(provider, ss.GetStopSequences())
+	}
+
+	if sd, ok := cfg.(interface{ GetSeed() *int }); ok {
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			p.Seed = sd.GetSeed()
+		case *OllamaProvider:
+			p.Seed = sd.GetSeed()
+		case *AnthropicProvider:
+			if sd.GetSeed() != nil {
+				warnIgnored(p, "seed")
+			}
+		}
+	}
+
+	applySampling(provider, cfg)
+
+	if xp, ok := cfg.(interface{ GetExtraParams() map[string]interface{} }); ok {
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			p.ExtraParams = xp.GetExtraParams()
+		case *OllamaProvider:
+			p.ExtraParams = xp.GetExtraParams()
+		case *AnthropicProvider:
+			if len(xp.GetExtraParams()) > 0 {
+				warnIgnored(p, "extra_params")
+			}
+		}
+	}
+
+	if rf, ok := cfg.(interface{ GetResponseFormat() string }); ok {
+		switch p := provider.(type) {
+		case *OpenAIProvider:
+			p.ResponseFormat = rf.GetResponseFormat()
+		case *OllamaProvider:
+			p.Format = rf.GetResponseFormat()
+		case *AnthropicProvider:
+			if rf.GetResponseFormat() != "" {
+				warnIgnored(p, "response_format")
+			}
+		}
+	}
+
+	applyEndpointOptions(provider, cfg)
+}
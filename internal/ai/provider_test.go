@@ -2,8 +2,16 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateProvider(t *testing.T) {
@@ -82,7 +90,11 @@ func TestCreateProvider(t *testing.T) {
 }
 
 func TestOpenAIProvider(t *testing.T) {
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, "Hello there", &captured)
+	defer server.Close()
 	provider := NewOpenAIProvider("test-key", "gpt-3.5-turbo", 0.7, 1000)
+	provider.BaseURL = server.URL
 	
 	if provider.GetName() != "OpenAI" {
 		t.Errorf("Expected provider name 'OpenAI', got %s", provider.GetName())
@@ -95,8 +107,52 @@ func TestOpenAIProvider(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 	
-	if !strings.Contains(response, "test prompt") {
-		t.Error("Response should contain the original prompt")
+	if response != "Hello there" {
+		t.Errorf("Expected the reply from the server, got %q", response)
+	}
+	if captured.Model != "gpt-3.5-turbo" || captured.MaxTokens != 1000 || captured.Stream {
+		t.Errorf("Unexpected request %+v", captured)
+	}
+	if len(captured.Messages) != 1 || captured.Messages[0] != (Message{Role: "user", Content: "test prompt"}) {
+		t.Errorf("Expected the prompt as the user message, got %+v", captured.Messages)
+	}
+}
+
+func TestOpenAIProviderStreaming(t *testing.T) {
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, "Hello there", &captured)
+	defer server.Close()
+	provider := NewOpenAIProvider("test-key", "gpt-4o", 0.7, 0)
+	provider.BaseURL = server.URL
+	
+	var chunks []string
+	response, err := provider.GenerateStreamingResponse(context.Background(), "hi", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !captured.Stream {
+		t.Error("Expected a streaming request")
+	}
+	if response != "Hello there" || len(chunks) != 2 {
+		t.Errorf("Expected the reply in two chunks, got %q in %q", response, chunks)
+	}
+}
+
+func TestOpenAIProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+	}))
+	defer server.Close()
+	provider := NewOpenAIProvider("sk-wrong", "gpt-4o", 0.7, 0)
+	provider.BaseURL = server.URL
+	
+	_, err := provider.GenerateResponse(context.Background(), "hi")
+	var requestErr *RequestError
+	if err == nil || !strings.Contains(err.Error(), "Incorrect API key provided") || !errors.As(err, &requestErr) {
+		t.Errorf("Expected the API's message with the request ID, got %v", err)
 	}
 }
 
@@ -138,4 +194,642 @@ func TestOllamaProviderCustomURL(t *testing.T) {
 	if provider.BaseURL != customURL {
 		t.Errorf("Expected custom base URL %s, got %s", customURL, provider.BaseURL)
 	}
-}
\ No newline at end of file
+}
+
+// testConfig implements the getters used by CreateProviderFromConfig
+type testConfig struct {
+	provider       string
+	model          string
+	systemPrompt   string
+	responseFormat string
+	stopSequences  []string
+}
+
+func (c *testConfig) GetProvider() string       { return c.provider }
+func (c *testConfig) GetAPIKey() string         { return "test-key" }
+func (c *testConfig) GetModel() string          { return c.model }
+func (c *testConfig) GetTemperature() float64   { return 0.7 }
+func (c *testConfig) GetMaxTokens() int         { return 0 }
+func (c *testConfig) GetSystemPrompt() string   { return c.systemPrompt }
+func (c *testConfig) GetResponseFormat() string { return c.responseFormat }
+func (c *testConfig) GetStopSequences() []string { return c.stopSequences }
+
+func TestCreateProviderFromConfigAppliesSystemPrompt(t *testing.T) {
+	for _, providerType := range []string{"openai", "anthropic", "ollama"} {
+		t.Run(providerType, func(t *testing.T) {
+			provider, err := CreateProviderFromConfig(&testConfig{provider: providerType, model: "m", systemPrompt: "Be brief."})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			
+			var got string
+			switch p := provider.(type) {
+			case *OpenAIProvider:
+				got = p.SystemPrompt
+			case *AnthropicProvider:
+				got = p.SystemPrompt
+			case *OllamaProvider:
+				got = p.SystemPrompt
+			}
+			if got != "Be brief." {
+				t.Errorf("Expected system prompt to be applied, got %q", got)
+			}
+		})
+	}
+}
+
+// newOllamaTestServer returns a server that records the last request body into captured
+// (an *OllamaChatRequest or *OllamaRequest) and answers both /api/chat and /api/generate
+func newOllamaTestServer(t *testing.T, response string, captured interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if captured != nil {
+			if err := json.Unmarshal(body, captured); err != nil {
+				t.Errorf("Failed to decode request body: %v", err)
+			}
+		}
+		if r.URL.Path == "/api/chat" {
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: response}, Done: true})
+			return
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+}
+
+// newOpenAITestServer returns a /chat/completions server that records the last
+// request body into captured and replies with response, as two events when
+// the request streams
+func newOpenAITestServer(t *testing.T, response string, captured *OpenAIChatRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if captured != nil {
+			if err := json.Unmarshal(body, captured); err != nil {
+				t.Errorf("Failed to decode request body: %v", err)
+			}
+		}
+		var req OpenAIChatRequest
+		json.Unmarshal(body, &req)
+		if !req.Stream {
+			fmt.Fprintf(w, `{"choices": [{"message": {"role": "assistant", "content": %q}, "finish_reason": "stop"}]}`, response)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		half := len(response) / 2
+		for _, chunk := range []string{response[:half], response[half:]} {
+			fmt.Fprintf(w, "data: {\"choices\": [{\"delta\": {\"content\": %q}}]}\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: {\"choices\": [{\"delta\": {}, \"finish_reason\": \"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+}
+
+func TestOllamaProviderSendsSystemPrompt(t *testing.T) {
+	var captured OllamaChatRequest
+	server := newOllamaTestServer(t, "ok", &captured)
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	provider.SystemPrompt = "You are a teacher."
+	
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	if len(captured.Messages) == 0 || captured.Messages[0] != (OllamaMessage{Role: "system", Content: "You are a teacher."}) {
+		t.Errorf("Expected system prompt as the first message, got %+v", captured.Messages)
+	}
+}
+
+func TestOllamaProviderChatSendsHistory(t *testing.T) {
+	var captured OllamaChatRequest
+	server := newOllamaTestServer(t, "4", &captured)
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	provider.History = []OllamaMessage{
+		{Role: "user", Content: "What is 1+1?"},
+		{Role: "assistant", Content: "2"},
+	}
+	
+	response, err := provider.GenerateResponse(context.Background(), "And 2+2?")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response != "4" {
+		t.Errorf("Expected chat reply, got %q", response)
+	}
+	
+	roles := make([]string, len(captured.Messages))
+	for i, m := range captured.Messages {
+		roles[i] = m.Role
+	}
+	if strings.Join(roles, ",") != "user,assistant,user" {
+		t.Errorf("Expected history followed by the prompt, got roles %v", roles)
+	}
+}
+
+func TestRememberTurnKeepsHistoryLimit(t *testing.T) {
+	provider := NewOllamaProvider("llama2", 0.7, 0, "")
+	provider.HistoryLimit = 4
+	for _, turn := range []string{"one", "two", "three"} {
+		RememberTurn(provider, turn, "ok")
+	}
+	if len(provider.History) != 4 || provider.History[0].Content != "two" {
+		t.Errorf("Expected the last two turns only, got %+v", provider.History)
+	}
+
+	var captured OllamaChatRequest
+	server := newOllamaTestServer(t, "[]", &captured)
+	defer server.Close()
+	provider.BaseURL = server.URL
+	if _, err := NewIntentDetector(provider).DetectIntent(context.Background(), "list files"); err != nil {
+		t.Fatalf("DetectIntent failed: %v", err)
+	}
+	if len(captured.Messages) != 1 || captured.Messages[0].Role != "user" {
+		t.Errorf("Expected intent detection without the history, got %+v", captured.Messages)
+	}
+}
+
+func TestOllamaProviderFallsBackToGenerate(t *testing.T) {
+	chatCalls := 0
+	var captured OllamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat" {
+			chatCalls++
+			http.NotFound(w, r) // an Ollama version without the chat endpoint
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &captured)
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "legacy", Done: true})
+	}))
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	provider.SystemPrompt = "Be brief."
+	
+	for i := 0; i < 2; i++ {
+		response, err := provider.GenerateResponse(context.Background(), "hi")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if response != "legacy" {
+			t.Errorf("Expected fallback response, got %q", response)
+		}
+	}
+	if chatCalls != 1 {
+		t.Errorf("Expected chat to be tried once and then skipped, got %d calls", chatCalls)
+	}
+	if captured.System != "Be brief." || captured.Prompt != "hi" {
+		t.Errorf("Expected generate request with system prompt, got %+v", captured)
+	}
+}
+
+func TestSupportedProvidersAreCreatable(t *testing.T) {
+	for _, info := range SupportedProviders() {
+		if _, err := CreateProvider(info.Name, "key", "model", 0.7, 100); err != nil {
+			t.Errorf("Supported provider %q could not be created: %v", info.Name, err)
+		}
+	}
+}
+
+func TestOllamaProviderNotRunning(t *testing.T) {
+	// Grab a free port and close it so nothing is listening
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, url)
+	
+	_, err := provider.GenerateResponse(context.Background(), "hi")
+	if !errors.Is(err, ErrOllamaNotRunning) {
+		t.Fatalf("Expected ErrOllamaNotRunning, got %v", err)
+	}
+	if !strings.Contains(err.Error(), url) || !strings.Contains(err.Error(), "ollama serve") {
+		t.Errorf("Expected actionable message with URL, got %q", err.Error())
+	}
+	
+	if err := provider.Ping(context.Background()); !errors.Is(err, ErrOllamaNotRunning) {
+		t.Errorf("Expected Ping to report ErrOllamaNotRunning, got %v", err)
+	}
+}
+
+func TestOllamaProviderPing(t *testing.T) {
+	server := newOllamaTestServer(t, "", nil)
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	if err := provider.Ping(context.Background()); err != nil {
+		t.Errorf("Expected Ping to succeed, got %v", err)
+	}
+}
+
+func TestOllamaProviderAutoPullsMissingModel(t *testing.T) {
+	pulled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/pull":
+			pulled = true
+			enc := json.NewEncoder(w)
+			enc.Encode(OllamaPullResponse{Status: "downloading", Total: 100, Completed: 50})
+			enc.Encode(OllamaPullResponse{Status: "success"})
+		case "/api/chat":
+			if !pulled {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"model \"llama2\" not found, try pulling it first"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: "pulled ok"}, Done: true})
+		}
+	}))
+	defer server.Close()
+	
+	defer func(orig func(string, string, int64, int64)) { PullProgress = orig }(PullProgress)
+	var statuses []string
+	PullProgress = func(model, status string, completed, total int64) {
+		statuses = append(statuses, status)
+	}
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	
+	// Without auto-pull the missing model is reported
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Expected ErrModelNotFound, got %v", err)
+	}
+	if pulled {
+		t.Fatal("Model should not be pulled when AutoPull is disabled")
+	}
+	
+	provider.AutoPull = true
+	response, err := provider.GenerateResponse(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response != "pulled ok" {
+		t.Errorf("Expected retried response, got %q", response)
+	}
+	if len(statuses) != 2 || statuses[1] != "success" {
+		t.Errorf("Expected pull progress to be reported, got %v", statuses)
+	}
+}
+
+func TestOllamaProviderPreloadSendsKeepAlive(t *testing.T) {
+	var captured OllamaRequest
+	server := newOllamaTestServer(t, "", &captured)
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	provider.KeepAlive = "30m"
+	
+	if err := provider.Preload(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if captured.Model != "llama2" || captured.Prompt != "" {
+		t.Errorf("Expected an empty-prompt load request for llama2, got %+v", captured)
+	}
+	if captured.KeepAlive != "30m" {
+		t.Errorf("Expected keep_alive 30m, got %q", captured.KeepAlive)
+	}
+}
+
+type preloadConfig struct {
+	endpointConfig
+	preload bool
+}
+
+func (c *preloadConfig) GetPreloadModel() bool { return c.preload }
+
+func TestCreateProviderPreloadsModel(t *testing.T) {
+	loads := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		loads <- req.Model
+		json.NewEncoder(w).Encode(OllamaResponse{Done: true})
+	}))
+	defer server.Close()
+
+	cfg := &preloadConfig{endpointConfig: endpointConfig{testConfig: testConfig{provider: "ollama", model: "llama3"}, baseURL: server.URL}}
+	if _, err := CreateProviderFromConfig(cfg); err != nil {
+		t.Fatalf("CreateProviderFromConfig failed: %v", err)
+	}
+	select {
+	case model := <-loads:
+		t.Fatalf("Expected no preload unless preload_model is set, got one for %s", model)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cfg.preload = true
+	if _, err := CreateProviderFromConfig(cfg); err != nil {
+		t.Fatalf("CreateProviderFromConfig failed: %v", err)
+	}
+	select {
+	case model := <-loads:
+		if model != "llama3" {
+			t.Errorf("Expected llama3 to be preloaded, got %s", model)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected creating the provider to preload its model")
+	}
+}
+
+func TestOllamaProviderSendsOptions(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "ok", Done: true})
+	}))
+	defer server.Close()
+	
+	tests := []struct {
+		name      string
+		maxTokens int
+		expected  string
+	}{
+		{"with max tokens", 256, `"options":{"temperature":0.2,"num_predict":256}`},
+		{"unlimited tokens", 0, `"options":{"temperature":0.2}`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewOllamaProvider("llama2", 0.2, tt.maxTokens, server.URL)
+			if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(string(body), tt.expected) {
+				t.Errorf("Expected request body to contain %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestOllamaProviderSendsFormat(t *testing.T) {
+	var captured OllamaChatRequest
+	server := newOllamaTestServer(t, "{}", &captured)
+	defer server.Close()
+	
+	cfg := &testConfig{provider: "ollama", model: "llama2", responseFormat: ResponseFormatJSON}
+	provider, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ollama := provider.(*OllamaProvider)
+	ollama.BaseURL = server.URL
+	
+	if _, err := ollama.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if captured.Format != "json" {
+		t.Errorf("Expected format json in request, got %q", captured.Format)
+	}
+}
+
+func TestOllamaProviderMarksTruncatedReplies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: "The first"}})
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: " part"}, Done: true, DoneReason: "length"})
+			return
+		}
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: "The first part"}, Done: true, DoneReason: "length"})
+	}))
+	defer server.Close()
+	
+	provider := NewOllamaProvider("llama2", 0.7, 2, server.URL)
+	want := "The first part\n\n" + TruncationNote
+	
+	response, err := provider.GenerateResponse(context.Background(), "hi")
+	if err != nil || response != want {
+		t.Errorf("Expected the truncation note, got %q (%v)", response, err)
+	}
+	
+	var streamed strings.Builder
+	response, err = provider.GenerateStreamingResponse(context.Background(), "hi", func(chunk string) { streamed.WriteString(chunk) })
+	if err != nil || response != want || streamed.String() != want {
+		t.Errorf("Expected the truncation note when streaming, got %q / %q (%v)", response, streamed.String(), err)
+	}
+	
+	if reply, truncated := StripTruncationNote(response); !truncated || reply != "The first part" {
+		t.Errorf("Expected StripTruncationNote to remove the note, got %q, %v", reply, truncated)
+	}
+	if _, truncated := StripTruncationNote("A complete reply"); truncated {
+		t.Error("Expected a complete reply not to count as truncated")
+	}
+}
+
+func TestStopSequences(t *testing.T) {
+	var options []*OllamaOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat" {
+			var req OllamaChatRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			options = append(options, req.Options)
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: "ok"}, Done: true})
+			return
+		}
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		options = append(options, req.Options)
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "ok", Done: true})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 100, server.URL)
+	SetStopSequences(WrapProvider(provider), []string{"###", "\n\n"})
+	provider.GenerateResponse(context.Background(), "hi")
+	provider.generateCompletion(context.Background(), "hi")
+	if len(options) != 2 {
+		t.Fatalf("Expected two requests, got %d", len(options))
+	}
+	for _, opts := range options {
+		if opts == nil || len(opts.Stop) != 2 || opts.Stop[0] != "###" {
+			t.Errorf("Expected options.stop to be sent, got %+v", opts)
+		}
+	}
+
+	// Config values reach every provider type that sends them
+	for _, providerType := range []string{"openai", "ollama", "mock"} {
+		p, err := CreateProviderFromConfig(&testConfig{provider: providerType, model: "m", stopSequences: []string{"END"}})
+		if err != nil {
+			t.Fatalf("CreateProviderFromConfig(%s) failed: %v", providerType, err)
+		}
+		var got []string
+		switch p := p.(type) {
+		case *OpenAIProvider:
+			got = p.StopSequences
+		case *OllamaProvider:
+			got = p.StopSequences
+		case *MockProvider:
+			got = p.StopSequences
+		}
+		if len(got) != 1 || got[0] != "END" {
+			t.Errorf("%s: expected the stop sequences to be applied, got %v", providerType, got)
+		}
+	}
+
+	// OpenAI receives them as "stop"
+	var captured OpenAIChatRequest
+	openaiServer := newOpenAITestServer(t, "ok", &captured)
+	defer openaiServer.Close()
+	openai := NewOpenAIProvider("sk-test", "gpt-4o", 0.7, 0)
+	openai.BaseURL = openaiServer.URL
+	SetStopSequences(WrapProvider(openai), []string{"###"})
+	if _, err := openai.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if len(captured.Stop) != 1 || captured.Stop[0] != "###" {
+		t.Errorf("Expected stop to be sent, got %+v", captured)
+	}
+
+	// The mock provider honours them, so scripts behave like a real model
+	mock := NewMockProvider("test")
+	mock.Responses["list"] = "1. apples\n2. pears\nEND of list"
+	mock.StopSequences = []string{"END"}
+	if response, _ := mock.GenerateResponse(context.Background(), "list fruit"); response != "1. apples\n2. pears\n" {
+		t.Errorf("Expected the reply to stop before END, got %q", response)
+	}
+}
+
+func TestOllamaProviderSendsSeed(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Content: "ok"}, Done: true})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 100, server.URL)
+	provider.GenerateResponse(context.Background(), "hi")
+	seed := 42
+	provider.Seed = &seed
+	provider.GenerateResponse(context.Background(), "hi")
+
+	if strings.Contains(bodies[0], "seed") {
+		t.Errorf("Expected no seed unless one is set, got %s", bodies[0])
+	}
+	if !strings.Contains(bodies[1], `"seed":42`) {
+		t.Errorf("Expected options.seed to be sent, got %s", bodies[1])
+	}
+}
+
+func TestOpenAIProviderSendsResponseFormat(t *testing.T) {
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, `{"ok": true}`, &captured)
+	defer server.Close()
+
+	provider, err := CreateProviderFromConfig(&endpointConfig{testConfig: testConfig{provider: "openai", model: "gpt-4o", responseFormat: ResponseFormatJSON}, baseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := provider.GenerateResponse(context.Background(), "hi"); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if captured.ResponseFormat == nil || captured.ResponseFormat.Type != "json_object" {
+		t.Errorf("Expected response_format json_object, got %+v", captured.ResponseFormat)
+	}
+}
+
+func TestOpenAIProviderSendsSeed(t *testing.T) {
+	var captured OpenAIChatRequest
+	server := newOpenAITestServer(t, "ok", &captured)
+	defer server.Close()
+
+	provider := NewOpenAIProvider("sk-test", "gpt-4o", 0.7, 0)
+	provider.BaseURL = server.URL
+	provider.GenerateResponse(context.Background(), "hi")
+	if captured.Seed != nil {
+		t.Errorf("Expected no seed unless one is set, got %d", *captured.Seed)
+	}
+	seed := 42
+	provider.Seed = &seed
+	provider.GenerateResponse(context.Background(), "hi")
+	if captured.Seed == nil || *captured.Seed != 42 {
+		t.Errorf("Expected seed 42 to be sent, got %v", captured.Seed)
+	}
+}
+
+func TestMockProvider(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	
+	provider, err := CreateProvider("mock", "", "demo", 0.7, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mock := provider.(*MockProvider)
+	mock.Responses["hello"] = "Hi there!"
+	mock.Responses["hello world"] = "Hello, world!"
+	mock.ToolCalls["make notes"] = []ToolCall{
+		{Name: "create_file", Arguments: map[string]interface{}{"filename": "notes.txt", "content": "demo"}},
+	}
+	
+	ctx := context.Background()
+	tests := map[string]string{
+		"HELLO":           "Hi there!",
+		"say hello world": "Hello, world!",
+		"anything else":   "Echo: anything else",
+	}
+	for prompt, expected := range tests {
+		if response, _ := provider.GenerateResponse(ctx, prompt); response != expected {
+			t.Errorf("GenerateResponse(%q) = %q, want %q", prompt, response, expected)
+		}
+	}
+	
+	var chunks []string
+	streamed, _ := provider.GenerateStreamingResponse(ctx, "hello", func(chunk string) { chunks = append(chunks, chunk) })
+	if streamed != "Hi there!" || strings.Join(chunks, "") != streamed {
+		t.Errorf("Expected streamed chunks to form the response, got %q from %v", streamed, chunks)
+	}
+	
+	_, results, err := provider.GenerateResponseWithTools(ctx, "please make notes")
+	if err != nil || len(results) != 1 || !results[0].Success {
+		t.Fatalf("Expected scripted tool call to succeed, got %+v (%v)", results, err)
+	}
+	if content, _ := os.ReadFile("notes.txt"); string(content) != "demo" {
+		t.Errorf("Expected scripted tool to create notes.txt, got %q", content)
+	}
+}
+
+func TestCreateProviderFromConfigAppliesMockScripts(t *testing.T) {
+	cfg := &mockScriptConfig{
+		testConfig: testConfig{provider: "mock", model: "demo"},
+		responses:  map[string]string{"ping": "pong"},
+		toolCalls: map[string]json.RawMessage{
+			"where":  json.RawMessage(`[{"name": "get_working_directory", "arguments": {}}]`),
+			"broken": json.RawMessage(`"not a list"`),
+		},
+	}
+	provider, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	mock := provider.(*MockProvider)
+	if mock.Responses["ping"] != "pong" {
+		t.Errorf("Expected configured response, got %v", mock.Responses)
+	}
+	if len(mock.ToolCalls["where"]) != 1 || mock.ToolCalls["where"][0].Name != "get_working_directory" {
+		t.Errorf("Expected configured tool call, got %v", mock.ToolCalls)
+	}
+	if _, exists := mock.ToolCalls["broken"]; exists {
+		t.Error("Expected malformed tool script to be skipped")
+	}
+}
+
+type mockScriptConfig struct {
+	testConfig
+	responses map[string]string
+	toolCalls map[string]json.RawMessage
+}
+
+func (c *mockScriptConfig) GetMockResponses() map[string]string          { return c.responses }
+func (c *mockScriptConfig) GetMockToolCalls() map[string]json.RawMessage { return c.toolCalls }
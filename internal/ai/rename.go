@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"fmt"
+
+	"tala/internal/fileops"
+)
+
+// ConfirmRenames shows the user what bulk_rename is about to change in dir and
+// asks whether to go ahead. When nil, as in headless mode, bulk renames are
+// refused.
+var ConfirmRenames func(dir string, renames []fileops.Rename) bool
+
+// bulkRename plans the renames, previews them through ConfirmRenames and
+// applies them once confirmed
+func bulkRename(dir, pattern, replacement string, regex bool) *fileops.FileOperation {
+	renames, refused := confirmRenames(dir, pattern, replacement, regex)
+	if refused != nil {
+		return refused
+	}
+	if err := fileops.ApplyRenames(dir, renames); err != nil {
+		return toolFailed(fmt.Sprintf("Error: %v", err))
+	}
+	return toolOutput(fmt.Sprintf("Renamed %d files in '%s':\n%s", len(renames), dir, fileops.FormatRenames(renames)))
+}
+
+// confirmRenames plans the renames and asks the user about them. When there is
+// nothing to rename or the user does not agree, it returns what to report
+// instead.
+func confirmRenames(dir, pattern, replacement string, regex bool) ([]fileops.Rename, *fileops.FileOperation) {
+	renames, err := fileops.PlanRenames(dir, pattern, replacement, regex)
+	if err != nil {
+		return nil, toolFailed(fmt.Sprintf("Error: %v", err))
+	}
+	if len(renames) == 0 {
+		return nil, toolOutput(fmt.Sprintf("No file names in '%s' match '%s'", dir, pattern))
+	}
+	preview := fileops.FormatRenames(renames)
+	if ConfirmRenames == nil {
+		return nil, toolFailed(fmt.Sprintf("Error: renaming files needs confirmation, which is not available here. It would rename:\n%s", preview))
+	}
+	if !ConfirmRenames(dir, renames) {
+		return nil, toolFailed(fmt.Sprintf("Error: the user declined renaming:\n%s", preview))
+	}
+	return renames, nil
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tala/internal/fileops"
+)
+
+func TestBulkRenameNeedsConfirmation(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	defer func() { ConfirmRenames = nil }()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Draft One.md"), []byte("one"), 0644)
+	args := map[string]interface{}{"path": dir, "pattern": " ", "replacement": "_"}
+
+	ConfirmRenames = nil
+	result := ExecuteTool("bulk_rename", args)
+	if result.Success || !strings.Contains(result.Content, "Draft One.md -> Draft_One.md") {
+		t.Errorf("Expected a refusal showing the preview, got %q", result.Content)
+	}
+
+	var previewed []fileops.Rename
+	ConfirmRenames = func(d string, renames []fileops.Rename) bool {
+		previewed = renames
+		return false
+	}
+	if result := ExecuteTool("bulk_rename", args); result.Success {
+		t.Errorf("Expected a declined rename to fail, got %q", result.Content)
+	}
+	if len(previewed) != 1 || previewed[0].To != "Draft_One.md" {
+		t.Errorf("Expected the rename to be previewed, got %+v", previewed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Draft One.md")); err != nil {
+		t.Error("Expected nothing to be renamed before confirming")
+	}
+
+	ConfirmRenames = func(string, []fileops.Rename) bool { return true }
+	if result := ExecuteTool("bulk_rename", args); !result.Success {
+		t.Fatalf("Expected the confirmed rename to succeed, got %q", result.Content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Draft_One.md")); err != nil {
+		t.Errorf("Expected Draft_One.md: %v", err)
+	}
+
+	if _, err := Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Draft One.md")); err != nil {
+		t.Errorf("Expected undo to restore the old name: %v", err)
+	}
+}
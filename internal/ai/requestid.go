@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader carries the ID tala gives each provider request, so a
+// failure can be matched to the server's or a gateway's logs
+const RequestIDHeader = "X-Request-ID"
+
+// NewRequestID returns a random version 4 UUID
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// RequestError is a failed provider request with the ID it was sent with
+type RequestError struct {
+	ID  string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v (request ID %s)", e.Err, e.ID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// providerRoundTripper adds the configured extra headers and an
+// X-Request-ID to each request, keeping any the provider set itself, and
+// logs the request under its ID: failures at info level, since the error
+// returned already names the ID, and everything else at debug
+type providerRoundTripper struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+func (t *providerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context()) // a RoundTripper must not change the caller's request
+	for name, values := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header[name] = values
+		}
+	}
+	id := req.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = NewRequestID()
+		req.Header.Set(RequestIDHeader, id)
+	}
+	slog.Debug("provider request", "request_id", id, "method", req.Method, "url", req.URL.Redacted())
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Info("provider request failed", "request_id", id, "url", req.URL.Redacted(), "error", err)
+		return nil, &RequestError{ID: id, Err: err}
+	}
+	if resp.StatusCode >= 400 {
+		slog.Info("provider request returned an error", "request_id", id, "url", req.URL.Redacted(), "status", resp.StatusCode)
+	} else {
+		slog.Debug("provider response", "request_id", id, "status", resp.StatusCode, "duration", time.Since(start))
+	}
+	return resp, nil
+}
+
+// withRequestID adds the ID the response's request was sent with to err
+func withRequestID(resp *http.Response, err error) error {
+	if err == nil || resp == nil || resp.Request == nil {
+		return err
+	}
+	if id := resp.Request.Header.Get(RequestIDHeader); id != "" {
+		return &RequestError{ID: id, Err: err}
+	}
+	return err
+}
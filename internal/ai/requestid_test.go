@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDOnProviderRequests(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(RequestIDHeader))
+		http.Error(w, "gateway overloaded", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama3", 0.7, 100, server.URL)
+	var errs []error
+	for i := 0; i < 2; i++ {
+		_, err := provider.generate(context.Background(), "hello")
+		errs = append(errs, err)
+	}
+
+	if len(ids) != 2 || ids[0] == ids[1] {
+		t.Fatalf("Expected two different request IDs, got %q", ids)
+	}
+	for i, id := range ids {
+		if !uuidPattern.MatchString(id) {
+			t.Errorf("Request ID %q is not a UUID", id)
+		}
+		var requestErr *RequestError
+		if !errors.As(errs[i], &requestErr) || requestErr.ID != id {
+			t.Errorf("Expected the error to carry request ID %s, got %v", id, errs[i])
+		}
+		if !strings.Contains(errs[i].Error(), id) {
+			t.Errorf("Expected the error message to show request ID %s, got %q", id, errs[i])
+		}
+	}
+}
+
+func TestRequestIDKeepsCallerHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(RequestIDHeader)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set(RequestIDHeader, "trace-42")
+	resp, err := NewHTTPClient(RequestTimeout).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != "trace-42" {
+		t.Errorf("Expected the caller's request ID to be sent, got %q", got)
+	}
+}
+
+func TestRequestIDOnTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	_, err := NewHTTPClient(RequestTimeout).Get(url)
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || !uuidPattern.MatchString(requestErr.ID) {
+		t.Errorf("Expected a failed request to report its ID, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SaveResponseTool writes the AI's own reply to a file. Unlike other tools it
+// runs after the reply is generated, with the generated code or text as content.
+const SaveResponseTool = "save_response"
+
+// deferSaves splits off save_response intents, which have to wait for the reply
+func deferSaves(intents []Intent) (now, saves []Intent) {
+	for _, intent := range intents {
+		if intent.Tool == SaveResponseTool {
+			saves = append(saves, intent)
+		} else {
+			now = append(now, intent)
+		}
+	}
+	return now, saves
+}
+
+// saveInstruction asks the model to shape its reply as the content of the files
+// it will be saved to
+func saveInstruction(saves []Intent) string {
+	var files []string
+	for _, save := range saves {
+		if filename, ok := save.Parameters["filename"].(string); ok && filename != "" {
+			files = append(files, filename)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Your reply will be saved to %s, so put the complete file content in a single fenced code block.\n",
+		strings.Join(files, ", "))
+}
+
+// saveResponse runs the deferred save_response intents with the reply's content
+func saveResponse(saves []Intent, response string) []ToolResult {
+	var results []ToolResult
+	content := GeneratedContent(response)
+	for _, save := range saves {
+		args := make(map[string]interface{}, len(save.Parameters)+1)
+		for k, v := range save.Parameters {
+			args[k] = v
+		}
+		args["content"] = content
+		results = append(results, ExecuteTool(SaveResponseTool, args))
+	}
+	return results
+}
+
+// GeneratedContent picks what save_response writes from a reply: the first
+// fenced code block, or else the whole reply. Reasoning and the truncation note
+// are left out.
+func GeneratedContent(response string) string {
+	response, _ = SplitThinking(response)
+	response, _ = StripTruncationNote(response)
+
+	if start := strings.Index(response, "```"); start != -1 {
+		block := response[start+3:]
+		if newline := strings.Index(block, "\n"); newline != -1 {
+			block = block[newline+1:] // drop the language tag
+			if end := strings.Index(block, "```"); end != -1 {
+				block = block[:end]
+			}
+			return strings.TrimRight(block, " \n") + "\n"
+		}
+	}
+	return strings.TrimSpace(response) + "\n"
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"code block", "Here is the server:\n\n```go\npackage main\n\nfunc main() {}\n```\n\nRun it with go run.", "package main\n\nfunc main() {}\n"},
+		{"plain text", "  Dear team,\nthanks.  ", "Dear team,\nthanks.\n"},
+		{"reasoning left out", "<think>plan</think>```\nhello\n```", "hello\n"},
+		{"truncated block", "```python\nprint(1)\n\n" + TruncationNote, "print(1)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GeneratedContent(tt.response); got != tt.want {
+				t.Errorf("GeneratedContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaProviderSavesResponse(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "server.go")
+
+	var replyPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt := req.Messages[len(req.Messages)-1].Content
+		content := "```go\npackage main\n```"
+		if strings.Contains(prompt, "intent detection system") {
+			intents, _ := json.Marshal([]Intent{{Tool: SaveResponseTool, Parameters: map[string]interface{}{"filename": target}, Confidence: 0.95}})
+			content = string(intents)
+		} else {
+			replyPrompt = prompt
+		}
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: content}, Done: true})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	response, results, err := provider.GenerateResponseWithTools(context.Background(), "write a hello server and save it to server.go")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != SaveResponseTool || !results[0].Success {
+		t.Fatalf("Expected one successful save, got %+v", results)
+	}
+	if !strings.Contains(replyPrompt, "saved to "+target) {
+		t.Errorf("Expected the reply prompt to mention the file, got %q", replyPrompt)
+	}
+	if !strings.Contains(response, "package main") {
+		t.Errorf("Expected the reply to be returned as well, got %q", response)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != "package main\n" {
+		t.Errorf("Expected the code block saved to the file, got %q (%v)", data, err)
+	}
+}
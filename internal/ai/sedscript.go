@@ -0,0 +1,140 @@
+package ai
+
+import "strings"
+
+// sedRisk explains why a sed command could do more than print what it reads:
+// its script runs commands (e, s///e), writes files (w, W, s///w) or comes from
+// a file that cannot be checked. It returns "" for sed that only prints.
+func sedRisk(args []string) string {
+	var scripts, operands []string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			operands = append(operands, args[i+1:]...)
+			i = len(args)
+		case arg == "--expression":
+			if i+1 < len(args) {
+				i++
+				scripts = append(scripts, args[i])
+			}
+		case strings.HasPrefix(arg, "--expression="):
+			scripts = append(scripts, strings.TrimPrefix(arg, "--expression="))
+		case arg == "--file" || strings.HasPrefix(arg, "--file="):
+			return "runs a sed script from a file"
+		case arg == "--line-length":
+			i++
+		case strings.HasPrefix(arg, "--"):
+		case isFlag(arg):
+			// Short options can be grouped, and -e, -f and -l take the rest of
+			// the word or the next argument
+			for j := 1; j < len(arg); j++ {
+				switch arg[j] {
+				case 'e':
+					if j+1 < len(arg) {
+						scripts = append(scripts, arg[j+1:])
+					} else if i+1 < len(args) {
+						i++
+						scripts = append(scripts, args[i])
+					}
+					j = len(arg)
+				case 'f':
+					return "runs a sed script from a file"
+				case 'l':
+					if j+1 == len(arg) {
+						i++
+					}
+					j = len(arg)
+				}
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+
+	// Without -e the first operand is the script
+	if len(scripts) == 0 && len(operands) > 0 {
+		scripts = operands[:1]
+	}
+	for _, script := range scripts {
+		if reason := sedScriptRisk(script); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// sedScriptRisk reads a sed script command by command, skipping addresses,
+// regular expressions, replacements, text and labels, so only the command
+// letters themselves are checked
+func sedScriptRisk(script string) string {
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '/' || c == '\\':
+			// An address regular expression, \cREGEXc for another delimiter
+			delim := byte('/')
+			if c == '\\' {
+				if i+1 >= len(script) {
+					return ""
+				}
+				delim = script[i+1]
+				i++
+			}
+			i = skipSedDelimited(script, i+1, delim)
+			for i < len(script) && (script[i] == 'I' || script[i] == 'M') {
+				i++
+			}
+		case c == 's' || c == 'y':
+			if i+1 >= len(script) {
+				return ""
+			}
+			delim := script[i+1]
+			i = skipSedDelimited(script, i+2, delim)
+			i = skipSedDelimited(script, i, delim)
+			if c == 'y' {
+				continue
+			}
+			for ; i < len(script) && !strings.ContainsRune(";\n}", rune(script[i])); i++ {
+				switch script[i] {
+				case 'e':
+					return "sed script runs a command"
+				case 'w':
+					return "sed script writes a file"
+				}
+			}
+		case c == 'e':
+			return "sed script runs a command"
+		case c == 'w' || c == 'W':
+			return "sed script writes a file"
+		case strings.IndexByte("aicrR#", c) >= 0:
+			// Text, file names and comments run to the end of the line
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte(":btTv", c) >= 0:
+			// Labels end at a semicolon or the end of the line
+			for i < len(script) && script[i] != ';' && script[i] != '\n' {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return ""
+}
+
+// skipSedDelimited returns the position after the next unescaped delim
+func skipSedDelimited(script string, i int, delim byte) int {
+	for i < len(script) {
+		switch script[i] {
+		case '\\':
+			i += 2
+		case delim:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(script)
+}
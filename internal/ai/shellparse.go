@@ -0,0 +1,260 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+)
+
+// shellCommand is one simple command of a shell command line, split the way
+// the shell would split it so the safety checks see what actually runs
+type shellCommand struct {
+	Args   []string // program and arguments, with quotes and escapes removed
+	Writes []string // files its output is redirected to
+	Nested []string // command substitutions and process substitutions it runs
+}
+
+var errUnterminated = errors.New("unterminated quote or substitution")
+
+// parseShell splits a POSIX shell command line into its simple commands.
+// Quotes and backslashes are honored; ; && || | & newlines and parentheses
+// separate commands; redirections are recorded rather than kept as arguments.
+// It covers what the safety checks need, not the whole shell grammar.
+func parseShell(line string) ([]shellCommand, error) {
+	r := []rune(line)
+	var (
+		commands []shellCommand
+		current  shellCommand
+		word     strings.Builder
+		inWord   bool
+		target   int // 0, or what the next word is: 1 a written file, 2 a read file
+	)
+	flushWord := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		switch target {
+		case 1:
+			current.Writes = append(current.Writes, w)
+		case 2:
+		default:
+			current.Args = append(current.Args, w)
+		}
+		target = 0
+	}
+	endCommand := func() {
+		flushWord()
+		if len(current.Args) > 0 || len(current.Writes) > 0 || len(current.Nested) > 0 {
+			commands = append(commands, current)
+		}
+		current = shellCommand{}
+	}
+	// substitution records $(...) or `...` starting at r[i] and returns the
+	// index of its last rune
+	substitution := func(i int) (int, error) {
+		var end int
+		if r[i] == '`' {
+			end = i + 1
+			for end < len(r) && r[end] != '`' {
+				if r[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(r) {
+				return 0, errUnterminated
+			}
+			current.Nested = append(current.Nested, string(r[i+1:end]))
+		} else {
+			end = closingParen(r, i+1)
+			if end < 0 {
+				return 0, errUnterminated
+			}
+			inner := string(r[i+2 : end])
+			if strings.HasPrefix(inner, "(") {
+				// $((...)) is arithmetic, but may still hold substitutions
+				inner = inner[1 : len(inner)-1]
+				for _, sub := range nestedSubstitutions(inner) {
+					current.Nested = append(current.Nested, sub)
+				}
+			} else {
+				current.Nested = append(current.Nested, inner)
+			}
+		}
+		word.WriteString(string(r[i : end+1]))
+		inWord = true
+		return end, nil
+	}
+	// fdPrefix drops a word of digits just before a redirection, like the 2 in 2>
+	fdPrefix := func() {
+		if inWord && strings.Trim(word.String(), "0123456789") == "" {
+			word.Reset()
+			inWord = false
+		} else {
+			flushWord()
+		}
+	}
+
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		next := rune(0)
+		if i+1 < len(r) {
+			next = r[i+1]
+		}
+		switch {
+		case c == '\\':
+			if next == '\n' {
+				i++ // line continuation
+				continue
+			}
+			if next != 0 {
+				word.WriteRune(next)
+				i++
+			}
+			inWord = true
+		case c == '\'':
+			inWord = true
+			for i++; i < len(r) && r[i] != '\''; i++ {
+				word.WriteRune(r[i])
+			}
+			if i >= len(r) {
+				return nil, errUnterminated
+			}
+		case c == '"':
+			inWord = true
+			i++
+			for ; i < len(r) && r[i] != '"'; i++ {
+				switch {
+				case r[i] == '\\' && i+1 < len(r) && strings.ContainsRune("\\\"$`\n", r[i+1]):
+					i++
+					if r[i] != '\n' {
+						word.WriteRune(r[i])
+					}
+				case r[i] == '`' || (r[i] == '$' && i+1 < len(r) && r[i+1] == '('):
+					end, err := substitution(i)
+					if err != nil {
+						return nil, err
+					}
+					i = end
+				default:
+					word.WriteRune(r[i])
+				}
+			}
+			if i >= len(r) {
+				return nil, errUnterminated
+			}
+		case c == '`' || (c == '$' && next == '('):
+			end, err := substitution(i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '#' && !inWord:
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+			endCommand()
+		case c == ' ' || c == '\t':
+			flushWord()
+		case c == '\n' || c == ';' || c == '(' || c == ')':
+			endCommand()
+		case c == '|':
+			if next == '|' || next == '&' {
+				i++
+			}
+			endCommand()
+		case c == '&' && next == '>':
+			flushWord()
+			i++
+			if i+1 < len(r) && r[i+1] == '>' {
+				i++
+			}
+			target = 1
+		case c == '&':
+			if next == '&' {
+				i++
+			}
+			endCommand()
+		case c == '>' || c == '<':
+			fdPrefix()
+			if next == '(' {
+				// Process substitution runs a command too
+				end := closingParen(r, i+1)
+				if end < 0 {
+					return nil, errUnterminated
+				}
+				current.Nested = append(current.Nested, string(r[i+2:end]))
+				i = end
+				continue
+			}
+			if next == c || (c == '>' && next == '|') {
+				i++
+			}
+			if i+1 < len(r) && r[i+1] == '<' {
+				i++ // here-string
+			}
+			if i+1 < len(r) && r[i+1] == '&' {
+				// Duplicating a descriptor, as in 2>&1, names no file
+				i++
+				for i+1 < len(r) && (r[i+1] == '-' || (r[i+1] >= '0' && r[i+1] <= '9')) {
+					i++
+				}
+				continue
+			}
+			if c == '>' {
+				target = 1
+			} else {
+				target = 2
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// closingParen returns the index of the ) matching the ( at r[open], skipping
+// quoted text, or -1 when it is missing
+func closingParen(r []rune, open int) int {
+	depth := 0
+	for i := open; i < len(r); i++ {
+		switch r[i] {
+		case '\\':
+			i++
+		case '\'':
+			for i++; i < len(r) && r[i] != '\''; i++ {
+			}
+		case '"':
+			for i++; i < len(r) && r[i] != '"'; i++ {
+				if r[i] == '\\' {
+					i++
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// nestedSubstitutions finds the $(...) and `...` commands inside text
+func nestedSubstitutions(text string) []string {
+	commands, err := parseShell("true " + text)
+	if err != nil {
+		return []string{text}
+	}
+	var nested []string
+	for _, command := range commands {
+		nested = append(nested, command.Nested...)
+	}
+	return nested
+}
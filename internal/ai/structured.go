@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ResponseFormatJSON asks providers to constrain their output to valid JSON
+const ResponseFormatJSON = "json"
+
+// formatKey carries a response format for one request, overriding the provider's
+type formatKey struct{}
+
+// withResponseFormat asks for a format, such as ResponseFormatJSON, for the
+// requests made with ctx, for providers that support it
+func withResponseFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, formatKey{}, format)
+}
+
+// responseFormat returns the format requested through ctx, or fallback
+func responseFormat(ctx context.Context, fallback string) string {
+	if format, ok := ctx.Value(formatKey{}).(string); ok {
+		return format
+	}
+	return fallback
+}
+
+// maxStructuredAttempts bounds how often GenerateJSON re-prompts after invalid output
+const maxStructuredAttempts = 3
+
+// GenerateJSON asks the provider for a JSON reply and re-prompts with the validation
+// error until the output parses and, when schema is non-nil, matches it
+func GenerateJSON(ctx context.Context, provider Provider, prompt string, schema map[string]interface{}) (string, error) {
+	request := prompt + "\n\nRespond with only valid JSON and no other text."
+	if schema != nil {
+		schemaJSON, _ := json.Marshal(schema)
+		request += "\nThe JSON must match this JSON Schema: " + string(schemaJSON)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxStructuredAttempts; attempt++ {
+		response, err := provider.GenerateResponse(ctx, request)
+		if err != nil {
+			return "", err
+		}
+
+		// Reasoning models put their thinking before the JSON
+		answer, _ := SplitThinking(response)
+		cleaned := stripCodeFence(answer)
+		if lastErr = ValidateJSONResponse(cleaned, schema); lastErr == nil {
+			return cleaned, nil
+		}
+
+		request = fmt.Sprintf("%s\n\nYour previous reply was rejected: %v. Reply again with only the corrected JSON.", request, lastErr)
+	}
+	return "", fmt.Errorf("no valid JSON after %d attempts: %w", maxStructuredAttempts, lastErr)
+}
+
+// ValidateJSONResponse checks that response is valid JSON and, when schema is
+// non-nil, that it satisfies the schema
+func ValidateJSONResponse(response string, schema map[string]interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(response), &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if schema == nil {
+		return nil
+	}
+	return validateSchema(value, schema, "$")
+}
+
+// validateSchema implements the commonly used subset of JSON Schema:
+// type, enum, required, properties, items, minItems/maxItems and minLength/maxLength
+func validateSchema(value interface{}, schema map[string]interface{}, path string) error {
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v", path, enum)
+		}
+	}
+
+	if schemaType, ok := schema["type"].(string); ok && !matchesType(value, schemaType) {
+		return fmt.Errorf("%s must be of type %s", path, schemaType)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if key, isString := name.(string); isString {
+					if _, exists := v[key]; !exists {
+						return fmt.Errorf("%s is missing required property %q", path, key)
+					}
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			for key, propSchema := range properties {
+				child, exists := v[key]
+				sub, isSchema := propSchema.(map[string]interface{})
+				if !exists || !isSchema {
+					continue
+				}
+				if err := validateSchema(child, sub, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+			return fmt.Errorf("%s must have at least %v items", path, min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+			return fmt.Errorf("%s must have at most %v items", path, max)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if min, ok := schema["minLength"].(float64); ok && float64(len([]rune(v))) < min {
+			return fmt.Errorf("%s must be at least %v characters", path, min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(len([]rune(v))) > max {
+			return fmt.Errorf("%s must be at most %v characters", path, max)
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether a decoded JSON value has the given JSON Schema type
+func matchesType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true // unknown types are not enforced
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+// stripCodeFence removes a surrounding ``` or ```json fence that models often add
+func stripCodeFence(response string) string {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return trimmed
+	}
+	trimmed = strings.TrimSuffix(trimmed[3:], "```")
+	if newline := strings.Index(trimmed, "\n"); newline != -1 && !strings.ContainsAny(trimmed[:newline], "{[") {
+		trimmed = trimmed[newline+1:] // drop the language tag
+	}
+	return strings.TrimSpace(trimmed)
+}
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// scriptedProvider returns its responses in order and records the prompts it saw
+type scriptedProvider struct {
+	responses []string
+	prompts   []string
+}
+
+func (p *scriptedProvider) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	response := p.responses[0]
+	if len(p.responses) > 1 {
+		p.responses = p.responses[1:]
+	}
+	return response, nil
+}
+
+func (p *scriptedProvider) GenerateResponseWithTools(ctx context.Context, prompt string) (string, []ToolResult, error) {
+	response, err := p.GenerateResponse(ctx, prompt)
+	return response, nil, err
+}
+
+func (p *scriptedProvider) GenerateStreamingResponse(ctx context.Context, prompt string, callback func(chunk string)) (string, error) {
+	return p.GenerateResponse(ctx, prompt)
+}
+
+func (p *scriptedProvider) GetName() string         { return "Scripted" }
+func (p *scriptedProvider) SupportsTools() bool     { return false }
+func (p *scriptedProvider) SupportsStreaming() bool { return false }
+func (p *scriptedProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrNotSupported
+}
+
+func mustSchema(t *testing.T, raw string) map[string]interface{} {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		t.Fatalf("Bad test schema: %v", err)
+	}
+	return schema
+}
+
+func TestValidateJSONResponse(t *testing.T) {
+	schema := mustSchema(t, `{
+		"type": "object",
+		"required": ["name", "tags"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer"},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		}
+	}`)
+	
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{"valid", `{"name": "Ada", "age": 36, "role": "admin", "tags": ["math"]}`, ""},
+		{"not json", `Sure! Here you go`, "invalid JSON"},
+		{"missing required", `{"name": "Ada"}`, `"tags"`},
+		{"wrong type", `{"name": "Ada", "age": 36.5, "tags": []}`, "$.age must be of type integer"},
+		{"enum", `{"name": "Ada", "role": "root", "tags": []}`, "$.role must be one of"},
+		{"item type", `{"name": "Ada", "tags": [1]}`, "$.tags[0]"},
+		{"too many items", `{"name": "Ada", "tags": ["a", "b", "c"]}`, "at most 2 items"},
+		{"empty string", `{"name": "", "tags": []}`, "at least 1 characters"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSONResponse(tt.response, schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected valid response, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := map[string]string{
+		"```json\n{\"a\": 1}\n```": `{"a": 1}`,
+		"```\n[1, 2]\n```":         `[1, 2]`,
+		"```{\"a\": 1}```":         `{"a": 1}`,
+		`  {"a": 1}  `:             `{"a": 1}`,
+	}
+	for input, expected := range tests {
+		if got := stripCodeFence(input); got != expected {
+			t.Errorf("stripCodeFence(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestGenerateJSONRepromptsOnInvalidOutput(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		"Here is the data: {name: Ada}",
+		"```json\n{\"name\": \"Ada\"}\n```",
+	}}
+	schema := mustSchema(t, `{"type": "object", "required": ["name"]}`)
+	
+	response, err := GenerateJSON(context.Background(), provider, "Who wrote the first program?", schema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response != `{"name": "Ada"}` {
+		t.Errorf("Expected cleaned JSON, got %q", response)
+	}
+	if len(provider.prompts) != 2 || !strings.Contains(provider.prompts[1], "rejected") {
+		t.Errorf("Expected a second prompt explaining the rejection, got %v", provider.prompts)
+	}
+	
+	provider = &scriptedProvider{responses: []string{"never json"}}
+	if _, err := GenerateJSON(context.Background(), provider, "hi", nil); err == nil {
+		t.Error("Expected an error after repeated invalid output")
+	}
+	if len(provider.prompts) != maxStructuredAttempts {
+		t.Errorf("Expected %d attempts, got %d", maxStructuredAttempts, len(provider.prompts))
+	}
+}
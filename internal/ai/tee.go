@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"io"
+	"sync"
+)
+
+// Tee fans streamed chunks out to several writers, like io.MultiWriter. A
+// writer that fails is dropped while the others keep receiving chunks, and
+// the tee can be closed while a stream is still running, as when it times out.
+type Tee struct {
+	mu      sync.Mutex
+	writers []io.Writer
+	err     error
+	closed  bool
+	written int // bytes of chunks passed on
+}
+
+// NewTee returns a tee writing to the given writers
+func NewTee(writers ...io.Writer) *Tee {
+	return &Tee{writers: writers}
+}
+
+// Chunk writes a chunk to every writer; it is a streaming callback
+func (t *Tee) Chunk(chunk string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	kept := t.writers[:0]
+	for _, w := range t.writers {
+		if _, err := io.WriteString(w, chunk); err != nil {
+			if t.err == nil {
+				t.err = err
+			}
+			continue
+		}
+		kept = append(kept, w)
+	}
+	t.writers = kept
+	t.written += len(chunk)
+}
+
+// Written returns how many bytes of chunks the tee has passed on
+func (t *Tee) Written() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.written
+}
+
+// Close flushes the writers that buffer, such as a bufio.Writer, and drops
+// chunks that arrive afterwards. The writers themselves stay open. It returns
+// the first error any writer had.
+func (t *Tee) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return t.err
+	}
+	t.closed = true
+	for _, w := range t.writers {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil && t.err == nil {
+				t.err = err
+			}
+		}
+	}
+	return t.err
+}
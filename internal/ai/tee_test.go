@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestTeeWritesEveryChunkToEachWriter(t *testing.T) {
+	var terminal, file strings.Builder
+	buffered := bufio.NewWriter(&file)
+	tee := NewTee(&terminal, failingWriter{}, buffered)
+
+	tee.Chunk("Hello, ")
+	tee.Chunk("world")
+	if file.Len() != 0 {
+		t.Fatalf("Expected buffered output before closing, got %q", file.String())
+	}
+	if err := tee.Close(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the failed writer's error, got %v", err)
+	}
+	tee.Chunk("!") // after a timeout, the stream may still be running
+	if tee.Written() != len("Hello, world") {
+		t.Errorf("Expected chunks after closing not to count, got %d bytes", tee.Written())
+	}
+
+	if terminal.String() != "Hello, world" {
+		t.Errorf("Terminal got %q", terminal.String())
+	}
+	if file.String() != "Hello, world" {
+		t.Errorf("Expected the partial output to be flushed on close, got %q", file.String())
+	}
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"strings"
+)
+
+// Tags reasoning models such as deepseek-r1 put around their chain of thought
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// SplitThinking separates <think>...</think> blocks from a response and returns
+// the answer and the reasoning. An unclosed block runs to the end of the response,
+// and a closing tag with no opening tag (some models omit it) ends a leading block.
+func SplitThinking(response string) (answer, thinking string) {
+	if open, end := strings.Index(response, thinkOpenTag), strings.Index(response, thinkCloseTag); end >= 0 && (open < 0 || end < open) {
+		response = thinkOpenTag + response
+	}
+
+	var f ThinkingFilter
+	answer = f.Write(response) + f.Flush()
+	return strings.TrimSpace(answer), strings.TrimSpace(f.Thinking())
+}
+
+// ThinkingFilter removes <think> blocks from a streamed response. Text that could
+// be the start of a tag split across chunks is held back until the next chunk.
+type ThinkingFilter struct {
+	inside   bool
+	pending  string
+	thinking strings.Builder
+}
+
+// Write takes the next chunk and returns the part of it that is safe to show
+func (f *ThinkingFilter) Write(chunk string) string {
+	text := f.pending + chunk
+	f.pending = ""
+
+	var out strings.Builder
+	for text != "" {
+		tag := thinkOpenTag
+		if f.inside {
+			tag = thinkCloseTag
+		}
+
+		if i := strings.Index(text, tag); i >= 0 {
+			f.emit(&out, text[:i])
+			text = text[i+len(tag):]
+			f.inside = !f.inside
+			continue
+		}
+
+		// Hold back a suffix that may turn into the tag once more text arrives
+		keep := partialSuffix(text, tag)
+		f.emit(&out, text[:len(text)-keep])
+		f.pending = text[len(text)-keep:]
+		break
+	}
+	return out.String()
+}
+
+// Flush returns whatever was held back once the stream has ended
+func (f *ThinkingFilter) Flush() string {
+	var out strings.Builder
+	f.emit(&out, f.pending)
+	f.pending = ""
+	return out.String()
+}
+
+// Thinking returns the reasoning removed so far
+func (f *ThinkingFilter) Thinking() string {
+	return f.thinking.String()
+}
+
+func (f *ThinkingFilter) emit(out *strings.Builder, text string) {
+	if f.inside {
+		f.thinking.WriteString(text)
+	} else {
+		out.WriteString(text)
+	}
+}
+
+// partialSuffix returns the length of the longest suffix of text that is a
+// proper prefix of tag
+func partialSuffix(text, tag string) int {
+	for n := len(tag) - 1; n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
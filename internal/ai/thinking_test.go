@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"testing"
+)
+
+func TestSplitThinking(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		answer   string
+		thinking string
+	}{
+		{"no tags", "Hello there", "Hello there", ""},
+		{"leading block", "<think>\nThe user greets me.\n</think>\n\nHello!", "Hello!", "The user greets me."},
+		{"unclosed block", "<think>still reasoning when the output stopped", "", "still reasoning when the output stopped"},
+		{"missing open tag", "reasoning first</think>The answer", "The answer", "reasoning first"},
+		{"two blocks", "<think>a</think>One<think>b</think> two", "One two", "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, thinking := SplitThinking(tt.response)
+			if answer != tt.answer || thinking != tt.thinking {
+				t.Errorf("SplitThinking(%q) = %q, %q; want %q, %q", tt.response, answer, thinking, tt.answer, tt.thinking)
+			}
+		})
+	}
+}
+
+func TestThinkingFilterAcrossChunks(t *testing.T) {
+	chunks := []string{"<th", "ink>plan the", " reply</th", "ink>Hi", " <", "b>there</b>"}
+
+	var f ThinkingFilter
+	var shown string
+	for _, chunk := range chunks {
+		shown += f.Write(chunk)
+	}
+	shown += f.Flush()
+
+	if shown != "Hi <b>there</b>" {
+		t.Errorf("Expected the think block to be removed, got %q", shown)
+	}
+	if f.Thinking() != "plan the reply" {
+		t.Errorf("Expected the reasoning to be kept, got %q", f.Thinking())
+	}
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default tool execution limits; a runaway response stops here
+const (
+	DefaultMaxToolCallsPerTurn   = 10
+	DefaultMaxToolCallsPerMinute = 30
+)
+
+// ToolLimitConfig is the part of the configuration that limits tool executions.
+// 0 means the default and a negative value means no limit.
+type ToolLimitConfig interface {
+	GetMaxToolCallsPerTurn() int
+	GetMaxToolCallsPerMinute() int
+}
+
+// ToolUsage is how many tool executions were allowed in the current turn and
+// the last minute, against their limits (0 = unlimited)
+type ToolUsage struct {
+	Turn, TurnLimit     int
+	Minute, MinuteLimit int
+}
+
+var toolLimits = struct {
+	sync.Mutex
+	perTurn, perMinute int
+	active             bool // a response is being handled, so calls come from the AI
+	turn               int
+	recent             []time.Time
+}{perTurn: DefaultMaxToolCallsPerTurn, perMinute: DefaultMaxToolCallsPerMinute}
+
+// ConfigureToolLimits sets how many tool executions a turn and a minute allow
+func ConfigureToolLimits(cfg ToolLimitConfig) {
+	toolLimits.Lock()
+	defer toolLimits.Unlock()
+	toolLimits.perTurn = limitOrDefault(cfg.GetMaxToolCallsPerTurn(), DefaultMaxToolCallsPerTurn)
+	toolLimits.perMinute = limitOrDefault(cfg.GetMaxToolCallsPerMinute(), DefaultMaxToolCallsPerMinute)
+}
+
+func limitOrDefault(limit, def int) int {
+	if limit == 0 {
+		return def
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// beginToolTurn starts counting the tool executions of a new response to
+// prompt, which the audit log records with them. The limits apply until the
+// returned function ends the turn.
+func beginToolTurn(prompt string) func() {
+	setAuditPrompt(prompt)
+	toolLimits.Lock()
+	defer toolLimits.Unlock()
+	toolLimits.turn = 0
+	toolLimits.active = true
+	return func() {
+		setAuditPrompt("")
+		toolLimits.Lock()
+		defer toolLimits.Unlock()
+		toolLimits.active = false
+	}
+}
+
+// allowToolCall counts a tool execution the AI asked for, or explains why it
+// is refused. Calls outside a response are not limited.
+func allowToolCall(now time.Time) error {
+	toolLimits.Lock()
+	defer toolLimits.Unlock()
+	if !toolLimits.active {
+		return nil
+	}
+	toolLimits.recent = pruneCalls(toolLimits.recent, now)
+
+	if toolLimits.perTurn > 0 && toolLimits.turn >= toolLimits.perTurn {
+		return fmt.Errorf("tool call limit reached: at most %d per response (max_tool_calls_per_turn)", toolLimits.perTurn)
+	}
+	if toolLimits.perMinute > 0 && len(toolLimits.recent) >= toolLimits.perMinute {
+		return fmt.Errorf("tool call limit reached: at most %d per minute (max_tool_calls_per_minute)", toolLimits.perMinute)
+	}
+	toolLimits.turn++
+	toolLimits.recent = append(toolLimits.recent, now)
+	return nil
+}
+
+// pruneCalls drops calls more than a minute old
+func pruneCalls(calls []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(calls) && now.Sub(calls[i]) >= time.Minute {
+		i++
+	}
+	return calls[i:]
+}
+
+// CurrentToolUsage reports the tool executions counted against the limits
+func CurrentToolUsage() ToolUsage {
+	toolLimits.Lock()
+	defer toolLimits.Unlock()
+	toolLimits.recent = pruneCalls(toolLimits.recent, time.Now())
+	return ToolUsage{
+		Turn: toolLimits.turn, TurnLimit: toolLimits.perTurn,
+		Minute: len(toolLimits.recent), MinuteLimit: toolLimits.perMinute,
+	}
+}
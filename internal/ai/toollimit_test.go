@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type toolLimitConfig struct {
+	perTurn, perMinute int
+}
+
+func (c toolLimitConfig) GetMaxToolCallsPerTurn() int   { return c.perTurn }
+func (c toolLimitConfig) GetMaxToolCallsPerMinute() int { return c.perMinute }
+
+func TestToolCallLimitPerTurn(t *testing.T) {
+	ConfigureToolLimits(toolLimitConfig{perTurn: 3, perMinute: -1})
+	defer ConfigureToolLimits(toolLimitConfig{})
+
+	now := time.Now()
+	end := beginToolTurn("")
+	for i := 0; i < 3; i++ {
+		if err := allowToolCall(now); err != nil {
+			t.Fatalf("Call %d refused: %v", i+1, err)
+		}
+	}
+	err := allowToolCall(now)
+	if err == nil || !strings.Contains(err.Error(), "per response") {
+		t.Errorf("Expected the fourth call to be refused, got %v", err)
+	}
+	usage := CurrentToolUsage()
+	if usage.Turn != 3 || usage.TurnLimit != 3 || usage.MinuteLimit != 0 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	end()
+
+	// Outside a response nothing is limited
+	if err := allowToolCall(now); err != nil {
+		t.Errorf("Expected no limit outside a response, got %v", err)
+	}
+
+	// A new response starts counting again
+	defer beginToolTurn("")()
+	if err := allowToolCall(now); err != nil {
+		t.Errorf("Expected a new response to reset the count, got %v", err)
+	}
+}
+
+func TestToolCallLimitPerMinute(t *testing.T) {
+	ConfigureToolLimits(toolLimitConfig{perTurn: -1, perMinute: 2})
+	defer ConfigureToolLimits(toolLimitConfig{})
+	toolLimits.recent = nil
+
+	start := time.Now()
+	defer beginToolTurn("")()
+	for i := 0; i < 2; i++ {
+		if err := allowToolCall(start); err != nil {
+			t.Fatalf("Call %d refused: %v", i+1, err)
+		}
+	}
+	err := allowToolCall(start.Add(30 * time.Second))
+	if err == nil || !strings.Contains(err.Error(), "per minute") {
+		t.Errorf("Expected the third call within a minute to be refused, got %v", err)
+	}
+	if err := allowToolCall(start.Add(time.Minute)); err != nil {
+		t.Errorf("Expected calls to be allowed once the minute passed, got %v", err)
+	}
+}
+
+func TestConfigureToolLimitsDefaults(t *testing.T) {
+	defer ConfigureToolLimits(toolLimitConfig{})
+
+	ConfigureToolLimits(toolLimitConfig{})
+	usage := CurrentToolUsage()
+	if usage.TurnLimit != DefaultMaxToolCallsPerTurn || usage.MinuteLimit != DefaultMaxToolCallsPerMinute {
+		t.Errorf("Expected the default limits, got %+v", usage)
+	}
+
+	ConfigureToolLimits(toolLimitConfig{perTurn: -1, perMinute: -1})
+	usage = CurrentToolUsage()
+	if usage.TurnLimit != 0 || usage.MinuteLimit != 0 {
+		t.Errorf("Expected negative limits to mean unlimited, got %+v", usage)
+	}
+}
+
+func TestExecuteToolRefusedOverLimit(t *testing.T) {
+	ConfigureToolLimits(toolLimitConfig{perTurn: 1, perMinute: -1})
+	defer ConfigureToolLimits(toolLimitConfig{})
+
+	defer beginToolTurn("")()
+	args := map[string]interface{}{"path": t.TempDir()}
+	ExecuteTool("list_files", args)
+	result := ExecuteTool("list_files", args)
+	if result.Success || !strings.Contains(result.Content, "tool call limit reached") {
+		t.Errorf("Expected the second call to be refused, got %+v", result)
+	}
+}
@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRememberToolResults(t *testing.T) {
+	provider := NewOllamaProvider("llama3.2", 0.7, 0, "")
+	RememberToolResults(provider, []ToolResult{{Name: "execute_command", Content: "main.go:3: undefined: foo", Success: true}})
+	if len(provider.History) != 1 || provider.History[0].Role != "system" {
+		t.Fatalf("Expected the output as a system message, got %v", provider.History)
+	}
+	if content := provider.History[0].Content; !strings.Contains(content, "undefined: foo") || !strings.Contains(content, untrustedEnd) {
+		t.Errorf("Expected the guarded output in the history, got %q", content)
+	}
+
+	// A later turn's output replaces it rather than adding to it
+	RememberTurn(provider, "why?", "foo is not declared")
+	RememberToolResults(provider, []ToolResult{{Name: "read_file", Content: "package main", Success: true}})
+	if len(provider.History) != 3 || provider.History[2].Role != "system" || !strings.Contains(provider.History[2].Content, "package main") {
+		t.Fatalf("Expected only the newest tool output after the turn, got %v", provider.History)
+	}
+
+	defer func() { RememberToolOutput = true }()
+	RememberToolOutput = false
+	RememberToolResults(provider, []ToolResult{{Name: "list_files", Content: "a.txt"}})
+	if len(provider.History) != 3 {
+		t.Errorf("Expected nothing remembered when turned off, got %v", provider.History)
+	}
+}
+
+func TestToolMemoryBudget(t *testing.T) {
+	results := []ToolResult{
+		{Name: "read_file", Content: strings.Repeat("word ", 10)},
+		{Name: "list_files", Content: "a.txt b.txt"},
+	}
+	memory := toolMemory(results, 4)
+	if !strings.Contains(memory, "word word word word\n[... truncated to fit the budget]") {
+		t.Errorf("Expected the first output cut to the budget, got %q", memory)
+	}
+	if strings.Contains(memory, "a.txt") || !strings.Contains(memory, "[... omitted to fit the budget]") {
+		t.Errorf("Expected the output past the budget to be left out, got %q", memory)
+	}
+}
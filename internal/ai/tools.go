@@ -1,20 +1,101 @@
 package ai
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"tala/internal/fileops"
+	"tala/internal/glyphs"
 	"time"
 )
 
-// Tool represents a function that the AI can call
+// DefaultCommandTimeout is used when a command does not request a timeout
+const DefaultCommandTimeout = 30 * time.Second
+
+// MaxCommandTimeout is the ceiling applied to shell command timeouts.
+// It defaults to DefaultCommandTimeout and can be raised via ConfigureTools.
+var MaxCommandTimeout = DefaultCommandTimeout
+
+// ConfirmCommandTimeout makes a command that times out ask through KeepWaiting
+// before it is killed
+var ConfirmCommandTimeout bool
+
+// KeepWaiting asks the user whether to give a command that is still running
+// after elapsed another timeout period. When it is nil, as in headless mode,
+// timed-out commands are always killed.
+var KeepWaiting func(command string, elapsed time.Duration) bool
+
+// FileProgress, when set, hears how far the tools that walk whole trees, such
+// as directory_size, project_tree and find_duplicates, have got
+var FileProgress fileops.ProgressFunc
+
+// ToolConfig is implemented by configuration types that carry tool settings
+type ToolConfig interface {
+	GetMaxCommandTimeout() time.Duration
+	GetConfirmCommandTimeout() bool
+	GetLineEndings() string
+	GetDefaultFileExtension() string
+	GetUseTrash() bool
+	GetWritableDirs() []string
+	GetReadonlyDirs() []string
+	GetConfirmOutsideCwd() bool
+	GetGuardToolOutput() bool
+	GetMaxToolOutput() int
+	GetRememberToolOutput() bool
+	GetToolMemoryBudget() int
+	GetAuditLog() bool
+	ToolLimitConfig
+	CommandPolicyConfig
+	IntentPromptConfig
+}
+
+// ConfigureTools applies tool execution settings from the given config
+func ConfigureTools(cfg ToolConfig) {
+	MaxCommandTimeout = cfg.GetMaxCommandTimeout()
+	ConfirmCommandTimeout = cfg.GetConfirmCommandTimeout()
+	fileops.LineEndings = cfg.GetLineEndings()
+	DefaultFileExtension = cfg.GetDefaultFileExtension()
+	fileops.UseTrash = cfg.GetUseTrash()
+	fileops.SetWriteAccess(cfg.GetWritableDirs(), cfg.GetReadonlyDirs())
+	ConfirmOutsideCwd = cfg.GetConfirmOutsideCwd()
+	GuardToolOutput = cfg.GetGuardToolOutput()
+	MaxToolOutput = limitOrDefault(cfg.GetMaxToolOutput(), DefaultMaxToolOutput)
+	RememberToolOutput = cfg.GetRememberToolOutput()
+	ToolMemoryBudget = cfg.GetToolMemoryBudget()
+	if ToolMemoryBudget <= 0 {
+		ToolMemoryBudget = DefaultToolMemoryBudget
+	}
+	ConfigureToolLimits(cfg)
+	AuditLog = cfg.GetAuditLog()
+	ConfigureCommandPolicy(cfg)
+	if err := ConfigureIntentPrompt(cfg); err != nil {
+		slog.Warn("using the built-in intent prompt", "error", err)
+	}
+}
+
+// ClarifyFunc asks the user for a missing tool parameter. It returns false
+// when the user declined to answer.
+type ClarifyFunc func(tool, param, description string) (string, bool)
+
+// Clarify is consulted by ExecuteTool when required parameters are missing.
+// It is nil in headless mode, where missing parameters produce an error instead.
+var Clarify ClarifyFunc
+
+// Tool represents a function that the AI can call. Execute reports through
+// the operation's Success whether the call did what was asked, which decides
+// whether it is recorded for undo, audit and metrics.
 type Tool struct {
-	Name        string                                   `json:"name"`
-	Description string                                   `json:"description"`
-	Parameters  map[string]interface{}                   `json:"parameters"`
-	Execute     func(args map[string]interface{}) string `json:"-"`
+	Name        string                                                   `json:"name"`
+	Description string                                                   `json:"description"`
+	Parameters  map[string]interface{}                                   `json:"parameters"`
+	Execute     func(args map[string]interface{}) *fileops.FileOperation `json:"-"`
 }
 
 // ToolCall represents a request from AI to execute a tool
@@ -25,9 +106,10 @@ type ToolCall struct {
 
 // ToolResult represents the result of executing a tool
 type ToolResult struct {
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	Success bool   `json:"success"`
+	Name          string   `json:"name"`
+	Content       string   `json:"content"`
+	Success       bool     `json:"success"`
+	MissingParams []string `json:"missing_params,omitempty"`
 }
 
 // ToolChain represents a sequence of tools to execute
@@ -58,13 +140,13 @@ func GetAvailableTools() []Tool {
 					},
 				},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				path := ""
 				if p, ok := args["path"].(string); ok {
 					path = p
 				}
 				result := fileops.ListDirectory(path)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -80,13 +162,171 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"filename"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				filename, ok := args["filename"].(string)
 				if !ok {
-					return "Error: filename is required"
+					return toolFailed("Error: filename is required")
 				}
 				result := fileops.ReadFile(filename)
-				return result.Message
+				return result
+			},
+		},
+		{
+			Name:        "read_file_with_context",
+			Description: "Read a file along with a short directory tree of its folder, for questions about code in a project",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the file to read",
+					},
+					"depth": map[string]interface{}{
+						"type":        "number",
+						"description": fmt.Sprintf("Optional tree depth (default %d, max %d)", fileops.DefaultContextDepth, fileops.MaxContextDepth),
+					},
+				},
+				"required": []string{"filename"},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				filename, ok := args["filename"].(string)
+				if !ok {
+					return toolFailed("Error: filename is required")
+				}
+				depth := 0
+				if d, ok := args["depth"].(float64); ok {
+					depth = int(d)
+				}
+				result := fileops.ReadFileWithContext(filename, depth)
+				return result
+			},
+		},
+		{
+			Name:        "project_tree",
+			Description: "Show the project's directory structure like `tree`, respecting .gitignore and skipping dependency folders",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional project root. If not provided, uses the current directory",
+					},
+					"depth": map[string]interface{}{
+						"type":        "number",
+						"description": fmt.Sprintf("Optional depth (default %d, max %d)", fileops.DefaultProjectTreeDepth, fileops.MaxProjectTreeDepth),
+					},
+				},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path := "."
+				if p, ok := args["path"].(string); ok && p != "" {
+					path = p
+				}
+				depth := 0
+				if d, ok := args["depth"].(float64); ok {
+					depth = int(d)
+				}
+				tree, err := fileops.ProjectTree(path, depth, FileProgress)
+				if err != nil {
+					return toolFailed(fmt.Sprintf("Error: %v", err))
+				}
+				return toolOutput(tree)
+			},
+		},
+		{
+			Name:        "disk_usage",
+			Description: "Show the total, used and free space of the disk holding a path",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional path on the disk. If not provided, uses the current directory",
+					},
+				},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path := "."
+				if p, ok := args["path"].(string); ok && p != "" {
+					path = p
+				}
+				space, err := fileops.DiskUsage(path)
+				if err != nil {
+					return toolFailed(fmt.Sprintf("Error: %v", err))
+				}
+				percent := 0.0
+				if space.Total > 0 {
+					percent = float64(space.Used) / float64(space.Total) * 100
+				}
+				return toolOutput(fmt.Sprintf("Disk holding '%s':\nTotal: %s\nUsed: %s (%.0f%%)\nFree: %s",
+					path, fileops.FormatSize(space.Total), fileops.FormatSize(space.Used), percent, fileops.FormatSize(space.Free)))
+			},
+		},
+		{
+			Name:        "directory_size",
+			Description: "Show how much space a directory's files use, like du -s",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional directory. If not provided, uses the current directory",
+					},
+				},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path := "."
+				if p, ok := args["path"].(string); ok && p != "" {
+					path = p
+				}
+				size, files, skipped, err := fileops.DirectorySize(path, FileProgress)
+				if err != nil {
+					return toolFailed(fmt.Sprintf("Error: %v", err))
+				}
+				result := fmt.Sprintf("'%s' uses %s in %d files", path, fileops.FormatSize(uint64(size)), files)
+				if skipped > 0 {
+					result += fmt.Sprintf(" (%d entries could not be read)", skipped)
+				}
+				return toolOutput(result)
+			},
+		},
+		{
+			Name:        "find_duplicates",
+			Description: "Find files with identical content under a directory, to help reclaim disk space",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional directory to search. If not provided, uses the current directory",
+					},
+					"max_files": map[string]interface{}{
+						"type":        "number",
+						"description": fmt.Sprintf("Optional number of files to scan at most (default %d)", fileops.DefaultDuplicateScanFiles),
+					},
+					"max_file_size_mb": map[string]interface{}{
+						"type":        "number",
+						"description": "Optional size in MB above which files are skipped (default: no limit)",
+					},
+				},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path := "."
+				if p, ok := args["path"].(string); ok && p != "" {
+					path = p
+				}
+				opts := fileops.DuplicateOptions{Progress: FileProgress}
+				if n, ok := args["max_files"].(float64); ok {
+					opts.MaxFiles = int(n)
+				}
+				if mb, ok := args["max_file_size_mb"].(float64); ok && mb > 0 {
+					opts.MaxFileSize = int64(mb * (1 << 20))
+				}
+				report, err := fileops.FindDuplicates(path, opts)
+				if err != nil {
+					return toolFailed(fmt.Sprintf("Error: %v", err))
+				}
+				return toolOutput(report.String())
 			},
 		},
 		{
@@ -106,14 +346,65 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"filename", "content"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				filename, ok1 := args["filename"].(string)
 				content, ok2 := args["content"].(string)
 				if !ok1 || !ok2 {
-					return "Error: filename and content are required"
+					return toolFailed("Error: filename and content are required")
 				}
 				result := fileops.CreateFile(filename, content)
-				return result.Message
+				return result
+			},
+		},
+		{
+			Name:        "touch",
+			Description: "Create an empty file, or update the modification time of an existing one, like the Unix touch",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the file to touch",
+					},
+				},
+				"required": []string{"filename"},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				filename, ok := args["filename"].(string)
+				if !ok {
+					return toolFailed("Error: filename is required")
+				}
+				result := fileops.Touch(filename)
+				return result
+			},
+		},
+		{
+			Name:        "set_permissions",
+			Description: "Set the Unix permissions of a file or directory, e.g. make a generated script executable with 755. Does nothing on Windows",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "File or directory to change",
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"description": "Octal permissions such as \"755\" or \"644\"",
+					},
+				},
+				"required": []string{"path", "mode"},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path, ok1 := args["path"].(string)
+				mode, ok2 := args["mode"].(string)
+				if n, isNumber := args["mode"].(float64); isNumber {
+					mode, ok2 = fmt.Sprint(int(n)), true // 755 rather than "755"
+				}
+				if !ok1 || !ok2 {
+					return toolFailed("Error: path and mode are required")
+				}
+				return setPermissions(path, mode)
 			},
 		},
 		{
@@ -133,14 +424,37 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"filename", "content"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				filename, ok1 := args["filename"].(string)
 				content, ok2 := args["content"].(string)
 				if !ok1 || !ok2 {
-					return "Error: filename and content are required"
+					return toolFailed("Error: filename and content are required")
 				}
 				result := fileops.UpdateFile(filename, content)
-				return result.Message
+				return result
+			},
+		},
+		{
+			Name:        SaveResponseTool,
+			Description: "Save the code or text you generate in your reply to a file, e.g. \"write the generated code to server.go\"",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the file to save the reply to",
+					},
+				},
+				"required": []string{"filename"},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				filename, ok1 := args["filename"].(string)
+				content, ok2 := args["content"].(string)
+				if !ok1 || !ok2 {
+					return toolFailed("Error: filename is required and the reply must have content")
+				}
+				result := fileops.CreateFile(filename, content)
+				return result
 			},
 		},
 		{
@@ -156,13 +470,13 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"filename"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				filename, ok := args["filename"].(string)
 				if !ok {
-					return "Error: filename is required"
+					return toolFailed("Error: filename is required")
 				}
 				result := fileops.DeleteFile(filename)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -178,13 +492,13 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"dirname"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				dirname, ok := args["dirname"].(string)
 				if !ok {
-					return "Error: dirname is required"
+					return toolFailed("Error: dirname is required")
 				}
 				result := fileops.CreateDirectory(dirname)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -200,13 +514,13 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"dirname"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				dirname, ok := args["dirname"].(string)
 				if !ok {
-					return "Error: dirname is required"
+					return toolFailed("Error: dirname is required")
 				}
 				result := fileops.DeleteDirectory(dirname)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -226,14 +540,14 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"source", "destination"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				source, ok1 := args["source"].(string)
 				destination, ok2 := args["destination"].(string)
 				if !ok1 || !ok2 {
-					return "Error: source and destination are required"
+					return toolFailed("Error: source and destination are required")
 				}
 				result := fileops.CopyFile(source, destination)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -253,14 +567,50 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"source", "destination"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				source, ok1 := args["source"].(string)
 				destination, ok2 := args["destination"].(string)
 				if !ok1 || !ok2 {
-					return "Error: source and destination are required"
+					return toolFailed("Error: source and destination are required")
 				}
 				result := fileops.MoveFile(source, destination)
-				return result.Message
+				return result
+			},
+		},
+		{
+			Name:        "bulk_rename",
+			Description: "Rename every file in a directory whose name matches a pattern, e.g. replace spaces with underscores, add a prefix or lowercase names. The user sees the changes and confirms them first",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Directory whose files to rename, . for the current directory",
+					},
+					"pattern": map[string]interface{}{
+						"type":        "string",
+						"description": "Text to replace in file names, or a regular expression when regex is true",
+					},
+					"replacement": map[string]interface{}{
+						"type":        "string",
+						"description": "What to put instead; {lower} and {upper} are the matched text in lower or upper case, and with regex $1 is the first group",
+					},
+					"regex": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Optional: treat the pattern as a regular expression, e.g. ^ to add a prefix or .* with {lower} to lowercase names",
+					},
+				},
+				"required": []string{"path", "pattern", "replacement"},
+			},
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
+				path, ok1 := args["path"].(string)
+				pattern, ok2 := args["pattern"].(string)
+				replacement, ok3 := args["replacement"].(string)
+				if !ok1 || !ok2 || !ok3 {
+					return toolFailed("Error: path, pattern and replacement are required")
+				}
+				regex, _ := args["regex"].(bool)
+				return bulkRename(path, pattern, replacement, regex)
 			},
 		},
 		{
@@ -270,9 +620,9 @@ func GetAvailableTools() []Tool {
 				"type": "object",
 				"properties": map[string]interface{}{},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				result := fileops.GetWorkingDirectory()
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -288,13 +638,13 @@ func GetAvailableTools() []Tool {
 				},
 				"required": []string{"path"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				path, ok := args["path"].(string)
 				if !ok {
-					return "Error: path is required"
+					return toolFailed("Error: path is required")
 				}
 				result := fileops.ChangeDirectory(path)
-				return result.Message
+				return result
 			},
 		},
 		{
@@ -309,25 +659,41 @@ func GetAvailableTools() []Tool {
 					},
 					"timeout": map[string]interface{}{
 						"type":        "number",
-						"description": "Optional timeout in seconds (default: 30)",
+						"description": "Optional timeout in seconds (default: 30, capped by the configured maximum)",
+					},
+					"cwd": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional directory to run the command in instead of the current one",
 					},
 				},
 				"required": []string{"command"},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				command, ok := args["command"].(string)
 				if !ok {
-					return "Error: command is required"
+					return toolFailed("Error: command is required")
 				}
 				
-				// Get timeout (default 30 seconds)
-				timeout := 30.0
+				// Get timeout (0 means default, capped by MaxCommandTimeout)
+				timeout := 0.0
 				if t, ok := args["timeout"].(float64); ok && t > 0 {
 					timeout = t
 				}
 				
-				result := ExecuteShellCommand(command, time.Duration(timeout)*time.Second)
-				return result
+				// Report where the command ran, since change_directory moves it
+				dir, _ := args["cwd"].(string)
+				if dir == "" {
+					cwd, err := os.Getwd()
+					if err != nil {
+						return toolFailed(fmt.Sprintf("Error: cannot determine the current directory: %v", err))
+					}
+					dir = cwd
+				} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					return toolFailed(fmt.Sprintf("Error: cwd '%s' is not a directory", dir))
+				}
+				
+				result := ExecuteShellCommandIn(context.Background(), command, dir, time.Duration(timeout*float64(time.Second)))
+				return commandOutput(strings.TrimRight(result, "\n") + "\n(ran in " + dir + ")")
 			},
 		},
 		{
@@ -342,7 +708,7 @@ func GetAvailableTools() []Tool {
 					},
 				},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				filter := ""
 				if f, ok := args["filter"].(string); ok {
 					filter = f
@@ -374,7 +740,7 @@ func GetAvailableTools() []Tool {
 				
 				output, err := cmd.Output()
 				if err != nil {
-					return fmt.Sprintf("Error listing processes: %v", err)
+					return toolFailed(fmt.Sprintf("Error listing processes: %v", err))
 				}
 				
 				result := string(output)
@@ -390,7 +756,7 @@ func GetAvailableTools() []Tool {
 					result = strings.Join(filtered, "\n")
 				}
 				
-				return result
+				return toolOutput(result)
 			},
 		},
 		{
@@ -400,7 +766,7 @@ func GetAvailableTools() []Tool {
 				"type": "object",
 				"properties": map[string]interface{}{},
 			},
-			Execute: func(args map[string]interface{}) string {
+			Execute: func(args map[string]interface{}) *fileops.FileOperation {
 				info := fmt.Sprintf("OS: %s\nArchitecture: %s\nCPUs: %d",
 					runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
 				
@@ -425,7 +791,7 @@ func GetAvailableTools() []Tool {
 					}
 				}
 				
-				return info
+				return toolOutput(info)
 			},
 		},
 	}
@@ -437,11 +803,46 @@ func ExecuteTool(toolName string, args map[string]interface{}) ToolResult {
 	
 	for _, tool := range tools {
 		if tool.Name == toolName {
-			content := tool.Execute(args)
-			// Determine success based on whether the content indicates an error
-			contentStr := content
-			success := !strings.HasPrefix(contentStr, "Error") && 
-					  !strings.HasPrefix(contentStr, "Failed")
+			args, missing := resolveMissingParameters(tool, args)
+			if len(missing) > 0 {
+				return ToolResult{
+					Name:          toolName,
+					Content:       fmt.Sprintf("Error: %s is missing required parameters: %s", toolName, strings.Join(missing, ", ")),
+					Success:       false,
+					MissingParams: missing,
+				}
+			}
+
+			args, err := resolveToolPaths(toolName, args)
+			if err != nil {
+				slog.Warn("tool call refused", "tool", toolName, "error", err)
+				return ToolResult{Name: toolName, Content: "Error: " + err.Error(), Success: false}
+			}
+
+			if err := allowToolCall(time.Now()); err != nil {
+				slog.Warn("tool call refused", "tool", toolName, "error", err)
+				return ToolResult{Name: toolName, Content: "Error: " + err.Error(), Success: false}
+			}
+
+			if staged, ok := stageTool(toolName, args); ok {
+				return staged
+			}
+
+			undo := snapshotTool(toolName, args)
+			start := time.Now()
+			result := tool.Execute(args)
+			success := result.Success
+			content := digestOutput(toolName, result.Message)
+			recordToolCall(toolName, success, time.Since(start))
+			auditTool(currentAuditPrompt(), toolName, args, content, success)
+			slog.Debug("tool executed", "tool", toolName, "success", success, "duration", time.Since(start))
+			if undo != nil {
+				if success {
+					pushUndo(undo)
+				} else {
+					undo.snapshot.Discard()
+				}
+			}
 			
 			return ToolResult{
 				Name:    toolName,
@@ -458,6 +859,119 @@ func ExecuteTool(toolName string, args map[string]interface{}) ToolResult {
 	}
 }
 
+// toolOutput wraps the text of a tool that completed
+func toolOutput(message string) *fileops.FileOperation {
+	return &fileops.FileOperation{Success: true, Message: message}
+}
+
+// toolFailed wraps the error a tool reports to the model
+func toolFailed(message string) *fileops.FileOperation {
+	return &fileops.FileOperation{Success: false, Message: message}
+}
+
+// commandOutput wraps shell command output, which only says in its text
+// whether the command could run
+func commandOutput(output string) *fileops.FileOperation {
+	failed := strings.HasPrefix(output, "Error") || strings.HasPrefix(output, "Command failed")
+	return &fileops.FileOperation{Success: !failed, Message: output}
+}
+
+// ToolParameter describes one parameter from a tool's JSON schema
+type ToolParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// FindTool returns the available tool with the given name
+func FindTool(name string) (Tool, bool) {
+	for _, tool := range GetAvailableTools() {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// ParameterList returns the tool's parameters sorted by name
+func (t Tool) ParameterList() []ToolParameter {
+	properties, _ := t.Parameters["properties"].(map[string]interface{})
+	required, _ := t.Parameters["required"].([]string)
+
+	params := make([]ToolParameter, 0, len(properties))
+	for name, raw := range properties {
+		prop, _ := raw.(map[string]interface{})
+		param := ToolParameter{Name: name}
+		param.Type, _ = prop["type"].(string)
+		param.Description, _ = prop["description"].(string)
+		for _, r := range required {
+			if r == name {
+				param.Required = true
+			}
+		}
+		params = append(params, param)
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// MissingParameters returns the required parameters of tool that are absent or empty in args
+func MissingParameters(tool Tool, args map[string]interface{}) []string {
+	required, _ := tool.Parameters["required"].([]string)
+
+	var missing []string
+	for _, name := range required {
+		value, ok := args[name]
+		if !ok || value == nil {
+			missing = append(missing, name)
+			continue
+		}
+		// Empty file content or replacement text is legitimate, and a pattern may
+		// be a space; an empty name or command is not
+		str, isString := value.(string)
+		if !isString || name == "content" || name == "replacement" || (name == "pattern" && str != "") {
+			continue
+		}
+		if strings.TrimSpace(str) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// resolveMissingParameters asks Clarify for any missing required parameters and
+// returns the completed arguments along with whatever is still missing
+func resolveMissingParameters(tool Tool, args map[string]interface{}) (map[string]interface{}, []string) {
+	missing := MissingParameters(tool, args)
+	if len(missing) == 0 || Clarify == nil {
+		return args, missing
+	}
+
+	completed := make(map[string]interface{}, len(args)+len(missing))
+	for k, v := range args {
+		completed[k] = v
+	}
+
+	properties, _ := tool.Parameters["properties"].(map[string]interface{})
+	var stillMissing []string
+	for _, name := range missing {
+		description := ""
+		if prop, ok := properties[name].(map[string]interface{}); ok {
+			description, _ = prop["description"].(string)
+		}
+
+		answer, ok := Clarify(tool.Name, name, description)
+		answer = strings.TrimSpace(answer)
+		if !ok || answer == "" {
+			stillMissing = append(stillMissing, name)
+			continue
+		}
+		completed[name] = answer
+	}
+	return completed, stillMissing
+}
+
 // ParseToolCalls attempts to parse tool calls from AI response text
 func ParseToolCalls(responseText string) []ToolCall {
 	var toolCalls []ToolCall
@@ -504,189 +1018,151 @@ func FormatToolsForPrompt() string {
 	return prompt
 }
 
-// ExecuteShellCommand executes a shell command with timeout and security checks
+// ExecuteShellCommand executes a shell command with timeout and security checks.
+// Safe commands run directly, risky ones only once ConfirmCommand allows them,
+// and blocked ones never.
 func ExecuteShellCommand(command string, timeout time.Duration) string {
-	// Security check: block dangerous commands
-	if !isCommandSafe(command) {
-		return "Error: Command blocked for security reasons"
+	return ExecuteShellCommandIn(context.Background(), command, "", timeout)
+}
+
+// ExecuteShellCommandContext is ExecuteShellCommand that kills the command's
+// process tree when ctx is cancelled, returning what it printed so far
+func ExecuteShellCommandContext(ctx context.Context, command string, timeout time.Duration) string {
+	return ExecuteShellCommandIn(ctx, command, "", timeout)
+}
+
+// ExecuteShellCommandIn is ExecuteShellCommandContext running the command in
+// dir, or in the current directory when dir is empty
+func ExecuteShellCommandIn(ctx context.Context, command, dir string, timeout time.Duration) string {
+	switch risk, reason := ClassifyCommand(command); risk {
+	case RiskBlocked:
+		return fmt.Sprintf("Error: Command blocked for security reasons: %s", reason)
+	case RiskRisky:
+		if ConfirmCommand == nil {
+			return fmt.Sprintf("Error: Command needs confirmation (%s), which is not available here. Add it to safe_commands in the config to allow it", reason)
+		}
+		if !ConfirmCommand(command, reason) {
+			return "Error: Command not run: the user declined it"
+		}
 	}
 	
+	return runShellCommand(ctx, command, dir, timeout)
+}
+
+// runShellCommand runs a command through the platform shell, killing its
+// whole process tree when ctx is cancelled or it exceeds the timeout, unless
+// the user chooses to keep waiting
+func runShellCommand(ctx context.Context, command, dir string, timeout time.Duration) string {
 	var cmd *exec.Cmd
 	
 	// Choose shell based on OS
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/C", command)
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
 	} else {
-		cmd = exec.Command("sh", "-c", command)
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
 	}
 	
-	// Set up timeout (default max 30 seconds)
-	if timeout <= 0 || timeout > 30*time.Second {
-		timeout = 30 * time.Second
+	cmd.Dir = dir
+	cmd.Cancel = func() error { return killProcessTree(cmd) }
+	timeout = resolveCommandTimeout(timeout)
+	setProcessGroup(cmd)
+	
+	// Capture output incrementally so it is available if the command times out
+	output := &lockedBuffer{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	
+	if err := cmd.Start(); err != nil {
+		if ctx.Err() != nil {
+			return "Command interrupted"
+		}
+		return fmt.Sprintf("Command failed: %v", err)
 	}
 	
-	// Execute with timeout
 	done := make(chan error, 1)
-	var output []byte
-	var err error
-	
 	go func() {
-		output, err = cmd.CombinedOutput()
-		done <- err
+		done <- cmd.Wait()
 	}()
 	
-	select {
-	case <-time.After(timeout):
-		if cmd.Process != nil {
-			if err := cmd.Process.Kill(); err != nil {
-				// Log error but continue - process might already be dead
+	waited := timeout
+	deadline := time.After(timeout)
+	for {
+		select {
+		case execErr := <-done:
+			if ctx.Err() != nil {
+				partial := truncateOutput(output.String())
+				if partial == "" {
+					return "Command interrupted"
+				}
+				return fmt.Sprintf("Command interrupted\nPartial output:\n%s", partial)
 			}
+			if execErr != nil {
+				return fmt.Sprintf("Command failed: %v\nOutput: %s", execErr, truncateOutput(output.String()))
+			}
+			return truncateOutput(output.String())
+		case <-deadline:
 		}
-		return fmt.Sprintf("Command timed out after %v", timeout)
-	case execErr := <-done:
-		if execErr != nil {
-			return fmt.Sprintf("Command failed: %v\nOutput: %s", execErr, string(output))
+		if !ConfirmCommandTimeout || KeepWaiting == nil || !KeepWaiting(command, waited) {
+			break
 		}
+		waited += timeout
+		deadline = time.After(timeout)
 	}
 	
-	// Limit output size to prevent memory issues
-	result := string(output)
-	if len(result) > 10000 {
-		result = result[:10000] + "\n... (output truncated)"
+	_ = killProcessTree(cmd) // Process might already be dead - still report the timeout
+	// Give the process a moment to exit so trailing output is flushed
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
 	}
-	
-	return result
+	partial := truncateOutput(output.String())
+	if partial == "" {
+		return fmt.Sprintf("Command timed out after %v", waited)
+	}
+	return fmt.Sprintf("Command timed out after %v\nPartial output:\n%s", waited, partial)
 }
 
-// isCommandSafe checks if a command is safe to execute
-func isCommandSafe(command string) bool {
-	command = strings.ToLower(strings.TrimSpace(command))
-	
-	// Block empty commands
-	if command == "" {
-		return false
-	}
-	
-	// List of dangerous command patterns
-	dangerousPatterns := []string{
-		"rm -rf",
-		"rm -r /",
-		"mkfs",
-		"dd if=",
-		":(){ :|:& };:",  // fork bomb
-		"curl", "wget",   // network access (can be dangerous)
-		"sudo",
-		"su ",
-		"passwd",
-		"useradd",
-		"userdel",
-		"chmod 777",
-		"chown root",
-		"systemctl",
-		"service",
-		"reboot",
-		"shutdown",
-		"halt",
-		"poweroff",
-		"mount",
-		"umount",
-		"fdisk",
-		"parted",
-		"format",
-		"> /dev/",
-		"nc ", "netcat",  // network tools
-		"ssh",
-		"scp",
-		"rsync",
-		"crontab",
-		"at ",
-		"killall",
-		"pkill",
-		"kill -9",
-		"python -c",
-		"perl -e",
-		"ruby -e",
-		"node -e",
-		"eval",
-		"exec",
-		"/bin/bash",
-		"/bin/sh",
-		"bash -c",
-		"sh -c",
-	}
-	
-	// Check against dangerous patterns
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(command, pattern) {
-			return false
-		}
-	}
-	
-	// Block commands with potentially dangerous characters
-	dangerousChars := []string{
-		";",     // command chaining
-		"&&",    // command chaining
-		"||",    // command chaining
-		"|",     // piping (can be dangerous)
-		">",     // redirection
-		">>",    // redirection
-		"<",     // redirection
-		"`",     // command substitution
-		"$(",    // command substitution
-		"$()",   // command substitution
-		"../",   // directory traversal
-		"./",    // current directory execution
-	}
-	
-	for _, char := range dangerousChars {
-		if strings.Contains(command, char) {
-			return false
-		}
-	}
-	
-	// Allow only specific safe commands
-	safeCommands := []string{
-		"ls", "dir", "pwd", "cd", "echo", "cat", "head", "tail",
-		"grep", "find", "which", "where", "type", "file",
-		"date", "whoami", "id", "uptime", "uname", "hostname",
-		"ps", "top", "df", "du", "free", "lscpu", "lsblk",
-		"env", "printenv", "history", "alias",
-		"wc", "sort", "uniq", "cut", "awk", "sed",
-		"git status", "git log", "git branch", "git diff",
-		"go version", "go list", "go mod",
-		"npm list", "npm version",
-		"python --version", "python3 --version",
-		"node --version", "php --version",
-		"java -version", "javac -version",
-		"gcc --version", "clang --version",
-	}
-	
-	// Extract the base command (first word)
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		return false
+// resolveCommandTimeout applies the default and the configured ceiling to a requested timeout
+func resolveCommandTimeout(timeout time.Duration) time.Duration {
+	ceiling := MaxCommandTimeout
+	if ceiling <= 0 {
+		ceiling = DefaultCommandTimeout
 	}
 	
-	baseCommand := parts[0]
-	
-	// Check if base command is in safe list
-	for _, safe := range safeCommands {
-		if strings.HasPrefix(safe, baseCommand) {
-			return true
-		}
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
 	}
-	
-	// Special case: allow version checks
-	if strings.Contains(command, "--version") || strings.Contains(command, "-version") {
-		return true
+	if timeout > ceiling {
+		timeout = ceiling
 	}
 	
-	// Special case: allow help commands
-	if strings.Contains(command, "--help") || strings.Contains(command, "-h") {
-		return true
+	return timeout
+}
+
+// truncateOutput limits output size to prevent memory issues
+func truncateOutput(result string) string {
+	if len(result) > 10000 {
+		result = result[:10000] + "\n... (output truncated)"
 	}
-	
-	return false
+	return result
+}
+
+// lockedBuffer is a bytes.Buffer that is safe to read while a command writes to it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
 }
 
 // ExecuteToolChain executes a chain of tools with context passing
@@ -802,9 +1278,9 @@ func (te *ToolExecution) GetExecutionSummary() string {
 	summary.WriteString(fmt.Sprintf("Successful: %d, Failed: %d\n\n", successCount, len(te.Results)-successCount))
 	
 	for i, result := range te.Results {
-		status := "✓"
+		status := glyphs.Check
 		if !result.Success {
-			status = "✗"
+			status = glyphs.Cross
 		}
 		summary.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, status, result.Name))
 		if len(result.Content) > 100 {
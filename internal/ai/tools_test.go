@@ -3,7 +3,11 @@ package ai
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestDir(t *testing.T) string {
@@ -29,9 +33,9 @@ func TestGetAvailableTools(t *testing.T) {
 	
 	// Check that essential tools are present
 	expectedTools := []string{
-		"list_files", "read_file", "create_file", "update_file", "delete_file",
+		"list_files", "read_file", "create_file", "touch", "set_permissions", "update_file", "delete_file",
 		"create_directory", "delete_directory", "copy_file", "move_file",
-		"get_working_directory", "change_directory",
+		"get_working_directory", "change_directory", "disk_usage", "directory_size", "find_duplicates",
 	}
 	
 	toolMap := make(map[string]bool)
@@ -115,6 +119,36 @@ func TestExecuteTool(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:     "touch file",
+			toolName: "touch",
+			args: map[string]interface{}{
+				"filename": "stamp",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "disk usage",
+			toolName: "disk_usage",
+			args:     map[string]interface{}{},
+			wantErr:  false,
+		},
+		{
+			name:     "directory size",
+			toolName: "directory_size",
+			args: map[string]interface{}{
+				"path": ".",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "directory size of a missing directory",
+			toolName: "directory_size",
+			args: map[string]interface{}{
+				"path": "missing",
+			},
+			wantErr: true,
+		},
 		{
 			name:     "create directory",
 			toolName: "create_directory",
@@ -162,6 +196,54 @@ func TestExecuteTool(t *testing.T) {
 	}
 }
 
+func TestExecuteToolMissingParameters(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	
+	defer func(orig ClarifyFunc) { Clarify = orig }(Clarify)
+	
+	// Headless: no clarifier, so the result lists the missing parameters
+	Clarify = nil
+	result := ExecuteTool("create_file", map[string]interface{}{"content": ""})
+	if result.Success {
+		t.Fatal("Expected failure when filename is missing")
+	}
+	if len(result.MissingParams) != 1 || result.MissingParams[0] != "filename" {
+		t.Errorf("Expected missing filename, got %v", result.MissingParams)
+	}
+	if !strings.Contains(result.Content, "filename") {
+		t.Errorf("Expected error to name the missing parameter, got %q", result.Content)
+	}
+	
+	// Interactive: the clarifier supplies the filename
+	var asked []string
+	Clarify = func(tool, param, description string) (string, bool) {
+		asked = append(asked, tool+"."+param)
+		return "answered.txt", true
+	}
+	result = ExecuteTool("create_file", map[string]interface{}{"filename": " ", "content": ""})
+	if !result.Success {
+		t.Fatalf("Expected success after clarification, got %q", result.Content)
+	}
+	if len(asked) != 1 || asked[0] != "create_file.filename" {
+		t.Errorf("Expected a single filename question, got %v", asked)
+	}
+	if _, err := os.Stat("answered.txt"); err != nil {
+		t.Errorf("Expected clarified file to be created: %v", err)
+	}
+	
+	// Interactive but declined
+	Clarify = func(tool, param, description string) (string, bool) { return "", false }
+	result = ExecuteTool("delete_file", map[string]interface{}{})
+	if result.Success || len(result.MissingParams) != 1 {
+		t.Errorf("Expected declined clarification to fail with missing params, got %+v", result)
+	}
+}
+
 func TestFormatToolsForPrompt(t *testing.T) {
 	prompt := FormatToolsForPrompt()
 	
@@ -305,4 +387,108 @@ func containsHelper(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestResolveCommandTimeout(t *testing.T) {
+	originalMax := MaxCommandTimeout
+	defer func() { MaxCommandTimeout = originalMax }()
+	
+	tests := []struct {
+		name      string
+		ceiling   time.Duration
+		requested time.Duration
+		want      time.Duration
+	}{
+		{"default when unset", 30 * time.Second, 0, 30 * time.Second},
+		{"honored below ceiling", 30 * time.Second, 10 * time.Second, 10 * time.Second},
+		{"capped at default ceiling", 30 * time.Second, 5 * time.Minute, 30 * time.Second},
+		{"raised ceiling honors larger request", 10 * time.Minute, 5 * time.Minute, 5 * time.Minute},
+		{"default capped by lower ceiling", 5 * time.Second, 0, 5 * time.Second},
+		{"invalid ceiling falls back", 0, 5 * time.Minute, 30 * time.Second},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MaxCommandTimeout = tt.ceiling
+			if got := resolveCommandTimeout(tt.requested); got != tt.want {
+				t.Errorf("resolveCommandTimeout(%v) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteShellCommandTimeoutReturnsPartialOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tail -f is not available on Windows")
+	}
+	
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	
+	if err := os.WriteFile("test.log", []byte("partial-line\n"), 0600); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	
+	originalMax := MaxCommandTimeout
+	MaxCommandTimeout = 500 * time.Millisecond
+	defer func() { MaxCommandTimeout = originalMax }()
+	
+	result := ExecuteShellCommand("tail -f test.log", 10*time.Second)
+	
+	if !strings.Contains(result, "timed out after 500ms") {
+		t.Errorf("Expected timeout capped at ceiling, got: %s", result)
+	}
+	if !strings.Contains(result, "partial-line") {
+		t.Errorf("Expected partial output in timeout result, got: %s", result)
+	}
+}
+
+func TestExecuteCommandCwd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ls is not available on Windows")
+	}
+	
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	if err := os.WriteFile(filepath.Join(tmpDir, "marker.txt"), nil, 0600); err != nil {
+		t.Fatalf("Failed to write marker file: %v", err)
+	}
+	
+	result := ExecuteTool("execute_command", map[string]interface{}{"command": "ls", "cwd": tmpDir})
+	if !result.Success || !strings.Contains(result.Content, "marker.txt") {
+		t.Errorf("Expected the command to run in cwd, got: %s", result.Content)
+	}
+	if !strings.HasSuffix(result.Content, "(ran in "+tmpDir+")") {
+		t.Errorf("Expected the result to name the directory, got: %s", result.Content)
+	}
+	
+	result = ExecuteTool("execute_command", map[string]interface{}{"command": "ls", "cwd": filepath.Join(tmpDir, "missing")})
+	if result.Success {
+		t.Errorf("Expected a missing cwd to fail, got: %s", result.Content)
+	}
+}
+
+func TestToolParameterList(t *testing.T) {
+	tool, ok := FindTool("create_file")
+	if !ok {
+		t.Fatal("Expected create_file to be found")
+	}
+	
+	params := tool.ParameterList()
+	if len(params) != 2 || params[0].Name != "content" || params[1].Name != "filename" {
+		t.Fatalf("Expected sorted content and filename parameters, got %+v", params)
+	}
+	for _, param := range params {
+		if !param.Required || param.Type != "string" || param.Description == "" {
+			t.Errorf("Unexpected parameter details: %+v", param)
+		}
+	}
+	
+	if _, ok := FindTool("no_such_tool"); ok {
+		t.Error("Expected unknown tool lookup to fail")
+	}
+}
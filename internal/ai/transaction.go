@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"tala/internal/fileops"
+)
+
+var (
+	// ErrTransactionActive is returned by BeginTransaction when one is already open
+	ErrTransactionActive = errors.New("a transaction is already in progress")
+	// ErrNoTransaction is returned when committing or rolling back without a transaction
+	ErrNoTransaction = errors.New("no transaction in progress")
+)
+
+// stagedOp is a mutating tool call held back until the transaction commits.
+// File content is kept in the staging directory rather than in memory.
+type stagedOp struct {
+	tool        string
+	args        map[string]interface{}
+	contentFile string
+	renames     []fileops.Rename // what the user confirmed for bulk_rename
+	prompt      string           // what the AI was answering, for the audit log
+}
+
+// transaction collects staged tool calls between /tx begin and /tx commit
+type transaction struct {
+	staging string
+	ops     []stagedOp
+}
+
+var (
+	txMu     sync.Mutex
+	activeTx *transaction
+)
+
+// BeginTransaction starts staging mutating file tools instead of running them.
+// Reads still see the filesystem as it is, without the staged changes.
+func BeginTransaction() error {
+	txMu.Lock()
+	defer txMu.Unlock()
+
+	if activeTx != nil {
+		return ErrTransactionActive
+	}
+	staging, err := os.MkdirTemp("", "tala-tx-")
+	if err != nil {
+		return err
+	}
+	activeTx = &transaction{staging: staging}
+	return nil
+}
+
+// TransactionActive reports whether tool calls are currently being staged
+func TransactionActive() bool {
+	txMu.Lock()
+	defer txMu.Unlock()
+	return activeTx != nil
+}
+
+// StagedOperations describes the tool calls waiting in the open transaction
+func StagedOperations() []string {
+	txMu.Lock()
+	defer txMu.Unlock()
+
+	if activeTx == nil {
+		return nil
+	}
+	descriptions := make([]string, len(activeTx.ops))
+	for i, op := range activeTx.ops {
+		descriptions[i] = fmt.Sprintf("%s %s", op.tool, strings.Join(mutatedPaths(op.tool, op.args), " -> "))
+	}
+	return descriptions
+}
+
+// stageTool holds back a mutating tool call when a transaction is open. It reports
+// false when the call should run immediately. Bulk renames are confirmed here,
+// since the commit applies them without asking.
+func stageTool(toolName string, args map[string]interface{}) (ToolResult, bool) {
+	paths := mutatedPaths(toolName, args)
+	if !TransactionActive() || paths == nil {
+		return ToolResult{}, false
+	}
+
+	var renames []fileops.Rename
+	if toolName == "bulk_rename" {
+		pattern, _ := args["pattern"].(string)
+		replacement, _ := args["replacement"].(string)
+		regex, _ := args["regex"].(bool)
+		var refused *fileops.FileOperation
+		if renames, refused = confirmRenames(paths[0], pattern, replacement, regex); refused != nil {
+			return ToolResult{Name: toolName, Content: refused.Message, Success: refused.Success}, true
+		}
+	}
+
+	txMu.Lock()
+	defer txMu.Unlock()
+	if activeTx == nil {
+		return ToolResult{}, false
+	}
+
+	op := stagedOp{tool: toolName, args: make(map[string]interface{}, len(args)), renames: renames, prompt: currentAuditPrompt()}
+	for k, v := range args {
+		op.args[k] = v
+	}
+	if content, ok := args["content"].(string); ok {
+		op.contentFile = filepath.Join(activeTx.staging, fmt.Sprintf("%d.content", len(activeTx.ops)))
+		if err := os.WriteFile(op.contentFile, []byte(content), 0600); err != nil {
+			return ToolResult{
+				Name:    toolName,
+				Content: fmt.Sprintf("Error: failed to stage %s: %v", toolName, err),
+				Success: false,
+			}, true
+		}
+		delete(op.args, "content")
+	}
+	activeTx.ops = append(activeTx.ops, op)
+
+	return ToolResult{
+		Name:    toolName,
+		Content: fmt.Sprintf("Staged %s %s (applied on /tx commit)", toolName, strings.Join(paths, " -> ")),
+		Success: true,
+	}, true
+}
+
+// CommitTransaction applies every staged tool call in order. If any of them fails,
+// the files they touched are put back as they were and the transaction stays open
+// so it can be fixed or rolled back. A successful commit is a single /undo step.
+func CommitTransaction() (int, error) {
+	txMu.Lock()
+	defer txMu.Unlock()
+
+	if activeTx == nil {
+		return 0, ErrNoTransaction
+	}
+	tx := activeTx
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, op := range tx.ops {
+		for _, path := range mutatedPaths(op.tool, op.args) {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	snapshot, err := fileops.TakeSnapshot(paths...)
+	if err != nil {
+		return 0, fmt.Errorf("cannot back up files before commit: %w", err)
+	}
+
+	for i, op := range tx.ops {
+		if err := tx.apply(op); err != nil {
+			auditTool(op.prompt, op.tool, op.args, "Error: "+err.Error(), false)
+			if restoreErr := snapshot.Restore(); restoreErr != nil {
+				err = fmt.Errorf("%v; restoring files also failed: %v", err, restoreErr)
+			}
+			snapshot.Discard()
+			return 0, fmt.Errorf("operation %d (%s) failed, nothing was applied: %w", i+1, op.tool, err)
+		}
+	}
+
+	for _, op := range tx.ops {
+		auditTool(op.prompt, op.tool, op.args, "", true)
+	}
+
+	count := len(tx.ops)
+	if count > 0 {
+		pushUndo(&undoEntry{description: fmt.Sprintf("transaction of %d operations", count), snapshot: snapshot})
+	} else {
+		snapshot.Discard()
+	}
+	os.RemoveAll(tx.staging)
+	activeTx = nil
+	return count, nil
+}
+
+// apply runs one staged tool call against the filesystem. It calls fileops
+// directly so failures like a missing file are not mistaken for success.
+func (tx *transaction) apply(op stagedOp) error {
+	str := func(key string) string {
+		value, _ := op.args[key].(string)
+		return value
+	}
+
+	content := ""
+	if op.contentFile != "" {
+		data, err := os.ReadFile(op.contentFile)
+		if err != nil {
+			return err
+		}
+		content = string(data)
+	}
+
+	var result *fileops.FileOperation
+	switch op.tool {
+	case "create_file", SaveResponseTool:
+		result = fileops.CreateFile(str("filename"), content)
+	case "touch":
+		result = fileops.Touch(str("filename"))
+	case "update_file":
+		result = fileops.UpdateFile(str("filename"), content)
+	case "delete_file":
+		result = fileops.DeleteFile(str("filename"))
+	case "create_directory":
+		result = fileops.CreateDirectory(str("dirname"))
+	case "delete_directory":
+		result = fileops.DeleteDirectory(str("dirname"))
+	case "copy_file":
+		result = fileops.CopyFile(str("source"), str("destination"))
+	case "move_file":
+		result = fileops.MoveFile(str("source"), str("destination"))
+	case "bulk_rename":
+		return fileops.ApplyRenames(str("path"), op.renames)
+	default:
+		return fmt.Errorf("%s cannot run in a transaction", op.tool)
+	}
+	if !result.Success {
+		return errors.New(result.Message)
+	}
+	return nil
+}
+
+// RollbackTransaction discards every staged tool call without touching the files
+func RollbackTransaction() (int, error) {
+	txMu.Lock()
+	defer txMu.Unlock()
+
+	if activeTx == nil {
+		return 0, ErrNoTransaction
+	}
+	count := len(activeTx.ops)
+	os.RemoveAll(activeTx.staging)
+	activeTx = nil
+	return count, nil
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tala/internal/fileops"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	created := filepath.Join(dir, "created.txt")
+	os.WriteFile(existing, []byte("old"), 0600)
+
+	if err := BeginTransaction(); err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if err := BeginTransaction(); !errors.Is(err, ErrTransactionActive) {
+		t.Errorf("Expected ErrTransactionActive, got %v", err)
+	}
+
+	ExecuteTool("create_file", map[string]interface{}{"filename": created, "content": "new file"})
+	result := ExecuteTool("update_file", map[string]interface{}{"filename": existing, "content": "updated"})
+	if !result.Success {
+		t.Fatalf("Expected staging to succeed, got %q", result.Content)
+	}
+
+	// Nothing touches the filesystem until commit
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Expected staged file not to exist before commit")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("Expected staged update not to be applied yet, got %q", data)
+	}
+	if ops := StagedOperations(); len(ops) != 2 {
+		t.Errorf("Expected 2 staged operations, got %v", ops)
+	}
+
+	count, err := CommitTransaction()
+	if err != nil || count != 2 {
+		t.Fatalf("Expected 2 operations committed, got %d (%v)", count, err)
+	}
+	if data, _ := os.ReadFile(created); string(data) != "new file" {
+		t.Errorf("Expected created file after commit, got %q", data)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "updated" {
+		t.Errorf("Expected updated file after commit, got %q", data)
+	}
+
+	// The whole transaction is one undo step
+	if UndoDepth() != 1 {
+		t.Fatalf("Expected one undo entry, got %d", UndoDepth())
+	}
+	Undo()
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Expected undo to remove the created file")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("Expected undo to restore the old content, got %q", data)
+	}
+}
+
+func TestTransactionFailedCommitLeavesFilesUntouched(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	existing := filepath.Join(dir, "existing.txt")
+	os.WriteFile(existing, []byte("keep"), 0600)
+
+	BeginTransaction()
+	defer RollbackTransaction()
+	ExecuteTool("create_file", map[string]interface{}{"filename": first, "content": "1"})
+	ExecuteTool("update_file", map[string]interface{}{"filename": existing, "content": "changed"})
+	ExecuteTool("delete_file", map[string]interface{}{"filename": filepath.Join(dir, "missing.txt")})
+
+	if _, err := CommitTransaction(); err == nil {
+		t.Fatal("Expected commit to fail on the missing file")
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("Expected the created file to be rolled back")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Errorf("Expected the update to be rolled back, got %q", data)
+	}
+	if !TransactionActive() {
+		t.Error("Expected the transaction to stay open after a failed commit")
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+
+	if _, err := RollbackTransaction(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Expected ErrNoTransaction, got %v", err)
+	}
+
+	BeginTransaction()
+	ExecuteTool("create_file", map[string]interface{}{"filename": file, "content": "x"})
+	count, err := RollbackTransaction()
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 operation discarded, got %d (%v)", count, err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("Expected rollback to leave the filesystem untouched")
+	}
+	if TransactionActive() {
+		t.Error("Expected no transaction after rollback")
+	}
+}
+
+func TestTransactionConfirmsBulkRename(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	defer func() { ConfirmRenames = nil }()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Draft One.md"), []byte("one"), 0644)
+	args := map[string]interface{}{"path": dir, "pattern": " ", "replacement": "_"}
+
+	BeginTransaction()
+	defer RollbackTransaction()
+
+	// Without a way to confirm, or when the user declines, nothing is staged
+	ConfirmRenames = nil
+	if result := ExecuteTool("bulk_rename", args); result.Success {
+		t.Errorf("Expected an unconfirmed rename to be refused, got %q", result.Content)
+	}
+	ConfirmRenames = func(string, []fileops.Rename) bool { return false }
+	if result := ExecuteTool("bulk_rename", args); result.Success {
+		t.Errorf("Expected a declined rename to be refused, got %q", result.Content)
+	}
+	if ops := StagedOperations(); len(ops) != 0 {
+		t.Fatalf("Expected nothing staged, got %v", ops)
+	}
+
+	ConfirmRenames = func(string, []fileops.Rename) bool { return true }
+	if result := ExecuteTool("bulk_rename", args); !result.Success {
+		t.Fatalf("Expected the confirmed rename to be staged, got %q", result.Content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Draft One.md")); err != nil {
+		t.Error("Expected nothing renamed before commit")
+	}
+
+	// The commit applies what was confirmed without asking again
+	ConfirmRenames = nil
+	if _, err := CommitTransaction(); err != nil {
+		t.Fatalf("CommitTransaction failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Draft_One.md")); err != nil {
+		t.Errorf("Expected Draft_One.md after commit: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package ai
+
+import "strings"
+
+// TruncationNote is appended to a reply the model stopped because it reached
+// max_tokens, so the cut-off is never silent
+const TruncationNote = "[response truncated — increase max_tokens]"
+
+// isTruncatedFinish reports whether a finish reason means the token limit ended
+// the reply: "length" from Ollama and OpenAI, "max_tokens" from Anthropic
+func isTruncatedFinish(reason string) bool {
+	return reason == "length" || reason == "max_tokens"
+}
+
+// markTruncated appends TruncationNote to a reply
+func markTruncated(response string) string {
+	return strings.TrimRight(response, " \n") + truncationSuffix
+}
+
+// truncationSuffix separates the note from the reply
+const truncationSuffix = "\n\n" + TruncationNote
+
+// StripTruncationNote removes TruncationNote from the end of a reply and reports
+// whether it was there
+func StripTruncationNote(response string) (string, bool) {
+	trimmed := strings.TrimRight(response, " \n")
+	if !strings.HasSuffix(trimmed, TruncationNote) {
+		return response, false
+	}
+	return strings.TrimRight(strings.TrimSuffix(trimmed, TruncationNote), " \n"), true
+}
+
+// continueContext is how much of the reply so far ContinuePrompt quotes, enough
+// for the model to find its place without resending a whole document
+const continueContext = 1500
+
+// ContinuePrompt asks the model to carry on with a reply that stopped early. The
+// end of the reply is quoted so providers without chat history can pick up too.
+func ContinuePrompt(reply string) string {
+	tail := []rune(reply)
+	if len(tail) > continueContext {
+		tail = tail[len(tail)-continueContext:]
+	}
+	return "Your previous reply was cut off. Continue it exactly where it stopped: do not repeat " +
+		"anything, do not add an introduction, and keep the same format (finish any open code block). " +
+		"It ended with:\n\n" + string(tail)
+}
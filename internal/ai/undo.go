@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"tala/internal/fileops"
+)
+
+// MaxUndoDepth is how many mutating tool calls /undo can step back through
+const MaxUndoDepth = 20
+
+// ErrNothingToUndo is returned by Undo when no tool call has been recorded
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// undoEntry is a mutating tool call along with the state it replaced
+type undoEntry struct {
+	description string
+	snapshot    *fileops.Snapshot
+}
+
+var (
+	undoMu    sync.Mutex
+	undoStack []undoEntry
+)
+
+// mutatedPaths returns the paths a tool call will change, or nil for tools that
+// cannot be undone
+func mutatedPaths(toolName string, args map[string]interface{}) []string {
+	var keys []string
+	switch toolName {
+	case "create_file", "touch", "update_file", "delete_file", SaveResponseTool:
+		keys = []string{"filename"}
+	case "create_directory", "delete_directory":
+		keys = []string{"dirname"}
+	case "copy_file":
+		keys = []string{"destination"}
+	case "move_file":
+		keys = []string{"source", "destination"}
+	case "bulk_rename":
+		keys = []string{"path"}
+	default:
+		return nil
+	}
+
+	var paths []string
+	for _, key := range keys {
+		if path, ok := args[key].(string); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) != len(keys) {
+		return nil
+	}
+	return paths
+}
+
+// snapshotTool captures the state a tool call is about to change. It returns nil
+// when the call cannot be undone.
+func snapshotTool(toolName string, args map[string]interface{}) *undoEntry {
+	paths := mutatedPaths(toolName, args)
+	if paths == nil {
+		return nil
+	}
+	snapshot, err := fileops.TakeSnapshot(paths...)
+	if err != nil {
+		return nil
+	}
+	return &undoEntry{
+		description: fmt.Sprintf("%s %s", toolName, strings.Join(paths, " -> ")),
+		snapshot:    snapshot,
+	}
+}
+
+// pushUndo records a completed tool call, dropping the oldest beyond MaxUndoDepth
+func pushUndo(entry *undoEntry) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+
+	undoStack = append(undoStack, *entry)
+	if len(undoStack) > MaxUndoDepth {
+		undoStack[0].snapshot.Discard()
+		undoStack = undoStack[1:]
+	}
+}
+
+// Undo reverses the most recent mutating tool call and describes what was undone
+func Undo() (string, error) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+
+	if len(undoStack) == 0 {
+		return "", ErrNothingToUndo
+	}
+	entry := undoStack[len(undoStack)-1]
+	if err := entry.snapshot.Restore(); err != nil {
+		return "", fmt.Errorf("failed to undo %s: %w", entry.description, err)
+	}
+	entry.snapshot.Discard()
+	undoStack = undoStack[:len(undoStack)-1]
+	return entry.description, nil
+}
+
+// UndoDepth returns how many tool calls can currently be undone
+func UndoDepth() int {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	return len(undoStack)
+}
+
+// ClearUndo forgets every recorded tool call
+func ClearUndo() {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+
+	for _, entry := range undoStack {
+		entry.snapshot.Discard()
+	}
+	undoStack = nil
+}
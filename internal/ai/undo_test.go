@@ -0,0 +1,127 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tala/internal/fileops"
+)
+
+func TestUndoFileOperations(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	renamed := filepath.Join(dir, "renamed.txt")
+
+	ExecuteTool("create_file", map[string]interface{}{"filename": notes, "content": "v1"})
+	ExecuteTool("update_file", map[string]interface{}{"filename": notes, "content": "v2"})
+	ExecuteTool("move_file", map[string]interface{}{"source": notes, "destination": renamed})
+	ExecuteTool("delete_file", map[string]interface{}{"filename": renamed})
+	if UndoDepth() != 4 {
+		t.Fatalf("Expected 4 undo entries, got %d", UndoDepth())
+	}
+
+	// Undo the delete
+	if _, err := Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if data, _ := os.ReadFile(renamed); string(data) != "v2" {
+		t.Errorf("Expected deleted file to be recreated, got %q", data)
+	}
+
+	// Undo the move
+	Undo()
+	if _, err := os.Stat(renamed); !os.IsNotExist(err) {
+		t.Error("Expected moved file to leave its destination")
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "v2" {
+		t.Errorf("Expected file back at its source, got %q", data)
+	}
+
+	// Undo the update
+	Undo()
+	if data, _ := os.ReadFile(notes); string(data) != "v1" {
+		t.Errorf("Expected original content, got %q", data)
+	}
+
+	// Undo the create
+	Undo()
+	if _, err := os.Stat(notes); !os.IsNotExist(err) {
+		t.Error("Expected created file to be removed")
+	}
+
+	if _, err := Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoStackDepth(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	dir := t.TempDir()
+
+	for i := 0; i < MaxUndoDepth+5; i++ {
+		ExecuteTool("create_directory", map[string]interface{}{"dirname": filepath.Join(dir, "d", string(rune('a'+i)))})
+	}
+	if UndoDepth() != MaxUndoDepth {
+		t.Errorf("Expected undo depth to be capped at %d, got %d", MaxUndoDepth, UndoDepth())
+	}
+
+	// Read-only and failed tools are not recorded
+	ClearUndo()
+	ExecuteTool("list_files", map[string]interface{}{"path": dir})
+	ExecuteTool("update_file", map[string]interface{}{"filename": filepath.Join(dir, "d"), "content": "x"})
+	if UndoDepth() != 0 {
+		t.Errorf("Expected nothing recorded, got %d entries", UndoDepth())
+	}
+}
+
+func TestUndoTrashedDelete(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	fileops.TrashDir, fileops.UseTrash = filepath.Join(t.TempDir(), "trash"), true
+	defer func() { fileops.TrashDir, fileops.UseTrash = "", false }()
+	notes := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notes, []byte("keep me"), 0600)
+
+	ExecuteTool("delete_file", map[string]interface{}{"filename": notes})
+	if items, _ := fileops.ListTrash(); len(items) != 1 {
+		t.Fatalf("Expected the delete to go to the trash, got %d items", len(items))
+	}
+	if _, err := Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "keep me" {
+		t.Errorf("Expected the deleted file to be back, got %q", data)
+	}
+	// The file is back where it was, so a trash copy would restore nothing
+	if items, _ := fileops.ListTrash(); len(items) != 0 {
+		t.Errorf("Expected the trash entry to be removed, got %+v", items)
+	}
+}
+
+func TestUndoSkipsMissingFileDelete(t *testing.T) {
+	ClearUndo()
+	defer ClearUndo()
+	notes := filepath.Join(t.TempDir(), "notes.txt")
+
+	// Deleting a file that is not there does nothing, so there is nothing to undo
+	if result := ExecuteTool("delete_file", map[string]interface{}{"filename": notes}); result.Success {
+		t.Fatalf("Expected deleting a missing file to fail, got %q", result.Content)
+	}
+	if UndoDepth() != 0 {
+		t.Fatalf("Expected nothing recorded, got %d entries", UndoDepth())
+	}
+
+	// A file created later must survive /undo
+	os.WriteFile(notes, []byte("new"), 0600)
+	if _, err := Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "new" {
+		t.Errorf("Expected the new file to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,61 @@
+package ai
+
+import "time"
+
+// Usage is what a provider reported about generating its latest response
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	FirstToken       time.Duration // from sending the request to the first token
+	Generation       time.Duration // spent producing the completion tokens
+	At               time.Time     // when the response finished
+}
+
+// TokensPerSecond is the completion throughput, or 0 when it is unknown
+func (u Usage) TokensPerSecond() float64 {
+	if u.CompletionTokens == 0 || u.Generation <= 0 {
+		return 0
+	}
+	return float64(u.CompletionTokens) / u.Generation.Seconds()
+}
+
+// OllamaStats are the counts and timings Ollama adds to the final message of
+// a response; durations are in nanoseconds
+type OllamaStats struct {
+	LoadDuration       int64 `json:"load_duration,omitempty"`
+	PromptEvalCount    int   `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int   `json:"eval_count,omitempty"`
+	EvalDuration       int64 `json:"eval_duration,omitempty"`
+}
+
+// usage converts the stats; the first token follows loading the model and
+// reading the prompt
+func (s OllamaStats) usage() Usage {
+	return Usage{
+		PromptTokens:     s.PromptEvalCount,
+		CompletionTokens: s.EvalCount,
+		FirstToken:       time.Duration(s.LoadDuration + s.PromptEvalDuration),
+		Generation:       time.Duration(s.EvalDuration),
+		At:               time.Now(),
+	}
+}
+
+// LastUsage returns the usage of the provider's latest response. ok is false
+// when the provider does not report token counts.
+func LastUsage(provider Provider) (usage Usage, ok bool) {
+	if p, isOllama := UnwrapProvider(provider).(*OllamaProvider); isOllama {
+		return p.lastUsage, p.lastUsage.CompletionTokens > 0
+	}
+	return Usage{}, false
+}
+
+// streamedUsage converts the stats of a streamed response, whose first token
+// was timed as it arrived
+func streamedUsage(stats OllamaStats, firstToken time.Duration) Usage {
+	usage := stats.usage()
+	if firstToken > 0 {
+		usage.FirstToken = firstToken
+	}
+	return usage
+}
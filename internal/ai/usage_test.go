@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOllamaProviderReportsUsage(t *testing.T) {
+	stats := OllamaStats{
+		LoadDuration:       int64(100 * time.Millisecond),
+		PromptEvalCount:    12,
+		PromptEvalDuration: int64(150 * time.Millisecond),
+		EvalCount:          40,
+		EvalDuration:       int64(2 * time.Second),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: "hi"}, Done: true, OllamaStats: stats})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	if _, ok := LastUsage(provider); ok {
+		t.Error("Expected no usage before the first response")
+	}
+	if _, err := provider.GenerateResponse(context.Background(), "hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	usage, ok := LastUsage(WrapProvider(provider))
+	if !ok {
+		t.Fatal("Expected usage after a response")
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 40 {
+		t.Errorf("Unexpected token counts: %+v", usage)
+	}
+	if usage.FirstToken != 250*time.Millisecond {
+		t.Errorf("Expected the first token after loading and reading the prompt, got %v", usage.FirstToken)
+	}
+	if rate := usage.TokensPerSecond(); rate != 20 {
+		t.Errorf("Expected 20 tok/s, got %v", rate)
+	}
+}
+
+func TestOllamaStreamTimesFirstToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		time.Sleep(50 * time.Millisecond)
+		enc.Encode(OllamaChatResponse{Message: OllamaMessage{Role: "assistant", Content: "a"}})
+		enc.Encode(OllamaChatResponse{Done: true, OllamaStats: OllamaStats{EvalCount: 2, EvalDuration: int64(time.Second)}})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider("llama2", 0.7, 0, server.URL)
+	if _, err := provider.GenerateStreamingResponse(context.Background(), "hello", func(string) {}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	usage, ok := LastUsage(provider)
+	if !ok || usage.CompletionTokens != 2 {
+		t.Fatalf("Expected usage from the final message, got %+v", usage)
+	}
+	if usage.FirstToken < 50*time.Millisecond {
+		t.Errorf("Expected the first token to be timed as it arrived, got %v", usage.FirstToken)
+	}
+}
+
+func TestUsageWithoutTiming(t *testing.T) {
+	if rate := (Usage{CompletionTokens: 10}).TokensPerSecond(); rate != 0 {
+		t.Errorf("Expected no throughput without a generation time, got %v", rate)
+	}
+}
@@ -0,0 +1,129 @@
+// Package audit keeps an append-only record of the operations tala ran on the
+// user's system: which tool, with what parameters, whether it worked and the
+// prompt that led to it. Unlike the debug log it is always written, one JSON
+// entry per line, so it can be reviewed after the fact.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File overrides where the audit log is stored, mainly for tests. When empty
+// it is ~/.config/tala/audit.log.
+var File = ""
+
+// Entry is one executed operation
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Tool    string                 `json:"tool"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Prompt  string                 `json:"prompt,omitempty"`
+}
+
+var mu sync.Mutex
+
+// Path returns where the audit log is stored
+func Path() (string, error) {
+	if File != "" {
+		return File, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "tala", "audit.log"), nil
+}
+
+// Append adds an entry to the end of the log. The file is only ever opened
+// for appending, so earlier entries are never rewritten.
+func Append(entry Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadFrom returns the entries written after offset bytes into the log, and the
+// offset to continue from. A missing log has no entries. Lines that are not
+// valid entries, such as one still being written, are skipped.
+func ReadFrom(offset int64) ([]Entry, int64, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, offset, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		offset = 0 // the log was replaced
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var entries []Entry
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// An unterminated line is picked up again once it is complete
+			return entries, offset, nil
+		}
+		if err != nil {
+			return entries, offset, err
+		}
+		offset += int64(len(line))
+		var entry Entry
+		if json.Unmarshal(bytes.TrimSpace(line), &entry) == nil && entry.Tool != "" {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// Tail returns the last n entries (all of them when n <= 0) and the offset
+// where the log ends
+func Tail(n int) ([]Entry, int64, error) {
+	entries, offset, err := ReadFrom(0)
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, offset, err
+}
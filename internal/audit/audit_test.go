@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTestFile stores the audit log in a temporary directory for the duration of a test
+func useTestFile(t *testing.T) string {
+	File = filepath.Join(t.TempDir(), "audit.log")
+	t.Cleanup(func() {
+		File = ""
+	})
+	return File
+}
+
+func TestAppendAndTail(t *testing.T) {
+	useTestFile(t)
+
+	if entries, _, err := Tail(10); err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty log before anything ran, got %v, %v", entries, err)
+	}
+
+	for _, tool := range []string{"create_file", "execute_command", "delete_file"} {
+		err := Append(Entry{Tool: tool, Params: map[string]interface{}{"filename": "a.txt"}, Success: true, Prompt: "tidy up"})
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	entries, _, err := Tail(2)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Tool != "execute_command" || entries[1].Tool != "delete_file" {
+		t.Fatalf("Expected the last two entries, got %+v", entries)
+	}
+	if entries[1].Time.IsZero() || entries[1].Prompt != "tidy up" || entries[1].Params["filename"] != "a.txt" {
+		t.Errorf("Expected the entry to round-trip with a timestamp, got %+v", entries[1])
+	}
+}
+
+func TestReadFromContinues(t *testing.T) {
+	path := useTestFile(t)
+
+	Append(Entry{Tool: "create_file", Success: true})
+	_, offset, err := Tail(0)
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+
+	Append(Entry{Tool: "delete_file", Success: false, Error: "no such file"})
+	// A line still being written is left for the next read
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	file.WriteString(`{"tool":"move_f`)
+	file.Close()
+
+	entries, next, err := ReadFrom(offset)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Tool != "delete_file" || entries[0].Error != "no such file" {
+		t.Errorf("Expected only the new entry, got %+v", entries)
+	}
+	if entries, _, _ := ReadFrom(next); len(entries) != 0 {
+		t.Errorf("Expected the partial line to wait, got %+v", entries)
+	}
+}
+
+func TestLogIsPrivate(t *testing.T) {
+	path := useTestFile(t)
+
+	Append(Entry{Tool: "execute_command", Success: true})
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected the log to exist: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected mode 0600, got %o", perm)
+	}
+}
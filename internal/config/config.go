@@ -3,34 +3,133 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"tala/internal/fileops"
+	"tala/internal/logging"
 )
 
 type Config struct {
-	APIKey       string            `json:"api_key"`
-	Provider     string            `json:"provider"`
-	Model        string            `json:"model"`
-	Temperature  float64           `json:"temperature"`
-	MaxTokens    int               `json:"max_tokens"`
-	SystemPrompt string            `json:"system_prompt"`
+	APIKey         string  `json:"api_key"`
+	Provider       string  `json:"provider"`
+	Model          string  `json:"model"`
+	Temperature    float64 `json:"temperature"`
+	MaxTokens      int     `json:"max_tokens"`
+	SystemPrompt   string  `json:"system_prompt"`
+	ResponseFormat string  `json:"response_format"` // "" for free text, "json" for structured output
+	StopSequences  []string `json:"stop_sequences"` // generation ends before any of these
+	Seed           *int    `json:"seed,omitempty"` // fixed sampling seed for repeatable replies, unset = random
+	TopP             *float64 `json:"top_p,omitempty"`             // nucleus sampling, 0.0-1.0, unset = provider default
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`  // -2.0-2.0, positive favors new topics, unset = provider default
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"` // -2.0-2.0, positive discourages repetition, unset = provider default
+	ExtraParams    map[string]interface{} `json:"extra_params"` // passed to the provider as they are: OpenAI request body, Ollama options
+	BaseURL         string `json:"base_url"`          // API root for provider "openai-compatible", or another server for openai and ollama
+	AzureResource   string `json:"azure_resource"`    // Azure OpenAI resource name or endpoint URL, for provider "azure-openai"
+	AzureAPIVersion string `json:"azure_api_version"` // Azure OpenAI API version, empty = 2024-10-21
+	RequestTimeout int     `json:"request_timeout"` // seconds a provider request may take, 0 = 120
+	HTTPProxy      string  `json:"http_proxy"`      // proxy for provider requests, empty = HTTPS_PROXY/HTTP_PROXY
+	CACertFile     string  `json:"ca_cert_file"`    // PEM file of extra CA certificates to trust, e.g. a corporate root
+	ExtraHeaders   map[string]string `json:"extra_headers"` // sent with every provider request; built-in headers such as Authorization win
 	
 	// Global settings
 	EnableStreaming bool              `json:"enable_streaming"`
 	DefaultMode     string            `json:"default_mode"` // "tui", "gui", "headless"
 	CustomPrompts   map[string]string `json:"custom_prompts"`
 	Aliases         map[string]string `json:"aliases"`
+	Personas        map[string]string `json:"personas"` // user-defined persona system prompts
+	Persona         string            `json:"persona"`  // active persona, empty = system_prompt
+	Profiles        map[string]Profile `json:"profiles"` // named provider setups for --profile and /profile
+	ActiveProfile   string            `json:"-"`        // profile applied by UseProfile, empty = top-level settings
 	
 	// UI preferences
 	ShowTimestamps  bool   `json:"show_timestamps"`
 	ShowTokens      bool   `json:"show_tokens"`
 	CompactMode     bool   `json:"compact_mode"`
 	Theme           string `json:"theme"` // "default", "minimal", "colorful"
+	Language        string `json:"language"` // UI language code, empty = detect from $LANG
+	Editor          string `json:"editor"`   // command for /edit, empty = $VISUAL or $EDITOR
+	StatusLine      bool   `json:"status_line"` // pin provider, model, cwd and tokens to the bottom row of the TUI
+	HideThinking    bool   `json:"hide_thinking"` // strip <think> blocks from replies; shown in verbose mode
+	TypingDelayMs   *int   `json:"typing_delay_ms,omitempty"` // pause between paragraphs of a reply, 0 = instant, unset = 200
+	TypewriterEffect bool  `json:"typewriter_effect"` // reveal replies a character at a time; Ctrl+C skips ahead
+	TypewriterCPS   int    `json:"typewriter_cps"`    // typewriter speed in characters per second, 0 = 80
+	Unicode         *bool  `json:"unicode,omitempty"` // false prints ASCII instead of emoji and box drawing, unset = detect from the terminal
 	
 	// Session settings
 	SaveHistory     bool   `json:"save_history"`
 	HistoryLimit    int    `json:"history_limit"`
 	AutoSave        bool   `json:"auto_save"`
+	
+	// Tool settings
+	MaxCommandTimeout int      `json:"max_command_timeout"` // seconds, ceiling for execute_command
+	ConfirmCommandTimeout bool `json:"confirm_command_timeout"` // ask before killing a timed-out command instead of killing it at once
+	LineEndings       string   `json:"line_endings"`        // "preserve" (default), "lf" or "crlf" for written files
+	DefaultFileExtension string `json:"default_file_extension"` // for files named without one and no language mentioned, empty = ".txt"
+	UseTrash          bool     `json:"use_trash"`           // move deleted files to the tala trash instead of removing them
+	WritableDirs      []string `json:"writable_dirs"`       // if set, the AI may only write beneath these directories
+	ReadonlyDirs      []string `json:"readonly_dirs"`       // directories the AI may read but never write
+	ConfirmOutsideCwd bool     `json:"confirm_outside_cwd"` // ask before a tool acts on a path outside the current directory
+	GuardToolOutput   *bool    `json:"guard_tool_output,omitempty"` // mark tool output as untrusted data in prompts, unset = true
+	MaxToolOutput     int      `json:"max_tool_output"`   // bytes of tool output shown in full, 0 = 16000, -1 = no limit
+	RememberToolOutput *bool   `json:"remember_tool_output,omitempty"` // keep tool and /shell output as chat context for follow-ups, unset = true
+	ToolMemoryBudget  int      `json:"tool_memory_budget"` // most tokens of tool output remembered per turn, 0 = 1000
+	MaxToolCallsPerTurn   int  `json:"max_tool_calls_per_turn"`   // tool executions per response, 0 = 10, -1 = no limit
+	MaxToolCallsPerMinute int  `json:"max_tool_calls_per_minute"` // tool executions per minute, 0 = 30, -1 = no limit
+	AuditLog          *bool    `json:"audit_log,omitempty"`         // record executed tools in ~/.config/tala/audit.log, unset = true
+	SafeCommands      []string `json:"safe_commands"`    // command patterns execute_command runs without asking
+	RiskyCommands     []string `json:"risky_commands"`   // command patterns that need confirmation
+	BlockedCommands   []string `json:"blocked_commands"` // command patterns that never run
+	IntentPromptFile  string   `json:"intent_prompt_file"` // text/template asking which tools a request needs, empty = built in
+	IntentExamples    map[string]json.RawMessage `json:"intent_examples"` // request -> intents array shown to the detector, unset = built in
+	
+	// Files sent as system context with every request, re-read when they change
+	ContextFiles       []string `json:"context_files"`
+	ContextTokenBudget int      `json:"context_token_budget"` // most tokens of context files per request, 0 = 4000
+	
+	// Document retrieval from the index built by `tala index`
+	EmbeddingModel string `json:"embedding_model"` // empty = the provider's default embedding model
+	RetrievalTopK  int    `json:"retrieval_top_k"` // chunks added to each prompt, 0 = 4, -1 = off
+	
+	// Reuse the response to an earlier prompt that means the same thing
+	SemanticCache   bool    `json:"semantic_cache"`
+	CacheSimilarity float64 `json:"cache_similarity"` // cosine similarity needed for a hit, 0 = 0.95
+	
+	// Mock provider scripts, keyed by a case-insensitive substring of the prompt
+	MockResponses map[string]string          `json:"mock_responses"`
+	MockToolCalls map[string]json.RawMessage `json:"mock_tool_calls"` // arrays of {"name", "arguments"}
+	
+	// Ollama settings
+	AutoPullModels bool   `json:"auto_pull_models"` // pull a missing model on first use
+	PreloadModel   bool   `json:"preload_model"`    // load the model at startup to cut first-token latency
+	KeepAlive      string `json:"keep_alive"`       // how long Ollama keeps the model loaded ("30m", "-1" = forever)
+
+	// Diagnostics written to stderr
+	LogLevel  string `json:"log_level"`  // "debug", "info", "warn" (default) or "error"
+	LogFormat string `json:"log_format"` // "text" (default) or "json"
+
+	// Prometheus metrics for requests, tokens and tool calls, served at /metrics
+	MetricsAddr string `json:"metrics_addr"` // e.g. "localhost:9090", empty = off
+
+	// Top-level provider settings, kept while a profile is active
+	base *Profile
+}
+
+// Profile is a named provider setup. Fields left empty keep the top-level value.
+type Profile struct {
+	Provider    string   `json:"provider"`
+	Model       string   `json:"model"`
+	APIKey      string   `json:"api_key,omitempty"`
+	BaseURL     string   `json:"base_url,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
 }
 
 // Getter methods for provider creation
@@ -54,6 +153,291 @@ func (c *Config) GetMaxTokens() int {
 	return c.MaxTokens
 }
 
+// GetSystemPrompt returns the system prompt of the active persona, or SystemPrompt
+func (c *Config) GetSystemPrompt() string {
+	if c.Persona != "" {
+		if prompt, exists := c.GetPersona(c.Persona); exists {
+			return prompt
+		}
+	}
+	return c.SystemPrompt
+}
+
+// GetAutoPullModels reports whether missing Ollama models should be pulled automatically
+func (c *Config) GetAutoPullModels() bool {
+	return c.AutoPullModels
+}
+
+// GetResponseFormat returns the requested output format ("" or "json")
+func (c *Config) GetResponseFormat() string {
+	return c.ResponseFormat
+}
+
+// GetStopSequences returns the strings that end generation
+func (c *Config) GetStopSequences() []string {
+	return c.StopSequences
+}
+
+// GetSeed returns the sampling seed, or nil for a random one each request
+func (c *Config) GetSeed() *int {
+	return c.Seed
+}
+
+// GetTopP returns the nucleus sampling threshold, or nil for the provider default
+func (c *Config) GetTopP() *float64 {
+	return c.TopP
+}
+
+// GetPresencePenalty returns the presence penalty, or nil for the provider default
+func (c *Config) GetPresencePenalty() *float64 {
+	return c.PresencePenalty
+}
+
+// GetFrequencyPenalty returns the frequency penalty, or nil for the provider default
+func (c *Config) GetFrequencyPenalty() *float64 {
+	return c.FrequencyPenalty
+}
+
+// GetMockResponses returns the canned responses for the mock provider
+func (c *Config) GetMockResponses() map[string]string {
+	return c.MockResponses
+}
+
+// GetMockToolCalls returns the scripted tool calls for the mock provider
+func (c *Config) GetMockToolCalls() map[string]json.RawMessage {
+	return c.MockToolCalls
+}
+
+// GetKeepAlive returns how long Ollama should keep the model loaded between requests
+func (c *Config) GetKeepAlive() string {
+	return c.KeepAlive
+}
+
+// GetPreloadModel reports whether providers load their model as soon as they are created
+func (c *Config) GetPreloadModel() bool {
+	return c.PreloadModel
+}
+
+// GetHistoryLimit returns how many earlier messages are kept as chat context, 0 = no limit
+func (c *Config) GetHistoryLimit() int {
+	return c.HistoryLimit
+}
+
+// GetUseTrash reports whether deletions go to the trash
+func (c *Config) GetUseTrash() bool {
+	return c.UseTrash
+}
+
+// GetWritableDirs returns the directories the AI may write to; empty means anywhere
+func (c *Config) GetWritableDirs() []string {
+	return c.WritableDirs
+}
+
+// GetReadonlyDirs returns the directories the AI may not write to
+func (c *Config) GetReadonlyDirs() []string {
+	return c.ReadonlyDirs
+}
+
+// GetContextFiles returns the files sent as context with every request
+func (c *Config) GetContextFiles() []string {
+	return c.ContextFiles
+}
+
+// GetContextTokenBudget returns the most tokens of context files per request; 0 means the default
+func (c *Config) GetContextTokenBudget() int {
+	return c.ContextTokenBudget
+}
+
+// GetEmbeddingModel returns the embedding model; empty means the provider's default
+func (c *Config) GetEmbeddingModel() string {
+	return c.EmbeddingModel
+}
+
+// GetRetrievalTopK returns how many indexed chunks are added to each prompt; 0 means the default, negative turns retrieval off
+func (c *Config) GetRetrievalTopK() int {
+	return c.RetrievalTopK
+}
+
+// GetSemanticCache reports whether responses are reused for similar prompts
+func (c *Config) GetSemanticCache() bool {
+	return c.SemanticCache
+}
+
+// GetCacheSimilarity returns the similarity a prompt needs to reuse a cached response; 0 means the default
+func (c *Config) GetCacheSimilarity() float64 {
+	return c.CacheSimilarity
+}
+
+// GetGuardToolOutput reports whether tool output is marked as untrusted data in prompts, defaulting to true
+func (c *Config) GetGuardToolOutput() bool {
+	return c.GuardToolOutput == nil || *c.GuardToolOutput
+}
+
+// GetRememberToolOutput reports whether tool output is kept as chat context, defaulting to true
+func (c *Config) GetRememberToolOutput() bool {
+	return c.RememberToolOutput == nil || *c.RememberToolOutput
+}
+
+// GetToolMemoryBudget returns the most tokens of tool output remembered per turn; 0 means the default
+func (c *Config) GetToolMemoryBudget() int {
+	return c.ToolMemoryBudget
+}
+
+// GetSafeCommands returns the configured command patterns that run without confirmation
+func (c *Config) GetSafeCommands() []string {
+	return c.SafeCommands
+}
+
+// GetRiskyCommands returns the configured command patterns that need confirmation
+func (c *Config) GetRiskyCommands() []string {
+	return c.RiskyCommands
+}
+
+// GetBlockedCommands returns the configured command patterns that never run
+func (c *Config) GetBlockedCommands() []string {
+	return c.BlockedCommands
+}
+
+// GetIntentPromptFile returns the template file for the intent detection prompt
+func (c *Config) GetIntentPromptFile() string {
+	return c.IntentPromptFile
+}
+
+// GetIntentExamples returns the examples shown to the intent detector
+func (c *Config) GetIntentExamples() map[string]json.RawMessage {
+	return c.IntentExamples
+}
+
+// GetAuditLog reports whether executed tools are recorded in the audit log, defaulting to true
+func (c *Config) GetAuditLog() bool {
+	return c.AuditLog == nil || *c.AuditLog
+}
+
+// GetMaxToolOutput returns how many bytes of tool output are shown in full; 0 means the default, negative no limit
+func (c *Config) GetMaxToolOutput() int {
+	return c.MaxToolOutput
+}
+
+// GetMaxToolCallsPerTurn returns the tool executions allowed per response; 0 means the default, negative no limit
+func (c *Config) GetMaxToolCallsPerTurn() int {
+	return c.MaxToolCallsPerTurn
+}
+
+// GetMaxToolCallsPerMinute returns the tool executions allowed per minute; 0 means the default, negative no limit
+func (c *Config) GetMaxToolCallsPerMinute() int {
+	return c.MaxToolCallsPerMinute
+}
+
+// GetLineEndings returns the line-ending style for written files, defaulting to preserve
+func (c *Config) GetLineEndings() string {
+	if c.LineEndings == "" {
+		return fileops.LineEndingsPreserve
+	}
+	return c.LineEndings
+}
+
+// GetConfirmCommandTimeout reports whether a timed-out command asks before it is killed
+func (c *Config) GetConfirmCommandTimeout() bool {
+	return c.ConfirmCommandTimeout
+}
+
+// GetConfirmOutsideCwd reports whether tools ask before acting outside the current directory
+func (c *Config) GetConfirmOutsideCwd() bool {
+	return c.ConfirmOutsideCwd
+}
+
+// GetDefaultFileExtension returns the extension for new files named without
+// one, with its leading dot, defaulting to .txt
+func (c *Config) GetDefaultFileExtension() string {
+	ext := strings.TrimSpace(c.DefaultFileExtension)
+	if ext == "" {
+		return ".txt"
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// DefaultTypingDelay is the pause between reply paragraphs when typing_delay_ms is unset
+const DefaultTypingDelay = 200 * time.Millisecond
+
+// GetTypingDelay returns the pause between paragraphs of a reply; 0 shows replies at once
+func (c *Config) GetTypingDelay() time.Duration {
+	if c.TypingDelayMs == nil {
+		return DefaultTypingDelay
+	}
+	if *c.TypingDelayMs <= 0 {
+		return 0
+	}
+	return time.Duration(*c.TypingDelayMs) * time.Millisecond
+}
+
+// GetTypewriterCPS returns the typewriter speed in characters per second, defaulting to 80
+func (c *Config) GetTypewriterCPS() int {
+	if c.TypewriterCPS <= 0 {
+		return 80
+	}
+	return c.TypewriterCPS
+}
+
+// GetMaxCommandTimeout returns the shell command timeout ceiling, falling back to 30s
+func (c *Config) GetMaxCommandTimeout() time.Duration {
+	if c.MaxCommandTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.MaxCommandTimeout) * time.Second
+}
+
+// GetExtraParams returns the parameters passed to the provider as they are
+func (c *Config) GetExtraParams() map[string]interface{} {
+	return c.ExtraParams
+}
+
+// GetBaseURL returns the configured API root, empty for the provider's default
+func (c *Config) GetBaseURL() string {
+	return c.BaseURL
+}
+
+// GetAzureResource returns the Azure OpenAI resource name or endpoint URL
+func (c *Config) GetAzureResource() string {
+	return c.AzureResource
+}
+
+// GetAzureAPIVersion returns the Azure OpenAI API version, empty for the default
+func (c *Config) GetAzureAPIVersion() string {
+	return c.AzureAPIVersion
+}
+
+// GetRequestTimeout returns how long a provider request may take, 0 meaning the default
+func (c *Config) GetRequestTimeout() time.Duration {
+	if c.RequestTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.RequestTimeout) * time.Second
+}
+
+// GetHTTPProxy returns the configured proxy for provider requests
+func (c *Config) GetHTTPProxy() string {
+	return c.HTTPProxy
+}
+
+// GetExtraHeaders returns the headers added to provider requests
+func (c *Config) GetExtraHeaders() map[string]string {
+	return c.ExtraHeaders
+}
+
+// GetCACertFile returns the extra CA certificate file, with ~ expanded
+func (c *Config) GetCACertFile() string {
+	if c.CACertFile == "" {
+		return ""
+	}
+	if path, err := fileops.AbsPath(c.CACertFile); err == nil {
+		return path
+	}
+	return c.CACertFile
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Provider:     "ollama",
@@ -67,17 +451,24 @@ func DefaultConfig() *Config {
 		DefaultMode:     "tui",
 		CustomPrompts:   make(map[string]string),
 		Aliases:         make(map[string]string),
+		Personas:        make(map[string]string),
 		
 		// UI preferences
 		ShowTimestamps:  false,
 		ShowTokens:      true,
 		CompactMode:     false,
 		Theme:           "default",
+		StatusLine:      true,
+		HideThinking:    true,
 		
 		// Session settings
 		SaveHistory:     true,
 		HistoryLimit:    1000,
 		AutoSave:        true,
+		
+		// Tool settings
+		MaxCommandTimeout: 30,
+		UseTrash:          true,
 	}
 }
 
@@ -104,10 +495,34 @@ func Load() (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	config.clampRanges()
 
 	return &config, nil
 }
 
+// clampRanges corrects temperatures outside 0.0-2.0 from a hand-edited file,
+// with a warning, so providers never receive them. A negative max tokens is
+// left for Validate to reject, since no value can stand in for it.
+func (c *Config) clampRanges() {
+	c.Temperature = clampTemperature("temperature", c.Temperature)
+	for name, profile := range c.Profiles {
+		if profile.Temperature != nil {
+			temperature := clampTemperature("profiles."+name+".temperature", *profile.Temperature)
+			profile.Temperature = &temperature
+		}
+		c.Profiles[name] = profile
+	}
+}
+
+// clampTemperature returns temperature limited to 0.0-2.0, warning when it was outside
+func clampTemperature(key string, temperature float64) float64 {
+	clamped := math.Max(0, math.Min(2, temperature))
+	if clamped != temperature {
+		slog.Warn("config value out of range, clamped", "key", key, "value", temperature, "using", clamped)
+	}
+	return clamped
+}
+
 func (c *Config) Save() error {
 	configPath, err := getConfigPath()
 	if err != nil {
@@ -119,7 +534,11 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	// A profile only lasts for the session; save the top-level settings it replaced
+	saved := *c
+	saved.restoreBase()
+
+	data, err := json.MarshalIndent(&saved, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -137,7 +556,7 @@ var getConfigPath = func() (string, error) {
 }
 
 func (c *Config) Validate() error {
-	if c.Provider != "ollama" && c.APIKey == "" {
+	if needsAPIKey(c.Provider) && c.APIKey == "" {
 		return fmt.Errorf("API key is required for provider: %s", c.Provider)
 	}
 	if c.Provider == "" {
@@ -146,6 +565,175 @@ func (c *Config) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	if c.Provider == "azure-openai" && c.AzureResource == "" {
+		return fmt.Errorf("azure_resource is required for provider: azure-openai")
+	}
+	if c.Provider == "openai-compatible" && c.BaseURL == "" {
+		return fmt.Errorf("base_url is required for provider: openai-compatible")
+	}
+	if c.Persona != "" {
+		if _, exists := c.GetPersona(c.Persona); !exists {
+			return fmt.Errorf("unknown persona: %s", c.Persona)
+		}
+	}
+	return c.validateSettings()
+}
+
+// needsAPIKey reports whether a provider cannot be used without an API key.
+// Local servers, including most OpenAI-compatible ones, need none.
+func needsAPIKey(provider string) bool {
+	switch provider {
+	case "ollama", "mock", "openai-compatible":
+		return false
+	}
+	return true
+}
+
+// validateSettings checks the values of settings that have a fixed range or
+// set of choices, leaving out what is only required to make requests
+func (c *Config) validateSettings() error {
+	if err := ValidateTemperature(c.Temperature); err != nil {
+		return err
+	}
+	if err := ValidateMaxTokens(c.MaxTokens); err != nil {
+		return err
+	}
+	if c.TopP != nil {
+		if err := ValidateTopP(*c.TopP); err != nil {
+			return err
+		}
+	}
+	if c.PresencePenalty != nil {
+		if err := ValidatePenalty("presence_penalty", *c.PresencePenalty); err != nil {
+			return err
+		}
+	}
+	if c.FrequencyPenalty != nil {
+		if err := ValidatePenalty("frequency_penalty", *c.FrequencyPenalty); err != nil {
+			return err
+		}
+	}
+	for name, profile := range c.Profiles {
+		if profile.Temperature != nil {
+			if err := ValidateTemperature(*profile.Temperature); err != nil {
+				return fmt.Errorf("profile %s: %v", name, err)
+			}
+		}
+		if profile.MaxTokens != nil {
+			if err := ValidateMaxTokens(*profile.MaxTokens); err != nil {
+				return fmt.Errorf("profile %s: %v", name, err)
+			}
+		}
+		if err := validateBaseURL(profile.BaseURL); err != nil {
+			return fmt.Errorf("profile %s: %v", name, err)
+		}
+	}
+	if err := ValidateMode(c.DefaultMode); err != nil {
+		return err
+	}
+	if err := fileops.ValidateLineEndings(c.LineEndings); err != nil {
+		return err
+	}
+	if err := logging.ValidateLevel(c.LogLevel); err != nil {
+		return err
+	}
+	if err := logging.ValidateFormat(c.LogFormat); err != nil {
+		return err
+	}
+	for _, seq := range c.StopSequences {
+		if seq == "" {
+			return fmt.Errorf("stop_sequences must not contain an empty string")
+		}
+	}
+	if c.CacheSimilarity < 0 || c.CacheSimilarity > 1 {
+		return fmt.Errorf("cache_similarity must be between 0 and 1, got %v", c.CacheSimilarity)
+	}
+	if c.ResponseFormat != "" && c.ResponseFormat != "json" {
+		return fmt.Errorf("unsupported response format: %s (use \"json\" or leave empty)", c.ResponseFormat)
+	}
+	if err := validateBaseURL(c.BaseURL); err != nil {
+		return err
+	}
+	for name := range c.ExtraParams {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("extra_params must not contain an empty name")
+		}
+	}
+	for name, value := range c.ExtraHeaders {
+		if err := ValidateHeader(name, value); err != nil {
+			return fmt.Errorf("extra_headers: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateMode checks a launch mode; empty means the default, "tui"
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", "tui", "gui", "headless":
+		return nil
+	}
+	return fmt.Errorf("mode must be \"tui\", \"gui\" or \"headless\", got %q", mode)
+}
+
+// ValidateTemperature checks that a temperature is within the range accepted by providers
+func ValidateTemperature(temperature float64) error {
+	if temperature < 0.0 || temperature > 2.0 {
+		return fmt.Errorf("temperature must be between 0.0 and 2.0, got %g", temperature)
+	}
+	return nil
+}
+
+// ValidateTopP checks that a nucleus sampling threshold is a probability
+func ValidateTopP(topP float64) error {
+	if topP < 0.0 || topP > 1.0 {
+		return fmt.Errorf("top_p must be between 0.0 and 1.0, got %g", topP)
+	}
+	return nil
+}
+
+// ValidatePenalty checks that a presence or frequency penalty is within the
+// range OpenAI accepts
+func ValidatePenalty(name string, penalty float64) error {
+	if penalty < -2.0 || penalty > 2.0 {
+		return fmt.Errorf("%s must be between -2.0 and 2.0, got %g", name, penalty)
+	}
+	return nil
+}
+
+// ValidateHeader checks that name is a valid HTTP header name and value
+// cannot break out of its header line
+func ValidateHeader(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("header name must not be empty")
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return fmt.Errorf("header %s must not contain line breaks", name)
+	}
+	return nil
+}
+
+// ValidateMaxTokens checks that a max token limit is not negative (0 means unlimited)
+func ValidateMaxTokens(maxTokens int) error {
+	if maxTokens < 0 {
+		return fmt.Errorf("max tokens must be 0 (unlimited) or positive, got %d", maxTokens)
+	}
+	return nil
+}
+
+// validateBaseURL checks that a base_url, when set, is an http or https URL
+func validateBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return nil
+	}
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base_url must be an http or https URL, got %q", baseURL)
+	}
 	return nil
 }
 
@@ -215,3 +803,171 @@ func (c *Config) ListAliases() []string {
 	return aliases
 }
 
+
+// BuiltinPersonas are persona presets available without any configuration
+var BuiltinPersonas = map[string]string{
+	"concise":       "You are a helpful AI assistant. Answer as briefly as possible: no preamble, no repetition, short sentences.",
+	"teacher":       "You are a patient teacher. Explain concepts step by step, define jargon, and finish with a short example or check-for-understanding question.",
+	"code-reviewer": "You are an experienced code reviewer. Point out bugs, edge cases, security issues and unclear naming first, then suggest concrete improvements.",
+}
+
+// Persona management
+func (c *Config) AddPersona(name, prompt string) {
+	if c.Personas == nil {
+		c.Personas = make(map[string]string)
+	}
+	c.Personas[name] = prompt
+}
+
+// GetPersona returns the system prompt for a persona; user personas override built-ins
+func (c *Config) GetPersona(name string) (string, bool) {
+	if prompt, exists := c.Personas[name]; exists {
+		return prompt, true
+	}
+	prompt, exists := BuiltinPersonas[name]
+	return prompt, exists
+}
+
+func (c *Config) RemovePersona(name string) {
+	if c.Personas != nil {
+		delete(c.Personas, name)
+	}
+}
+
+// ListPersonas returns the names of all built-in and user personas, sorted
+func (c *Config) ListPersonas() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range BuiltinPersonas {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range c.Personas {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile management
+
+// UseProfile switches the provider, model, key and sampling settings to the named
+// profile. Switching always starts from the top-level settings, so nothing carries
+// over from the previous profile; "" (or "none"/"default") goes back to them.
+func (c *Config) UseProfile(name string) error {
+	if name == "none" || name == "default" {
+		name = ""
+	}
+	profile, exists := c.Profiles[name]
+	if name != "" && !exists {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+
+	if c.base == nil {
+		temperature, maxTokens := c.Temperature, c.MaxTokens
+		c.base = &Profile{
+			Provider:    c.Provider,
+			Model:       c.Model,
+			APIKey:      c.APIKey,
+			BaseURL:     c.BaseURL,
+			Temperature: &temperature,
+			MaxTokens:   &maxTokens,
+		}
+	}
+	c.restoreBase()
+	c.applyProfile(profile)
+	c.ActiveProfile = name
+	return nil
+}
+
+// restoreBase puts back the top-level settings a profile replaced
+func (c *Config) restoreBase() {
+	if c.base == nil {
+		return
+	}
+	c.Provider = c.base.Provider
+	c.Model = c.base.Model
+	c.APIKey = c.base.APIKey
+	c.BaseURL = c.base.BaseURL
+	c.Temperature = *c.base.Temperature
+	c.MaxTokens = *c.base.MaxTokens
+}
+
+// applyProfile copies the fields the profile sets onto the config
+func (c *Config) applyProfile(p Profile) {
+	if p.Provider != "" {
+		c.Provider = p.Provider
+	}
+	if p.Model != "" {
+		c.Model = p.Model
+	}
+	if p.APIKey != "" {
+		c.APIKey = p.APIKey
+	}
+	if p.BaseURL != "" {
+		c.BaseURL = p.BaseURL
+	}
+	if p.Temperature != nil {
+		c.Temperature = *p.Temperature
+	}
+	if p.MaxTokens != nil {
+		c.MaxTokens = *p.MaxTokens
+	}
+}
+
+// ForTarget returns a copy of the config set up for one model in a comparison.
+// target is a profile name or provider:model; a provider other than the current
+// one takes its API key and base_url from the top-level settings or the first
+// profile (by name) with one for it.
+func (c *Config) ForTarget(target string) (*Config, error) {
+	t := *c
+	if profile, ok := c.Profiles[target]; ok {
+		t.restoreBase()
+		t.applyProfile(profile)
+		return &t, nil
+	}
+
+	provider, model, ok := strings.Cut(target, ":")
+	if !ok || provider == "" || model == "" {
+		return nil, fmt.Errorf("target must be a profile name or provider:model, got %q", target)
+	}
+	t.Model = model
+	if provider != c.Provider {
+		t.Provider = provider
+		t.APIKey, t.BaseURL = "", ""
+		if c.base != nil && c.base.Provider == provider {
+			t.APIKey, t.BaseURL = c.base.APIKey, c.base.BaseURL
+		}
+		for _, name := range c.ListProfiles() {
+			p := c.Profiles[name]
+			if p.Provider != provider {
+				continue
+			}
+			if t.APIKey == "" {
+				t.APIKey = p.APIKey
+			}
+			if t.BaseURL == "" {
+				t.BaseURL = p.BaseURL
+			}
+		}
+	}
+	if needsAPIKey(t.Provider) && t.APIKey == "" {
+		return nil, fmt.Errorf("no API key for %s; add a profile with its api_key", t.Provider)
+	}
+	if t.Provider == "openai-compatible" && t.BaseURL == "" {
+		return nil, fmt.Errorf("no base_url for openai-compatible; add a profile with its base_url")
+	}
+	return &t, nil
+}
+
+// ListProfiles returns the names of the configured profiles, sorted
+func (c *Config) ListProfiles() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
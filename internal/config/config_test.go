@@ -1,8 +1,11 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -103,6 +106,51 @@ func TestConfigValidation(t *testing.T) {
 			},
 			hasErr: true,
 		},
+		{
+			name: "azure without resource",
+			config: &Config{
+				APIKey:   "test-key",
+				Provider: "azure-openai",
+				Model:    "gpt-4o-deployment",
+			},
+			hasErr: true,
+		},
+		{
+			name: "openai-compatible without key",
+			config: &Config{
+				Provider: "openai-compatible",
+				Model:    "qwen2.5-7b-instruct",
+				BaseURL:  "http://localhost:1234/v1",
+			},
+			hasErr: false,
+		},
+		{
+			name: "openai-compatible without base_url",
+			config: &Config{
+				Provider: "openai-compatible",
+				Model:    "qwen2.5-7b-instruct",
+			},
+			hasErr: true,
+		},
+		{
+			name: "base_url without scheme",
+			config: &Config{
+				Provider: "openai-compatible",
+				Model:    "qwen2.5-7b-instruct",
+				BaseURL:  "localhost:1234",
+			},
+			hasErr: true,
+		},
+		{
+			name: "azure with resource",
+			config: &Config{
+				APIKey:        "test-key",
+				Provider:      "azure-openai",
+				Model:         "gpt-4o-deployment",
+				AzureResource: "contoso",
+			},
+			hasErr: false,
+		},
 	}
 	
 	for _, tt := range tests {
@@ -116,4 +164,327 @@ func TestConfigValidation(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestGetMaxCommandTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.GetMaxCommandTimeout(); got != 30*time.Second {
+		t.Errorf("Expected default max command timeout 30s, got %v", got)
+	}
+	
+	cfg.MaxCommandTimeout = 600
+	if got := cfg.GetMaxCommandTimeout(); got != 10*time.Minute {
+		t.Errorf("Expected raised max command timeout 10m, got %v", got)
+	}
+	
+	cfg.MaxCommandTimeout = 0
+	if got := cfg.GetMaxCommandTimeout(); got != 30*time.Second {
+		t.Errorf("Expected unset max command timeout to fall back to 30s, got %v", got)
+	}
+}
+
+func TestGetTypingDelay(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.GetTypingDelay(); got != DefaultTypingDelay {
+		t.Errorf("Expected unset typing delay to default to %v, got %v", DefaultTypingDelay, got)
+	}
+
+	delay := 0
+	cfg.TypingDelayMs = &delay
+	if got := cfg.GetTypingDelay(); got != 0 {
+		t.Errorf("Expected typing_delay_ms 0 to disable the delay, got %v", got)
+	}
+
+	delay = 50
+	if got := cfg.GetTypingDelay(); got != 50*time.Millisecond {
+		t.Errorf("Expected 50ms typing delay, got %v", got)
+	}
+}
+
+func TestGetDefaultFileExtension(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.GetDefaultFileExtension(); got != ".txt" {
+		t.Errorf("Expected default file extension .txt, got %q", got)
+	}
+
+	for _, ext := range []string{".md", "md", " md "} {
+		cfg.DefaultFileExtension = ext
+		if got := cfg.GetDefaultFileExtension(); got != ".md" {
+			t.Errorf("Expected %q to give .md, got %q", ext, got)
+		}
+	}
+}
+
+func TestPersonas(t *testing.T) {
+	cfg := DefaultConfig()
+	
+	if cfg.GetSystemPrompt() != cfg.SystemPrompt {
+		t.Errorf("Expected system prompt without persona, got %q", cfg.GetSystemPrompt())
+	}
+	
+	cfg.Persona = "concise"
+	if cfg.GetSystemPrompt() != BuiltinPersonas["concise"] {
+		t.Errorf("Expected built-in concise persona prompt, got %q", cfg.GetSystemPrompt())
+	}
+	
+	cfg.AddPersona("concise", "Custom concise prompt")
+	cfg.AddPersona("pirate", "Talk like a pirate.")
+	if cfg.GetSystemPrompt() != "Custom concise prompt" {
+		t.Errorf("Expected user persona to override built-in, got %q", cfg.GetSystemPrompt())
+	}
+	
+	names := cfg.ListPersonas()
+	if len(names) != len(BuiltinPersonas)+1 {
+		t.Errorf("Expected %d personas, got %v", len(BuiltinPersonas)+1, names)
+	}
+	
+	cfg.Persona = "unknown"
+	cfg.Model = "test"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown persona")
+	}
+}
+
+func TestValidateRanges(t *testing.T) {
+	for _, temp := range []float64{0, 0.7, 2} {
+		if err := ValidateTemperature(temp); err != nil {
+			t.Errorf("Expected temperature %g to be valid, got %v", temp, err)
+		}
+	}
+	for _, temp := range []float64{-0.1, 2.1} {
+		if err := ValidateTemperature(temp); err == nil {
+			t.Errorf("Expected temperature %g to be rejected", temp)
+		}
+	}
+	
+	if err := ValidateMaxTokens(0); err != nil {
+		t.Errorf("Expected max tokens 0 to be valid, got %v", err)
+	}
+	if err := ValidateMaxTokens(-1); err == nil {
+		t.Error("Expected negative max tokens to be rejected")
+	}
+	
+	for _, mode := range []string{"", "tui", "gui", "headless"} {
+		if err := ValidateMode(mode); err != nil {
+			t.Errorf("Expected mode %q to be valid, got %v", mode, err)
+		}
+	}
+	if err := ValidateMode("web"); err == nil {
+		t.Error("Expected unknown mode to be rejected")
+	}
+	
+	for topP, valid := range map[float64]bool{0: true, 0.9: true, 1: true, -0.1: false, 1.1: false} {
+		if err := ValidateTopP(topP); (err == nil) != valid {
+			t.Errorf("ValidateTopP(%g) = %v, expected valid=%v", topP, err, valid)
+		}
+	}
+	for penalty, valid := range map[float64]bool{-2: true, 0: true, 1.5: true, 2: true, -2.5: false, 2.1: false} {
+		if err := ValidatePenalty("presence_penalty", penalty); (err == nil) != valid {
+			t.Errorf("ValidatePenalty(%g) = %v, expected valid=%v", penalty, err, valid)
+		}
+	}
+	
+	cfg := DefaultConfig()
+	if err := cfg.Set("top_p", "1.5"); err == nil {
+		t.Error("Expected top_p 1.5 to be rejected by Set")
+	}
+	if err := cfg.Set("frequency_penalty", "-1"); err != nil || cfg.FrequencyPenalty == nil || *cfg.FrequencyPenalty != -1 {
+		t.Errorf("Expected frequency_penalty -1 to be set, got %v", err)
+	}
+}
+
+func TestValidateMockProviderNeedsNoAPIKey(t *testing.T) {
+	cfg := &Config{Provider: "mock", Model: "demo"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected mock provider to validate without an API key, got %v", err)
+	}
+}
+
+func TestLineEndingsConfig(t *testing.T) {
+	cfg := &Config{Provider: "mock", Model: "demo"}
+	if got := cfg.GetLineEndings(); got != "preserve" {
+		t.Errorf("Expected default line endings 'preserve', got %q", got)
+	}
+
+	cfg.LineEndings = "crlf"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected crlf to be valid, got %v", err)
+	}
+
+	cfg.LineEndings = "dos"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown line endings to fail validation")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	zero := 0.0
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]Profile{
+		"work":  {Provider: "openai", Model: "gpt-4o", APIKey: "sk-work", Temperature: &zero},
+		"local": {Model: "llama3.1:8b"},
+	}
+
+	if err := cfg.UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile(work) failed: %v", err)
+	}
+	if cfg.Provider != "openai" || cfg.Model != "gpt-4o" || cfg.APIKey != "sk-work" || cfg.Temperature != 0 {
+		t.Errorf("Expected the work profile to be applied, got %s/%s/%s/%g", cfg.Provider, cfg.Model, cfg.APIKey, cfg.Temperature)
+	}
+
+	// Switching starts from the top-level settings, not the previous profile
+	if err := cfg.UseProfile("local"); err != nil {
+		t.Fatalf("UseProfile(local) failed: %v", err)
+	}
+	if cfg.Provider != "ollama" || cfg.Model != "llama3.1:8b" || cfg.APIKey != "" || cfg.Temperature != 0.7 {
+		t.Errorf("Expected local to keep top-level values it does not set, got %s/%s/%s/%g", cfg.Provider, cfg.Model, cfg.APIKey, cfg.Temperature)
+	}
+	if cfg.ActiveProfile != "local" {
+		t.Errorf("Expected active profile local, got %q", cfg.ActiveProfile)
+	}
+
+	if err := cfg.UseProfile("none"); err != nil || cfg.Model != "llama3.2:1b" || cfg.ActiveProfile != "" {
+		t.Errorf("Expected none to restore the top-level settings, got %s (%v)", cfg.Model, err)
+	}
+	if err := cfg.UseProfile("missing"); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+	if names := cfg.ListProfiles(); len(names) != 2 || names[0] != "local" {
+		t.Errorf("Expected sorted profile names, got %v", names)
+	}
+}
+
+func TestForTarget(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]Profile{
+		"work": {Provider: "openai", Model: "gpt-4o", APIKey: "sk-work"},
+	}
+
+	target, err := cfg.ForTarget("ollama:qwen2.5:7b")
+	if err != nil || target.Provider != "ollama" || target.Model != "qwen2.5:7b" {
+		t.Errorf("Expected ollama/qwen2.5:7b, got %+v (%v)", target, err)
+	}
+	target, err = cfg.ForTarget("openai:gpt-4o-mini")
+	if err != nil || target.Model != "gpt-4o-mini" || target.APIKey != "sk-work" {
+		t.Errorf("Expected the openai key from the work profile, got %+v (%v)", target, err)
+	}
+	target, err = cfg.ForTarget("work")
+	if err != nil || target.Provider != "openai" || target.Model != "gpt-4o" {
+		t.Errorf("Expected the work profile, got %+v (%v)", target, err)
+	}
+	if cfg.Provider != "ollama" || cfg.Model != "llama3.2:1b" {
+		t.Errorf("Expected ForTarget to leave the config unchanged, got %s/%s", cfg.Provider, cfg.Model)
+	}
+
+	if _, err := cfg.ForTarget("anthropic:claude-3-5-haiku-latest"); err == nil {
+		t.Error("Expected an error for a provider without an API key")
+	}
+	if _, err := cfg.ForTarget("llama3"); err == nil {
+		t.Error("Expected an error for a target that is neither a profile nor provider:model")
+	}
+}
+
+func TestProfileBaseURL(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BaseURL = "http://gpu-box:11434"
+	cfg.Profiles = map[string]Profile{
+		"studio": {Provider: "openai-compatible", Model: "qwen2.5-7b-instruct", BaseURL: "http://localhost:1234/v1"},
+	}
+
+	if err := cfg.UseProfile("studio"); err != nil || cfg.BaseURL != "http://localhost:1234/v1" {
+		t.Errorf("Expected the profile's base_url, got %q (%v)", cfg.BaseURL, err)
+	}
+	if err := cfg.UseProfile("none"); err != nil || cfg.BaseURL != "http://gpu-box:11434" {
+		t.Errorf("Expected the top-level base_url back, got %q (%v)", cfg.BaseURL, err)
+	}
+
+	target, err := cfg.ForTarget("studio")
+	if err != nil || target.BaseURL != "http://localhost:1234/v1" {
+		t.Errorf("Expected the studio profile's base_url, got %+v (%v)", target, err)
+	}
+	target, err = cfg.ForTarget("openai-compatible:llama-3.1-8b")
+	if err != nil || target.BaseURL != "http://localhost:1234/v1" {
+		t.Errorf("Expected the base_url of the first openai-compatible profile, got %+v (%v)", target, err)
+	}
+	target, err = cfg.ForTarget("mock:echo")
+	if err != nil || target.BaseURL != "" {
+		t.Errorf("Expected another provider not to inherit Ollama's base_url, got %+v (%v)", target, err)
+	}
+
+	cfg.Profiles["studio"] = Profile{Provider: "openai-compatible", BaseURL: "localhost:1234"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an invalid profile base_url to be rejected")
+	}
+}
+
+func TestSaveKeepsTopLevelSettingsUnderProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	originalGetConfigPath := getConfigPath
+	defer func() {
+		getConfigPath = originalGetConfigPath
+	}()
+	getConfigPath = func() (string, error) {
+		return configPath, nil
+	}
+
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]Profile{"work": {Provider: "openai", Model: "gpt-4o", APIKey: "sk-work"}}
+	if err := cfg.UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Provider != "ollama" || loaded.APIKey != "" {
+		t.Errorf("Expected the profile not to be saved as top-level settings, got %s/%s", loaded.Provider, loaded.APIKey)
+	}
+	if cfg.Provider != "openai" {
+		t.Errorf("Expected Save to leave the active profile applied, got %s", cfg.Provider)
+	}
+}
+
+func TestLoadClampsOutOfRangeValues(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalGetConfigPath := getConfigPath
+	defer func() {
+		getConfigPath = originalGetConfigPath
+	}()
+	configPath := filepath.Join(tmpDir, "config.json")
+	getConfigPath = func() (string, error) {
+		return configPath, nil
+	}
+	
+	data := `{"provider": "ollama", "model": "llama3", "temperature": 5.0, "max_tokens": -20,
+		"profiles": {"cold": {"provider": "ollama", "model": "llama3", "temperature": -1, "max_tokens": -5}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Temperature != 2.0 {
+		t.Errorf("Expected temperature 2.0, got %g", cfg.Temperature)
+	}
+	cold := cfg.Profiles["cold"]
+	if *cold.Temperature != 0 {
+		t.Errorf("Expected the profile temperature clamped to 0, got %g", *cold.Temperature)
+	}
+	// A negative max tokens is an error, not a silent switch to unlimited
+	if cfg.MaxTokens != -20 || *cold.MaxTokens != -5 {
+		t.Errorf("Expected max tokens to be kept as written, got %d and %d", cfg.MaxTokens, *cold.MaxTokens)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "max tokens") {
+		t.Errorf("Expected a negative max tokens to fail validation, got %v", err)
+	}
+	cfg.MaxTokens = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "profile cold") {
+		t.Errorf("Expected the profile's negative max tokens to fail validation, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tala/internal/logging"
+)
+
+// Setting is one configuration value as `tala config list` shows it
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Get returns a setting as text. Keys are the JSON names from the config
+// file, with dots reaching into maps and profiles, as in aliases.deploy or
+// profiles.work.model. Unset optional values are empty.
+func (c *Config) Get(key string) (string, error) {
+	v, err := lookup(reflect.ValueOf(c).Elem(), splitKey(key))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return formatValue(v), nil
+}
+
+// Set parses value for the type of the setting named by key and stores it
+// once the result is valid; c is left unchanged on error. An empty value
+// clears an optional setting, lists are comma-separated, and maps, profiles
+// and intent examples take JSON. Free-form values, such as extra_params
+// entries, are JSON when they parse as JSON and text otherwise.
+func (c *Config) Set(key, value string) error {
+	next, err := c.clone()
+	if err != nil {
+		return err
+	}
+	if err := assign(reflect.ValueOf(next).Elem(), splitKey(key), value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if err := next.validateSettings(); err != nil {
+		return err
+	}
+	next.ActiveProfile, next.base = c.ActiveProfile, c.base
+	*c = *next
+	return nil
+}
+
+// Settings returns every setting sorted by key, with one entry per map item.
+// Secrets are redacted.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	flatten("", reflect.ValueOf(c).Elem(), &settings)
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// clone returns a deep copy of the saved settings
+func (c *Config) clone() (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var copied Config
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+func splitKey(key string) []string {
+	return strings.Split(strings.TrimSpace(key), ".")
+}
+
+// settingField returns the field of a struct saved under the JSON name
+func settingField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == name && tag != "-" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// lookup follows a dotted key from v
+func lookup(v reflect.Value, parts []string) (reflect.Value, error) {
+	for _, part := range parts {
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := settingField(v, part)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("unknown setting %q", part)
+			}
+			v = field
+		case reflect.Map:
+			entry := v.MapIndex(reflect.ValueOf(part))
+			if !entry.IsValid() {
+				return reflect.Value{}, fmt.Errorf("no entry %q", part)
+			}
+			v = entry
+		default:
+			return reflect.Value{}, fmt.Errorf("%q is not a group of settings", part)
+		}
+	}
+	return v, nil
+}
+
+// assign stores raw at the dotted key below v, which must be settable.
+// Map entries are copied out, changed and stored back.
+func assign(v reflect.Value, parts []string, raw string) error {
+	if len(parts) == 0 {
+		return parseValue(v, raw)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := settingField(v, parts[0])
+		if !ok {
+			return fmt.Errorf("unknown setting %q", parts[0])
+		}
+		return assign(field, parts[1:], raw)
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(parts[0])
+		entry := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			entry.Set(existing)
+		}
+		if err := assign(entry, parts[1:], raw); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, entry)
+		return nil
+	}
+	return fmt.Errorf("%q is not a group of settings", parts[0])
+}
+
+// parseValue converts raw to the type of v and stores it
+func parseValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(json.RawMessage(nil)) {
+		if !json.Valid([]byte(raw)) {
+			return fmt.Errorf("%q is not valid JSON", raw)
+		}
+		v.SetBytes([]byte(raw))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		if raw == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := parseValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Interface:
+		// Passed-through values keep their JSON type; anything else is text
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+			parsed = raw
+		}
+		if parsed == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		v.Set(reflect.ValueOf(parsed))
+	case reflect.Map, reflect.Struct:
+		fresh := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(raw), fresh.Interface()); err != nil {
+			return fmt.Errorf("expected a JSON object: %v", err)
+		}
+		v.Set(fresh.Elem())
+	default:
+		return fmt.Errorf("cannot be set from the command line")
+	}
+	return nil
+}
+
+// formatValue renders a setting: strings as they are, unset pointers as
+// empty, lists comma-separated and anything else as JSON
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	case reflect.Slice:
+		if items, ok := v.Interface().([]string); ok {
+			return strings.Join(items, ",")
+		}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+// flatten lists the settings below v, expanding maps entry by entry
+func flatten(key string, v reflect.Value, settings *[]Setting) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.IsExported() && name != "" && name != "-" {
+				flatten(joinKey(key, name), v.Field(i), settings)
+			}
+		}
+		return
+	case reflect.Map:
+		for _, entry := range v.MapKeys() {
+			flatten(joinKey(key, entry.String()), v.MapIndex(entry), settings)
+		}
+		return
+	}
+
+	value := formatValue(v)
+	if IsSecret(key) && value != "" {
+		value = logging.Redacted
+	}
+	*settings = append(*settings, Setting{Key: key, Value: value})
+}
+
+// IsSecret reports whether the setting holds a credential, such as an API key
+// or an extra header, which usually carries a token, and is hidden when shown
+func IsSecret(key string) bool {
+	return key == "api_key" || strings.HasSuffix(key, ".api_key") ||
+		key == "extra_headers" || strings.HasPrefix(key, "extra_headers.")
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
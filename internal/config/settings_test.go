@@ -0,0 +1,107 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSetAndGet(t *testing.T) {
+	cfg := DefaultConfig()
+	settings := map[string]string{
+		"temperature":         "0.2",
+		"max_tokens":          "512",
+		"auto_save":           "false",
+		"aliases.deploy":      "git push",
+		"profiles.work.model": "llama3",
+		"seed":                "42",
+		"safe_commands":       "make,go test",
+	}
+	for key, value := range settings {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%q, %q) failed: %v", key, value, err)
+		}
+		if got, err := cfg.Get(key); err != nil || got != value {
+			t.Errorf("Get(%q) = %q, %v; expected %q", key, got, err, value)
+		}
+	}
+	if cfg.Temperature != 0.2 || cfg.Aliases["deploy"] != "git push" || cfg.Profiles["work"].Model != "llama3" || *cfg.Seed != 42 {
+		t.Errorf("Expected the settings to be stored, got %+v", cfg)
+	}
+
+	if err := cfg.Set("seed", ""); err != nil || cfg.Seed != nil {
+		t.Errorf("Expected an empty value to clear the seed, got %v, %v", cfg.Seed, err)
+	}
+}
+
+func TestSetRejectsInvalidValues(t *testing.T) {
+	cfg := DefaultConfig()
+	for key, value := range map[string]string{
+		"temperature":         "5",
+		"max_tokens":          "-1",
+		"auto_save":           "maybe",
+		"history_limit":       "many",
+		"default_mode":        "web",
+		"no_such_key":         "x",
+		"model.name":          "x",
+		"extra_headers":       `{"Bad Header": "x"}`,
+		"extra_headers.X-Org": "a\r\nInjected: 1",
+	} {
+		if err := cfg.Set(key, value); err == nil {
+			t.Errorf("Expected Set(%q, %q) to fail", key, value)
+		}
+	}
+	if cfg.Temperature != DefaultConfig().Temperature || cfg.DefaultMode != "tui" {
+		t.Errorf("Expected rejected values to leave the config unchanged, got %+v", cfg)
+	}
+}
+
+func TestSettingsRedactsAPIKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = "sk-secret"
+	cfg.ExtraHeaders = map[string]string{"Helicone-Auth": "Bearer secret"}
+	cfg.Aliases["deploy"] = "git push"
+	found := 0
+	for _, setting := range cfg.Settings() {
+		switch setting.Key {
+		case "api_key":
+			found++
+			if setting.Value == "sk-secret" {
+				t.Error("Expected the API key to be redacted")
+			}
+		case "extra_headers.Helicone-Auth":
+			found++
+			if setting.Value == "Bearer secret" {
+				t.Error("Expected the extra header to be redacted")
+			}
+		case "aliases.deploy":
+			found++
+			if setting.Value != "git push" {
+				t.Errorf("Expected the alias to be listed, got %q", setting.Value)
+			}
+		}
+	}
+	if found != 3 {
+		t.Errorf("Expected api_key, extra_headers.Helicone-Auth and aliases.deploy to be listed, found %d", found)
+	}
+}
+
+func TestSetExtraParams(t *testing.T) {
+	cfg := DefaultConfig()
+	for key, value := range map[string]string{
+		"extra_params.mirostat":   "2",
+		"extra_params.stop_token": "</s>",
+		"extra_params.logit_bias": `{"50256": -100}`,
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%q) failed: %v", key, err)
+		}
+	}
+	if cfg.ExtraParams["mirostat"] != 2.0 || cfg.ExtraParams["stop_token"] != "</s>" {
+		t.Errorf("Expected JSON values parsed and text kept as text, got %v", cfg.ExtraParams)
+	}
+	if _, ok := cfg.ExtraParams["logit_bias"].(map[string]interface{}); !ok {
+		t.Errorf("Expected a JSON object value, got %T", cfg.ExtraParams["logit_bias"])
+	}
+	if got, _ := cfg.Get("extra_params.logit_bias"); got != `{"50256":-100}` {
+		t.Errorf("Expected the object back as JSON, got %s", got)
+	}
+}
@@ -0,0 +1,140 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Directories the mutating functions may or may not write to, as absolute paths.
+// When WritableDirs is empty, writes are allowed anywhere outside ReadonlyDirs.
+// Shell commands run by execute_command are not covered.
+var (
+	WritableDirs []string
+	ReadonlyDirs []string
+)
+
+// SetWriteAccess resolves the configured directories against the current working
+// directory and installs them as WritableDirs and ReadonlyDirs
+func SetWriteAccess(writable, readonly []string) {
+	WritableDirs = resolveDirs(writable)
+	ReadonlyDirs = resolveDirs(readonly)
+}
+
+func resolveDirs(dirs []string) []string {
+	var resolved []string
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+		if path, err := resolvePath(dir); err == nil {
+			resolved = append(resolved, path)
+		}
+	}
+	return resolved
+}
+
+// resolvePath makes path absolute and resolves symlinks in the part of it that
+// exists, so a link cannot be used to write outside an allowed directory
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	existing, rest := abs, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return abs, nil
+	}
+	return filepath.Join(real, rest), nil
+}
+
+// AbsPath returns path as a clean absolute path, with a leading ~ standing for
+// the home directory. Relative paths are taken from the current directory.
+func AbsPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Abs(path)
+}
+
+// InCurrentDir reports whether path, once made absolute, is the current
+// directory or lies beneath it
+func InCurrentDir(path string) bool {
+	abs, err := AbsPath(path)
+	if err != nil {
+		return false
+	}
+	cwd, err := os.Getwd()
+	return err == nil && isWithin(abs, cwd)
+}
+
+// isWithin reports whether path is dir or lies beneath it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CheckWritable returns an error when path may not be written. The most specific
+// matching directory decides, so a writable ./src inside a read-only project works.
+func CheckWritable(path string) error {
+	if len(WritableDirs) == 0 && len(ReadonlyDirs) == 0 {
+		return nil
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+
+	match, readonly := "", false
+	for _, dir := range WritableDirs {
+		if isWithin(resolved, dir) && len(dir) > len(match) {
+			match, readonly = dir, false
+		}
+	}
+	for _, dir := range ReadonlyDirs {
+		if isWithin(resolved, dir) && len(dir) >= len(match) {
+			match, readonly = dir, true
+		}
+	}
+
+	switch {
+	case readonly:
+		return fmt.Errorf("'%s' is inside read-only directory '%s'", path, match)
+	case match == "" && len(WritableDirs) > 0:
+		return fmt.Errorf("'%s' is outside the writable directories (%s)", path, strings.Join(WritableDirs, ", "))
+	}
+	return nil
+}
+
+// writeDenied returns a failed FileOperation when any of paths may not be written
+func writeDenied(paths ...string) *FileOperation {
+	for _, path := range paths {
+		if err := CheckWritable(path); err != nil {
+			return &FileOperation{
+				Success: false,
+				Error:   err,
+				Message: fmt.Sprintf("Failed to write: %v", err),
+			}
+		}
+	}
+	return nil
+}
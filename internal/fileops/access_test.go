@@ -0,0 +1,101 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAccess(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src", "docs", "secrets", "src/generated", "other"} {
+		os.MkdirAll(filepath.Join(root, dir), 0750)
+	}
+	os.WriteFile(filepath.Join(root, "secrets", "key.txt"), []byte("secret"), 0600)
+
+	SetWriteAccess(
+		[]string{filepath.Join(root, "src"), filepath.Join(root, "docs")},
+		[]string{filepath.Join(root, "secrets"), filepath.Join(root, "src", "generated")},
+	)
+	defer SetWriteAccess(nil, nil)
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"src/main.go", true},
+		{"src/pkg/new/file.go", true},
+		{"docs/README.md", true},
+		{"secrets/key.txt", false},
+		{"src/generated/api.go", false},
+		{"other/file.txt", false},
+		{"top-level.txt", false},
+		{"src/../secrets/key.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := CheckWritable(filepath.Join(root, tt.path))
+			if tt.allowed && err != nil {
+				t.Errorf("Expected write to be allowed, got %v", err)
+			}
+			if !tt.allowed && err == nil {
+				t.Error("Expected write to be denied")
+			}
+		})
+	}
+}
+
+func TestWriteAccessDeniesMutations(t *testing.T) {
+	root := t.TempDir()
+	secrets := filepath.Join(root, "secrets")
+	key := filepath.Join(secrets, "key.txt")
+	os.MkdirAll(secrets, 0750)
+	os.WriteFile(key, []byte("secret"), 0600)
+
+	SetWriteAccess(nil, []string{secrets})
+	defer SetWriteAccess(nil, nil)
+
+	denied := []*FileOperation{
+		CreateFile(filepath.Join(secrets, "new.txt"), "x"),
+		UpdateFile(key, "changed"),
+		DeleteFile(key),
+		CreateDirectory(filepath.Join(secrets, "sub")),
+		DeleteDirectory(secrets),
+		CopyFile(key, filepath.Join(secrets, "copy.txt")),
+		MoveFile(key, filepath.Join(root, "stolen.txt")),
+	}
+	for i, result := range denied {
+		if result.Success || !strings.Contains(result.Message, "read-only") {
+			t.Errorf("Operation %d: expected a read-only error, got %q", i, result.Message)
+		}
+	}
+	if data, _ := os.ReadFile(key); string(data) != "secret" {
+		t.Errorf("Expected the read-only file to be untouched, got %q", data)
+	}
+
+	// Reads and writes elsewhere are unaffected
+	if result := ReadFile(key); !result.Success {
+		t.Errorf("Expected reads to be allowed, got %q", result.Message)
+	}
+	if result := CopyFile(key, filepath.Join(root, "copy.txt")); !result.Success {
+		t.Errorf("Expected copying out of a read-only directory to be allowed, got %q", result.Message)
+	}
+}
+
+func TestWriteAccessFollowsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src"), 0750)
+	os.MkdirAll(filepath.Join(root, "secrets"), 0750)
+	if err := os.Symlink(filepath.Join(root, "secrets"), filepath.Join(root, "src", "link")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	SetWriteAccess([]string{filepath.Join(root, "src")}, nil)
+	defer SetWriteAccess(nil, nil)
+
+	if err := CheckWritable(filepath.Join(root, "src", "link", "key.txt")); err == nil {
+		t.Error("Expected a symlink out of the writable directory to be denied")
+	}
+}
@@ -2,6 +2,7 @@ package fileops
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -13,8 +14,34 @@ type Command struct {
 	Execute     func(args []string) *FileOperation
 }
 
-// GetCommands returns all available file operation commands
+// CommandAliases maps familiar synonyms from other shells to the built-in commands
+var CommandAliases = map[string]string{
+	"dir":    "ls",
+	"type":   "cat",
+	"del":    "rm",
+	"erase":  "rm",
+	"md":     "mkdir",
+	"rd":     "rmdir",
+	"copy":   "cp",
+	"move":   "mv",
+	"ren":    "mv",
+	"rename": "mv",
+	"chdir":  "cd",
+}
+
+// GetCommands returns all available file operation commands, including the
+// CommandAliases synonyms, which share their target's Command
 func GetCommands() map[string]*Command {
+	commands := builtinCommands()
+	for alias, target := range CommandAliases {
+		if cmd, ok := commands[target]; ok {
+			commands[alias] = cmd
+		}
+	}
+	return commands
+}
+
+func builtinCommands() map[string]*Command {
 	return map[string]*Command{
 		"ls": {
 			Name:        "ls",
@@ -192,11 +219,18 @@ func GetHelpText() string {
 	var help strings.Builder
 	help.WriteString("Available file operations:\n\n")
 	
-	commands := GetCommands()
+	commands := builtinCommands()
 	for _, cmd := range commands {
 		help.WriteString(fmt.Sprintf("  %s - %s\n", cmd.Usage, cmd.Description))
 	}
 	
+	var aliases []string
+	for alias, target := range CommandAliases {
+		aliases = append(aliases, fmt.Sprintf("%s=%s", alias, target))
+	}
+	sort.Strings(aliases)
+	help.WriteString("\nAliases: " + strings.Join(aliases, ", ") + "\n")
+	
 	help.WriteString("\nExample usage:\n")
 	help.WriteString("  /ls                    # List current directory\n")
 	help.WriteString("  /cat myfile.txt        # Display file content\n")
@@ -238,6 +272,12 @@ func ExecuteCommand(input string) *FileOperation {
 		return cmd.Execute(args)
 	}
 	
+	if suggestion := SuggestCommand(commandName, CommandNames()...); suggestion != "" {
+		return &FileOperation{
+			Success: false,
+			Message: fmt.Sprintf("Unknown command: %s. Did you mean /%s?", commandName, suggestion),
+		}
+	}
 	return &FileOperation{
 		Success: false,
 		Message: fmt.Sprintf("Unknown command: %s. Type '/help' for available commands.", commandName),
@@ -232,4 +232,77 @@ func TestCommandExecution(t *testing.T) {
 			t.Error("cp command should fail with insufficient arguments")
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestSuggestCommand(t *testing.T) {
+	candidates := append(CommandNames(), "clear", "persona")
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"crete", "create"},
+		{"mkdr", "mkdir"},
+		{"persna", "persona"},
+		{"lss", "ls"},
+		{"create", ""},    // exact match needs no suggestion
+		{"x", ""},         // too short to guess
+		{"frobnicate", ""}, // nothing close
+	}
+
+	for _, tt := range tests {
+		if got := SuggestCommand(tt.input, candidates...); got != tt.want {
+			t.Errorf("SuggestCommand(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	result := ExecuteCommand("/crete notes.txt")
+	if result.Success || !strings.Contains(result.Message, "Did you mean /create?") {
+		t.Errorf("Expected a suggestion for /crete, got %q", result.Message)
+	}
+}
+
+func TestCommandAliases(t *testing.T) {
+	commands := GetCommands()
+
+	for alias, target := range map[string]string{
+		"dir": "ls", "type": "cat", "del": "rm", "erase": "rm", "md": "mkdir",
+		"rd": "rmdir", "copy": "cp", "move": "mv", "ren": "mv", "rename": "mv", "chdir": "cd",
+	} {
+		cmd, ok := commands[alias]
+		if !ok {
+			t.Errorf("Expected alias %s to be registered", alias)
+			continue
+		}
+		if cmd.Name != target {
+			t.Errorf("Expected %s to resolve to %s, got %s", alias, target, cmd.Name)
+		}
+	}
+}
+
+func TestCommandAliasesExecute(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("notes.txt", []byte("hello"), 0600)
+
+	if result := ExecuteCommand("/type notes.txt"); !result.Success || !strings.Contains(result.Message, "hello") {
+		t.Errorf("Expected /type to read the file, got %q", result.Message)
+	}
+	if result := ExecuteCommand("/dir"); !result.Success || !strings.Contains(result.Message, "notes.txt") {
+		t.Errorf("Expected /dir to list the directory, got %q", result.Message)
+	}
+	if result := ExecuteCommand("/ren notes.txt renamed.txt"); !result.Success {
+		t.Errorf("Expected /ren to move the file, got %q", result.Message)
+	}
+	if result := ExecuteCommand("/del renamed.txt"); !result.Success {
+		t.Errorf("Expected /del to delete the file, got %q", result.Message)
+	}
+	if _, err := os.Stat("renamed.txt"); !os.IsNotExist(err) {
+		t.Error("Expected renamed.txt to be deleted")
+	}
+}
@@ -0,0 +1,79 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DiskSpace is the capacity of the filesystem holding a path, in bytes
+type DiskSpace struct {
+	Total uint64
+	Free  uint64 // available to the current user
+	Used  uint64
+}
+
+// DiskUsage reports the total, used and free space of the filesystem that
+// holds path, without shelling out to df
+func DiskUsage(path string) (DiskSpace, error) {
+	abs, err := AbsPath(path)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	if !info.IsDir() {
+		abs = filepath.Dir(abs) // Windows only asks about directories
+	}
+	return diskSpace(abs)
+}
+
+// DirectorySize sums the sizes of the regular files under root, like du -s.
+// Unreadable entries are skipped and counted in skipped. report, if set,
+// hears about a long walk.
+func DirectorySize(root string, report ProgressFunc) (size int64, files, skipped int, err error) {
+	progress := startProgress(report, "sizing "+filepath.Base(root), 0)
+	defer progress.finish()
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			skipped++
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+		size += info.Size()
+		files++
+		progress.step()
+		return nil
+	})
+	return size, files, skipped, err
+}
+
+// FormatSize renders a byte count in binary units, e.g. "1.5 GiB"
+func FormatSize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 5; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
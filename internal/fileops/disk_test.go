@@ -0,0 +1,66 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskUsage(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	file := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(file, []byte("hello"), 0644)
+
+	for _, path := range []string{tmpDir, file} {
+		space, err := DiskUsage(path)
+		if err != nil {
+			t.Fatalf("DiskUsage(%s) error = %v", path, err)
+		}
+		if space.Total == 0 || space.Used > space.Total || space.Free > space.Total {
+			t.Errorf("DiskUsage(%s) = %+v, want used and free within a non-zero total", path, space)
+		}
+	}
+
+	if _, err := DiskUsage(filepath.Join(tmpDir, "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestDirectorySize(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	os.MkdirAll(filepath.Join(tmpDir, "sub", "deeper"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("12345"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sub", "b.txt"), []byte("1234567890"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sub", "deeper", "c.bin"), make([]byte, 2048), 0644)
+
+	size, files, skipped, err := DirectorySize(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("DirectorySize() error = %v", err)
+	}
+	if size != 2063 || files != 3 || skipped != 0 {
+		t.Errorf("DirectorySize() = %d bytes in %d files (%d skipped), want 2063 in 3", size, files, skipped)
+	}
+
+	if _, _, _, err := DirectorySize(filepath.Join(tmpDir, "missing"), nil); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[uint64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1024:            "1.0 KiB",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+		3 << 30:         "3.0 GiB",
+		1<<64 - 1:       "16.0 EiB",
+	}
+	for bytes, want := range tests {
+		if got := FormatSize(bytes); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
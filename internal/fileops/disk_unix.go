@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package fileops
+
+import "syscall"
+
+// diskSpace asks statfs for the capacity of the filesystem holding path
+func diskSpace(path string) (DiskSpace, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return DiskSpace{}, err
+	}
+	blockSize := uint64(st.Bsize)
+	total := uint64(st.Blocks) * blockSize
+	return DiskSpace{
+		Total: total,
+		Free:  uint64(st.Bavail) * blockSize,
+		Used:  total - uint64(st.Bfree)*blockSize,
+	}, nil
+}
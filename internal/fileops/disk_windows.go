@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package fileops
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// diskSpace asks GetDiskFreeSpaceEx for the capacity of the volume holding path
+func diskSpace(path string) (DiskSpace, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	var available, total, free uint64
+	ok, _, err := getDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&free)),
+	)
+	if ok == 0 {
+		return DiskSpace{}, err
+	}
+	return DiskSpace{Total: total, Free: available, Used: total - free}, nil
+}
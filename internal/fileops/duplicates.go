@@ -0,0 +1,206 @@
+package fileops
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Limits for FindDuplicates
+const (
+	DefaultDuplicateScanFiles = 10000
+	MaxDuplicateGroupsShown   = 50
+)
+
+// DuplicateOptions limit how much of a tree FindDuplicates reads
+type DuplicateOptions struct {
+	MaxFiles    int          // files considered at most; 0 = DefaultDuplicateScanFiles
+	MaxFileSize int64        // larger files are skipped; 0 = no limit
+	Progress    ProgressFunc // hears about a long scan; nil = silent
+}
+
+// DuplicateGroup is a set of files with identical content
+type DuplicateGroup struct {
+	Size  int64 // of each file
+	Paths []string
+}
+
+// Wasted is the space the copies beyond the first take up
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// DuplicateReport is what FindDuplicates found
+type DuplicateReport struct {
+	Root      string
+	Groups    []DuplicateGroup // most wasted space first
+	Scanned   int              // files considered
+	Large     int              // files skipped for exceeding MaxFileSize
+	Unread    int              // files that could not be read
+	Truncated bool             // the MaxFiles limit was reached
+}
+
+// Wasted is the space that removing every duplicate would reclaim
+func (r *DuplicateReport) Wasted() int64 {
+	var wasted int64
+	for _, g := range r.Groups {
+		wasted += g.Wasted()
+	}
+	return wasted
+}
+
+// FindDuplicates walks root for files with identical content. Files are first
+// grouped by size, so only those sharing a size with another are hashed. Empty
+// files, symlinks and the directories a tree skips, like .git, are ignored.
+func FindDuplicates(root string, opts DuplicateOptions) (*DuplicateReport, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", root)
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultDuplicateScanFiles
+	}
+
+	report := &DuplicateReport{Root: root}
+	bySize := make(map[int64][]string)
+	scanning := startProgress(opts.Progress, "scanning "+filepath.Base(root), 0)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if report.Scanned >= opts.MaxFiles {
+			report.Truncated = true
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() == 0 {
+			return nil
+		}
+		if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+			report.Large++
+			return nil
+		}
+		report.Scanned++
+		bySize[info.Size()] = append(bySize[info.Size()], path)
+		scanning.step()
+		return nil
+	})
+	scanning.finish()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := 0
+	for _, paths := range bySize {
+		if len(paths) > 1 {
+			candidates += len(paths)
+		}
+	}
+	hashing := startProgress(opts.Progress, "comparing files in "+filepath.Base(root), candidates)
+	defer hashing.finish()
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := make(map[[sha256.Size]byte][]string)
+		for _, path := range paths {
+			hashing.step()
+			sum, err := hashFile(path)
+			if err != nil {
+				report.Unread++
+				continue
+			}
+			byHash[sum] = append(byHash[sum], path)
+		}
+		for _, same := range byHash {
+			if len(same) > 1 {
+				sort.Strings(same)
+				report.Groups = append(report.Groups, DuplicateGroup{Size: size, Paths: same})
+			}
+		}
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Wasted() != b.Wasted() {
+			return a.Wasted() > b.Wasted()
+		}
+		return a.Paths[0] < b.Paths[0]
+	})
+	return report, nil
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// String lists the groups, largest waste first, with paths relative to the
+// root, showing at most MaxDuplicateGroupsShown of them
+func (r *DuplicateReport) String() string {
+	var b strings.Builder
+	if len(r.Groups) == 0 {
+		fmt.Fprintf(&b, "No duplicate files among %d files in '%s'", r.Scanned, r.Root)
+	} else {
+		fmt.Fprintf(&b, "Found %d groups of identical files among %d files in '%s'; removing the copies would free %s\n",
+			len(r.Groups), r.Scanned, r.Root, FormatSize(uint64(r.Wasted())))
+		for i, g := range r.Groups {
+			if i == MaxDuplicateGroupsShown {
+				fmt.Fprintf(&b, "\n... and %d more groups\n", len(r.Groups)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n%d files of %s each:\n", len(g.Paths), FormatSize(uint64(g.Size)))
+			for _, path := range g.Paths {
+				if rel, err := filepath.Rel(r.Root, path); err == nil {
+					path = rel
+				}
+				fmt.Fprintf(&b, "  %s\n", path)
+			}
+		}
+	}
+
+	var notes []string
+	if r.Truncated {
+		notes = append(notes, fmt.Sprintf("stopped after %d files", r.Scanned))
+	}
+	if r.Large > 0 {
+		notes = append(notes, fmt.Sprintf("%d files over the size limit were skipped", r.Large))
+	}
+	if r.Unread > 0 {
+		notes = append(notes, fmt.Sprintf("%d files could not be read", r.Unread))
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(&b, "\nNote: %s", strings.Join(notes, "; "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
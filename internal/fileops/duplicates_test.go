@@ -0,0 +1,94 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDuplicateTree(t *testing.T, root string) {
+	files := map[string]string{
+		"a.txt":          "same content",
+		"copy/a.txt":     "same content",
+		"copy/b.txt":     "same content",
+		"other.txt":      "diff content", // same size, different content
+		"big1.bin":       strings.Repeat("x", 4096),
+		"big2.bin":       strings.Repeat("x", 4096),
+		"empty1":         "",
+		"empty2":         "",
+		".git/objects/x": "same content",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	writeDuplicateTree(t, tmpDir)
+
+	report, err := FindDuplicates(tmpDir, DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+	if len(report.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", report.Groups)
+	}
+	big, small := report.Groups[0], report.Groups[1]
+	if big.Size != 4096 || len(big.Paths) != 2 {
+		t.Errorf("Expected the group wasting most space first, got %+v", big)
+	}
+	want := []string{
+		filepath.Join(tmpDir, "a.txt"),
+		filepath.Join(tmpDir, "copy", "a.txt"),
+		filepath.Join(tmpDir, "copy", "b.txt"),
+	}
+	if strings.Join(small.Paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, small.Paths)
+	}
+	if report.Wasted() != 4096+2*12 {
+		t.Errorf("Wasted() = %d", report.Wasted())
+	}
+
+	summary := report.String()
+	for _, wanted := range []string{"Found 2 groups", "3 files of 12 B each:\n  a.txt\n  " + filepath.Join("copy", "a.txt")} {
+		if !strings.Contains(summary, wanted) {
+			t.Errorf("Expected %q in:\n%s", wanted, summary)
+		}
+	}
+}
+
+func TestFindDuplicatesLimits(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	writeDuplicateTree(t, tmpDir)
+
+	report, err := FindDuplicates(tmpDir, DuplicateOptions{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+	if len(report.Groups) != 1 || report.Large != 2 {
+		t.Errorf("Expected the large files to be skipped, got %d groups and %d large", len(report.Groups), report.Large)
+	}
+	if !strings.Contains(report.String(), "2 files over the size limit were skipped") {
+		t.Errorf("Expected the skipped files to be noted:\n%s", report.String())
+	}
+
+	report, err = FindDuplicates(tmpDir, DuplicateOptions{MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+	if !report.Truncated || report.Scanned != 2 {
+		t.Errorf("Expected the scan to stop after 2 files, got %d (truncated %v)", report.Scanned, report.Truncated)
+	}
+
+	if _, err := FindDuplicates(filepath.Join(tmpDir, "a.txt"), DuplicateOptions{}); err == nil {
+		t.Error("Expected an error for a file")
+	}
+}
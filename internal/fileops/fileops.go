@@ -1,11 +1,17 @@
 package fileops
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tala/internal/glyphs"
 )
 
 // FileOperation represents a file system operation result
@@ -42,9 +48,9 @@ func ListDirectory(path string) *FileOperation {
 	result.WriteString(fmt.Sprintf("Contents of %s:\n", path))
 	
 	for _, entry := range entries {
-		prefix := "📄"
+		prefix := glyphs.File
 		if entry.IsDir() {
-			prefix = "📁"
+			prefix = glyphs.Dir
 		}
 		result.WriteString(fmt.Sprintf("%s %s\n", prefix, entry.Name()))
 	}
@@ -64,7 +70,12 @@ func CreateFile(filename string, content string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(filename); denied != nil {
+		return denied
+	}
+
 	// Create file with content
+	content = NormalizeLineEndings(content, LineEndings)
 	err := os.WriteFile(filename, []byte(content), 0600)
 	if err != nil {
 		return &FileOperation{
@@ -80,6 +91,53 @@ func CreateFile(filename string, content string) *FileOperation {
 	}
 }
 
+// Touch creates an empty file when path does not exist, otherwise it sets the
+// modification time to now, like the Unix touch
+func Touch(path string) *FileOperation {
+	if path == "" {
+		return &FileOperation{
+			Success: false,
+			Message: "Filename cannot be empty",
+		}
+	}
+
+	if denied := writeDenied(path); denied != nil {
+		return denied
+	}
+
+	now := time.Now()
+	err := os.Chtimes(path, now, now)
+	if err == nil {
+		return &FileOperation{
+			Success: true,
+			Message: fmt.Sprintf("Updated the timestamp of '%s'", path),
+		}
+	}
+	if !os.IsNotExist(err) {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to touch '%s': %v", path, err),
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to create file '%s': %v", path, err),
+		}
+	}
+	return &FileOperation{
+		Success: true,
+		Message: fmt.Sprintf("Created empty file '%s'", path),
+	}
+}
+
 // ReadFile reads and returns the content of a file
 func ReadFile(filename string) *FileOperation {
 	if filename == "" {
@@ -98,12 +156,37 @@ func ReadFile(filename string) *FileOperation {
 		}
 	}
 
+	// Dumping binary bytes would corrupt the terminal and waste AI context
+	if IsBinary(content) {
+		return &FileOperation{
+			Success: false,
+			Message: fmt.Sprintf("'%s' is a binary file, %d bytes, type %s", filename, len(content), http.DetectContentType(content)),
+		}
+	}
+
 	return &FileOperation{
 		Success: true,
 		Message: fmt.Sprintf("Content of '%s':\n%s", filename, string(content)),
 	}
 }
 
+// binarySniffLen is how much of a file IsBinary inspects
+const binarySniffLen = 8000
+
+// IsBinary reports whether data looks like binary rather than text: it contains a
+// NUL byte or is not valid UTF-8 within the first few kilobytes
+func IsBinary(data []byte) bool {
+	sample := data
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+		// Don't count a multi-byte character cut at the boundary as invalid
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return bytes.IndexByte(sample, 0) != -1 || !utf8.Valid(sample)
+}
+
 // UpdateFile updates an existing file with new content
 func UpdateFile(filename string, content string) *FileOperation {
 	if filename == "" {
@@ -113,6 +196,10 @@ func UpdateFile(filename string, content string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(filename); denied != nil {
+		return denied
+	}
+
 	// Check if file exists
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return &FileOperation{
@@ -121,6 +208,7 @@ func UpdateFile(filename string, content string) *FileOperation {
 		}
 	}
 
+	content = NormalizeLineEndings(content, LineEndings)
 	err := os.WriteFile(filename, []byte(content), 0600)
 	if err != nil {
 		return &FileOperation{
@@ -145,6 +233,10 @@ func DeleteFile(filename string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(filename); denied != nil {
+		return denied
+	}
+
 	// Check if file exists
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return &FileOperation{
@@ -153,7 +245,7 @@ func DeleteFile(filename string) *FileOperation {
 		}
 	}
 
-	err := os.Remove(filename)
+	trashed, err := removePath(filename, false)
 	if err != nil {
 		return &FileOperation{
 			Success: false,
@@ -162,6 +254,12 @@ func DeleteFile(filename string) *FileOperation {
 		}
 	}
 
+	if trashed {
+		return &FileOperation{
+			Success: true,
+			Message: fmt.Sprintf("Moved file '%s' to trash", filename),
+		}
+	}
 	return &FileOperation{
 		Success: true,
 		Message: fmt.Sprintf("Deleted file '%s'", filename),
@@ -177,6 +275,10 @@ func CreateDirectory(dirname string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(dirname); denied != nil {
+		return denied
+	}
+
 	err := os.MkdirAll(dirname, 0750)
 	if err != nil {
 		return &FileOperation{
@@ -201,6 +303,10 @@ func DeleteDirectory(dirname string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(dirname); denied != nil {
+		return denied
+	}
+
 	// Check if directory exists
 	if _, err := os.Stat(dirname); os.IsNotExist(err) {
 		return &FileOperation{
@@ -209,7 +315,7 @@ func DeleteDirectory(dirname string) *FileOperation {
 		}
 	}
 
-	err := os.RemoveAll(dirname)
+	trashed, err := removePath(dirname, true)
 	if err != nil {
 		return &FileOperation{
 			Success: false,
@@ -218,6 +324,12 @@ func DeleteDirectory(dirname string) *FileOperation {
 		}
 	}
 
+	if trashed {
+		return &FileOperation{
+			Success: true,
+			Message: fmt.Sprintf("Moved directory '%s' to trash", dirname),
+		}
+	}
 	return &FileOperation{
 		Success: true,
 		Message: fmt.Sprintf("Deleted directory '%s'", dirname),
@@ -233,6 +345,10 @@ func CopyFile(src, dst string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(dst); denied != nil {
+		return denied
+	}
+
 	// Check if source file exists
 	if _, err := os.Stat(src); os.IsNotExist(err) {
 		return &FileOperation{
@@ -285,6 +401,10 @@ func MoveFile(src, dst string) *FileOperation {
 		}
 	}
 
+	if denied := writeDenied(src, dst); denied != nil {
+		return denied
+	}
+
 	// Check if source file exists
 	if _, err := os.Stat(src); os.IsNotExist(err) {
 		return &FileOperation{
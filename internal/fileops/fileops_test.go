@@ -1,9 +1,12 @@
 package fileops
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // setupTestDir creates a temporary directory for testing
@@ -78,6 +81,52 @@ func TestCreateFile(t *testing.T) {
 	}
 }
 
+func TestTouchCreatesEmptyFile(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	path := filepath.Join(tmpDir, "notes.txt")
+
+	result := Touch(path)
+	if !result.Success {
+		t.Fatalf("Touch() failed: %s", result.Message)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected the file to be created: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("Expected an empty file, got %d bytes", info.Size())
+	}
+
+	if result := Touch(filepath.Join(tmpDir, "missing", "notes.txt")); result.Success {
+		t.Error("Expected Touch() to fail in a missing directory")
+	}
+	if result := Touch(""); result.Success {
+		t.Error("Expected Touch() to fail without a filename")
+	}
+}
+
+func TestTouchUpdatesTimestamp(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	path := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(path, []byte("keep me"), 0644)
+	old := time.Now().Add(-24 * time.Hour)
+	os.Chtimes(path, old, old)
+
+	result := Touch(path)
+	if !result.Success || !strings.Contains(result.Message, "Updated the timestamp") {
+		t.Fatalf("Touch() = %s", result.Message)
+	}
+	info, _ := os.Stat(path)
+	if time.Since(info.ModTime()) > time.Minute {
+		t.Errorf("Expected the modification time to be now, got %v", info.ModTime())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Errorf("Expected the content to be kept, got %q", data)
+	}
+}
+
 func TestReadFile(t *testing.T) {
 	tmpDir := setupTestDir(t)
 	defer cleanupTestDir(t, tmpDir)
@@ -132,6 +181,57 @@ func TestReadFile(t *testing.T) {
 	}
 }
 
+func TestReadFileBinaryGuard(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	// Minimal PNG: signature plus the start of an IHDR chunk
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R', 0, 0, 0, 1}
+	os.WriteFile("image.png", png, 0644)
+
+	result := ReadFile("image.png")
+	if result.Success {
+		t.Fatal("ReadFile() should refuse binary files")
+	}
+	if !contains(result.Message, "binary file, 20 bytes, type image/png") {
+		t.Errorf("ReadFile() message = %q, want size and content type", result.Message)
+	}
+
+	utf8Text := "héllo wörld, 世界 👋"
+	os.WriteFile("utf8.txt", []byte(utf8Text), 0644)
+
+	result = ReadFile("utf8.txt")
+	if !result.Success || !contains(result.Message, utf8Text) {
+		t.Errorf("ReadFile() should read UTF-8 text, got %q", result.Message)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	// A multi-byte character straddling the sniff boundary is still text
+	boundary := append(bytes.Repeat([]byte("a"), binarySniffLen-1), []byte("世界")...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", []byte{}, false},
+		{"ascii", []byte("plain text\n"), false},
+		{"nul byte", []byte("abc\x00def"), true},
+		{"invalid utf-8", []byte{0xff, 0xfe, 0xfd}, true},
+		{"rune cut at boundary", boundary, false},
+	}
+	for _, tt := range tests {
+		if got := IsBinary(tt.data); got != tt.want {
+			t.Errorf("IsBinary(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestDeleteFile(t *testing.T) {
 	tmpDir := setupTestDir(t)
 	defer cleanupTestDir(t, tmpDir)
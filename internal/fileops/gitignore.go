@@ -0,0 +1,145 @@
+package fileops
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is one compiled line of a .gitignore file
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool // "!pattern" re-includes a previously ignored path
+	dirOnly bool // "pattern/" only matches directories
+}
+
+// gitignore holds the patterns of one .gitignore file. Paths are matched
+// relative to base, the directory containing the file.
+type gitignore struct {
+	base     string
+	patterns []ignorePattern
+}
+
+// loadGitignore parses dir/.gitignore, returning nil when there is none
+func loadGitignore(dir string) *gitignore {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	ignore := &gitignore{base: dir}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(scanner.Text()); ok {
+			ignore.patterns = append(ignore.patterns, pattern)
+		}
+	}
+	if len(ignore.patterns) == 0 {
+		return nil
+	}
+	return ignore
+}
+
+// parseIgnorePattern compiles a single .gitignore line
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\") // escaped leading "#" or "!"
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the .gitignore directory;
+	// otherwise it matches a name at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "(^|/)" + expr + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// globToRegexp converts gitignore glob syntax (*, ?, [...], **) to a regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + class + "]")
+				i += end
+			} else {
+				b.WriteString(regexp.QuoteMeta("["))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignoreStack is the set of .gitignore files that apply to the current directory,
+// outermost first
+type ignoreStack []*gitignore
+
+// ignored reports whether path should be skipped. Later patterns override earlier
+// ones, and deeper .gitignore files override shallower ones, as in git.
+func (s ignoreStack) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, ignore := range s {
+		rel, err := filepath.Rel(ignore.base, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range ignore.patterns {
+			if p.dirOnly && !isDir {
+				continue
+			}
+			if p.re.MatchString(rel) {
+				ignored = !p.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,43 @@
+package fileops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Line-ending styles applied to content written by CreateFile and UpdateFile
+const (
+	LineEndingsPreserve = "preserve" // write content exactly as given
+	LineEndingsLF       = "lf"
+	LineEndingsCRLF     = "crlf"
+)
+
+// LineEndings is the style applied to written files. It defaults to preserving
+// the input and is set from the configuration via ai.ConfigureTools.
+var LineEndings = LineEndingsPreserve
+
+// ValidateLineEndings checks that style is one of the supported line-ending styles
+func ValidateLineEndings(style string) error {
+	switch style {
+	case "", LineEndingsPreserve, LineEndingsLF, LineEndingsCRLF:
+		return nil
+	}
+	return fmt.Errorf("line endings must be %q, %q or %q, got %q", LineEndingsPreserve, LineEndingsLF, LineEndingsCRLF, style)
+}
+
+// NormalizeLineEndings converts every line break in content to the given style.
+// Lone "\r" (classic Mac) breaks are converted too.
+func NormalizeLineEndings(content, style string) string {
+	switch style {
+	case LineEndingsLF, LineEndingsCRLF:
+	default:
+		return content
+	}
+
+	lf := strings.ReplaceAll(content, "\r\n", "\n")
+	lf = strings.ReplaceAll(lf, "\r", "\n")
+	if style == LineEndingsCRLF {
+		return strings.ReplaceAll(lf, "\n", "\r\n")
+	}
+	return lf
+}
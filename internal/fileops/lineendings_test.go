@@ -0,0 +1,76 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeLineEndings(t *testing.T) {
+	input := "one\r\ntwo\nthree\rfour"
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{LineEndingsPreserve, input},
+		{"", input},
+		{LineEndingsLF, "one\ntwo\nthree\nfour"},
+		{LineEndingsCRLF, "one\r\ntwo\r\nthree\r\nfour"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLineEndings(input, tt.style); got != tt.want {
+			t.Errorf("NormalizeLineEndings(%q) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLineEndings(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	defer func() { LineEndings = LineEndingsPreserve }()
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{LineEndingsPreserve, "a\r\nb\n"},
+		{LineEndingsLF, "a\nb\n"},
+		{LineEndingsCRLF, "a\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			LineEndings = tt.style
+			filename := filepath.Join(tmpDir, tt.style+".txt")
+
+			if result := CreateFile(filename, "a\r\nb\n"); !result.Success {
+				t.Fatalf("CreateFile failed: %v", result.Error)
+			}
+			data, _ := os.ReadFile(filename)
+			if string(data) != tt.want {
+				t.Errorf("CreateFile wrote %q, want %q", data, tt.want)
+			}
+
+			if result := UpdateFile(filename, "a\r\nb\n"); !result.Success {
+				t.Fatalf("UpdateFile failed: %v", result.Error)
+			}
+			data, _ = os.ReadFile(filename)
+			if string(data) != tt.want {
+				t.Errorf("UpdateFile wrote %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLineEndings(t *testing.T) {
+	for _, style := range []string{"", LineEndingsPreserve, LineEndingsLF, LineEndingsCRLF} {
+		if err := ValidateLineEndings(style); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", style, err)
+		}
+	}
+	if err := ValidateLineEndings("cr"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
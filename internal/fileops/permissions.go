@@ -0,0 +1,76 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// ParseMode reads an octal permission string such as "755" or "0644". Only
+// the read, write and execute bits may be set, not setuid, setgid or sticky.
+func ParseMode(mode string) (os.FileMode, error) {
+	if len(mode) < 3 || len(mode) > 4 {
+		return 0, fmt.Errorf("invalid mode '%s': expected three octal digits such as 755", mode)
+	}
+	bits, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mode '%s': expected three octal digits such as 755", mode)
+	}
+	if bits&^0777 != 0 {
+		return 0, fmt.Errorf("invalid mode '%s': setuid, setgid and sticky bits are not allowed", mode)
+	}
+	return os.FileMode(bits), nil
+}
+
+// Chmod sets the permissions of path to an octal mode such as "755". Windows
+// has no such permissions, so there it changes nothing.
+func Chmod(path, mode string) *FileOperation {
+	if path == "" {
+		return &FileOperation{
+			Success: false,
+			Message: "Path cannot be empty",
+		}
+	}
+
+	perm, err := ParseMode(mode)
+	if err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to set permissions: %v", err),
+		}
+	}
+
+	if denied := writeDenied(path); denied != nil {
+		return denied
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to set permissions of '%s': %v", path, err),
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return &FileOperation{
+			Success: true,
+			Message: fmt.Sprintf("Windows does not use Unix permissions, so '%s' was left unchanged", path),
+		}
+	}
+
+	if err := os.Chmod(path, perm); err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to set permissions of '%s': %v", path, err),
+		}
+	}
+	return &FileOperation{
+		Success: true,
+		Message: fmt.Sprintf("Changed permissions of '%s' from %s to %s", path, info.Mode().Perm(), perm),
+	}
+}
@@ -0,0 +1,61 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	valid := map[string]os.FileMode{"755": 0755, "0644": 0644, "600": 0600, "000": 0}
+	for mode, want := range valid {
+		if got, err := ParseMode(mode); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %o, %v, want %o", mode, got, err, want)
+		}
+	}
+	for _, mode := range []string{"", "75", "789", "rwx", "+x", "4755", "17777", "-755"} {
+		if _, err := ParseMode(mode); err == nil {
+			t.Errorf("ParseMode(%q) should fail", mode)
+		}
+	}
+}
+
+func TestChmod(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	script := filepath.Join(tmpDir, "run.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho hi\n"), 0644)
+
+	result := Chmod(script, "755")
+	if !result.Success {
+		t.Fatalf("Chmod() failed: %s", result.Message)
+	}
+	if runtime.GOOS == "windows" {
+		if !strings.Contains(result.Message, "left unchanged") {
+			t.Errorf("Expected a no-op on Windows, got %s", result.Message)
+		}
+		return
+	}
+	info, _ := os.Stat(script)
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 755, got %o", info.Mode().Perm())
+	}
+	if !strings.Contains(result.Message, "-rw-r--r-- to -rwxr-xr-x") {
+		t.Errorf("Expected the old and new modes in %q", result.Message)
+	}
+
+	if result := Chmod(script, "u+x"); result.Success {
+		t.Error("Expected an invalid mode to fail")
+	}
+	if result := Chmod(filepath.Join(tmpDir, "missing"), "644"); result.Success {
+		t.Error("Expected a missing file to fail")
+	}
+
+	defer SetWriteAccess(nil, nil)
+	SetWriteAccess(nil, []string{tmpDir})
+	if result := Chmod(script, "700"); result.Success {
+		t.Error("Expected a read-only directory to be refused")
+	}
+}
@@ -0,0 +1,47 @@
+package fileops
+
+import "time"
+
+// ProgressFunc is told how far a long operation has got: done of total files,
+// with total 0 while it is unknown. The last call has done equal to total.
+// Operations that walk whole trees, such as sizing a directory, listing a
+// project tree and finding duplicates, take one per call; it is only called
+// for operations still running after progressInterval, so quick ones stay
+// silent. A nil ProgressFunc reports nothing.
+type ProgressFunc func(operation string, done, total int)
+
+// progressInterval is how often a ProgressFunc hears about a running operation
+var progressInterval = 500 * time.Millisecond
+
+// progress reports one operation to its ProgressFunc, at most every progressInterval
+type progress struct {
+	report    ProgressFunc
+	operation string
+	total     int
+	done      int
+	last      time.Time
+	reported  bool
+}
+
+// startProgress begins reporting an operation over total files, 0 if unknown
+func startProgress(report ProgressFunc, operation string, total int) *progress {
+	return &progress{report: report, operation: operation, total: total, last: time.Now()}
+}
+
+// step counts a processed file
+func (p *progress) step() {
+	p.done++
+	if p.report == nil || time.Since(p.last) < progressInterval {
+		return
+	}
+	p.last = time.Now()
+	p.reported = true
+	p.report(p.operation, p.done, p.total)
+}
+
+// finish reports the end of an operation that was reported while running
+func (p *progress) finish() {
+	if p.report != nil && p.reported {
+		p.report(p.operation, p.done, p.done)
+	}
+}
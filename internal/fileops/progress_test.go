@@ -0,0 +1,63 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type progressCall struct {
+	operation   string
+	done, total int
+}
+
+// recordProgress returns a ProgressFunc that appends to calls
+func recordProgress(calls *[]progressCall) ProgressFunc {
+	return func(operation string, done, total int) {
+		*calls = append(*calls, progressCall{operation, done, total})
+	}
+}
+
+func TestProgressReportsLongOperations(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("%d.txt", i)), []byte("data"), 0644)
+	}
+	defer func(interval time.Duration) { progressInterval = interval }(progressInterval)
+	var calls []progressCall
+
+	// Quick operations stay silent
+	progressInterval = time.Hour
+	DirectorySize(tmpDir, recordProgress(&calls))
+	if len(calls) != 0 {
+		t.Fatalf("Expected no progress for a quick operation, got %v", calls)
+	}
+
+	progressInterval = 0
+	DirectorySize(tmpDir, recordProgress(&calls))
+	operation := "sizing " + filepath.Base(tmpDir)
+	if len(calls) != 6 || calls[0] != (progressCall{operation, 1, 0}) || calls[5] != (progressCall{operation, 5, 5}) {
+		t.Errorf("Expected each file and the end to be reported, with an unknown total until then, got %v", calls)
+	}
+
+	calls = nil
+	FindDuplicates(tmpDir, DuplicateOptions{Progress: recordProgress(&calls)})
+	if last := calls[len(calls)-1]; last != (progressCall{"comparing files in " + filepath.Base(tmpDir), 5, 5}) {
+		t.Errorf("Expected the duplicate scan to report its comparisons, got %v", calls)
+	}
+
+	calls = nil
+	os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+	ProjectTree(tmpDir, 0, recordProgress(&calls))
+	if last := calls[len(calls)-1]; last != (progressCall{"listing " + filepath.Base(tmpDir), 5, 5}) {
+		t.Errorf("Expected the tree walk to report the files it listed, got %v", calls)
+	}
+
+	// Without a callback nothing is reported, and nothing else is affected
+	if _, files, _, err := DirectorySize(tmpDir, nil); err != nil || files != 5 {
+		t.Errorf("DirectorySize() without progress = %d files, %v", files, err)
+	}
+}
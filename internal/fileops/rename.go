@@ -0,0 +1,149 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rename is one file name change planned by PlanRenames
+type Rename struct {
+	From string
+	To   string
+}
+
+// PlanRenames works out the new names of the files directly in dir when
+// pattern is replaced by replacement in their names, without renaming anything.
+// With regex the pattern is a regular expression and the replacement may use
+// $1-style groups. In either mode {lower} and {upper} in the replacement stand
+// for the matched text in lower or upper case, so ".*" and "{lower}" lowercase
+// every name. It fails when dir may not be written, or a new name is empty,
+// holds a path separator or collides with another file.
+func PlanRenames(dir, pattern, replacement string, regex bool) ([]Rename, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern cannot be empty")
+	}
+	var re *regexp.Regexp
+	if regex {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	} else {
+		re = regexp.MustCompile(regexp.QuoteMeta(pattern))
+	}
+
+	if err := CheckWritable(dir); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var renames []Rename
+	targets := make(map[string]string) // new name -> old name
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		newName := replaceName(re, name, replacement, regex)
+		if newName == name {
+			continue
+		}
+		if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+			return nil, fmt.Errorf("'%s' would be renamed to the invalid name '%s'", name, newName)
+		}
+		if other, taken := targets[newName]; taken {
+			return nil, fmt.Errorf("'%s' and '%s' would both be renamed to '%s'", other, name, newName)
+		}
+		if existing, err := os.Stat(filepath.Join(dir, newName)); err == nil {
+			// A case-only rename finds the file itself on case-insensitive systems
+			current, _ := entry.Info()
+			if current == nil || !os.SameFile(existing, current) {
+				return nil, fmt.Errorf("'%s' cannot be renamed to '%s', which already exists", name, newName)
+			}
+		}
+		targets[newName] = name
+		renames = append(renames, Rename{From: name, To: newName})
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+	return renames, nil
+}
+
+// replaceName applies the replacement to every match of re in name
+func replaceName(re *regexp.Regexp, name, replacement string, regex bool) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(name, -1) {
+		match := name[m[0]:m[1]]
+		result := replacement
+		if regex {
+			result = string(re.ExpandString(nil, replacement, name, m))
+		}
+		result = strings.ReplaceAll(result, "{lower}", strings.ToLower(match))
+		result = strings.ReplaceAll(result, "{upper}", strings.ToUpper(match))
+		b.WriteString(name[last:m[0]])
+		b.WriteString(result)
+		last = m[1]
+	}
+	b.WriteString(name[last:])
+	return b.String()
+}
+
+// ApplyRenames renames the planned files in dir. If one fails, those already
+// renamed are put back.
+func ApplyRenames(dir string, renames []Rename) error {
+	for i, r := range renames {
+		if err := os.Rename(filepath.Join(dir, r.From), filepath.Join(dir, r.To)); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(filepath.Join(dir, renames[j].To), filepath.Join(dir, renames[j].From))
+			}
+			return fmt.Errorf("renaming '%s' to '%s': %w", r.From, r.To, err)
+		}
+	}
+	return nil
+}
+
+// BulkRename renames the files in dir matching pattern, as planned by PlanRenames
+func BulkRename(dir, pattern, replacement string, regex bool) *FileOperation {
+	renames, err := PlanRenames(dir, pattern, replacement, regex)
+	if err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to rename files in '%s': %v", dir, err),
+		}
+	}
+	if len(renames) == 0 {
+		return &FileOperation{
+			Success: true,
+			Message: fmt.Sprintf("No file names in '%s' match '%s'", dir, pattern),
+		}
+	}
+	if err := ApplyRenames(dir, renames); err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to rename files in '%s': %v", dir, err),
+		}
+	}
+	return &FileOperation{
+		Success: true,
+		Message: fmt.Sprintf("Renamed %d files in '%s':\n%s", len(renames), dir, FormatRenames(renames)),
+	}
+}
+
+// FormatRenames lists planned renames one per line, as a preview
+func FormatRenames(renames []Rename) string {
+	lines := make([]string, len(renames))
+	for i, r := range renames {
+		lines[i] = fmt.Sprintf("  %s -> %s", r.From, r.To)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,106 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPlanRenames(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	writeFiles(t, tmpDir, "My Notes.TXT", "Photo 01.JPG", "ok.txt")
+	os.Mkdir(filepath.Join(tmpDir, "Sub Dir"), 0755)
+
+	tests := []struct {
+		name        string
+		pattern     string
+		replacement string
+		regex       bool
+		want        string
+	}{
+		{"spaces", " ", "_", false, "My Notes.TXT -> My_Notes.TXT, Photo 01.JPG -> Photo_01.JPG"},
+		{"prefix", "^", "old_", true, "My Notes.TXT -> old_My Notes.TXT, Photo 01.JPG -> old_Photo 01.JPG, ok.txt -> old_ok.txt"},
+		{"lowercase", ".*", "{lower}", true, "My Notes.TXT -> my notes.txt, Photo 01.JPG -> photo 01.jpg"},
+		{"groups", `^Photo (\d+)`, "img-$1", true, "Photo 01.JPG -> img-01.JPG"},
+		{"no match", "zzz", "y", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renames, err := PlanRenames(tmpDir, tt.pattern, tt.replacement, tt.regex)
+			if err != nil {
+				t.Fatalf("PlanRenames() error = %v", err)
+			}
+			var got []string
+			for _, r := range renames {
+				got = append(got, r.From+" -> "+r.To)
+			}
+			if strings.Join(got, ", ") != tt.want {
+				t.Errorf("PlanRenames() = %q, want %q", strings.Join(got, ", "), tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRenamesRejectsCollisions(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	writeFiles(t, tmpDir, "a-1.txt", "a_1.txt", "b-1.txt", "b 1.txt")
+
+	for _, tt := range []struct{ pattern, replacement, want string }{
+		{"-", "_", "already exists"},      // a-1.txt -> a_1.txt
+		{"^b[- ]", "b_", "would both be"}, // b-1.txt and b 1.txt -> b_1.txt
+		{"\\.txt$", "/x", "invalid name"},
+		{"[", "x", "invalid pattern"},
+	} {
+		if _, err := PlanRenames(tmpDir, tt.pattern, tt.replacement, true); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("PlanRenames(%q, %q) error = %v, want %q", tt.pattern, tt.replacement, err, tt.want)
+		}
+	}
+}
+
+func TestBulkRename(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	writeFiles(t, tmpDir, "one two.txt", "three.txt")
+
+	result := BulkRename(tmpDir, " ", "_", false)
+	if !result.Success {
+		t.Fatalf("BulkRename() failed: %s", result.Message)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "one_two.txt")); err != nil {
+		t.Errorf("Expected one_two.txt after renaming: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "three.txt")); err != nil {
+		t.Errorf("Expected three.txt to be left alone: %v", err)
+	}
+
+	err := ApplyRenames(tmpDir, []Rename{{From: "three.txt", To: "3.txt"}, {From: "missing.txt", To: "m.txt"}})
+	if err == nil {
+		t.Fatal("Expected renaming a missing file to fail")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "three.txt")); err != nil {
+		t.Errorf("Expected the earlier rename to be put back: %v", err)
+	}
+}
+
+func TestPlanRenamesHonoursReadonlyDirs(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+	defer SetWriteAccess(nil, nil)
+	writeFiles(t, tmpDir, "a b.txt")
+
+	SetWriteAccess(nil, []string{tmpDir})
+	if _, err := PlanRenames(tmpDir, " ", "_", false); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected renames in a read-only directory to be refused, got %v", err)
+	}
+}
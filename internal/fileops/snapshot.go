@@ -0,0 +1,132 @@
+package fileops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxSnapshotSize caps how many bytes a single snapshot may back up
+var MaxSnapshotSize int64 = 100 << 20
+
+// ErrSnapshotTooLarge reports that the paths hold more data than MaxSnapshotSize
+var ErrSnapshotTooLarge = errors.New("snapshot too large")
+
+// pathState is the captured state of one path
+type pathState struct {
+	path    string
+	exists  bool
+	mode    os.FileMode
+	content []byte // file content
+	backup  string // temporary copy of a directory
+}
+
+// Snapshot records the state of a set of paths so it can be put back later
+type Snapshot struct {
+	states []pathState
+	taken  time.Time
+}
+
+// TakeSnapshot captures the current state of paths: absent, a file's content, or a
+// copy of a directory tree
+func TakeSnapshot(paths ...string) (*Snapshot, error) {
+	snapshot := &Snapshot{taken: time.Now()}
+	var total int64
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			snapshot.Discard()
+			return nil, err
+		}
+
+		state := pathState{path: abs}
+		info, err := os.Stat(abs)
+		if os.IsNotExist(err) {
+			snapshot.states = append(snapshot.states, state)
+			continue
+		}
+		if err != nil {
+			snapshot.Discard()
+			return nil, err
+		}
+
+		size, err := pathSize(abs)
+		if err != nil {
+			snapshot.Discard()
+			return nil, err
+		}
+		if total += size; total > MaxSnapshotSize {
+			snapshot.Discard()
+			return nil, ErrSnapshotTooLarge
+		}
+
+		state.exists = true
+		state.mode = info.Mode().Perm()
+		if info.IsDir() {
+			backup, err := os.MkdirTemp("", "tala-snapshot-")
+			if err == nil {
+				state.backup = filepath.Join(backup, "data")
+				err = copyPath(abs, state.backup)
+			}
+			if err != nil {
+				snapshot.Discard()
+				return nil, err
+			}
+		} else {
+			if state.content, err = os.ReadFile(abs); err != nil {
+				snapshot.Discard()
+				return nil, err
+			}
+		}
+		snapshot.states = append(snapshot.states, state)
+	}
+	return snapshot, nil
+}
+
+// Paths returns the absolute paths covered by the snapshot
+func (s *Snapshot) Paths() []string {
+	paths := make([]string, len(s.states))
+	for i, state := range s.states {
+		paths[i] = state.path
+	}
+	return paths
+}
+
+// Restore puts every path back into its captured state, removing paths that did
+// not exist. Once everything is back, items moved to the trash from those paths
+// since the snapshot was taken are dropped, so a restored delete does not stay
+// in the trash as well. The snapshot stays usable until Discard is called.
+func (s *Snapshot) Restore() error {
+	for i := len(s.states) - 1; i >= 0; i-- {
+		state := s.states[i]
+		if err := os.RemoveAll(state.path); err != nil {
+			return err
+		}
+		if !state.exists {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(state.path), 0750); err != nil {
+			return err
+		}
+		if state.backup != "" {
+			if err := copyPath(state.backup, state.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(state.path, state.content, state.mode); err != nil {
+			return err
+		}
+	}
+	return removeTrashed(s.Paths(), s.taken)
+}
+
+// Discard frees any temporary copies held by the snapshot
+func (s *Snapshot) Discard() {
+	for _, state := range s.states {
+		if state.backup != "" {
+			_ = os.RemoveAll(filepath.Dir(state.backup))
+		}
+	}
+}
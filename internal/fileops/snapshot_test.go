@@ -0,0 +1,51 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	tree := filepath.Join(dir, "tree")
+	absent := filepath.Join(dir, "absent.txt")
+	os.WriteFile(file, []byte("original"), 0600)
+	os.MkdirAll(filepath.Join(tree, "sub"), 0750)
+	os.WriteFile(filepath.Join(tree, "sub", "a.txt"), []byte("a"), 0600)
+
+	snapshot, err := TakeSnapshot(file, tree, absent)
+	if err != nil {
+		t.Fatalf("TakeSnapshot failed: %v", err)
+	}
+	defer snapshot.Discard()
+
+	os.WriteFile(file, []byte("changed"), 0600)
+	os.RemoveAll(tree)
+	os.WriteFile(absent, []byte("new"), 0600)
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "original" {
+		t.Errorf("Expected file content restored, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tree, "sub", "a.txt")); string(data) != "a" {
+		t.Errorf("Expected directory restored, got %q", data)
+	}
+	if _, err := os.Stat(absent); !os.IsNotExist(err) {
+		t.Error("Expected path that did not exist to be removed")
+	}
+}
+
+func TestSnapshotTooLarge(t *testing.T) {
+	defer func(size int64) { MaxSnapshotSize = size }(MaxSnapshotSize)
+	MaxSnapshotSize = 3
+	file := filepath.Join(t.TempDir(), "big.txt")
+	os.WriteFile(file, []byte("too big"), 0600)
+
+	if _, err := TakeSnapshot(file); err != ErrSnapshotTooLarge {
+		t.Errorf("Expected ErrSnapshotTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package fileops
+
+import "sort"
+
+// maxSuggestionDistance is the largest edit distance still offered as a suggestion
+const maxSuggestionDistance = 2
+
+// CommandNames returns the names of the file operation commands, sorted
+func CommandNames() []string {
+	var names []string
+	for name := range GetCommands() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SuggestCommand returns the candidate closest to a mistyped command name, or ""
+// when nothing is close enough. Ties go to the earlier candidate.
+func SuggestCommand(name string, candidates ...string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range candidates {
+		if candidate == name {
+			return ""
+		}
+		d := levenshtein(name, candidate)
+		// Very short names would match almost anything at distance 2
+		if d < bestDistance && d < len([]rune(name)) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the number of single-rune edits turning a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
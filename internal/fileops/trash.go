@@ -0,0 +1,318 @@
+package fileops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UseTrash makes DeleteFile and DeleteDirectory move items to the trash instead of
+// removing them. It is set from the configuration via ai.ConfigureTools.
+var UseTrash = false
+
+// MaxTrashSize is the total size in bytes the trash may hold. Items that would not
+// fit are deleted permanently.
+var MaxTrashSize int64 = 1 << 30
+
+// TrashDir overrides the trash location, mainly for tests. When empty the trash
+// lives in $XDG_DATA_HOME/tala/trash (~/.local/share/tala/trash).
+var TrashDir = ""
+
+// errTrashFull reports that an item does not fit in the trash
+var errTrashFull = errors.New("trash is full")
+
+// TrashItem describes an entry in the trash
+type TrashItem struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	IsDir        bool      `json:"is_dir"`
+	Size         int64     `json:"size"`
+}
+
+// trashPaths returns the directories holding trashed data and their metadata
+func trashPaths() (files, info string, err error) {
+	dir := TrashDir
+	if dir == "" {
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", err
+			}
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		dir = filepath.Join(dataHome, "tala", "trash")
+	}
+	return filepath.Join(dir, "files"), filepath.Join(dir, "info"), nil
+}
+
+// MoveToTrash moves path into the trash, recording where it came from
+func MoveToTrash(path string) (*TrashItem, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := pathSize(abs)
+	if err != nil {
+		return nil, err
+	}
+	items, err := ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	var used int64
+	for _, item := range items {
+		used += item.Size
+	}
+	if used+size > MaxTrashSize {
+		return nil, errTrashFull
+	}
+
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return nil, err
+	}
+
+	item := &TrashItem{
+		ID:           fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(abs)),
+		OriginalPath: abs,
+		DeletedAt:    time.Now(),
+		IsDir:        stat.IsDir(),
+		Size:         size,
+	}
+	if err := movePath(abs, filepath.Join(filesDir, item.ID)); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(infoDir, item.ID+".json"), data, 0600)
+	}
+	if err != nil {
+		// Without metadata the item could never be restored, so put it back
+		_ = movePath(filepath.Join(filesDir, item.ID), abs)
+		return nil, err
+	}
+	return item, nil
+}
+
+// ListTrash returns the items in the trash, most recently deleted first
+func ListTrash() ([]TrashItem, error) {
+	_, infoDir, err := trashPaths()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(infoDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []TrashItem
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(infoDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var item TrashItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// RestoreFromTrash moves a trashed item back to its original location. The id may
+// be a unique prefix of the item's ID; an empty id restores the latest deletion.
+func RestoreFromTrash(id string) *FileOperation {
+	items, err := ListTrash()
+	if err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to read trash: %v", err),
+		}
+	}
+
+	var matches []TrashItem
+	for _, item := range items {
+		if id == "" || strings.HasPrefix(item.ID, id) {
+			matches = append(matches, item)
+		}
+	}
+	if len(matches) == 0 {
+		return &FileOperation{
+			Success: false,
+			Message: fmt.Sprintf("No trash item matches '%s'", id),
+		}
+	}
+	if id != "" && len(matches) > 1 {
+		return &FileOperation{
+			Success: false,
+			Message: fmt.Sprintf("'%s' matches %d trash items, use a longer ID", id, len(matches)),
+		}
+	}
+	item := matches[0]
+	if denied := writeDenied(item.OriginalPath); denied != nil {
+		return denied
+	}
+
+	if _, err := os.Stat(item.OriginalPath); err == nil {
+		return &FileOperation{
+			Success: false,
+			Message: fmt.Sprintf("Cannot restore: '%s' already exists", item.OriginalPath),
+		}
+	}
+
+	filesDir, infoDir, _ := trashPaths()
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0750); err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to restore '%s': %v", item.OriginalPath, err),
+		}
+	}
+	if err := movePath(filepath.Join(filesDir, item.ID), item.OriginalPath); err != nil {
+		return &FileOperation{
+			Success: false,
+			Error:   err,
+			Message: fmt.Sprintf("Failed to restore '%s': %v", item.OriginalPath, err),
+		}
+	}
+	_ = os.Remove(filepath.Join(infoDir, item.ID+".json"))
+
+	return &FileOperation{
+		Success: true,
+		Message: fmt.Sprintf("Restored '%s'", item.OriginalPath),
+	}
+}
+
+// removeTrashed deletes the trash items that were moved there from paths, or
+// from beneath them, since the given time
+func removeTrashed(paths []string, since time.Time) error {
+	items, err := ListTrash()
+	if err != nil || len(items) == 0 {
+		return err
+	}
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.DeletedAt.Before(since) {
+			continue
+		}
+		for _, path := range paths {
+			if isWithin(item.OriginalPath, path) {
+				if err := os.RemoveAll(filepath.Join(filesDir, item.ID)); err != nil {
+					return err
+				}
+				_ = os.Remove(filepath.Join(infoDir, item.ID+".json"))
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// removePath deletes path, moving it to the trash when enabled and it fits.
+// It reports whether the item went to the trash.
+func removePath(path string, isDir bool) (bool, error) {
+	if UseTrash {
+		if _, err := MoveToTrash(path); err == nil {
+			return true, nil
+		} else if !errors.Is(err, errTrashFull) {
+			return false, err
+		}
+	}
+	if isDir {
+		return false, os.RemoveAll(path)
+	}
+	return false, os.Remove(path)
+}
+
+// pathSize returns the total size of the files under path
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// movePath renames src to dst, copying across filesystems when a rename is not possible
+func movePath(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyPath(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath recursively copies a file or directory, keeping permissions
+func copyPath(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,101 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestTrash enables the trash in a temporary directory for the duration of a test
+func useTestTrash(t *testing.T) {
+	dir := t.TempDir()
+	TrashDir = filepath.Join(dir, "trash")
+	UseTrash = true
+	t.Cleanup(func() {
+		TrashDir = ""
+		UseTrash = false
+		MaxTrashSize = 1 << 30
+	})
+}
+
+func TestDeleteFileMovesToTrash(t *testing.T) {
+	useTestTrash(t)
+	filename := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(filename, []byte("keep me"), 0600)
+
+	result := DeleteFile(filename)
+	if !result.Success || !strings.Contains(result.Message, "trash") {
+		t.Fatalf("Expected file to be trashed, got %q", result.Message)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Expected file to be gone from its original location")
+	}
+
+	items, err := ListTrash()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("Expected one trash item, got %d (%v)", len(items), err)
+	}
+	if items[0].OriginalPath != filename || items[0].Size != 7 {
+		t.Errorf("Unexpected trash item: %+v", items[0])
+	}
+
+	if result := RestoreFromTrash(items[0].ID); !result.Success {
+		t.Fatalf("Restore failed: %s", result.Message)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil || string(data) != "keep me" {
+		t.Errorf("Expected restored content, got %q (%v)", data, err)
+	}
+	if items, _ := ListTrash(); len(items) != 0 {
+		t.Errorf("Expected trash to be empty after restore, got %d items", len(items))
+	}
+}
+
+func TestDeleteDirectoryMovesToTrash(t *testing.T) {
+	useTestTrash(t)
+	dir := filepath.Join(t.TempDir(), "project")
+	os.MkdirAll(filepath.Join(dir, "src"), 0750)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main"), 0600)
+
+	if result := DeleteDirectory(dir); !result.Success {
+		t.Fatalf("DeleteDirectory failed: %s", result.Message)
+	}
+	if result := RestoreFromTrash(""); !result.Success {
+		t.Fatalf("Restore of latest item failed: %s", result.Message)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "main.go")); err != nil {
+		t.Errorf("Expected directory contents to be restored: %v", err)
+	}
+}
+
+func TestTrashFallsBackWhenFull(t *testing.T) {
+	useTestTrash(t)
+	MaxTrashSize = 4
+	filename := filepath.Join(t.TempDir(), "big.txt")
+	os.WriteFile(filename, []byte("too large"), 0600)
+
+	result := DeleteFile(filename)
+	if !result.Success || strings.Contains(result.Message, "trash") {
+		t.Fatalf("Expected permanent delete, got %q", result.Message)
+	}
+	if items, _ := ListTrash(); len(items) != 0 {
+		t.Errorf("Expected nothing in the trash, got %d items", len(items))
+	}
+}
+
+func TestRestoreRefusesToOverwrite(t *testing.T) {
+	useTestTrash(t)
+	filename := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(filename, []byte("old"), 0600)
+	DeleteFile(filename)
+	os.WriteFile(filename, []byte("new"), 0600)
+
+	if result := RestoreFromTrash(""); result.Success {
+		t.Error("Expected restore to refuse overwriting an existing file")
+	}
+	data, _ := os.ReadFile(filename)
+	if string(data) != "new" {
+		t.Errorf("Expected existing file to be untouched, got %q", data)
+	}
+}
@@ -0,0 +1,183 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tala/internal/glyphs"
+)
+
+// Limits for the directory tree included by ReadFileWithContext
+const (
+	DefaultContextDepth = 2
+	MaxContextDepth     = 4
+	MaxContextEntries   = 100
+)
+
+// Limits for ProjectTree
+const (
+	DefaultProjectTreeDepth = 3
+	MaxProjectTreeDepth     = 6
+	MaxProjectTreeEntries   = 500
+)
+
+// skippedDirs are never descended into when rendering a tree
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+// projectSkippedDirs adds dependency, build and tooling directories that
+// ProjectTree leaves out even without a .gitignore
+var projectSkippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+	".venv":        true,
+	"venv":         true,
+	".idea":        true,
+	".vscode":      true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+}
+
+// DirectoryTree renders the structure under root like `tree`, descending at most
+// maxDepth levels and listing at most maxEntries entries. .gitignore files found
+// along the way are honoured.
+func DirectoryTree(root string, maxDepth, maxEntries int) (string, error) {
+	return renderTree(root, maxDepth, maxEntries, skippedDirs, nil)
+}
+
+// ProjectTree renders a project's layout for the AI: like DirectoryTree, but also
+// skipping common dependency and build directories, capped at MaxProjectTreeEntries.
+// report, if set, hears about a long walk.
+func ProjectTree(root string, maxDepth int, report ProgressFunc) (string, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultProjectTreeDepth
+	}
+	if maxDepth > MaxProjectTreeDepth {
+		maxDepth = MaxProjectTreeDepth
+	}
+	return renderTree(root, maxDepth, MaxProjectTreeEntries, projectSkippedDirs, report)
+}
+
+// treeWalker carries the limits and state of a single tree rendering
+type treeWalker struct {
+	b          strings.Builder
+	maxDepth   int
+	maxEntries int
+	count      int
+	skip       map[string]bool
+	progress   *progress
+}
+
+func renderTree(root string, maxDepth, maxEntries int, skip map[string]bool, report ProgressFunc) (string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("'%s' is not a directory", root)
+	}
+
+	w := &treeWalker{maxDepth: maxDepth, maxEntries: maxEntries, skip: skip,
+		progress: startProgress(report, "listing "+filepath.Base(root), 0)}
+	defer w.progress.finish()
+	w.b.WriteString(filepath.Base(root) + "/\n")
+	if w.write(root, "", 1, nil) {
+		w.b.WriteString(fmt.Sprintf("... (truncated after %d entries)\n", maxEntries))
+	}
+	return w.b.String(), nil
+}
+
+// write renders the entries of dir and reports whether the entry limit was hit
+func (w *treeWalker) write(dir, prefix string, depth int, ignores ignoreStack) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	if ignore := loadGitignore(dir); ignore != nil {
+		ignores = append(ignores[:len(ignores):len(ignores)], ignore)
+	}
+
+	var visible []os.DirEntry
+	for _, entry := range entries {
+		if entry.IsDir() && w.skip[entry.Name()] {
+			continue
+		}
+		if ignores.ignored(filepath.Join(dir, entry.Name()), entry.IsDir()) {
+			continue
+		}
+		visible = append(visible, entry)
+	}
+	// Directories first, then files, each alphabetically
+	sort.SliceStable(visible, func(i, j int) bool {
+		if visible[i].IsDir() != visible[j].IsDir() {
+			return visible[i].IsDir()
+		}
+		return visible[i].Name() < visible[j].Name()
+	})
+
+	for i, entry := range visible {
+		if w.count >= w.maxEntries {
+			return true
+		}
+		w.count++
+		if !entry.IsDir() {
+			w.progress.step()
+		}
+
+		connector, childPrefix := glyphs.Tee, prefix+glyphs.Pipe
+		if i == len(visible)-1 {
+			connector, childPrefix = glyphs.Elbow, prefix+"    "
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		w.b.WriteString(prefix + connector + name + "\n")
+
+		if entry.IsDir() && depth < w.maxDepth {
+			if w.write(filepath.Join(dir, entry.Name()), childPrefix, depth+1, ignores) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadFileWithContext reads a file and appends a small tree of its directory,
+// giving the AI the surrounding project layout along with the content
+func ReadFileWithContext(filename string, depth int) *FileOperation {
+	result := ReadFile(filename)
+	if !result.Success {
+		return result
+	}
+
+	if depth <= 0 {
+		depth = DefaultContextDepth
+	}
+	if depth > MaxContextDepth {
+		depth = MaxContextDepth
+	}
+
+	dir := filepath.Dir(filename)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	tree, err := DirectoryTree(dir, depth, MaxContextEntries)
+	if err != nil {
+		// The content is still useful without the layout
+		return result
+	}
+
+	result.Message += fmt.Sprintf("\n\nDirectory structure around '%s':\n%s", filename, tree)
+	return result
+}
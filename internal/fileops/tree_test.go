@@ -0,0 +1,159 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectoryTree(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	os.MkdirAll(filepath.Join(tmpDir, "pkg", "deep", "deeper"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "node_modules", "lib"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "pkg", "util.go"), []byte("package pkg"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "pkg", "deep", "deeper", "hidden.go"), []byte("package deeper"), 0644)
+
+	tree, err := DirectoryTree(tmpDir, 2, 100)
+	if err != nil {
+		t.Fatalf("DirectoryTree() error = %v", err)
+	}
+
+	expected := filepath.Base(tmpDir) + "/\n" +
+		"├── pkg/\n" +
+		"│   ├── deep/\n" +
+		"│   └── util.go\n" +
+		"└── main.go\n"
+	if tree != expected {
+		t.Errorf("DirectoryTree() =\n%s\nwant\n%s", tree, expected)
+	}
+}
+
+func TestDirectoryTreeEntryLimit(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	for i := 0; i < 10; i++ {
+		os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("file%d.txt", i)), []byte("x"), 0644)
+	}
+
+	tree, err := DirectoryTree(tmpDir, 1, 3)
+	if err != nil {
+		t.Fatalf("DirectoryTree() error = %v", err)
+	}
+	if strings.Count(tree, "file") != 3 || !strings.Contains(tree, "truncated after 3 entries") {
+		t.Errorf("Expected 3 entries and a truncation note, got:\n%s", tree)
+	}
+}
+
+func TestReadFileWithContext(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.Mkdir("src", 0755)
+	os.WriteFile(filepath.Join("src", "app.go"), []byte("package src"), 0644)
+	os.WriteFile(filepath.Join("src", "app_test.go"), []byte("package src"), 0644)
+
+	result := ReadFileWithContext(filepath.Join("src", "app.go"), 0)
+	if !result.Success {
+		t.Fatalf("ReadFileWithContext() failed: %s", result.Message)
+	}
+	if !contains(result.Message, "package src") {
+		t.Error("Expected file content in result")
+	}
+	if !contains(result.Message, "Directory structure") || !contains(result.Message, "app_test.go") {
+		t.Errorf("Expected sibling files in the tree, got:\n%s", result.Message)
+	}
+
+	if result := ReadFileWithContext("missing.go", 0); result.Success {
+		t.Error("Expected failure for a missing file")
+	}
+}
+
+func TestParseIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "debug.txt", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"/root.txt", "root.txt", false, true},
+		{"/root.txt", "sub/root.txt", false, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"**/cache", "a/b/cache", true, true},
+		{"out/**", "out/x/y", false, true},
+		{"file?.txt", "file1.txt", false, true},
+		{"[abc].go", "b.go", false, true},
+		{"[!abc].go", "b.go", false, false},
+	}
+
+	for _, tt := range tests {
+		p, ok := parseIgnorePattern(tt.pattern)
+		if !ok {
+			t.Fatalf("parseIgnorePattern(%q) failed", tt.pattern)
+		}
+		stack := ignoreStack{{base: "/repo", patterns: []ignorePattern{p}}}
+		if got := stack.ignored("/repo/"+tt.path, tt.isDir); got != tt.ignored {
+			t.Errorf("pattern %q on %q (dir=%v): ignored = %v, want %v", tt.pattern, tt.path, tt.isDir, got, tt.ignored)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "!"} {
+		if _, ok := parseIgnorePattern(line); ok {
+			t.Errorf("Expected %q to be skipped", line)
+		}
+	}
+}
+
+func TestProjectTreeRespectsGitignore(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer cleanupTestDir(t, tmpDir)
+
+	files := map[string]string{
+		".gitignore":        "*.log\nsecrets/\n!keep.log\n",
+		"main.go":           "package main",
+		"app.log":           "noise",
+		"keep.log":          "kept",
+		"secrets/key.pem":   "secret",
+		"vendor/lib/lib.go": "package lib",
+		"web/.gitignore":    "generated.js\n",
+		"web/generated.js":  "// generated",
+		"web/index.js":      "// source",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	tree, err := ProjectTree(tmpDir, 0, nil)
+	if err != nil {
+		t.Fatalf("ProjectTree() error = %v", err)
+	}
+
+	for _, want := range []string{"main.go", "keep.log", "web/", "index.js"} {
+		if !strings.Contains(tree, want) {
+			t.Errorf("Expected %s in tree:\n%s", want, tree)
+		}
+	}
+	for _, unwanted := range []string{"app.log", "secrets", "vendor", "generated.js"} {
+		if strings.Contains(tree, unwanted) {
+			t.Errorf("Expected %s to be skipped:\n%s", unwanted, tree)
+		}
+	}
+}
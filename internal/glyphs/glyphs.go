@@ -0,0 +1,120 @@
+// Package glyphs holds the symbols tala prints, such as check marks, file
+// icons and tree lines, with plain ASCII stand-ins for terminals that cannot
+// show Unicode. Output code uses these variables instead of literals, so
+// SetUnicode swaps every symbol at once.
+package glyphs
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// The symbols in use; SetUnicode switches them between the Unicode and ASCII forms
+var (
+	Check      = "✓"
+	Cross      = "✗"
+	File       = "📄"
+	Dir        = "📁"
+	Tee        = "├── "
+	Elbow      = "└── "
+	Pipe       = "│   "
+	Rule       = "──"
+	ThumbsUp   = "👍"
+	ThumbsDown = "👎"
+	Infinity   = "∞"
+)
+
+// symbol pairs a variable with its Unicode and ASCII forms
+type symbol struct {
+	v              *string
+	unicode, ascii string
+}
+
+var symbols = []symbol{
+	{&Check, "✓", "[ok]"},
+	{&Cross, "✗", "[x]"},
+	{&File, "📄", "[file]"},
+	{&Dir, "📁", "[dir]"},
+	{&Tee, "├── ", "|-- "},
+	{&Elbow, "└── ", "`-- "},
+	{&Pipe, "│   ", "|   "},
+	{&Rule, "──", "--"},
+	{&ThumbsUp, "👍", "+1"},
+	{&ThumbsDown, "👎", "-1"},
+	{&Infinity, "∞", "inf"},
+}
+
+// decorations only appear inside translated text, like the banner, and are
+// replaced by Text
+var decorations = []string{
+	"🗣️", ">",
+	"🤔", "*",
+	"—", "-",
+	"•", "*",
+}
+
+var (
+	unicode = true
+	toASCII *strings.Replacer
+)
+
+func init() {
+	pairs := append([]string(nil), decorations...)
+	for _, s := range symbols {
+		pairs = append(pairs, s.unicode, s.ascii)
+	}
+	toASCII = strings.NewReplacer(pairs...)
+}
+
+// SetUnicode chooses Unicode symbols, or ASCII ones when on is false
+func SetUnicode(on bool) {
+	unicode = on
+	for _, s := range symbols {
+		if on {
+			*s.v = s.unicode
+		} else {
+			*s.v = s.ascii
+		}
+	}
+}
+
+// Unicode reports whether Unicode symbols are in use
+func Unicode() bool {
+	return unicode
+}
+
+// Text returns text as is, or with its symbols replaced by ASCII ones when
+// Unicode is off. It is for text that is not built from the variables, such
+// as translated messages.
+func Text(text string) string {
+	if unicode {
+		return text
+	}
+	return toASCII.Replace(text)
+}
+
+// Detect returns the configured choice when there is one. Otherwise Unicode is
+// assumed unless the locale says the terminal is not UTF-8, or on Windows the
+// console is the legacy one rather than Windows Terminal or an editor's.
+func Detect(configured *bool) bool {
+	if configured != nil {
+		return *configured
+	}
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") != "" || os.Getenv("TERM_PROGRAM") != "" || os.Getenv("ConEmuANSI") == "ON"
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return localeIsUTF8(locale)
+		}
+	}
+	return true
+}
+
+// localeIsUTF8 reports whether a locale like en_US.UTF-8 uses UTF-8. The plain
+// C and POSIX locales do not; C.UTF-8 does.
+func localeIsUTF8(locale string) bool {
+	locale = strings.ToLower(locale)
+	return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+}
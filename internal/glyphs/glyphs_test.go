@@ -0,0 +1,56 @@
+package glyphs
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSetUnicode(t *testing.T) {
+	defer SetUnicode(true)
+
+	SetUnicode(false)
+	if Check != "[ok]" || File != "[file]" || Dir != "[dir]" || Tee != "|-- " {
+		t.Errorf("Expected ASCII symbols, got %q %q %q %q", Check, File, Dir, Tee)
+	}
+	if got := Text("🗣️ Tala ✓ done"); got != "> Tala [ok] done" {
+		t.Errorf("Text() = %q, want ASCII", got)
+	}
+
+	SetUnicode(true)
+	if Check != "✓" || File != "📄" {
+		t.Errorf("Expected Unicode symbols back, got %q %q", Check, File)
+	}
+	if got := Text("✓ done"); got != "✓ done" {
+		t.Errorf("Expected text unchanged with Unicode on, got %q", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	off := false
+	if Detect(&off) {
+		t.Error("Expected the configured choice to win")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("detection on Windows depends on the console")
+	}
+
+	tests := []struct {
+		lcAll, lang string
+		want        bool
+	}{
+		{"", "en_US.UTF-8", true},
+		{"", "es_ES.utf8", true},
+		{"", "C", false},
+		{"POSIX", "en_US.UTF-8", false},
+		{"C.UTF-8", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(nil); got != tt.want {
+			t.Errorf("Detect() with LC_ALL=%q LANG=%q = %v, want %v", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package gui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"tala/internal/ai"
 	"tala/internal/config"
 	"tala/internal/fileops"
+	"tala/internal/i18n"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -82,6 +84,7 @@ type App struct {
 	
 	// State
 	isLoading      bool
+	noTools        bool // plain chat: skip intent detection and tools
 	totalRequests  int
 	totalTokens    int
 	totalTime      time.Duration
@@ -96,7 +99,7 @@ type App struct {
 }
 
 func NewApp(cfg *config.Config) (*App, error) {
-	provider, err := ai.CreateProvider(cfg.Provider, cfg.APIKey, cfg.Model, cfg.Temperature, cfg.MaxTokens)
+	provider, err := ai.CreateProviderFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +143,7 @@ func (a *App) setupUI() {
 	
 	// Larger input field
 	a.input = widget.NewEntry()
-	a.input.SetPlaceHolder("Type your message here... (Enter for new line, Shift+Enter to send)")
+	a.input.SetPlaceHolder(i18n.T("gui.input.placeholder"))
 	a.input.MultiLine = true
 	a.input.Resize(fyne.NewSize(600, 100)) // Much larger input field
 	
@@ -180,7 +183,7 @@ func (a *App) setupUI() {
 	a.modelLabel.Importance = widget.MediumImportance
 	
 	// Status label with color
-	a.statusLabel = widget.NewLabel("Ready - Type your message below")
+	a.statusLabel = widget.NewLabel(i18n.T("gui.status.ready"))
 	a.statusLabel.Importance = widget.MediumImportance
 	
 	// Statistics label
@@ -230,21 +233,8 @@ func (a *App) setupUI() {
 }
 
 func (a *App) addWelcomeMessage() {
-	welcome := fmt.Sprintf(`Welcome to Tala!
-
-Provider: %s  
-Model: %s  
-Tools: %v
-
-Type your message below and press Enter to chat with AI. You can:
-- Ask questions naturally
-- Request file operations: "create a file called test.txt"
-- Execute commands: "list files in current directory"
-- Get help: "what can you do?"
-
-=================================================================
-
-`, a.provider.GetName(), a.config.Model, a.provider.SupportsTools())
+	welcome := fmt.Sprintf(i18n.T("gui.welcome"), a.provider.GetName(), a.config.Model, a.provider.SupportsTools())
+	welcome += "\n=================================================================\n\n"
 	
 	a.chatContent = welcome
 	a.chatHistory.SetText(welcome)
@@ -395,7 +385,7 @@ func (a *App) showSettings() {
 			}
 			
 			// Recreate provider with new config
-			provider, err := ai.CreateProvider(a.config.Provider, a.config.APIKey, a.config.Model, a.config.Temperature, a.config.MaxTokens)
+			provider, err := ai.CreateProviderFromConfig(a.config)
 			if err != nil {
 				dialog.ShowError(err, a.window)
 				return
@@ -482,7 +472,7 @@ func (a *App) processMessage(text string) {
 	a.addMessage("You", text, UserColor)
 	
 	// Set loading state
-	a.statusLabel.SetText("AI is thinking...")
+	a.statusLabel.SetText(i18n.T("gui.status.thinking"))
 	a.progressBar.Show()
 	a.progressBar.Start()
 	a.sendButton.Disable()
@@ -496,7 +486,7 @@ func (a *App) processMessage(text string) {
 			a.progressBar.Stop()
 			a.progressBar.Hide()
 			a.sendButton.Enable()
-			a.statusLabel.SetText("Ready - Type your message below")
+			a.statusLabel.SetText(i18n.T("gui.status.ready"))
 			a.updateStats()
 		}()
 		
@@ -510,7 +500,7 @@ func (a *App) processMessage(text string) {
 		}
 		
 		// Check if we should use tools
-		if a.provider.SupportsTools() {
+		if a.provider.SupportsTools() && !a.noTools {
 			response, toolResults, err := a.provider.GenerateResponseWithTools(ctx, text)
 			if err != nil {
 				a.addMessage("Error", fmt.Sprintf("Error: %v", err), ErrorColor)
@@ -555,27 +545,7 @@ func (a *App) handleSlashCommand(cmd string) {
 	
 	switch command {
 	case "/help":
-		helpText := `## Available Commands
-
-### System Commands
-- **/clear** - Clear chat history
-- **/stats** - Show session statistics
-- **/help** - Show this help message
-- **/quit** - Exit application
-
-### File Operations
-- **/ls [path]** - List files and directories
-- **/cat <file>** - Display file content
-- **/pwd** - Show current directory
-- **/cd <path>** - Change directory
-- **/create <file>** - Create new file
-- **/mkdir <dir>** - Create directory
-
-### Tips
-- You can also use natural language: "create a file called test.txt"
-- AI will understand and execute appropriate file operations
-- Use the input field below for normal conversations
-`
+		helpText := i18n.T("gui.help")
 		a.addMessage("System", helpText, SystemColor)
 		
 	case "/clear":
@@ -597,10 +567,37 @@ func (a *App) handleSlashCommand(cmd string) {
 			a.addMessage("System", "📊 No requests made yet", SystemColor)
 		}
 		
+	case "/persona":
+		a.handlePersona(parts[1:])
+		
+	case "/profile":
+		a.handleProfile(parts[1:])
+		
+	case "/tools":
+		a.handleTools(parts[1:])
+	case "/trash":
+		a.handleTrash(parts[1:])
+	case "/undo":
+		a.handleUndo()
+	case "/tx":
+		a.handleTransaction(parts[1:])
+	case "/notools":
+		a.noTools = !a.noTools
+		if a.noTools {
+			a.addMessage("System", "🔇 Tools disabled: replies are plain chat until /notools is used again", SystemColor)
+		} else {
+			a.addMessage("System", "🛠️ Tools enabled", SystemColor)
+		}
+		
 	case "/quit":
 		a.fyneApp.Quit()
 		
 	default:
+		if suggestion := a.suggestCommand(command); suggestion != "" {
+			a.addMessage("System", fmt.Sprintf("❌ Unknown command: %s. Did you mean %s?", command, suggestion), ErrorColor)
+			return
+		}
+		
 		// Try file operation
 		result := fileops.ExecuteCommand(cmd)
 		if strings.Contains(result.Message, "✓") || strings.Contains(result.Message, "success") {
@@ -611,7 +608,238 @@ func (a *App) handleSlashCommand(cmd string) {
 	}
 }
 
+// suggestCommand returns the closest known slash command for a mistyped one, or ""
+func (a *App) suggestCommand(command string) string {
+	name := strings.TrimPrefix(command, "/")
+	if _, ok := fileops.GetCommands()[name]; ok {
+		return ""
+	}
+	
+	candidates := append(fileops.CommandNames(),
+		"help", "clear", "stats", "persona", "profile", "tools", "trash", "undo", "tx", "notools", "quit")
+	if suggestion := fileops.SuggestCommand(name, candidates...); suggestion != "" {
+		return "/" + suggestion
+	}
+	return ""
+}
+
+// handlePersona lists personas or switches the active one for this session
+func (a *App) handlePersona(args []string) {
+	if len(args) == 0 {
+		var list strings.Builder
+		list.WriteString("Personas:\n\n")
+		for _, name := range a.config.ListPersonas() {
+			marker := "-"
+			if name == a.config.Persona {
+				marker = "*"
+			}
+			list.WriteString(fmt.Sprintf("%s %s\n", marker, name))
+		}
+		list.WriteString("\nUse /persona <name> to switch, /persona none to reset")
+		a.addMessage("System", list.String(), SystemColor)
+		return
+	}
+	
+	name := args[0]
+	if name == "none" || name == "default" {
+		name = ""
+	} else if _, exists := a.config.GetPersona(name); !exists {
+		a.addMessage("Error", fmt.Sprintf("❌ Unknown persona: %s", name), ErrorColor)
+		return
+	}
+	
+	previous := a.config.Persona
+	a.config.Persona = name
+	provider, err := ai.CreateProviderFromConfig(a.config)
+	if err != nil {
+		a.config.Persona = previous
+		a.addMessage("Error", fmt.Sprintf("❌ %v", err), ErrorColor)
+		return
+	}
+	a.provider = provider
+	
+	if name == "" {
+		a.addMessage("System", "✅ Persona reset to the default system prompt", SystemColor)
+	} else {
+		a.addMessage("System", fmt.Sprintf("✅ Persona switched to %s", name), SystemColor)
+	}
+}
+
+// handleProfile lists provider profiles or switches to one for this session
+func (a *App) handleProfile(args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		names := a.config.ListProfiles()
+		if len(names) == 0 {
+			a.addMessage("System", "No profiles configured. Add them under \"profiles\" in the config.", SystemColor)
+			return
+		}
+		var list strings.Builder
+		list.WriteString("Profiles:\n\n")
+		for _, name := range names {
+			marker := "-"
+			if name == a.config.ActiveProfile {
+				marker = "*"
+			}
+			profile := a.config.Profiles[name]
+			list.WriteString(fmt.Sprintf("%s %s (%s %s)\n", marker, name, profile.Provider, profile.Model))
+		}
+		list.WriteString("\nUse /profile use <name> to switch, /profile use none for the top-level settings")
+		a.addMessage("System", list.String(), SystemColor)
+		return
+	}
+	
+	if args[0] != "use" || len(args) < 2 {
+		a.addMessage("Error", "❌ Usage: /profile [list|use <name>]", ErrorColor)
+		return
+	}
+	
+	previous := a.config.ActiveProfile
+	err := a.config.UseProfile(args[1])
+	if err == nil {
+		err = a.config.Validate()
+	}
+	var provider ai.Provider
+	if err == nil {
+		provider, err = ai.CreateProviderFromConfig(a.config)
+	}
+	if err != nil {
+		_ = a.config.UseProfile(previous)
+		a.addMessage("Error", fmt.Sprintf("❌ %v", err), ErrorColor)
+		return
+	}
+	a.provider = provider
+	a.providerLabel.SetText(fmt.Sprintf("Provider: %s", a.provider.GetName()))
+	a.modelLabel.SetText(fmt.Sprintf("Model: %s", a.config.Model))
+	
+	if a.config.ActiveProfile == "" {
+		a.addMessage("System", fmt.Sprintf("✅ Back to the top-level settings (%s, %s)", provider.GetName(), a.config.Model), SystemColor)
+	} else {
+		a.addMessage("System", fmt.Sprintf("✅ Switched to profile %s (%s, %s)", a.config.ActiveProfile, provider.GetName(), a.config.Model), SystemColor)
+	}
+}
+
+func (a *App) handleTools(args []string) {
+	var text strings.Builder
+	if len(args) == 0 {
+		text.WriteString("🛠️ **Available Tools:**\n\n")
+		for _, tool := range ai.GetAvailableTools() {
+			text.WriteString(fmt.Sprintf("- **%s** - %s\n", tool.Name, tool.Description))
+		}
+		text.WriteString("\nUse /tools <name> to see a tool's parameters")
+		a.addMessage("System", text.String(), SystemColor)
+		return
+	}
+	
+	tool, ok := ai.FindTool(args[0])
+	if !ok {
+		a.addMessage("Error", fmt.Sprintf("❌ Unknown tool: %s", args[0]), ErrorColor)
+		return
+	}
+	
+	text.WriteString(fmt.Sprintf("🛠️ **%s** - %s\n\n", tool.Name, tool.Description))
+	for _, param := range tool.ParameterList() {
+		required := ""
+		if param.Required {
+			required = ", required"
+		}
+		text.WriteString(fmt.Sprintf("- **%s** (%s%s): %s\n", param.Name, param.Type, required, param.Description))
+	}
+	a.addMessage("System", text.String(), SystemColor)
+}
+
+func (a *App) handleUndo() {
+	description, err := ai.Undo()
+	if errors.Is(err, ai.ErrNothingToUndo) {
+		a.addMessage("System", "Nothing to undo", SystemColor)
+		return
+	}
+	if err != nil {
+		a.addMessage("Error", fmt.Sprintf("❌ %v", err), ErrorColor)
+		return
+	}
+	a.addMessage("System", "↩️ Undid "+description, SystemColor)
+}
+
+func (a *App) handleTransaction(args []string) {
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+	
+	var err error
+	switch action {
+	case "begin":
+		if err = ai.BeginTransaction(); err == nil {
+			a.addMessage("System", "📦 Transaction started. File changes are staged until /tx commit.", SystemColor)
+		}
+	case "commit":
+		var count int
+		if count, err = ai.CommitTransaction(); err == nil {
+			a.addMessage("System", fmt.Sprintf("✅ Transaction committed: %d operations applied", count), SystemColor)
+		}
+	case "rollback":
+		var count int
+		if count, err = ai.RollbackTransaction(); err == nil {
+			a.addMessage("System", fmt.Sprintf("↩️ Transaction rolled back: %d staged operations discarded", count), SystemColor)
+		}
+	case "":
+		if !ai.TransactionActive() {
+			a.addMessage("System", "No transaction in progress", SystemColor)
+			return
+		}
+		var text strings.Builder
+		text.WriteString("📦 **Staged operations:**\n\n")
+		for i, op := range ai.StagedOperations() {
+			text.WriteString(fmt.Sprintf("%d. %s\n", i+1, op))
+		}
+		a.addMessage("System", text.String(), SystemColor)
+	default:
+		err = fmt.Errorf("usage: /tx [begin|commit|rollback]")
+	}
+	
+	if err != nil {
+		a.addMessage("Error", fmt.Sprintf("❌ %v", err), ErrorColor)
+	}
+}
+
+func (a *App) handleTrash(args []string) {
+	if len(args) > 0 && args[0] == "restore" {
+		id := ""
+		if len(args) > 1 {
+			id = args[1]
+		}
+		result := fileops.RestoreFromTrash(id)
+		if !result.Success {
+			a.addMessage("Error", "❌ "+result.Message, ErrorColor)
+			return
+		}
+		a.addMessage("System", "✅ "+result.Message, SystemColor)
+		return
+	}
+	
+	items, err := fileops.ListTrash()
+	if err != nil {
+		a.addMessage("Error", fmt.Sprintf("❌ %v", err), ErrorColor)
+		return
+	}
+	if len(items) == 0 {
+		a.addMessage("System", "🗑️ Trash is empty", SystemColor)
+		return
+	}
+	
+	var text strings.Builder
+	text.WriteString("🗑️ **Trash:**\n\n")
+	for _, item := range items {
+		text.WriteString(fmt.Sprintf("- **%s** %s (%s)\n", item.ID, item.OriginalPath, item.DeletedAt.Format("2006-01-02 15:04")))
+	}
+	text.WriteString("\nUse /trash restore <id> to restore an item")
+	a.addMessage("System", text.String(), SystemColor)
+}
+
 func (a *App) addAIResponseWithDelay(response string) {
+	if a.config.HideThinking {
+		response, _ = ai.SplitThinking(response)
+	}
 	// Simply add the AI response as a regular message
 	a.addMessage("AI", response, AIColor)
 }
@@ -0,0 +1,291 @@
+package i18n
+
+// english is the reference catalog; every key must be present here
+var english = map[string]string{
+	// Shared
+	"app.title":      "🗣️ Tala - Terminal AI Language Assistant",
+	"common.goodbye": "Goodbye!",
+
+	// Startup errors
+	"config.error":         "Configuration error: %v",
+	"config.hint.settings": "Please set your API key and other configuration options.",
+	"config.hint.location": "Configuration file location: ~/.config/tala/config.json",
+
+	// TUI banner and prompts
+	"tui.provider":        "Provider:",
+	"tui.model":           "Model:",
+	"tui.banner.hint":     "Type '%s' for file operations or chat normally with AI",
+	"tui.banner.commands": "Type '%s' for commands or chat normally with AI",
+	"tui.banner.exit":     "Ctrl+C to exit, or to stop a reply in progress",
+	"tui.queued":          "[Queued]:",
+	"tui.you":             "You:",
+	"tui.ai":              "AI:",
+	"tui.reasoning":       "Reasoning:",
+	"tui.system":          "System:",
+	"tui.error":           "Error:",
+	"tui.warning":         "Warning:",
+	"tui.interrupted":     "[Interrupted]",
+	"tui.tools.executed":  "File operations executed:",
+	"tui.thinking":        "🤔 AI is thinking...",
+	"tui.session":         "Session:",
+	"tui.eta":             "~%.1fs left (avg %.1fs)",
+	"tui.overdue":         "%.1fs over the %.1fs average",
+	"tui.streamed":        "%d tokens",
+	"tui.throughput":      "%d tokens, %.1f tok/s",
+	"tui.files_done":      "%s: %d/%d files",
+	"tui.files_counted":   "%s: %d files",
+
+	// Tool parameter clarification
+	"clarify.filename":    "What should I name the file?",
+	"clarify.dirname":     "What should I name the directory?",
+	"clarify.command":     "Which command should I run?",
+	"clarify.source":      "Which file or directory should I use as the source?",
+	"clarify.destination": "Where should it go?",
+	"clarify.path":        "Which path should I use?",
+	"clarify.generic":     "Please provide %s (%s):",
+	"clarify.hint":        "(press Enter to cancel)",
+
+	// Answers that agree to a [y/N] question, comma separated
+	"answer.yes": "y,yes",
+
+	// Ollama model pulls
+	"pull.confirm":  "Model %s is not available locally. Pull it now? [y/N]",
+	"pull.progress": "Pulling %s: %s",
+	"pull.done":     "Model %s pulled successfully.",
+
+	// Risky shell commands
+	"command.confirm":      "The AI wants to run %s (%s). Run it? [y/N]",
+	"command.keep_waiting": "%s is still running after %v. Keep waiting instead of killing it? [y/N]",
+
+	// Tool paths outside the current directory
+	"path.confirm_outside": "%s wants to use %s, outside the current directory. Allow it? [y/N]",
+
+	// Bulk renames
+	"rename.confirm": "The AI wants to rename %d files in %s:\n%s\nRename them? [y/N]",
+
+	// Tool listing
+	"tools.title":    "Available Tools:",
+	"tools.hint":     "Use %s to see a tool's parameters",
+	"tools.unknown":  "Unknown tool: %s",
+	"tools.params":   "Parameters:",
+	"tools.none":     "No parameters",
+	"tools.required": "required",
+
+	// Trash
+	"trash.title": "Trash (newest first):",
+	"trash.empty": "Trash is empty",
+	"trash.hint":  "Use %s to restore an item",
+	"trash.usage": "Usage: %s",
+
+	// Undo
+	"undo.done":  "Undid %s",
+	"undo.empty": "Nothing to undo",
+
+	// Transactions
+	"tx.begun":       "Transaction started. File changes are staged until /tx commit.",
+	"tx.committed":   "Transaction committed: %d operations applied",
+	"tx.rolled_back": "Transaction rolled back: %d staged operations discarded",
+	"tx.staged":      "Staged operations:",
+	"tx.none":        "No transaction in progress",
+	"tx.usage":       "Usage: %s",
+
+	// Tool toggle
+	"notools.on":  "Tools disabled: replies are plain chat until /notools is used again",
+	"notools.off": "Tools enabled",
+
+	// Verbose intent display
+	"verbose.on":      "Verbose mode on: detected intents are shown before tools run",
+	"verbose.off":     "Verbose mode off",
+	"intent.detected": "Intent:",
+	"intent.run":      "confidence %.2f > %.2f, running",
+	"intent.skip":     "confidence %.2f <= %.2f, skipped",
+
+	// Unknown slash commands
+	"command.unknown": "Unknown command: %s",
+	"command.suggest": "Did you mean %s?",
+
+	// External editor
+	"edit.usage":     "Usage: %s",
+	"edit.no_editor": "No editor configured. Set $EDITOR (e.g. export EDITOR=nano) or \"editor\" in the config.",
+	"edit.failed":    "%s exited with an error: %v",
+	"edit.done":      "Finished editing %s",
+
+	// Multiline input
+	"paste.start": "Multiline mode: paste or type your text, then finish with %s on its own line",
+
+	// Status line
+	"status.tokens":          "%d tokens",
+	"statusline.on":          "Status line on",
+	"statusline.off":         "Status line off",
+	"statusline.unavailable": "The status line needs an interactive terminal",
+
+	// Provider profiles
+	"profile.title":    "Profiles:",
+	"profile.none":     "No profiles configured. Add them under \"profiles\" in the config.",
+	"profile.hint":     "Use %s to switch, %s for the top-level settings",
+	"profile.usage":    "Usage: %s",
+	"profile.switched": "Switched to profile %s (%s, %s)",
+	"profile.reset":    "Back to the top-level settings (%s, %s)",
+
+	// Raw output
+	"raw.on":  "Raw mode on: responses are printed verbatim",
+	"raw.off": "Raw mode off",
+
+	// Saved sessions
+	"session.title":       "Sessions:",
+	"session.none":        "No saved sessions yet",
+	"session.messages":    "%d msgs",
+	"session.hint":        "Use %s to continue one",
+	"session.usage":       "Usage: %s",
+	"session.resumed":     "Resumed session %s (%d messages)",
+	"session.forked":      "Session %s forked from %s with %d messages; %s goes back to the original",
+	"session.fork_empty":  "Nothing to fork yet",
+	"session.disabled":    "Sessions are not saved (save_history is off)",
+	"session.fork_of":     "(fork of %s)",
+	"session.save_failed": "could not save the session: %v",
+
+	// Response ratings
+	"rating.title":   "Ratings:",
+	"rating.recent":  "Latest rated responses:",
+	"rating.none":    "No rated responses yet. Rate the latest response with %s",
+	"rating.nothing": "No response to rate yet",
+	"rating.saved":   "Rated the latest response %s",
+
+	// Model comparison
+	"compare.usage":  "Usage: %s (a target is provider:model or a profile name)",
+	"compare.target": "%s: %v",
+
+	// Truncated replies
+	"continue.truncated": "Response truncated by max_tokens; %s fetches the rest",
+	"continue.nothing":   "No reply to continue yet",
+
+	// Context files
+	"context.title":       "Context files sent with every request:",
+	"context.none":        "No context files. Add one with %s",
+	"context.tokens":      "~%d tokens",
+	"context.budget":      "~%d of %d tokens used; files past the budget are cut short",
+	"context.over_budget": "The files exceed the budget, so the last ones are truncated",
+	"context.added":       "Added %s to the context",
+	"context.removed":     "Removed %s from the context",
+	"context.cleared":     "Cleared the context files",
+	"context.not_found":   "%s is not a context file",
+	"context.unreadable":  "cannot read %s: %v",
+	"context.usage":       "Usage: %s",
+
+	// Stop sequences
+	"stop.title":   "Stop sequences:",
+	"stop.none":    "No stop sequences. Set some with %s",
+	"stop.set":     "Generation now stops before %s",
+	"stop.cleared": "Cleared the stop sequences",
+
+	// Shell commands typed with /shell
+	"shell.usage":     "Usage: %s",
+	"shell.no_output": "(no output)",
+
+	// Settings changed from the TUI
+	"settings.title":     "Settings (press Enter to keep a value):",
+	"settings.ask":       "%s [%s]:",
+	"settings.saved":     "%s is now %q",
+	"settings.not_saved": "changed for this session, but not saved: %v",
+	"settings.unchanged": "No settings changed",
+	"settings.usage":     "Usage: %s",
+
+	// TUI help
+	"help.title":      "Available Commands:",
+	"help.system":     "System Commands:",
+	"help.files":      "File Operations:",
+	"help.shortcuts":  "Keyboard Shortcuts:",
+	"help.clear":      "Clear screen and reset session",
+	"help.stats":      "Show session statistics",
+	"help.config":     "Show current configuration",
+	"help.set":        "Change a setting now and in the config file",
+	"help.settings":   "Walk through provider, model, API key, temperature and max tokens",
+	"help.persona":    "List or switch personas",
+	"help.profile":    "List provider profiles or switch to one",
+	"help.sessions":   "List saved sessions with their first prompt",
+	"help.resume":     "Resume a saved session (default: the latest)",
+	"help.fork":       "Continue the conversation in a copy of this session",
+	"help.continue":   "Continue the latest reply where it stopped",
+	"help.context":    "List, add or remove files sent as context with every request",
+	"help.stop":       "Show or set strings that end generation (\\n for a newline)",
+	"help.rate":       "Rate the latest response, with an optional note",
+	"help.ratings":    "Summarize ratings per model",
+	"help.compare":    "Ask several models the same question side by side",
+	"help.tools":      "List available tools or describe one",
+	"help.trash":      "List trashed files or restore one",
+	"help.undo":       "Undo the last file change made by the AI",
+	"help.tx":         "Stage file changes and apply them all at once",
+	"help.notools":    "Toggle tool use off and on for this session",
+	"help.verbose":    "Show detected intents and their confidence",
+	"help.statusline": "Pin provider, model, directory and tokens to the bottom row",
+	"help.raw":        "Print responses verbatim, without formatting",
+	"help.edit":       "Open a file in your editor ($EDITOR)",
+	"help.paste":      "Send several lines as one prompt (or wrap them in \"\"\")",
+	"help.shell":      "Run a shell command (risky ones are confirmed)",
+	"help.help":       "Show this help message",
+	"help.exit":       "Exit application",
+	"help.ls":         "List files and directories",
+	"help.cat":        "Display file content",
+	"help.pwd":        "Show current directory",
+	"help.cd":         "Change directory",
+	"help.create":     "Create new file",
+	"help.mkdir":      "Create directory",
+	"help.key.exit":   "Exit application",
+	"help.key.enter":  "Send message",
+
+	// TUI stats and config
+	"stats.title":        "Session Stats:",
+	"stats.none":         "No requests made yet",
+	"stats.tools":        "Tool calls: %d/%s in the last response, %d/%s in the last minute",
+	"config.title":       "Current Configuration:",
+	"config.provider":    "Provider:",
+	"config.model":       "Model:",
+	"config.temperature": "Temperature:",
+	"config.max_tokens":  "Max Tokens:",
+	"config.tools":       "Tools:",
+
+	// GUI
+	"gui.status.ready":      "Ready - Type your message below",
+	"gui.status.thinking":   "AI is thinking...",
+	"gui.input.placeholder": "Type your message here... (Enter for new line, Shift+Enter to send)",
+	"gui.welcome": `Welcome to Tala!
+
+Provider: %s  
+Model: %s  
+Tools: %v
+
+Type your message below and press Enter to chat with AI. You can:
+- Ask questions naturally
+- Request file operations: "create a file called test.txt"
+- Execute commands: "list files in current directory"
+- Get help: "what can you do?"
+`,
+	"gui.help": `## Available Commands
+
+### System Commands
+- **/clear** - Clear chat history
+- **/stats** - Show session statistics
+- **/persona [name]** - List personas or switch the active one
+- **/profile [use name]** - List provider profiles or switch to one
+- **/tools [name]** - List available tools or describe one
+- **/trash [list|restore [id]]** - List trashed files or restore one
+- **/undo** - Undo the last file change made by the AI
+- **/tx [begin|commit|rollback]** - Stage file changes and apply them all at once
+- **/notools** - Toggle tool use off and on for this session
+- **/help** - Show this help message
+- **/quit** - Exit application
+
+### File Operations
+- **/ls [path]** - List files and directories
+- **/cat <file>** - Display file content
+- **/pwd** - Show current directory
+- **/cd <path>** - Change directory
+- **/create <file>** - Create new file
+- **/mkdir <dir>** - Create directory
+
+### Tips
+- You can also use natural language: "create a file called test.txt"
+- AI will understand and execute appropriate file operations
+- Use the input field below for normal conversations
+`,
+}
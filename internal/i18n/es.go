@@ -0,0 +1,263 @@
+package i18n
+
+// spanish translates the interactive prompts and help; missing keys fall back to English
+var spanish = map[string]string{
+	// Shared
+	"app.title":      "🗣️ Tala - Asistente de Lenguaje IA para Terminal",
+	"common.goodbye": "¡Hasta luego!",
+
+	// Startup errors
+	"config.error":         "Error de configuración: %v",
+	"config.hint.settings": "Configura tu clave de API y las demás opciones de configuración.",
+	"config.hint.location": "Ubicación del archivo de configuración: ~/.config/tala/config.json",
+
+	// TUI banner and prompts
+	"tui.provider":        "Proveedor:",
+	"tui.model":           "Modelo:",
+	"tui.banner.hint":     "Escribe '%s' para operaciones de archivos o conversa normalmente con la IA",
+	"tui.banner.commands": "Escribe '%s' para ver los comandos o conversa normalmente con la IA",
+	"tui.banner.exit":     "Ctrl+C para salir o para detener una respuesta en curso",
+	"tui.queued":          "[En cola]:",
+	"tui.you":             "Tú:",
+	"tui.ai":              "IA:",
+	"tui.reasoning":       "Razonamiento:",
+	"tui.system":          "Sistema:",
+	"tui.error":           "Error:",
+	"tui.warning":         "Aviso:",
+	"tui.interrupted":     "[Interrumpido]",
+	"tui.tools.executed":  "Operaciones de archivos ejecutadas:",
+	"tui.thinking":        "🤔 La IA está pensando...",
+	"tui.session":         "Sesión:",
+	"tui.eta":             "~%.1fs restantes (media %.1fs)",
+	"tui.overdue":         "%.1fs más que la media de %.1fs",
+	"tui.streamed":        "%d tokens",
+	"tui.throughput":      "%d tokens, %.1f tok/s",
+	"tui.files_done":      "%s: %d/%d archivos",
+	"tui.files_counted":   "%s: %d archivos",
+
+	// Tool parameter clarification
+	"clarify.filename":    "¿Qué nombre le pongo al archivo?",
+	"clarify.dirname":     "¿Qué nombre le pongo al directorio?",
+	"clarify.command":     "¿Qué comando debo ejecutar?",
+	"clarify.source":      "¿Qué archivo o directorio uso como origen?",
+	"clarify.destination": "¿A dónde debe ir?",
+	"clarify.path":        "¿Qué ruta debo usar?",
+	"clarify.generic":     "Indica %s (%s):",
+	"clarify.hint":        "(pulsa Enter para cancelar)",
+
+	// Answers that agree to a [y/N] question, comma separated
+	"answer.yes": "s,si,sí",
+
+	// Ollama model pulls
+	"pull.confirm":  "El modelo %s no está disponible localmente. ¿Descargarlo ahora? [s/N]",
+	"pull.progress": "Descargando %s: %s",
+	"pull.done":     "Modelo %s descargado correctamente.",
+
+	// Risky shell commands
+	"command.confirm":      "La IA quiere ejecutar %s (%s). ¿Ejecutarlo? [s/N]",
+	"command.keep_waiting": "%s sigue en ejecución tras %v. ¿Seguir esperando en vez de detenerlo? [s/N]",
+
+	// Tool paths outside the current directory
+	"path.confirm_outside": "%s quiere usar %s, fuera del directorio actual. ¿Permitirlo? [s/N]",
+
+	// Bulk renames
+	"rename.confirm": "La IA quiere renombrar %d archivos en %s:\n%s\n¿Renombrarlos? [s/N]",
+
+	// Tool listing
+	"tools.title":    "Herramientas disponibles:",
+	"tools.hint":     "Usa %s para ver los parámetros de una herramienta",
+	"tools.unknown":  "Herramienta desconocida: %s",
+	"tools.params":   "Parámetros:",
+	"tools.none":     "Sin parámetros",
+	"tools.required": "obligatorio",
+
+	// Trash
+	"trash.title": "Papelera (más recientes primero):",
+	"trash.empty": "La papelera está vacía",
+	"trash.hint":  "Usa %s para restaurar un elemento",
+	"trash.usage": "Uso: %s",
+
+	// Undo
+	"undo.done":  "Deshecho: %s",
+	"undo.empty": "No hay nada que deshacer",
+
+	// Transactions
+	"tx.begun":       "Transacción iniciada. Los cambios de archivos quedan pendientes hasta /tx commit.",
+	"tx.committed":   "Transacción confirmada: %d operaciones aplicadas",
+	"tx.rolled_back": "Transacción revertida: %d operaciones pendientes descartadas",
+	"tx.staged":      "Operaciones pendientes:",
+	"tx.none":        "No hay ninguna transacción en curso",
+	"tx.usage":       "Uso: %s",
+
+	// Tool toggle
+	"notools.on":  "Herramientas desactivadas: las respuestas son solo chat hasta volver a usar /notools",
+	"notools.off": "Herramientas activadas",
+
+	// Verbose intent display
+	"verbose.on":      "Modo detallado activado: se muestran las intenciones detectadas antes de ejecutar herramientas",
+	"verbose.off":     "Modo detallado desactivado",
+	"intent.detected": "Intención:",
+	"intent.run":      "confianza %.2f > %.2f, se ejecuta",
+	"intent.skip":     "confianza %.2f <= %.2f, se omite",
+
+	// Unknown slash commands
+	"command.unknown": "Comando desconocido: %s",
+	"command.suggest": "¿Quisiste decir %s?",
+
+	// External editor
+	"edit.usage":     "Uso: %s",
+	"edit.no_editor": "No hay editor configurado. Define $EDITOR (p. ej. export EDITOR=nano) o \"editor\" en la configuración.",
+	"edit.failed":    "%s terminó con un error: %v",
+	"edit.done":      "Edición de %s terminada",
+
+	// Multiline input
+	"paste.start": "Modo multilínea: pega o escribe el texto y termina con %s en una línea aparte",
+
+	// Status line
+	"status.tokens":          "%d tokens",
+	"statusline.on":          "Línea de estado activada",
+	"statusline.off":         "Línea de estado desactivada",
+	"statusline.unavailable": "La línea de estado necesita una terminal interactiva",
+
+	// Provider profiles
+	"profile.title":    "Perfiles:",
+	"profile.none":     "No hay perfiles configurados. Añádelos en \"profiles\" en la configuración.",
+	"profile.hint":     "Usa %s para cambiar, %s para la configuración principal",
+	"profile.usage":    "Uso: %s",
+	"profile.switched": "Cambiado al perfil %s (%s, %s)",
+	"profile.reset":    "De vuelta a la configuración principal (%s, %s)",
+
+	// Raw output
+	"raw.on":  "Modo sin procesar activado: las respuestas se muestran tal cual",
+	"raw.off": "Modo sin procesar desactivado",
+
+	// Saved sessions
+	"session.title":       "Sesiones:",
+	"session.none":        "Todavía no hay sesiones guardadas",
+	"session.messages":    "%d msjs",
+	"session.hint":        "Usa %s para continuar una",
+	"session.usage":       "Uso: %s",
+	"session.resumed":     "Sesión %s reanudada (%d mensajes)",
+	"session.forked":      "Sesión %s bifurcada de %s con %d mensajes; %s vuelve a la original",
+	"session.fork_empty":  "Todavía no hay nada que bifurcar",
+	"session.disabled":    "Las sesiones no se guardan (save_history está desactivado)",
+	"session.fork_of":     "(bifurcada de %s)",
+	"session.save_failed": "no se pudo guardar la sesión: %v",
+
+	// Response ratings
+	"rating.title":   "Valoraciones:",
+	"rating.recent":  "Últimas respuestas valoradas:",
+	"rating.none":    "Todavía no hay respuestas valoradas. Valora la última respuesta con %s",
+	"rating.nothing": "Todavía no hay ninguna respuesta que valorar",
+	"rating.saved":   "Última respuesta valorada: %s",
+
+	// Model comparison
+	"compare.usage":  "Uso: %s (un destino es proveedor:modelo o el nombre de un perfil)",
+	"compare.target": "%s: %v",
+
+	// Truncated replies
+	"continue.truncated": "Respuesta truncada por max_tokens; %s obtiene el resto",
+	"continue.nothing":   "Todavía no hay ninguna respuesta que continuar",
+
+	// Context files
+	"context.title":       "Archivos de contexto enviados con cada petición:",
+	"context.none":        "No hay archivos de contexto. Añade uno con %s",
+	"context.tokens":      "~%d tokens",
+	"context.budget":      "~%d de %d tokens usados; los archivos que superan el límite se recortan",
+	"context.over_budget": "Los archivos superan el límite, así que los últimos se truncan",
+	"context.added":       "%s añadido al contexto",
+	"context.removed":     "%s quitado del contexto",
+	"context.cleared":     "Archivos de contexto borrados",
+	"context.not_found":   "%s no es un archivo de contexto",
+	"context.unreadable":  "no se puede leer %s: %v",
+	"context.usage":       "Uso: %s",
+
+	// Stop sequences
+	"stop.title":   "Secuencias de parada:",
+	"stop.none":    "No hay secuencias de parada. Defínelas con %s",
+	"stop.set":     "La generación ahora se detiene antes de %s",
+	"stop.cleared": "Secuencias de parada borradas",
+
+	// Shell commands typed with /shell
+	"shell.usage":     "Uso: %s",
+	"shell.no_output": "(sin salida)",
+
+	// Settings changed from the TUI
+	"settings.title":     "Ajustes (pulsa Enter para mantener un valor):",
+	"settings.ask":       "%s [%s]:",
+	"settings.saved":     "%s ahora es %q",
+	"settings.not_saved": "cambiado para esta sesión, pero no guardado: %v",
+	"settings.unchanged": "No se cambió ningún ajuste",
+	"settings.usage":     "Uso: %s",
+
+	// TUI help
+	"help.title":      "Comandos disponibles:",
+	"help.system":     "Comandos del sistema:",
+	"help.files":      "Operaciones de archivos:",
+	"help.shortcuts":  "Atajos de teclado:",
+	"help.clear":      "Limpiar la pantalla y reiniciar la sesión",
+	"help.stats":      "Mostrar estadísticas de la sesión",
+	"help.config":     "Mostrar la configuración actual",
+	"help.set":        "Cambiar un ajuste ahora y en el archivo de configuración",
+	"help.settings":   "Repasar proveedor, modelo, clave de API, temperatura y tokens máximos",
+	"help.persona":    "Listar o cambiar de persona",
+	"help.profile":    "Listar perfiles de proveedor o cambiar a uno",
+	"help.sessions":   "Listar las sesiones guardadas con su primera pregunta",
+	"help.resume":     "Reanudar una sesión guardada (por defecto: la última)",
+	"help.fork":       "Continuar la conversación en una copia de esta sesión",
+	"help.continue":   "Continuar la última respuesta donde se quedó",
+	"help.context":    "Listar, añadir o quitar archivos enviados como contexto en cada petición",
+	"help.stop":       "Mostrar o definir cadenas que terminan la generación (\\n para un salto de línea)",
+	"help.rate":       "Valorar la última respuesta, con una nota opcional",
+	"help.ratings":    "Resumir las valoraciones por modelo",
+	"help.compare":    "Hacer la misma pregunta a varios modelos y comparar",
+	"help.tools":      "Listar las herramientas disponibles o describir una",
+	"help.trash":      "Listar los archivos de la papelera o restaurar uno",
+	"help.undo":       "Deshacer el último cambio de archivos hecho por la IA",
+	"help.tx":         "Preparar cambios de archivos y aplicarlos todos a la vez",
+	"help.notools":    "Activar o desactivar las herramientas en esta sesión",
+	"help.verbose":    "Mostrar las intenciones detectadas y su confianza",
+	"help.statusline": "Fijar proveedor, modelo, directorio y tokens en la última fila",
+	"help.raw":        "Mostrar las respuestas tal cual, sin formato",
+	"help.edit":       "Abrir un archivo en tu editor ($EDITOR)",
+	"help.paste":      "Enviar varias líneas como un solo mensaje (o envolverlas en \"\"\")",
+	"help.shell":      "Ejecutar un comando de shell (los arriesgados se confirman)",
+	"help.help":       "Mostrar este mensaje de ayuda",
+	"help.exit":       "Salir de la aplicación",
+	"help.ls":         "Listar archivos y directorios",
+	"help.cat":        "Mostrar el contenido de un archivo",
+	"help.pwd":        "Mostrar el directorio actual",
+	"help.cd":         "Cambiar de directorio",
+	"help.create":     "Crear un archivo nuevo",
+	"help.mkdir":      "Crear un directorio",
+	"help.key.exit":   "Salir de la aplicación",
+	"help.key.enter":  "Enviar mensaje",
+
+	// TUI stats and config
+	"stats.title":        "Estadísticas de la sesión:",
+	"stats.none":         "Todavía no se han hecho solicitudes",
+	"stats.tools":        "Llamadas a herramientas: %d/%s en la última respuesta, %d/%s en el último minuto",
+	"config.title":       "Configuración actual:",
+	"config.provider":    "Proveedor:",
+	"config.model":       "Modelo:",
+	"config.temperature": "Temperatura:",
+	"config.max_tokens":  "Tokens máximos:",
+	"config.tools":       "Herramientas:",
+
+	// GUI
+	"gui.status.ready":      "Listo - Escribe tu mensaje abajo",
+	"gui.status.thinking":   "La IA está pensando...",
+	"gui.input.placeholder": "Escribe tu mensaje aquí... (Enter para nueva línea, Shift+Enter para enviar)",
+	"gui.welcome": `¡Bienvenido a Tala!
+
+Proveedor: %s  
+Modelo: %s  
+Herramientas: %v
+
+Escribe tu mensaje abajo para conversar con la IA. Puedes:
+- Hacer preguntas de forma natural
+- Pedir operaciones de archivos: "crea un archivo llamado test.txt"
+- Ejecutar comandos: "lista los archivos del directorio actual"
+- Pedir ayuda: "¿qué puedes hacer?"
+`,
+}
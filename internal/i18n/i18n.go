@@ -0,0 +1,94 @@
+// Package i18n provides translated UI strings for the TUI and GUI.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when no language is configured and as the
+// fallback for keys missing from a translation
+const DefaultLanguage = "en"
+
+// catalogs maps a language code to its message catalog
+var catalogs = map[string]map[string]string{
+	"en": english,
+	"es": spanish,
+}
+
+var current = DefaultLanguage
+
+// SetLanguage selects the active catalog. Unknown languages fall back to English.
+func SetLanguage(lang string) {
+	lang = normalize(lang)
+	if _, ok := catalogs[lang]; !ok {
+		lang = DefaultLanguage
+	}
+	current = lang
+}
+
+// Language returns the active language code
+func Language() string {
+	return current
+}
+
+// Detect picks the language to use: the configured value if set, otherwise
+// the locale from $LC_ALL, $LC_MESSAGES or $LANG, otherwise English
+func Detect(configured string) string {
+	candidates := []string{configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, candidate := range candidates {
+		lang := normalize(candidate)
+		if lang == "" {
+			continue
+		}
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		// An explicit but unsupported setting should not be overridden by the environment
+		if candidate == configured {
+			return DefaultLanguage
+		}
+	}
+	return DefaultLanguage
+}
+
+// Available returns the supported language codes
+func Available() []string {
+	var langs []string
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// T returns the translated string for key, falling back to English and
+// finally to the key itself
+func T(key string) string {
+	if msg, ok := catalogs[current][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[DefaultLanguage][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// Tf formats the translated string for key with the given arguments
+func Tf(key string, args ...interface{}) string {
+	return fmt.Sprintf(T(key), args...)
+}
+
+// normalize converts locale strings like "es_ES.UTF-8" to "es"
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "c" || locale == "posix" {
+		return ""
+	}
+	if idx := strings.IndexAny(locale, "_.-@"); idx != -1 {
+		locale = locale[:idx]
+	}
+	return locale
+}
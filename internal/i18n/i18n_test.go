@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestTranslationFallback(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("es")
+	if got := T("common.goodbye"); got != "¡Hasta luego!" {
+		t.Errorf("Expected Spanish goodbye, got %q", got)
+	}
+
+	// gui.help is not translated and must fall back to English
+	if got := T("gui.help"); got != english["gui.help"] {
+		t.Errorf("Expected English fallback for missing key, got %q", got)
+	}
+
+	if got := T("missing.key"); got != "missing.key" {
+		t.Errorf("Expected unknown key to be returned as-is, got %q", got)
+	}
+}
+
+func TestSetLanguageUnknown(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("xx")
+	if Language() != DefaultLanguage {
+		t.Errorf("Expected unknown language to fall back to %s, got %s", DefaultLanguage, Language())
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		lang       string
+		want       string
+	}{
+		{"configured wins", "es", "en_US.UTF-8", "es"},
+		{"from LANG", "", "es_ES.UTF-8", "es"},
+		{"C locale", "", "C", "en"},
+		{"unsupported LANG", "", "ja_JP.UTF-8", "en"},
+		{"unsupported configured", "ja", "es_ES.UTF-8", "en"},
+		{"nothing set", "", "", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", tt.lang)
+			if got := Detect(tt.configured); got != tt.want {
+				t.Errorf("Detect(%q) with LANG=%q = %q, want %q", tt.configured, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogsOnlyContainKnownKeys(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for key := range catalog {
+			if _, ok := english[key]; !ok {
+				t.Errorf("Catalog %s has key %q missing from the English catalog", lang, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package logging sets up tala's leveled diagnostics on top of log/slog. Logs go
+// to stderr so they never mix with a headless response on stdout, and anything
+// that looks like a credential is redacted before it is written.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultLevel keeps normal runs quiet: only warnings and errors are shown
+const DefaultLevel = "warn"
+
+// secretKeys are attribute names whose values are never logged
+var secretKeys = []string{"api_key", "apikey", "key", "token", "secret", "password", "authorization"}
+
+// secretPattern matches credentials embedded in messages, such as an API key
+// echoed back in a provider error
+var secretPattern = regexp.MustCompile(`(?i)(sk-[a-z0-9_-]{8,}|bearer\s+[a-z0-9._~+/=-]{8,}|x-api-key:\s*\S+)`)
+
+// Redacted replaces secret values in the logs
+const Redacted = "[REDACTED]"
+
+// ParseLevel turns "debug", "info", "warn" or "error" into a slog level. An empty
+// name means DefaultLevel.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "", "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log level must be \"debug\", \"info\", \"warn\" or \"error\", got %q", name)
+}
+
+// ValidateLevel checks a log level name
+func ValidateLevel(name string) error {
+	_, err := ParseLevel(name)
+	return err
+}
+
+// ValidateFormat checks a log format: "text" (the default) or "json"
+func ValidateFormat(format string) error {
+	switch format {
+	case "", "text", "json":
+		return nil
+	}
+	return fmt.Errorf("log format must be \"text\" or \"json\", got %q", format)
+}
+
+// New returns a logger writing to w at the given level and format. Text logs
+// leave out the time, since they are read in a terminal as they happen; JSON
+// logs keep it for collection.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	minLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: minLevel}
+	if format == "json" {
+		opts.ReplaceAttr = redact
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return redact(groups, a)
+	}
+	return slog.New(slog.NewTextHandler(w, opts)), nil
+}
+
+// Setup makes a stderr logger at the given level and format the default for
+// slog and the log package
+func Setup(level, format string) error {
+	logger, err := New(os.Stderr, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// Fatal logs an error and exits with status 1
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// Redact hides credentials found in s
+func Redact(s string) string {
+	return secretPattern.ReplaceAllString(s, Redacted)
+}
+
+// redact hides secret attributes and credentials inside messages and errors
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if isSecretKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return a
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if key == secret || strings.HasSuffix(key, "_"+secret) || strings.HasSuffix(key, "."+secret) {
+			return true
+		}
+	}
+	return false
+}
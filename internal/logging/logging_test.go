@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewFiltersByLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "info", "text")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("shown", "provider", "ollama")
+
+	if strings.Contains(out.String(), "hidden") {
+		t.Errorf("Expected debug message to be filtered out, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), `level=INFO msg=shown provider=ollama`) {
+		t.Errorf("Unexpected text output: %q", out.String())
+	}
+	if strings.Contains(out.String(), "time=") {
+		t.Errorf("Expected text logs without a time, got %q", out.String())
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "debug", "json")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger.Debug("request", "model", "llama3")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", out.String(), err)
+	}
+	if entry["level"] != "DEBUG" || entry["msg"] != "request" || entry["model"] != "llama3" || entry["time"] == nil {
+		t.Errorf("Unexpected JSON entry: %v", entry)
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	var out bytes.Buffer
+	logger, _ := New(&out, "debug", "text")
+
+	logger.Info("calling provider", "api_key", "plain-secret-value", "auth.token", "abc")
+	logger.Error("request failed", "error", errors.New("401: invalid key sk-abcdefghijklmnop"))
+	logger.Warn("header Authorization: Bearer abcdefghijklmnop")
+
+	for _, secret := range []string{"plain-secret-value", "abc ", "sk-abcdefghijklmnop", "Bearer abcdefghijklmnop"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Expected %q to be redacted, got %q", secret, out.String())
+		}
+	}
+	if strings.Count(out.String(), Redacted) != 4 {
+		t.Errorf("Expected 4 redactions, got %q", out.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range []string{"", "debug", "INFO", "warn", "warning", "error"} {
+		if err := ValidateLevel(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	if err := ValidateLevel("verbose"); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+	if err := ValidateFormat("xml"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,171 @@
+// Package metrics collects request, token and tool counters and serves them in
+// the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram bounds in seconds. Local models answer
+// in well under a second or take minutes, so the range is wide.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Registry holds the metrics of one process
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[string]*family
+	histograms map[string]*family
+}
+
+// family is a metric name with its help text and one series per label set
+type family struct {
+	help   string
+	series map[string]*series
+}
+
+type series struct {
+	labels  string
+	value   float64   // counters
+	buckets []float64 // histograms: cumulative counts per bound in DefaultBuckets
+	sum     float64
+	count   uint64
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*family),
+		histograms: make(map[string]*family),
+	}
+}
+
+// Add increases a counter. labels are name/value pairs.
+func (r *Registry) Add(name, help string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seriesFor(r.counters, name, help, labels).value += value
+}
+
+// Observe records a value, such as a duration in seconds, in a histogram
+func (r *Registry) Observe(name, help string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.seriesFor(r.histograms, name, help, labels)
+	if s.buckets == nil {
+		s.buckets = make([]float64, len(DefaultBuckets))
+	}
+	for i, bound := range DefaultBuckets {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (r *Registry) seriesFor(families map[string]*family, name, help string, labels []string) *series {
+	f, ok := families[name]
+	if !ok {
+		f = &family{help: help, series: make(map[string]*series)}
+		families[name] = f
+	}
+	key := formatLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		f.series[key] = s
+	}
+	return s
+}
+
+// formatLabels renders name/value pairs as {a="1",b="2"}, or "" without labels
+func formatLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		pairs = append(pairs, labels[i]+`="`+value+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, name := range sortedNames(r.counters) {
+		f := r.counters[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, f.help, name)
+		for _, s := range sortedSeries(f) {
+			fmt.Fprintf(&b, "%s%s %g\n", name, s.labels, s.value)
+		}
+	}
+	for _, name := range sortedNames(r.histograms) {
+		f := r.histograms[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s histogram\n", name, f.help, name)
+		for _, s := range sortedSeries(f) {
+			for i, bound := range DefaultBuckets {
+				fmt.Fprintf(&b, "%s_bucket%s %g\n", name, withLabel(s.labels, "le", fmt.Sprintf("%g", bound)), s.buckets[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, withLabel(s.labels, "le", "+Inf"), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %g\n", name, s.labels, s.sum)
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, s.labels, s.count)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// withLabel adds one more label to a rendered label set
+func withLabel(labels, name, value string) string {
+	extra := fmt.Sprintf(`%s="%s"`, name, value)
+	if labels == "" {
+		return "{" + extra + "}"
+	}
+	return labels[:len(labels)-1] + "," + extra + "}"
+}
+
+func sortedNames(families map[string]*family) []string {
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedSeries(f *family) []*series {
+	list := make([]*series, 0, len(f.series))
+	for _, s := range f.series {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].labels < list[j].labels })
+	return list
+}
+
+// Handler serves the registry for Prometheus to scrape
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// Serve exposes the registry at addr under /metrics. It returns once the listener
+// fails; callers usually run it in a goroutine.
+func (r *Registry) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	r.Add("tala_requests_total", "Requests sent to providers.", 1, "provider", "Mock", "status", "ok")
+	r.Add("tala_requests_total", "Requests sent to providers.", 1, "provider", "Mock", "status", "ok")
+	r.Add("tala_requests_total", "Requests sent to providers.", 1, "provider", "Mock", "status", "error")
+	r.Observe("tala_request_duration_seconds", "Provider request latency.", 0.3, "provider", "Mock")
+	r.Observe("tala_request_duration_seconds", "Provider request latency.", 7, "provider", "Mock")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE tala_requests_total counter\n",
+		`tala_requests_total{provider="Mock",status="ok"} 2` + "\n",
+		`tala_requests_total{provider="Mock",status="error"} 1` + "\n",
+		"# TYPE tala_request_duration_seconds histogram\n",
+		`tala_request_duration_seconds_bucket{provider="Mock",le="0.25"} 0` + "\n",
+		`tala_request_duration_seconds_bucket{provider="Mock",le="0.5"} 1` + "\n",
+		`tala_request_duration_seconds_bucket{provider="Mock",le="10"} 2` + "\n",
+		`tala_request_duration_seconds_bucket{provider="Mock",le="+Inf"} 2` + "\n",
+		`tala_request_duration_seconds_sum{provider="Mock"} 7.3` + "\n",
+		`tala_request_duration_seconds_count{provider="Mock"} 2` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	r := NewRegistry()
+	r.Add("tala_tool_calls_total", "Tool calls.", 1, "tool", `say "hi"`)
+
+	var b strings.Builder
+	r.WriteText(&b)
+	if !strings.Contains(b.String(), `tala_tool_calls_total{tool="say \"hi\""} 1`) {
+		t.Errorf("Expected escaped label value, got:\n%s", b.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Add("tala_tokens_total", "Tokens.", 5)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") || !strings.Contains(string(body), "tala_tokens_total 5") {
+		t.Errorf("Unexpected response %q: %s", rec.Header().Get("Content-Type"), body)
+	}
+}
@@ -0,0 +1,146 @@
+// Package prompt fills {{.name}} placeholders in reusable prompts.
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Render resolves the {{.name}} placeholders in text with text/template. Values
+// come from vars first, then from the environment. A placeholder may give a
+// fallback with {{.name | default "value"}}; any other unresolved name is an
+// error listing all of them. Text without "{{" is returned unchanged.
+func Render(text string, vars map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("prompt").Funcs(template.FuncMap{"default": defaultValue}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	fields := make(map[string]bool) // name -> optional (has a default or is tested by if/with/range)
+	collectFields(tmpl.Tree.Root, fields)
+
+	data := make(map[string]interface{})
+	var missing []string
+	for name, optional := range fields {
+		if value, ok := vars[name]; ok {
+			data[name] = value
+		} else if value, ok := os.LookupEnv(name); ok {
+			data[name] = value
+		} else if !optional {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("prompt variables not set: %s (use --var name=value or the environment)", strings.Join(missing, ", "))
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("rendering prompt: %w", err)
+	}
+	return out.String(), nil
+}
+
+// ParseVars turns name=value pairs from --var flags into a map
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("expected name=value, got %q", pair)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	return vars, nil
+}
+
+// defaultValue backs {{.name | default "value"}}: the value, or the fallback when
+// the variable is unset or empty
+func defaultValue(fallback string, value interface{}) string {
+	if value == nil || value == "" {
+		return fallback
+	}
+	return fmt.Sprint(value)
+}
+
+// collectFields records the top-level fields a template uses and whether they
+// are optional: every use passes through default, or an if/with/range tests it
+func collectFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, fields)
+		}
+	case *parse.ActionNode:
+		collectPipe(n.Pipe, fields)
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, fields)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, fields)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, fields)
+	case *parse.TemplateNode:
+		collectPipe(n.Pipe, fields)
+	}
+}
+
+func collectBranch(branch *parse.BranchNode, fields map[string]bool) {
+	collectFields(branch.List, fields)
+	collectFields(branch.ElseList, fields)
+
+	// A variable the condition tests is optional, including inside the branch
+	if branch.Pipe != nil {
+		for _, cmd := range branch.Pipe.Cmds {
+			for _, arg := range cmd.Args {
+				if field, ok := arg.(*parse.FieldNode); ok {
+					fields[field.Ident[0]] = true
+				}
+			}
+		}
+	}
+}
+
+func collectPipe(pipe *parse.PipeNode, fields map[string]bool) {
+	if pipe == nil {
+		return
+	}
+	hasDefault := false
+	for _, cmd := range pipe.Cmds {
+		if len(cmd.Args) > 0 {
+			if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "default" {
+				hasDefault = true
+			}
+		}
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				addField(fields, a.Ident[0], hasDefault)
+			case *parse.PipeNode:
+				collectPipe(a, fields)
+			}
+		}
+	}
+}
+
+// addField marks a field as optional only if all its uses are
+func addField(fields map[string]bool, name string, hasDefault bool) {
+	if optional, seen := fields[name]; seen {
+		fields[name] = optional && hasDefault
+		return
+	}
+	fields[name] = hasDefault
+}
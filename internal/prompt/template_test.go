@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	t.Setenv("TALA_TEST_LANG", "Go")
+
+	tests := []struct {
+		name string
+		text string
+		vars map[string]string
+		want string
+	}{
+		{"no placeholders", "Review {this}", nil, "Review {this}"},
+		{"flag variable", "Review {{.file}}", map[string]string{"file": "main.go"}, "Review main.go"},
+		{"environment", "Write {{.TALA_TEST_LANG}}", nil, "Write Go"},
+		{"flag beats environment", "Write {{.TALA_TEST_LANG}}", map[string]string{"TALA_TEST_LANG": "Rust"}, "Write Rust"},
+		{"default used", `Focus on {{.focus | default "bugs"}}`, nil, "Focus on bugs"},
+		{"default overridden", `Focus on {{.focus | default "bugs"}}`, map[string]string{"focus": "style"}, "Focus on style"},
+		{"default as call", `Focus on {{default "bugs" .focus}}`, nil, "Focus on bugs"},
+		{"optional section", `Review{{if .file}} {{.file}}{{end}}`, nil, "Review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, tt.vars)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMissingVariables(t *testing.T) {
+	_, err := Render(`Compare {{.old}} with {{.new}} in {{.lang | default "Go"}}`, map[string]string{"old": "a.go"})
+	if err == nil || !strings.Contains(err.Error(), "not set: new") {
+		t.Errorf("Expected the missing variable to be named, got %v", err)
+	}
+
+	// A default on one use does not cover another use without one
+	_, err = Render(`{{.x | default "1"}} and {{.x}}`, nil)
+	if err == nil || !strings.Contains(err.Error(), "x") {
+		t.Errorf("Expected x to be required, got %v", err)
+	}
+
+	if _, err := Render("Broken {{.x", nil); err == nil {
+		t.Error("Expected a template syntax error")
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"file=main.go", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vars["file"] != "main.go" || vars["query"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("Unexpected vars: %v", vars)
+	}
+	if _, err := ParseVars([]string{"novalue"}); err == nil {
+		t.Error("Expected an error for a pair without =")
+	}
+}
@@ -0,0 +1,291 @@
+// Package retrieval indexes a directory of documents as embedded chunks and
+// finds the chunks most relevant to a question.
+package retrieval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File overrides where the index is stored, mainly for tests. When empty it is
+// $XDG_DATA_HOME/tala/index.json (~/.local/share/tala/index.json).
+var File = ""
+
+// ErrNoIndex reports that no index has been built
+var ErrNoIndex = errors.New("no document index")
+
+const (
+	// ChunkWords is the target size of a chunk, in words
+	ChunkWords = 200
+	// chunkOverlap is how many words a long paragraph's pieces share, so a
+	// sentence cut at a boundary still appears whole in one of them
+	chunkOverlap = 40
+	// maxFileSize skips files too large to be documents
+	maxFileSize = 1 << 20
+	// embedBatch is how many chunks are embedded per request
+	embedBatch = 32
+)
+
+// Embedder turns texts into vectors; ai providers with embeddings implement it
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Chunk is a piece of a document with its embedding
+type Chunk struct {
+	Source string    `json:"source"` // path relative to the indexed directory
+	Text   string    `json:"text"`
+	Vector []float32 `json:"vector"`
+}
+
+// Index is a directory's chunks, embedded by one provider and model. Queries
+// must be embedded the same way for the scores to mean anything.
+type Index struct {
+	Root     string    `json:"root"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Created  time.Time `json:"created"`
+	Files    int       `json:"files"`
+	Chunks   []Chunk   `json:"chunks"`
+}
+
+// Result is a chunk found by Search with its cosine similarity to the query
+type Result struct {
+	Chunk
+	Score float64
+}
+
+// Path returns where the index is stored
+func Path() (string, error) {
+	if File != "" {
+		return File, nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "tala", "index.json"), nil
+}
+
+// Build chunks every text file beneath root and embeds the chunks. Hidden
+// files and directories, binary files and files over 1 MB are skipped.
+// progress, if set, is called before each file is read.
+func Build(ctx context.Context, root string, embedder Embedder, progress func(file string)) (*Index, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{Root: root, Created: time.Now()}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if progress != nil {
+			progress(rel)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil // binary
+		}
+		chunks := ChunkText(string(data))
+		if len(chunks) == 0 {
+			return nil
+		}
+		index.Files++
+		for _, text := range chunks {
+			index.Chunks = append(index.Chunks, Chunk{Source: rel, Text: text})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Chunks) == 0 {
+		return nil, fmt.Errorf("no text files found in %s", root)
+	}
+
+	for start := 0; start < len(index.Chunks); start += embedBatch {
+		end := min(start+embedBatch, len(index.Chunks))
+		texts := make([]string, 0, end-start)
+		for _, chunk := range index.Chunks[start:end] {
+			texts = append(texts, chunk.Text)
+		}
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embedding chunks: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return nil, fmt.Errorf("embedding chunks: got %d vectors for %d chunks", len(vectors), len(texts))
+		}
+		for i, vector := range vectors {
+			index.Chunks[start+i].Vector = vector
+		}
+	}
+	return index, nil
+}
+
+// ChunkText splits text into chunks of about ChunkWords words. Paragraphs are
+// kept together where they fit; longer ones are split with some overlap.
+func ChunkText(text string) []string {
+	var chunks []string
+	var current []string
+	words := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current, words = nil, 0
+		}
+	}
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		fields := strings.Fields(paragraph)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > ChunkWords {
+			flush()
+			for start := 0; start < len(fields); start += ChunkWords - chunkOverlap {
+				end := min(start+ChunkWords, len(fields))
+				chunks = append(chunks, strings.Join(fields[start:end], " "))
+				if end == len(fields) {
+					break
+				}
+			}
+			continue
+		}
+		if words+len(fields) > ChunkWords {
+			flush()
+		}
+		current = append(current, paragraph)
+		words += len(fields)
+	}
+	flush()
+	return chunks
+}
+
+// Search returns the k chunks most similar to the query, best first
+func (index *Index) Search(ctx context.Context, embedder Embedder, query string, k int) ([]Result, error) {
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("got %d vectors for the query", len(vectors))
+	}
+
+	results := make([]Result, 0, len(index.Chunks))
+	for _, chunk := range index.Chunks {
+		results = append(results, Result{Chunk: chunk, Score: Cosine(vectors[0], chunk.Vector)})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 when their lengths
+// differ or either is zero
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Save writes the index, replacing any previous one
+func (index *Index) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads the stored index, returning ErrNoIndex when none was built
+func Load() (*Index, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoIndex
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &index, nil
+}
+
+// Remove deletes the stored index
+func Remove() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNoIndex
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
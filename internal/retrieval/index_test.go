@@ -0,0 +1,134 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// wordEmbedder embeds a text as counts of a few words, so texts sharing those
+// words are similar
+type wordEmbedder struct {
+	calls int
+}
+
+var vocabulary = []string{"cat", "dog", "tax", "invoice"}
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(vocabulary))
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			for j, v := range vocabulary {
+				if strings.Trim(word, ".,?") == v {
+					vector[j]++
+				}
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func TestChunkText(t *testing.T) {
+	if chunks := ChunkText("First paragraph.\n\nSecond paragraph.\n\n\n"); len(chunks) != 1 || chunks[0] != "First paragraph.\n\nSecond paragraph." {
+		t.Errorf("Expected short paragraphs in one chunk, got %q", chunks)
+	}
+
+	long := strings.Repeat("word ", ChunkWords*2)
+	chunks := ChunkText("Intro.\n\n" + long)
+	if len(chunks) != 4 || chunks[0] != "Intro." {
+		t.Fatalf("Expected the intro and three overlapping pieces, got %d chunks", len(chunks))
+	}
+	for _, chunk := range chunks[1:] {
+		if n := len(strings.Fields(chunk)); n > ChunkWords {
+			t.Errorf("Expected at most %d words per chunk, got %d", ChunkWords, n)
+		}
+	}
+
+	if chunks := ChunkText("  \n\n "); len(chunks) != 0 {
+		t.Errorf("Expected no chunks for blank text, got %q", chunks)
+	}
+}
+
+func TestBuildAndSearch(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "pets.md"), []byte("The cat sleeps. The dog barks."), 0644)
+	os.MkdirAll(filepath.Join(dir, "finance"), 0755)
+	os.WriteFile(filepath.Join(dir, "finance", "tax.txt"), []byte("Send the invoice before the tax deadline."), 0644)
+	os.WriteFile(filepath.Join(dir, "image.png"), []byte("cat\x00\x01"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, ".git", "config"), []byte("cat cat cat"), 0644)
+
+	embedder := &wordEmbedder{}
+	var seen []string
+	index, err := Build(context.Background(), dir, embedder, func(file string) { seen = append(seen, file) })
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if index.Files != 2 || len(index.Chunks) != 2 {
+		t.Fatalf("Expected two text files indexed, got %d files and %d chunks: %+v", index.Files, len(index.Chunks), index.Chunks)
+	}
+	for _, file := range seen {
+		if strings.HasPrefix(file, ".git") {
+			t.Errorf("Expected hidden directories to be skipped, saw %s", file)
+		}
+	}
+
+	results, err := index.Search(context.Background(), embedder, "When is the tax invoice due?", 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Source != filepath.Join("finance", "tax.txt") || results[0].Score <= 0.9 {
+		t.Errorf("Expected the tax file to match best, got %+v", results)
+	}
+
+	if _, err := Build(context.Background(), t.TempDir(), embedder, nil); err == nil {
+		t.Error("Expected an error for a directory without text files")
+	}
+}
+
+func TestSaveLoadRemove(t *testing.T) {
+	File = filepath.Join(t.TempDir(), "tala", "index.json")
+	defer func() { File = "" }()
+
+	if _, err := Load(); !errors.Is(err, ErrNoIndex) {
+		t.Fatalf("Expected ErrNoIndex before indexing, got %v", err)
+	}
+
+	index := &Index{Root: "/docs", Provider: "ollama", Model: "nomic-embed-text", Files: 1,
+		Chunks: []Chunk{{Source: "a.md", Text: "hello", Vector: []float32{0.5, 1}}}}
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Model != "nomic-embed-text" || len(loaded.Chunks) != 1 || loaded.Chunks[0].Vector[1] != 1 {
+		t.Errorf("Unexpected index after loading: %+v", loaded)
+	}
+
+	if err := Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := Remove(); !errors.Is(err, ErrNoIndex) {
+		t.Errorf("Expected ErrNoIndex after removing, got %v", err)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if got := Cosine([]float32{1, 0}, []float32{2, 0}); got < 0.999 {
+		t.Errorf("Expected parallel vectors to score 1, got %v", got)
+	}
+	if got := Cosine([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("Expected orthogonal vectors to score 0, got %v", got)
+	}
+	if got := Cosine([]float32{1}, []float32{1, 0}); got != 0 {
+		t.Errorf("Expected mismatched lengths to score 0, got %v", got)
+	}
+}
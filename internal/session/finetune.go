@@ -0,0 +1,142 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"tala/internal/ai"
+)
+
+// finetuneSchema describes one line of an OpenAI chat fine-tuning file
+var finetuneSchema = mustSchema(`{
+	"type": "object",
+	"required": ["messages"],
+	"properties": {
+		"messages": {
+			"type": "array",
+			"minItems": 2,
+			"items": {
+				"type": "object",
+				"required": ["role", "content"],
+				"properties": {
+					"role": {"enum": ["system", "user", "assistant"]},
+					"content": {"type": "string", "minLength": 1}
+				}
+			}
+		}
+	}
+}`)
+
+func mustSchema(text string) map[string]interface{} {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// FinetuneMessage is a message in a fine-tuning example
+type FinetuneMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// FinetuneExample is one line of an OpenAI chat fine-tuning file
+type FinetuneExample struct {
+	Messages []FinetuneMessage `json:"messages"`
+}
+
+// FinetuneOptions controls ExportFinetune
+type FinetuneOptions struct {
+	SystemPrompt string // added as the first message of every example when set
+	UpRatedOnly  bool   // one example per up-rated response instead of one per session
+}
+
+// FinetuneStats reports what ExportFinetune wrote and skipped
+type FinetuneStats struct {
+	Sessions int // sessions that contributed at least one example
+	Examples int
+	Skipped  int // examples that failed validation
+}
+
+// ExportFinetune writes the stored sessions, oldest first, as OpenAI chat
+// fine-tuning JSONL. Each example is checked against the format before it is
+// written; invalid ones (such as an empty reply) are skipped and counted.
+func ExportFinetune(w io.Writer, opts FinetuneOptions) (FinetuneStats, error) {
+	var stats FinetuneStats
+	sessions, err := List()
+	if err != nil {
+		return stats, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Started.Before(sessions[j].Started)
+	})
+
+	for _, info := range sessions {
+		s, err := Load(info.ID)
+		if err != nil {
+			return stats, err
+		}
+
+		written := 0
+		for _, example := range finetuneExamples(s.Messages, opts) {
+			if err := ValidateFinetuneExample(example); err != nil {
+				stats.Skipped++
+				continue
+			}
+			line, err := json.Marshal(example)
+			if err != nil {
+				return stats, err
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return stats, err
+			}
+			written++
+		}
+		if written > 0 {
+			stats.Sessions++
+			stats.Examples += written
+		}
+	}
+	return stats, nil
+}
+
+// finetuneExamples turns a conversation into examples: the whole conversation,
+// or the conversation up to each up-rated response
+func finetuneExamples(messages []Message, opts FinetuneOptions) []FinetuneExample {
+	var prefix []FinetuneMessage
+	if opts.SystemPrompt != "" {
+		prefix = append(prefix, FinetuneMessage{Role: "system", Content: opts.SystemPrompt})
+	}
+
+	var examples []FinetuneExample
+	conversation := prefix
+	for _, message := range messages {
+		conversation = append(conversation, FinetuneMessage{Role: message.Role, Content: message.Content})
+		if opts.UpRatedOnly && message.Role == "assistant" && message.Rating == RatingUp {
+			examples = append(examples, FinetuneExample{Messages: append([]FinetuneMessage(nil), conversation...)})
+		}
+	}
+	if !opts.UpRatedOnly && len(conversation) > len(prefix) {
+		examples = append(examples, FinetuneExample{Messages: conversation})
+	}
+	return examples
+}
+
+// ValidateFinetuneExample checks an example against the OpenAI chat fine-tuning
+// format: system, user and assistant messages with content, ending in a reply
+func ValidateFinetuneExample(example FinetuneExample) error {
+	data, err := json.Marshal(example)
+	if err != nil {
+		return err
+	}
+	if err := ai.ValidateJSONResponse(string(data), finetuneSchema); err != nil {
+		return err
+	}
+	if last := example.Messages[len(example.Messages)-1]; last.Role != "assistant" {
+		return fmt.Errorf("example must end with an assistant message, not %s", last.Role)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportFinetune(t *testing.T) {
+	useTestDir(t)
+
+	s := New()
+	s.Append("user", "Capital of France?")
+	s.Append("assistant", "Paris.")
+	s.Append("user", "And of Spain?")
+	s.Append("assistant", "Barcelona.")
+	s.Rate(RatingDown, "wrong")
+
+	var out bytes.Buffer
+	stats, err := ExportFinetune(&out, FinetuneOptions{SystemPrompt: "Be brief."})
+	if err != nil {
+		t.Fatalf("ExportFinetune failed: %v", err)
+	}
+	if stats.Examples != 1 || stats.Sessions != 1 {
+		t.Errorf("Expected one example from one session, got %+v", stats)
+	}
+
+	var example FinetuneExample
+	if err := json.Unmarshal(out.Bytes(), &example); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", out.String(), err)
+	}
+	if len(example.Messages) != 5 || example.Messages[0].Role != "system" || example.Messages[4].Content != "Barcelona." {
+		t.Errorf("Unexpected example: %+v", example)
+	}
+	if strings.Contains(out.String(), "rating") || strings.Contains(out.String(), "time") {
+		t.Errorf("Expected only role and content in the export, got %s", out.String())
+	}
+}
+
+func TestExportFinetuneUpRatedOnly(t *testing.T) {
+	useTestDir(t)
+
+	s := New()
+	s.Append("user", "Capital of France?")
+	s.Append("assistant", "Paris.")
+	s.Rate(RatingUp, "")
+	s.Append("user", "And of Spain?")
+	s.Append("assistant", "Barcelona.")
+	s.Rate(RatingDown, "")
+
+	var out bytes.Buffer
+	stats, err := ExportFinetune(&out, FinetuneOptions{UpRatedOnly: true})
+	if err != nil {
+		t.Fatalf("ExportFinetune failed: %v", err)
+	}
+	if stats.Examples != 1 {
+		t.Fatalf("Expected only the up-rated exchange, got %+v", stats)
+	}
+	if strings.Contains(out.String(), "Barcelona") {
+		t.Errorf("Expected the down-rated response to be left out, got %s", out.String())
+	}
+}
+
+func TestValidateFinetuneExample(t *testing.T) {
+	valid := FinetuneExample{Messages: []FinetuneMessage{{"user", "Hi"}, {"assistant", "Hello"}}}
+	if err := ValidateFinetuneExample(valid); err != nil {
+		t.Errorf("Expected a valid example, got %v", err)
+	}
+
+	invalid := []FinetuneExample{
+		{Messages: []FinetuneMessage{{"assistant", "Hello"}}},
+		{Messages: []FinetuneMessage{{"user", "Hi"}, {"assistant", ""}}},
+		{Messages: []FinetuneMessage{{"user", "Hi"}, {"tool", "output"}}},
+		{Messages: []FinetuneMessage{{"assistant", "Hello"}, {"user", "Hi"}}},
+	}
+	for _, example := range invalid {
+		if err := ValidateFinetuneExample(example); err == nil {
+			t.Errorf("Expected %+v to be rejected", example)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Package session stores conversations on disk so they can be resumed later.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir overrides where sessions are stored, mainly for tests. When empty they live
+// in $XDG_DATA_HOME/tala/sessions (~/.local/share/tala/sessions).
+var Dir = ""
+
+// ErrNotFound reports that no stored session matches an ID
+var ErrNotFound = errors.New("session not found")
+
+// ErrEmpty reports that a session has no messages to fork
+var ErrEmpty = errors.New("session has no messages")
+
+// ErrNothingToRate reports that a session has no response to rate yet
+var ErrNothingToRate = errors.New("no response to rate yet")
+
+// ErrNoResponse reports that a session has no response to extend yet
+var ErrNoResponse = errors.New("no response yet")
+
+// Ratings a response can be given with Rate
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// maxPreview bounds the first-prompt preview shown by List
+const maxPreview = 60
+
+// Message is one turn of a conversation
+type Message struct {
+	Role    string    `json:"role"` // "user" or "assistant"
+	Content string    `json:"content"`
+	Time    time.Time `json:"time"`
+	Model   string    `json:"model,omitempty"`  // model that wrote an assistant message
+	Rating  string    `json:"rating,omitempty"` // RatingUp or RatingDown, set by Rate
+	Note    string    `json:"note,omitempty"`   // the user's comment on the rating
+}
+
+// RatedMessage is a rated response together with the prompt it answered
+type RatedMessage struct {
+	SessionID string
+	Prompt    string
+	Message
+}
+
+// Session is a conversation stored as one JSONL file, a message per line
+type Session struct {
+	ID       string
+	Parent   string // session this one was forked from, if any
+	Messages []Message
+}
+
+// meta is kept in <id>.meta.json beside a forked session's messages
+type meta struct {
+	Parent string `json:"parent"`
+}
+
+// Info summarises a stored session for listings
+type Info struct {
+	ID       string
+	Started  time.Time
+	Updated  time.Time
+	Preview  string // start of the first prompt
+	Messages int
+	Parent   string
+}
+
+// dir returns the directory holding the session files
+func dir() (string, error) {
+	if Dir != "" {
+		return Dir, nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "tala", "sessions"), nil
+}
+
+// New starts a session with an ID based on the current time. Nothing is written
+// until the first message, so abandoned sessions leave no file behind.
+func New() *Session {
+	base := time.Now().Format("20060102-150405")
+	id := base
+	if d, err := dir(); err == nil {
+		// Two sessions started in the same second get distinct IDs
+		for n := 2; ; n++ {
+			if _, err := os.Stat(filepath.Join(d, id+".jsonl")); os.IsNotExist(err) {
+				break
+			}
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+	}
+	return &Session{ID: id}
+}
+
+// Append records a message in memory and on disk
+func (s *Session) Append(role, content string) error {
+	return s.Add(Message{Role: role, Content: content})
+}
+
+// Add records a message with extra details such as the model, stamping the time
+// if it is not set
+func (s *Session) Add(message Message) error {
+	if message.Time.IsZero() {
+		message.Time = time.Now()
+	}
+	s.Messages = append(s.Messages, message)
+	return s.write(message)
+}
+
+// Rate marks the latest response as RatingUp or RatingDown with an optional
+// note, replacing any earlier rating of it
+func (s *Session) Rate(rating, note string) error {
+	if rating != RatingUp && rating != RatingDown {
+		return fmt.Errorf("rating must be %q or %q, got %q", RatingUp, RatingDown, rating)
+	}
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role != "assistant" {
+			continue
+		}
+		s.Messages[i].Rating = rating
+		s.Messages[i].Note = note
+		return s.rewrite()
+	}
+	return ErrNothingToRate
+}
+
+// ExtendLast appends text to the latest response, such as the rest of a reply
+// that was cut off
+func (s *Session) ExtendLast(text string) error {
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role == "assistant" {
+			s.Messages[i].Content += text
+			return s.rewrite()
+		}
+	}
+	return ErrNoResponse
+}
+
+// Rated returns every rated response across the stored sessions, oldest first
+func Rated() ([]RatedMessage, error) {
+	sessions, err := List()
+	if err != nil {
+		return nil, err
+	}
+	d, _ := dir()
+
+	var rated []RatedMessage
+	for _, info := range sessions {
+		messages, err := readMessages(filepath.Join(d, info.ID+".jsonl"))
+		if err != nil {
+			continue
+		}
+		prompt := ""
+		for _, message := range messages {
+			if message.Role == "user" {
+				prompt = message.Content
+			}
+			if message.Role == "assistant" && message.Rating != "" {
+				rated = append(rated, RatedMessage{SessionID: info.ID, Prompt: prompt, Message: message})
+			}
+		}
+	}
+	sort.Slice(rated, func(i, j int) bool {
+		return rated[i].Time.Before(rated[j].Time)
+	})
+	return rated, nil
+}
+
+// Fork copies the first n messages of s into a new session whose parent is s,
+// so the conversation can continue differently without changing s. All messages
+// are copied when n is 0 or more than s has.
+func (s *Session) Fork(n int) (*Session, error) {
+	if len(s.Messages) == 0 {
+		return nil, ErrEmpty
+	}
+	if n <= 0 || n > len(s.Messages) {
+		n = len(s.Messages)
+	}
+
+	fork := s.Branch()
+	fork.Messages = append([]Message(nil), s.Messages[:n]...)
+	if err := fork.write(fork.Messages...); err != nil {
+		return nil, err
+	}
+	return fork, nil
+}
+
+// Branch starts an empty session whose parent is s, for another take on it such
+// as a replay against a different model. Like New, nothing is written until the
+// first message.
+func (s *Session) Branch() *Session {
+	branch := New()
+	branch.Parent = s.ID
+	return branch
+}
+
+// rewrite replaces the session file with the messages in memory. The new file is
+// written beside the old one and renamed over it, so a crash cannot lose both.
+func (s *Session) rewrite() error {
+	d, err := dir()
+	if err != nil {
+		return err
+	}
+	lines, err := encode(s.Messages)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(d, s.ID+".jsonl")
+	if err := os.WriteFile(path+".tmp", lines, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// write appends messages to the session file, creating it if needed
+func (s *Session) write(messages ...Message) error {
+	d, err := dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d, 0700); err != nil {
+		return err
+	}
+	lines, err := encode(messages)
+	if err != nil {
+		return err
+	}
+
+	// A branched session records its parent when its file is first created
+	path := filepath.Join(d, s.ID+".jsonl")
+	if _, err := os.Stat(path); os.IsNotExist(err) && s.Parent != "" {
+		data, err := json.Marshal(meta{Parent: s.Parent})
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(d, s.ID+".meta.json"), data, 0600); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(lines); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// encode turns messages into JSONL, one message per line
+func encode(messages []Message) ([]byte, error) {
+	var lines []byte
+	for _, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(append(lines, data...), '\n')
+	}
+	return lines, nil
+}
+
+// Load reads a stored session. The id may be a unique prefix of a session ID;
+// an empty id or "last" loads the most recent session.
+func Load(id string) (*Session, error) {
+	sessions, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Info
+	for _, info := range sessions {
+		if id == "" || id == "last" || strings.HasPrefix(info.ID, id) {
+			matches = append(matches, info)
+		}
+		if info.ID == id {
+			matches = []Info{info}
+			break
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if id != "" && id != "last" && len(matches) > 1 {
+		return nil, fmt.Errorf("'%s' matches %d sessions, use a longer ID", id, len(matches))
+	}
+
+	d, _ := dir()
+	messages, err := readMessages(filepath.Join(d, matches[0].ID+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	return &Session{ID: matches[0].ID, Parent: matches[0].Parent, Messages: messages}, nil
+}
+
+// List returns the stored sessions, most recently updated first
+func List() ([]Info, error) {
+	d, err := dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(d)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Info
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		messages, err := readMessages(filepath.Join(d, entry.Name()))
+		if err != nil || len(messages) == 0 {
+			continue
+		}
+
+		info := Info{
+			ID:       strings.TrimSuffix(entry.Name(), ".jsonl"),
+			Started:  messages[0].Time,
+			Updated:  messages[len(messages)-1].Time,
+			Messages: len(messages),
+			Parent:   readParent(d, strings.TrimSuffix(entry.Name(), ".jsonl")),
+		}
+		for _, message := range messages {
+			if message.Role == "user" {
+				info.Preview = Preview(message.Content)
+				break
+			}
+		}
+		sessions = append(sessions, info)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	return sessions, nil
+}
+
+// readMessages parses a session file, skipping lines that do not decode (such as
+// a line cut short by a crash)
+func readMessages(path string) ([]Message, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var message Message
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, scanner.Err()
+}
+
+// readParent returns the session a forked session came from, or ""
+func readParent(d, id string) string {
+	data, err := os.ReadFile(filepath.Join(d, id+".meta.json"))
+	if err != nil {
+		return ""
+	}
+	var m meta
+	if json.Unmarshal(data, &m) != nil {
+		return ""
+	}
+	return m.Parent
+}
+
+// Preview shortens a prompt to one line for listings
+func Preview(prompt string) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if runes := []rune(prompt); len(runes) > maxPreview {
+		return string(runes[:maxPreview-3]) + "..."
+	}
+	return prompt
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestDir stores sessions in a temporary directory for the duration of a test
+func useTestDir(t *testing.T) string {
+	Dir = t.TempDir()
+	t.Cleanup(func() {
+		Dir = ""
+	})
+	return Dir
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	d := useTestDir(t)
+
+	s := New()
+	if err := s.Append("user", "What is a goroutine?"); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := s.Append("assistant", "A lightweight thread managed by the Go runtime."); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(d, s.ID+".jsonl"))
+	if err != nil {
+		t.Fatalf("Expected a session file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected one JSON line per message, got %d", lines)
+	}
+
+	loaded, err := Load(s.ID[:8])
+	if err != nil {
+		t.Fatalf("Load by prefix failed: %v", err)
+	}
+	if loaded.ID != s.ID || len(loaded.Messages) != 2 || loaded.Messages[1].Role != "assistant" {
+		t.Errorf("Unexpected session: %+v", loaded)
+	}
+}
+
+func TestNewSessionWritesNothingUntilFirstMessage(t *testing.T) {
+	useTestDir(t)
+
+	New()
+	sessions, err := List()
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("Expected no stored sessions, got %v (%v)", sessions, err)
+	}
+}
+
+func TestListAndLoadLast(t *testing.T) {
+	useTestDir(t)
+
+	first := New()
+	first.Append("user", "first\nprompt "+strings.Repeat("x", 100))
+	second := New()
+	if second.ID == first.ID {
+		t.Fatalf("Expected distinct IDs, both are %s", first.ID)
+	}
+	second.Append("user", "second prompt")
+
+	sessions, err := List()
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("Expected two sessions, got %v (%v)", sessions, err)
+	}
+	if sessions[0].ID != second.ID {
+		t.Errorf("Expected the latest session first, got %s", sessions[0].ID)
+	}
+	if p := sessions[1].Preview; len([]rune(p)) != maxPreview || strings.Contains(p, "\n") {
+		t.Errorf("Expected a one-line preview of %d characters, got %q", maxPreview, p)
+	}
+
+	last, err := Load("last")
+	if err != nil || last.ID != second.ID {
+		t.Errorf("Expected last to load %s, got %v (%v)", second.ID, last, err)
+	}
+	if _, err := Load("1999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFork(t *testing.T) {
+	useTestDir(t)
+
+	original := New()
+	original.Append("user", "Name a color")
+	original.Append("assistant", "Blue")
+	original.Append("user", "Another one")
+	original.Append("assistant", "Green")
+
+	fork, err := original.Fork(2)
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if fork.ID == original.ID || fork.Parent != original.ID || len(fork.Messages) != 2 {
+		t.Fatalf("Unexpected fork: %+v", fork)
+	}
+
+	// The fork diverges without touching the original
+	fork.Append("user", "A warmer one")
+	reloaded, err := Load(original.ID)
+	if err != nil || len(reloaded.Messages) != 4 {
+		t.Fatalf("Expected the original to keep 4 messages, got %v (%v)", reloaded, err)
+	}
+
+	loadedFork, err := Load(fork.ID)
+	if err != nil || loadedFork.Parent != original.ID || len(loadedFork.Messages) != 3 {
+		t.Errorf("Expected the fork to reload with its parent and 3 messages, got %+v (%v)", loadedFork, err)
+	}
+
+	if _, err := New().Fork(0); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Expected ErrEmpty when forking an empty session, got %v", err)
+	}
+}
+
+func TestBranch(t *testing.T) {
+	useTestDir(t)
+
+	original := New()
+	original.Append("user", "Name a color")
+
+	branch := original.Branch()
+	if branch.Parent != original.ID || len(branch.Messages) != 0 {
+		t.Fatalf("Unexpected branch: %+v", branch)
+	}
+	sessions, _ := List()
+	if len(sessions) != 1 {
+		t.Errorf("Expected an empty branch to leave no file, got %d sessions", len(sessions))
+	}
+
+	branch.Add(Message{Role: "assistant", Content: "Red", Model: "new-model"})
+	loaded, err := Load(branch.ID)
+	if err != nil || loaded.Parent != original.ID || loaded.Messages[0].Model != "new-model" {
+		t.Errorf("Expected the branch to reload with its parent, got %+v (%v)", loaded, err)
+	}
+}
+
+func TestRate(t *testing.T) {
+	useTestDir(t)
+
+	s := New()
+	if err := s.Rate(RatingUp, ""); !errors.Is(err, ErrNothingToRate) {
+		t.Errorf("Expected ErrNothingToRate before any response, got %v", err)
+	}
+
+	s.Append("user", "Summarize Go generics")
+	s.Add(Message{Role: "assistant", Content: "Type parameters...", Model: "llama3.2:1b"})
+	if err := s.Rate("sideways", ""); err == nil {
+		t.Error("Expected an error for an unknown rating")
+	}
+	if err := s.Rate(RatingDown, "too vague"); err != nil {
+		t.Fatalf("Rate failed: %v", err)
+	}
+
+	reloaded, err := Load(s.ID)
+	if err != nil || len(reloaded.Messages) != 2 {
+		t.Fatalf("Expected the session to reload with 2 messages, got %v (%v)", reloaded, err)
+	}
+	if got := reloaded.Messages[1]; got.Rating != RatingDown || got.Note != "too vague" || got.Model != "llama3.2:1b" {
+		t.Errorf("Expected the rating to be stored on the response, got %+v", got)
+	}
+
+	rated, err := Rated()
+	if err != nil || len(rated) != 1 {
+		t.Fatalf("Expected one rated response, got %v (%v)", rated, err)
+	}
+	if rated[0].Prompt != "Summarize Go generics" || rated[0].SessionID != s.ID {
+		t.Errorf("Expected the rated response to carry its prompt and session, got %+v", rated[0])
+	}
+}
+
+func TestExtendLast(t *testing.T) {
+	useTestDir(t)
+
+	s := New()
+	if err := s.ExtendLast("more"); !errors.Is(err, ErrNoResponse) {
+		t.Errorf("Expected ErrNoResponse before any response, got %v", err)
+	}
+
+	s.Append("user", "Write a long essay")
+	s.Append("assistant", "The first half")
+	if err := s.ExtendLast(" and the second half."); err != nil {
+		t.Fatalf("ExtendLast failed: %v", err)
+	}
+
+	reloaded, err := Load(s.ID)
+	if err != nil || len(reloaded.Messages) != 2 {
+		t.Fatalf("Expected the session to reload with 2 messages, got %v (%v)", reloaded, err)
+	}
+	if got := reloaded.Messages[1].Content; got != "The first half and the second half." {
+		t.Errorf("Expected the continuation appended to the response, got %q", got)
+	}
+}
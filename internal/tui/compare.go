@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tala/internal/ai"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+)
+
+// compareUsage is shown when /compare is missing its targets or prompt
+const compareUsage = "/compare <provider:model|profile>... <prompt>"
+
+// compareModels asks several models the same question at once and shows the
+// answers one after another with their timing. The exchange is not added to the
+// conversation. It runs like a request, so targets can ask to pull a missing
+// model and Ctrl+C stops the comparison. /compare <target>... <prompt>
+func (s *SimpleTUI) compareModels(args []string) {
+	n := 0
+	for n < len(args) && s.isCompareTarget(args[n]) {
+		n++
+	}
+	if n == 0 || n == len(args) {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("compare.usage", compareUsage))
+		return
+	}
+
+	targets := make([]ai.CompareTarget, 0, n)
+	for _, name := range args[:n] {
+		cfg, err := s.config.ForTarget(name)
+		var provider ai.Provider
+		if err == nil {
+			provider, err = ai.CreateProviderFromConfig(cfg)
+		}
+		if err != nil {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("compare.target", name, err))
+			return
+		}
+		targets = append(targets, ai.CompareTarget{Name: name, Provider: provider})
+	}
+
+	prompt := strings.Join(args[n:], " ")
+	fmt.Printf("%s%s%s %s\n", Green+Bold, i18n.T("tui.you"), Reset, prompt)
+
+	start := time.Now()
+	done := make(chan bool, 1)
+	if !s.quiet {
+		go s.showThinkingProgress(start, done)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.setRequestCancel(cancel)
+	defer s.setRequestCancel(nil)
+	results := ai.Compare(ctx, targets, prompt)
+	done <- true
+	if !s.quiet {
+		fmt.Print("\r\033[K")
+	}
+
+	for _, result := range results {
+		fmt.Printf("%s%s %s%s %s(%s)%s\n", Cyan+Bold, glyphs.Rule, result.Target, Reset, Dim, result.Duration.Round(time.Millisecond), Reset)
+		if errors.Is(result.Err, context.Canceled) {
+			fmt.Printf("%s%s%s\n\n", Yellow, i18n.T("tui.interrupted"), Reset)
+			continue
+		}
+		if result.Err != nil {
+			fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, result.Err)
+			continue
+		}
+		response := result.Response
+		if s.raw {
+			fmt.Print(response)
+			if !strings.HasSuffix(response, "\n") {
+				fmt.Println()
+			}
+			fmt.Println()
+			continue
+		}
+		if s.config.HideThinking {
+			response, _ = ai.SplitThinking(response)
+		}
+		fmt.Printf("%s\n\n", s.wrapText(strings.TrimSpace(response), getTerminalWidth()))
+	}
+}
+
+// isCompareTarget reports whether a /compare argument names a model rather than
+// starting the prompt: a profile name, or provider:model for a known provider
+func (s *SimpleTUI) isCompareTarget(arg string) bool {
+	if _, ok := s.config.Profiles[arg]; ok {
+		return true
+	}
+	provider, model, ok := strings.Cut(arg, ":")
+	if !ok || model == "" {
+		return false
+	}
+	for _, p := range ai.SupportedProviders() {
+		if p.Name == provider {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"tala/internal/ai"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+)
+
+// contextUsage is shown when /context gets an unknown subcommand or no file
+const contextUsage = "/context [list|add <file>|remove <file>|clear]"
+
+// handleContext manages the files sent as context with every request. Changes
+// last for this run; context_files in the config sets them for every run.
+// /context [list|add <file>|remove <file>|clear]
+func (s *SimpleTUI) handleContext(args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		s.showContextFiles()
+		return
+	}
+
+	name := strings.Join(args[1:], " ")
+	switch {
+	case args[0] == "add" && name != "":
+		if err := ai.AddContextFile(name); err != nil {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("context.unreadable", name, err))
+			ai.RemoveContextFile(name)
+			return
+		}
+		fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("context.added", name))
+	case args[0] == "remove" && name != "":
+		if !ai.RemoveContextFile(name) {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("context.not_found", name))
+			return
+		}
+		fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("context.removed", name))
+	case args[0] == "clear" && name == "":
+		ai.ClearContextFiles()
+		fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("context.cleared"))
+	default:
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("context.usage", contextUsage))
+	}
+}
+
+// showContextFiles lists the context files with their size against the budget
+func (s *SimpleTUI) showContextFiles() {
+	files := ai.ContextFiles()
+	if len(files) == 0 {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("context.none", "/context add <file>"), Reset)
+		return
+	}
+
+	total := 0
+	fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("context.title"), Reset)
+	for _, file := range files {
+		if file.Err != nil {
+			fmt.Printf("  %s%s%s %s%v%s\n", Yellow, file.Name, Reset, Red, file.Err, Reset)
+			continue
+		}
+		total += file.Tokens
+		fmt.Printf("  %s%s%s %s%s%s\n", Yellow, file.Name, Reset, Dim, i18n.Tf("context.tokens", file.Tokens), Reset)
+	}
+
+	budget := ai.ContextBudget()
+	fmt.Printf("\n%s%s%s\n", Dim, i18n.Tf("context.budget", total, budget), Reset)
+	if total > budget {
+		fmt.Printf("%s%s%s\n", Yellow, i18n.T("context.over_budget"), Reset)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"tala/internal/ai"
+	"tala/internal/i18n"
+)
+
+// continueResponse asks the model to pick up its latest reply where it stopped
+// and prints the rest below it. The continuation becomes part of that reply in
+// the chat context and the saved session rather than a new turn. It runs like
+// a request: Ctrl+C stops it and keeps what was streamed so far. /continue
+func (s *SimpleTUI) continueResponse() {
+	if s.lastReply == "" {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("continue.nothing"), Reset)
+		return
+	}
+
+	start := time.Now()
+	done := make(chan bool, 1)
+	if !s.quiet {
+		go s.showThinkingProgress(start, done)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.setRequestCancel(cancel)
+	defer s.setRequestCancel(nil)
+
+	var response string
+	var err error
+	atomic.StoreInt64(&s.streamedChunks, 0)
+	atomic.StoreInt64(&s.firstChunkAt, 0)
+	if s.config.EnableStreaming && s.provider.SupportsStreaming() {
+		response, err = s.provider.GenerateStreamingResponse(ctx, ai.ContinuePrompt(s.lastReply), s.countChunk)
+	} else {
+		response, err = s.provider.GenerateResponse(ctx, ai.ContinuePrompt(s.lastReply))
+	}
+	done <- true
+	if !s.quiet {
+		fmt.Print("\r\033[K")
+	}
+
+	// An interrupted continuation is still kept as far as it got
+	interrupted := errors.Is(err, context.Canceled)
+	if interrupted && strings.TrimSpace(response) == "" {
+		fmt.Printf("%s%s%s\n\n", Yellow, i18n.T("tui.interrupted"), Reset)
+		return
+	}
+	if err != nil && !interrupted {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, err.Error())
+		return
+	}
+
+	if s.config.HideThinking && !s.raw {
+		response, _ = ai.SplitThinking(response)
+	}
+	rest, truncated := ai.StripTruncationNote(response)
+	s.extendReply(rest)
+
+	if s.raw {
+		fmt.Print(response)
+		if !strings.HasSuffix(response, "\n") {
+			fmt.Println()
+		}
+	} else {
+		fmt.Printf("%s%s%s ", Magenta+Bold, i18n.T("tui.ai"), Reset)
+		s.displayResponseByParagraphs(strings.TrimLeft(rest, " \n"))
+		if truncated {
+			s.showTruncated()
+		}
+	}
+	if interrupted {
+		fmt.Printf("%s%s%s\n", Yellow, i18n.T("tui.interrupted"), Reset)
+	}
+
+	duration := time.Since(start)
+	tokens := len(strings.Fields(rest))
+	usage, measured := s.usageSince(start)
+	if measured {
+		tokens = usage.CompletionTokens
+	}
+	s.totalRequests++
+	s.totalTokens += tokens
+	s.totalTime += duration
+	if s.quiet {
+		fmt.Println()
+		return
+	}
+	s.printFooter(tokens, duration, usage, measured)
+}
+
+// extendReply adds a continuation to the latest reply in the chat context and
+// the saved session
+func (s *SimpleTUI) extendReply(rest string) {
+	s.lastReply += rest
+	if ollama, ok := ai.UnwrapProvider(s.provider).(*ai.OllamaProvider); ok {
+		if n := len(ollama.History); n > 0 && ollama.History[n-1].Role == "assistant" {
+			ollama.History[n-1].Content += rest
+		}
+	}
+	if s.session != nil {
+		if err := s.session.ExtendLast(rest); err != nil {
+			fmt.Printf("%s%s%s %s\n", Yellow+Bold, i18n.T("tui.warning"), Reset, i18n.Tf("session.save_failed", err))
+		}
+	}
+}
+
+// showTruncated tells the user a reply hit max_tokens and how to get the rest
+func (s *SimpleTUI) showTruncated() {
+	fmt.Printf("%s%s%s\n", Yellow, i18n.Tf("continue.truncated", "/continue"), Reset)
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+)
+
+// errNoEditor means neither the config nor the environment names an editor
+var errNoEditor = errors.New("no editor configured")
+
+// editorCommand returns the editor to run: the config's editor, then $VISUAL, then $EDITOR.
+// The value may include arguments, as in "code --wait".
+func editorCommand(configured string) ([]string, error) {
+	for _, candidate := range []string{configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			return fields, nil
+		}
+	}
+	return nil, errNoEditor
+}
+
+// editFile opens filename in the user's editor and waits for it to exit. The
+// caller must make sure nothing else reads stdin meanwhile.
+func (s *SimpleTUI) editFile(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("edit.usage", "/edit <file>"))
+		return
+	}
+	filename := args[0]
+
+	editor, err := editorCommand(s.config.Editor)
+	if err != nil {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.T("edit.no_editor"))
+		return
+	}
+
+	saved := saveTerminalState()
+	cmd := exec.Command(editor[0], append(editor[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	restoreTerminalState(saved)
+	// Editors may turn bracketed paste off on exit
+	if bracketedPaste {
+		fmt.Print("\x1b[?2004h")
+	}
+	// Full-screen editors also reset the scroll region the status line relies on
+	s.status.layout()
+
+	if err != nil {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("edit.failed", editor[0], err))
+		return
+	}
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("edit.done", filename))
+}
+
+// saveTerminalState records the tty settings so a misbehaving editor cannot leave
+// the terminal in raw mode. It returns "" where stty is unavailable.
+func saveTerminalState() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	cmd := exec.Command("stty", "-g")
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// restoreTerminalState puts back settings captured by saveTerminalState
+func restoreTerminalState(state string) {
+	if state == "" {
+		return
+	}
+	cmd := exec.Command("stty", state)
+	cmd.Stdin = os.Stdin
+	_ = cmd.Run()
+}
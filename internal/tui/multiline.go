@@ -0,0 +1,60 @@
+package tui
+
+import "strings"
+
+// Sentinels that close a multiline block
+const (
+	fenceSentinel = `"""`  // opened by a line starting with """
+	pasteSentinel = "/end" // opened by /paste, for text that may itself contain """
+)
+
+// multilineBuffer collects lines into one prompt until the closing sentinel
+type multilineBuffer struct {
+	active bool
+	end    string
+	lines  []string
+}
+
+// start begins a block closed by a line equal to end
+func (m *multilineBuffer) start(end string) {
+	m.active = true
+	m.end = end
+	m.lines = nil
+}
+
+// add appends a raw input line. When the line closes the block it returns the
+// whole block and true, and the buffer is reset.
+func (m *multilineBuffer) add(line string) (string, bool) {
+	// Only a line holding just the sentinel closes the block, so code containing
+	// """ docstrings can still be pasted
+	if strings.TrimSpace(line) != m.end {
+		m.lines = append(m.lines, line)
+		return "", false
+	}
+
+	block := strings.Join(m.lines, "\n")
+	m.active = false
+	m.lines = nil
+	return block, true
+}
+
+// openFence starts a fenced block if line begins with """. Text after the opening
+// fence is the first line; a fence closed on the same line is complete at once.
+func (m *multilineBuffer) openFence(line string) (block string, opened, done bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, fenceSentinel) {
+		return "", false, false
+	}
+	m.start(fenceSentinel)
+
+	rest := strings.TrimPrefix(trimmed, fenceSentinel)
+	if strings.TrimSpace(rest) == "" {
+		return "", true, false
+	}
+	if closed := strings.TrimRight(rest, " \t\r"); strings.HasSuffix(closed, fenceSentinel) {
+		m.active = false
+		return strings.TrimSuffix(closed, fenceSentinel), true, true
+	}
+	m.lines = append(m.lines, rest)
+	return "", true, false
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Markers a terminal in bracketed-paste mode puts around pasted text
+const (
+	pasteStartMarker = "\x1b[200~"
+	pasteEndMarker   = "\x1b[201~"
+)
+
+// bracketedPaste is set while the TUI has bracketed-paste mode turned on
+var bracketedPaste bool
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// enableBracketedPaste asks the terminal to mark pasted text, so a multi-line paste
+// arrives as one input. The line discipline still splits it into lines; readInput
+// joins them again. restoreTerminal undoes the change.
+func enableBracketedPaste() {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return
+	}
+
+	// Without -echoctl the echoed markers would show up as ^[[200~
+	saved := saveTerminalState()
+	cmd := exec.Command("stty", "-echoctl")
+	cmd.Stdin = os.Stdin
+	_ = cmd.Run()
+
+	fmt.Print("\x1b[?2004h")
+	bracketedPaste = true
+	onRestore(func() {
+		bracketedPaste = false
+		fmt.Print("\x1b[?2004l")
+		restoreTerminalState(saved)
+	})
+}
+
+// readInput reads the next input from scanner. A bracketed paste spanning several
+// lines is returned whole, with the markers removed.
+func readInput(scanner *bufio.Scanner) (string, bool) {
+	if !scanner.Scan() {
+		return "", false
+	}
+	line := scanner.Text()
+	start := strings.Index(line, pasteStartMarker)
+	if start < 0 {
+		return line, true
+	}
+
+	var b strings.Builder
+	b.WriteString(line[:start])
+	line = line[start+len(pasteStartMarker):]
+	for {
+		if end := strings.Index(line, pasteEndMarker); end >= 0 {
+			// Anything typed after the paste, before Enter, belongs to the same input
+			b.WriteString(line[:end] + line[end+len(pasteEndMarker):])
+			return b.String(), true
+		}
+		b.WriteString(line)
+		if !scanner.Scan() {
+			return b.String(), true
+		}
+		b.WriteString("\n")
+		line = scanner.Text()
+	}
+}
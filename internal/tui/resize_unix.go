@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize delivers a signal on c whenever the terminal is resized
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
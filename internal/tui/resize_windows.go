@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package tui
+
+import "os"
+
+// notifyResize is a no-op on Windows, which has no resize signal
+func notifyResize(c chan<- os.Signal) {}
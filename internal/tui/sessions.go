@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tala/internal/ai"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+	"tala/internal/session"
+)
+
+// Resume loads a stored session and makes its messages the chat context. The id
+// may be a unique prefix; "last" picks the most recent session.
+func (s *SimpleTUI) Resume(id string) error {
+	loaded, err := session.Load(id)
+	if err != nil {
+		return err
+	}
+
+	s.loadContext(loaded.Messages)
+
+	// New turns are added to the resumed session, unless history is not saved
+	if s.config.SaveHistory {
+		s.session = loaded
+	}
+	s.resumed = loaded
+	return nil
+}
+
+// loadContext replaces the chat context with the newest stored messages, up to history_limit
+func (s *SimpleTUI) loadContext(messages []session.Message) {
+	if limit := s.config.HistoryLimit; limit > 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	s.lastReply = ""
+	if n := len(messages); n > 0 && messages[n-1].Role == "assistant" {
+		s.lastReply = messages[n-1].Content
+	}
+	if ollama, ok := ai.UnwrapProvider(s.provider).(*ai.OllamaProvider); ok {
+		ollama.History = nil
+		for _, message := range messages {
+			ollama.History = append(ollama.History, ai.OllamaMessage{Role: message.Role, Content: message.Content})
+		}
+	}
+}
+
+// forkSession copies the current session into a new one and continues there:
+// /fork [turns] keeps the first turns exchanges, or all of them
+func (s *SimpleTUI) forkSession(args []string) {
+	if s.session == nil {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.T("session.disabled"))
+		return
+	}
+	turns := 0
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("session.usage", "/fork [turns]"))
+			return
+		}
+		turns = n
+	}
+
+	fork, err := s.session.Fork(turns * 2)
+	if errors.Is(err, session.ErrEmpty) {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("session.fork_empty"), Reset)
+		return
+	}
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+
+	s.loadContext(fork.Messages)
+	s.session = fork
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, i18n.T("tui.system"), Reset,
+		i18n.Tf("session.forked", fork.ID, fork.Parent, len(fork.Messages), "/resume "+fork.Parent))
+}
+
+// recordTurn appends an exchange to the current session file
+func (s *SimpleTUI) recordTurn(input, response string) {
+	if s.session == nil {
+		return
+	}
+	err := s.session.Append("user", input)
+	if err == nil {
+		err = s.session.Add(session.Message{Role: "assistant", Content: response, Model: s.config.Model})
+	}
+	if err != nil {
+		fmt.Printf("%s%s%s %s\n", Yellow+Bold, i18n.T("tui.warning"), Reset, i18n.Tf("session.save_failed", err))
+	}
+}
+
+// newSession starts recording into a fresh session, when history is saved
+func (s *SimpleTUI) newSession() {
+	s.session = nil
+	s.resumed = nil
+	s.lastReply = ""
+	if s.config.SaveHistory {
+		s.session = session.New()
+	}
+}
+
+// rateResponse rates the latest response: /rate up|down [note]
+func (s *SimpleTUI) rateResponse(args []string) {
+	if len(args) == 0 || (args[0] != session.RatingUp && args[0] != session.RatingDown) {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("session.usage", "/rate up|down [note]"))
+		return
+	}
+	if s.session == nil {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.T("session.disabled"))
+		return
+	}
+
+	err := s.session.Rate(args[0], strings.Join(args[1:], " "))
+	if errors.Is(err, session.ErrNothingToRate) {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("rating.nothing"), Reset)
+		return
+	}
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	mark := glyphs.ThumbsUp
+	if args[0] == session.RatingDown {
+		mark = glyphs.ThumbsDown
+	}
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("rating.saved", mark))
+}
+
+// maxRecentRatings is how many of the latest rated responses /ratings lists
+const maxRecentRatings = 10
+
+// showRatings summarizes ratings across all saved sessions, per model
+func (s *SimpleTUI) showRatings() {
+	rated, err := session.Rated()
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	if len(rated) == 0 {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("rating.none", "/rate up|down [note]"), Reset)
+		return
+	}
+
+	type tally struct{ up, down int }
+	byModel := make(map[string]*tally)
+	var total tally
+	for _, r := range rated {
+		model := r.Model
+		if model == "" {
+			model = "?"
+		}
+		if byModel[model] == nil {
+			byModel[model] = &tally{}
+		}
+		if r.Rating == session.RatingUp {
+			byModel[model].up++
+			total.up++
+		} else {
+			byModel[model].down++
+			total.down++
+		}
+	}
+
+	fmt.Printf("%s%s%s %s%s %d%s  %s%s %d%s\n", Cyan+Bold, i18n.T("rating.title"), Reset, Green, glyphs.ThumbsUp, total.up, Reset, Red, glyphs.ThumbsDown, total.down, Reset)
+	models := make([]string, 0, len(byModel))
+	for model := range byModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		fmt.Printf("  %s%-24s%s %s %d  %s %d\n", Yellow, model, Reset, glyphs.ThumbsUp, byModel[model].up, glyphs.ThumbsDown, byModel[model].down)
+	}
+
+	fmt.Printf("\n%s%s%s\n", Yellow+Bold, i18n.T("rating.recent"), Reset)
+	start := 0
+	if len(rated) > maxRecentRatings {
+		start = len(rated) - maxRecentRatings
+	}
+	for i := len(rated) - 1; i >= start; i-- {
+		r := rated[i]
+		mark := Green + glyphs.ThumbsUp
+		if r.Rating == session.RatingDown {
+			mark = Red + glyphs.ThumbsDown
+		}
+		note := ""
+		if r.Note != "" {
+			note = glyphs.Text(" — ") + r.Note
+		}
+		fmt.Printf("  %s%s %s%s%s%s\n", mark, Reset, Dim, r.Time.Format("2006-01-02 15:04"), Reset, " "+session.Preview(r.Prompt)+note)
+	}
+	fmt.Println()
+}
+
+// handleSessions lists stored sessions: /sessions [list]
+func (s *SimpleTUI) handleSessions(args []string) {
+	if len(args) > 0 && args[0] != "list" {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("session.usage", "/sessions [list]"))
+		return
+	}
+
+	sessions, err := session.List()
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("session.none"), Reset)
+		return
+	}
+
+	fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("session.title"), Reset)
+	for _, info := range sessions {
+		marker := " "
+		if s.session != nil && info.ID == s.session.ID {
+			marker = "*"
+		}
+		fork := ""
+		if info.Parent != "" {
+			fork = " " + i18n.Tf("session.fork_of", info.Parent)
+		}
+		fmt.Printf("  %s%s %-20s%s %s%s  %s%s%s %s\n", Green, marker, info.ID, Reset,
+			Dim, info.Updated.Format("2006-01-02 15:04"), i18n.Tf("session.messages", info.Messages), fork, Reset, info.Preview)
+	}
+	fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("session.hint", "/resume <id>"), Reset)
+}
+
+// resumeSession switches to a stored session: /resume [id]
+func (s *SimpleTUI) resumeSession(args []string) {
+	id := "last"
+	if len(args) > 0 {
+		id = args[0]
+	}
+	if err := s.Resume(id); err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	s.showResumed()
+}
+
+// showResumed tells the user which session's context is loaded
+func (s *SimpleTUI) showResumed() {
+	if s.resumed == nil {
+		return
+	}
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, i18n.T("tui.system"), Reset,
+		i18n.Tf("session.resumed", s.resumed.ID, len(s.resumed.Messages)))
+}
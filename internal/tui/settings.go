@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"tala/internal/ai"
+	"tala/internal/config"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+	"tala/internal/logging"
+)
+
+// setUsage is shown when /set is missing its key or value
+const setUsage = "/set <key> <value>"
+
+// providerSettings are the settings a provider reads when it is created, so
+// changing one of them recreates it
+var providerSettings = map[string]bool{
+	"provider": true, "model": true, "api_key": true, "temperature": true, "max_tokens": true,
+	"system_prompt": true, "persona": true, "personas": true, "response_format": true,
+	"stop_sequences": true, "seed": true, "keep_alive": true, "auto_pull_models": true,
+	"embedding_model": true, "mock_responses": true, "mock_tool_calls": true, "request_timeout": true,
+	"http_proxy": true, "ca_cert_file": true, "extra_headers": true,
+	"azure_resource": true, "azure_api_version": true, "base_url": true, "extra_params": true,
+	"top_p": true, "presence_penalty": true, "frequency_penalty": true,
+}
+
+// guidedKeys are the settings /settings walks through
+var guidedKeys = []string{"provider", "model", "api_key", "temperature", "max_tokens"}
+
+// handleSet changes one setting for this session and in the config file:
+// /set <key> <value>, where the value is the rest of the line
+func (s *SimpleTUI) handleSet(cmd string) {
+	fields := strings.Fields(cmd)
+	if len(fields) < 3 {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("settings.usage", setUsage))
+		return
+	}
+	key := fields[1]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(cmd), fields[0]))
+	value := strings.TrimSpace(strings.TrimPrefix(rest, key))
+	changes, err := s.applySettings([]config.Setting{{Key: key, Value: value}})
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	s.showChanges(changes)
+}
+
+// guidedSettings asks for the main provider settings one at a time, Enter
+// keeping a value, and applies the answers together so a new provider can
+// get its model and key before it is created. It runs on the AI goroutine
+// so it can ask.
+func (s *SimpleTUI) guidedSettings() {
+	fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("settings.title"), Reset)
+	scratch := *s.config
+	var changes []config.Setting
+	for _, key := range guidedKeys {
+		for {
+			current := s.settingValue(key)
+			if key == "model" && scratch.Provider != s.config.Provider && !ai.ModelFits(scratch.Provider, scratch.Model) {
+				current = ai.DefaultModel(scratch.Provider) // what Enter gets after switching provider
+			}
+			answer := s.ask(i18n.Tf("settings.ask", Yellow+key+Reset, current))
+			if answer == "" {
+				break
+			}
+			if err := scratch.Set(key, answer); err != nil {
+				fmt.Printf("%s%s%s %v\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+				continue
+			}
+			changes = append(changes, config.Setting{Key: key, Value: answer})
+			break
+		}
+	}
+	if len(changes) == 0 {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("settings.unchanged"), Reset)
+		return
+	}
+	changes, err := s.applySettings(changes)
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	s.showChanges(changes)
+}
+
+// showChanges confirms the settings that were applied
+func (s *SimpleTUI) showChanges(changes []config.Setting) {
+	for _, change := range changes {
+		fmt.Printf("%s%s%s %s\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("settings.saved", change.Key, s.settingValue(change.Key)))
+	}
+	fmt.Println()
+}
+
+// applySettings validates and applies settings to the session, recreating the
+// provider when it depends on one of them, then saves them to the config
+// file. The file is read again so command-line overrides are not saved with
+// them. Nothing changes if a setting is invalid. It returns the changes
+// made, which include the new provider's default model when the model was
+// left at one the new provider does not serve.
+func (s *SimpleTUI) applySettings(changes []config.Setting) ([]config.Setting, error) {
+	next := *s.config
+	recreate, modelGiven := false, false
+	for _, change := range changes {
+		if err := next.Set(change.Key, change.Value); err != nil {
+			return nil, err
+		}
+		recreate = recreate || providerSettings[strings.Split(change.Key, ".")[0]]
+		modelGiven = modelGiven || change.Key == "model"
+	}
+	if next.Provider != s.config.Provider && !modelGiven && !ai.ModelFits(next.Provider, next.Model) {
+		if model := ai.DefaultModel(next.Provider); model != "" {
+			next.Model = model
+			changes = append(changes, config.Setting{Key: "model", Value: model})
+		}
+	}
+
+	if recreate {
+		if err := next.Validate(); err != nil {
+			return nil, err
+		}
+		if err := ai.ConfigureHTTP(&next); err != nil {
+			return nil, err
+		}
+		provider, err := ai.CreateProviderFromConfig(&next)
+		if err != nil {
+			return nil, err
+		}
+		// The conversation so far carries over to the new provider
+		if old, ok := ai.UnwrapProvider(s.provider).(*ai.OllamaProvider); ok {
+			if created, ok := ai.UnwrapProvider(provider).(*ai.OllamaProvider); ok {
+				created.History = old.History
+			}
+		}
+		s.provider = provider
+	}
+	*s.config = next
+	ai.ConfigureTools(s.config)
+
+	saved, err := config.Load()
+	for _, change := range changes {
+		if err == nil {
+			err = saved.Set(change.Key, change.Value)
+		}
+	}
+	if err == nil {
+		err = saved.Save()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s", i18n.Tf("settings.not_saved", err))
+	}
+	return changes, nil
+}
+
+// settingValue shows the current value of a setting, with secrets redacted
+func (s *SimpleTUI) settingValue(key string) string {
+	value, err := s.config.Get(key)
+	if err != nil {
+		return ""
+	}
+	if value != "" && config.IsSecret(key) {
+		return logging.Redacted
+	}
+	return value
+}
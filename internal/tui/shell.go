@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tala/internal/ai"
+	"tala/internal/i18n"
+)
+
+// shellUsage is shown when /shell is given no command
+const shellUsage = "/shell <command> or /!<command>"
+
+// shellCommand returns the command of a /shell or /! line, and whether the
+// line is one
+func shellCommand(input string) (string, bool) {
+	switch {
+	case strings.HasPrefix(input, "/!"):
+		return strings.TrimSpace(input[len("/!"):]), true
+	case input == "/shell":
+		return "", true
+	case strings.HasPrefix(input, "/shell "):
+		return strings.TrimSpace(input[len("/shell "):]), true
+	}
+	return "", false
+}
+
+// runShell runs a command typed by the user under the same policy as
+// execute_command: risky commands are confirmed and blocked ones refused.
+// Its output is remembered for follow-up questions. It runs on the AI
+// goroutine so the confirmation can be answered, and Ctrl+C stops the command
+// rather than tala.
+func (s *SimpleTUI) runShell(command string) {
+	if command == "" {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("shell.usage", shellUsage))
+		return
+	}
+	fmt.Printf("%s$ %s%s\n", Dim, command, Reset)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.setRequestCancel(cancel)
+	defer s.setRequestCancel(nil)
+
+	output := strings.TrimRight(ai.ExecuteShellCommandContext(ctx, command, ai.MaxCommandTimeout), "\n")
+	ai.RememberToolResults(s.provider, []ai.ToolResult{{Name: "shell", Content: "$ " + command + "\n" + output}})
+	if strings.HasPrefix(output, "Error:") {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, strings.TrimSpace(strings.TrimPrefix(output, "Error:")))
+		return
+	}
+	if output == "" {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("shell.no_output"), Reset)
+		return
+	}
+	fmt.Printf("%s\n\n", output)
+}
@@ -3,18 +3,25 @@ package tui
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"tala/internal/ai"
 	"tala/internal/config"
 	"tala/internal/fileops"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+	"tala/internal/session"
 )
 
 // ANSI color codes for better UX
@@ -53,158 +60,484 @@ type SimpleTUI struct {
 	totalTokens   int
 	totalRequests int
 	totalTime     time.Duration
+	quiet         bool
+	noTools       bool // plain chat: skip intent detection and tools
+	verbose       bool // show detected intents before tools run
+	raw           bool // print responses verbatim, without any post-processing
+	multiline     multilineBuffer
+	status        statusLine
+	session       *session.Session // where turns are saved, nil when save_history is off
+	resumed       *session.Session // the session loaded by Resume, if any
+	lastReply     string           // the latest reply, which /continue extends
+
+	// Clarification questions asked by tools while the AI is busy
+	answers  chan string
+	askMu    sync.Mutex // one question at a time
+	awaiting int32      // set while a tool is waiting on answers
+	paused   int32 // set while the thinking indicator must not draw
+
+	// Streamed chunks of the reply being generated, for the indicator's throughput
+	streamedChunks int64
+	firstChunkAt   int64 // UnixNano of the first chunk, 0 before it arrives
+	fileProgress   atomic.Value // string describing a long file operation, "" when none runs
+
+	// Ctrl+C while the typewriter effect runs shows the rest of the reply
+	skipTyping chan struct{}
+	typing     int32 // set while a reply is being typed out
+
+	// Ctrl+C while the AI is busy stops the request, keeping what was generated
+	cancelMu      sync.Mutex
+	cancelRequest context.CancelFunc // nil when no request is running
 }
 
 // NewSimpleTUI creates a new simple TUI instance
 func NewSimpleTUI(cfg *config.Config) (*SimpleTUI, error) {
-	provider, err := ai.CreateProvider(cfg.Provider, cfg.APIKey, cfg.Model, cfg.Temperature, cfg.MaxTokens)
+	provider, err := ai.CreateProviderFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SimpleTUI{
+	s := &SimpleTUI{
 		provider: provider,
 		config:   cfg,
-	}, nil
+		answers:  make(chan string),
+
+		skipTyping: make(chan struct{}, 1),
+	}
+	s.newSession()
+	ai.Clarify = s.askClarification
+	ai.ConfirmModelPull = s.confirmModelPull
+	ai.ConfirmCommand = s.confirmCommand
+	ai.KeepWaiting = s.keepWaiting
+	ai.ConfirmOutsidePath = s.confirmOutsidePath
+	ai.ConfirmRenames = s.confirmRenames
+	ai.PullProgress = s.showPullProgress
+	ai.FileProgress = s.showFileProgress
+	ai.ReportIntent = s.showIntent
+	return s, nil
+}
+
+// SetQuiet suppresses decorative output (banner, thinking indicator, stats)
+func (s *SimpleTUI) SetQuiet(quiet bool) {
+	s.quiet = quiet
+}
+
+// SetNoTools disables tool use so every reply is plain chat
+func (s *SimpleTUI) SetNoTools(noTools bool) {
+	s.noTools = noTools
+}
+
+// SetVerbose shows each detected intent and its confidence before tools run
+func (s *SimpleTUI) SetVerbose(verbose bool) {
+	s.verbose = verbose
+}
+
+// SetRaw prints responses exactly as the model sent them: no wrapping, coloring,
+// paragraph delays or thinking removal
+func (s *SimpleTUI) SetRaw(raw bool) {
+	s.raw = raw
 }
 
 // Run starts the simple TUI
 func (s *SimpleTUI) Run() error {
 	// Setup signal handling for clean exit
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	// Whatever ends the session, leave the terminal as we found it
+	defer restoreTerminal()
+	defer restoreOnPanic()
 	
 	// Print colorful header
-	fmt.Printf("\n%s🗣️ Tala - Terminal AI Language Assistant%s\n", Bold+Cyan, Reset)
-	fmt.Printf("%sProvider:%s %s%s%s %s|%s %sModel:%s %s%s%s\n", 
-		Dim, Reset, Green, s.provider.GetName(), Reset,
-		Dim, Reset, Dim, Reset, Yellow, s.config.Model, Reset)
-	fmt.Printf("%sType '%s/help%s' for file operations or chat normally with AI%s\n", 
-		Gray, Cyan, Gray, Reset)
-	fmt.Printf("%sCtrl+C to exit%s\n\n", Dim, Reset)
+	if !s.quiet {
+		fmt.Printf("\n%s%s%s\n", Bold+Cyan, glyphs.Text(i18n.T("app.title")), Reset)
+		fmt.Printf("%s%s%s %s%s%s %s|%s %s%s%s %s%s%s\n", 
+			Dim, i18n.T("tui.provider"), Reset, Green, s.provider.GetName(), Reset,
+			Dim, Reset, Dim, i18n.T("tui.model"), Reset, Yellow, s.config.Model, Reset)
+		fmt.Printf("%s%s%s\n", Gray, i18n.Tf("tui.banner.hint", Cyan+"/help"+Gray), Reset)
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("tui.banner.exit"), Reset)
+	}
+	s.showResumed()
+
+	// Catch an unreachable local server before the first prompt
+	if pinger, ok := ai.UnwrapProvider(s.provider).(interface{ Ping(context.Context) error }); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := pinger.Ping(ctx)
+		if err != nil {
+			fmt.Printf("%s%s%s %s\n\n", Yellow+Bold, i18n.T("tui.warning"), Reset, err.Error())
+		}
+		cancel()
+	}
+
+	// Pasted text arrives as one input instead of one prompt per line
+	enableBracketedPaste()
+
+	// Keep provider, model, directory and tokens in view on the bottom row
+	if s.config.StatusLine && !s.quiet {
+		s.status.enable()
+	}
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
 
 	// Channel for input
 	inputChan := make(chan string)
 	aiBusy := false
 	
-	// Start input reader goroutine
+	// Start input reader goroutine. It waits for a go-ahead before each read so
+	// a command that hands the terminal to another program (/edit) has it to itself.
+	readNext := make(chan struct{}, 1)
 	go func() {
+		defer restoreOnPanic()
 		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			inputChan <- scanner.Text()
+		for {
+			<-readNext
+			line, ok := readInput(scanner)
+			if !ok {
+				close(inputChan)
+				return
+			}
+			inputChan <- line
 		}
-		close(inputChan)
 	}()
+	readNext <- struct{}{}
 	
 	// Show initial prompt with color
-	fmt.Printf("%s> %s", Blue+Bold, Reset)
+	s.showPrompt()
 	
 	for {
 		select {
 		case <-c:
-			fmt.Println("\nGoodbye!")
+			if atomic.LoadInt32(&s.typing) == 1 {
+				select {
+				case s.skipTyping <- struct{}{}:
+				default:
+				}
+				continue
+			}
+			if s.interruptRequest() {
+				continue
+			}
+			fmt.Println("\n" + i18n.T("common.goodbye"))
 			return nil
+
+		case <-resized:
+			s.status.layout()
 			
 		case input, ok := <-inputChan:
 			if !ok {
 				return nil // EOF
 			}
 			
+			raw := input
 			input = strings.TrimSpace(input)
+
+			handsOffTerminal := !aiBusy && !s.multiline.active && !strings.Contains(raw, "\n") && usesTerminal(input)
+			if !handsOffTerminal {
+				readNext <- struct{}{}
+			}
+
+			// A tool is waiting for a missing parameter
+			if aiBusy && atomic.LoadInt32(&s.awaiting) == 1 {
+				s.answers <- input
+				continue
+			}
+
+			// Multiline blocks keep their lines verbatim and go to the AI as one prompt
+			block, isBlock := "", false
+			if strings.Contains(raw, "\n") && !s.multiline.active {
+				// A multi-line bracketed paste
+				block, isBlock = raw, true
+			} else if s.multiline.active {
+				block, isBlock = s.multiline.add(raw)
+			} else if b, opened, done := s.multiline.openFence(raw); opened {
+				block, isBlock = b, done
+			}
+			if s.multiline.active {
+				fmt.Printf("%s... %s", Dim, Reset)
+				continue
+			}
+			if isBlock {
+				input = strings.TrimSpace(block)
+			}
+
 			if input == "" {
 				if !aiBusy {
-					fmt.Printf("%s> %s", Blue+Bold, Reset)
+					s.showPrompt()
 				}
 				continue
 			}
 
 			// Handle exit commands
-			if input == "exit" || input == "quit" || input == "/quit" || input == "/exit" {
-				fmt.Println("Goodbye!")
+			if !isBlock && (input == "exit" || input == "quit" || input == "/quit" || input == "/exit") {
+				fmt.Println(i18n.T("common.goodbye"))
 				return nil
 			}
 
 			// If AI is busy, queue the input for later
 			if aiBusy {
-				fmt.Printf("\n%s[Queued]:%s %s\n", Yellow, Reset, input)
+				fmt.Printf("\n%s%s%s %s\n", Yellow, i18n.T("tui.queued"), Reset, input)
 				// TODO: Implement proper queuing
 				continue
 			}
 
+			// Shell commands and /settings run like a request, so they can ask
+			if run := s.askingCommand(input); run != nil && !isBlock {
+				aiBusy = true
+				go func() {
+					defer restoreOnPanic()
+					run()
+					aiBusy = false
+					s.showPrompt()
+				}()
+				continue
+			}
+
 			// Handle slash commands
-			if strings.HasPrefix(input, "/") {
+			if !isBlock && strings.HasPrefix(input, "/") {
 				s.handleSlashCommand(input)
-				fmt.Printf("%s> %s", Blue+Bold, Reset)
+				if handsOffTerminal {
+					readNext <- struct{}{}
+				}
+				if !s.multiline.active {
+					s.showPrompt()
+				}
 				continue
 			}
 
 			// Handle AI conversation
 			aiBusy = true
 			go func(prompt string) {
+				defer restoreOnPanic()
 				s.handleAIConversation(prompt)
 				aiBusy = false
-				fmt.Printf("%s> %s", Blue+Bold, Reset)
+				s.showPrompt()
 			}(input)
 		}
 	}
 }
 
+// showPrompt refreshes the status line and prints the input prompt
+func (s *SimpleTUI) showPrompt() {
+	s.updateStatusLine()
+	fmt.Printf("%s> %s", Blue+Bold, Reset)
+}
+
 // handleAIConversation processes AI chat with streaming paragraph updates
 func (s *SimpleTUI) handleAIConversation(input string) {
-	fmt.Printf("%sYou:%s %s\n", Green+Bold, Reset, input)
+	fmt.Printf("%s%s%s %s\n", Green+Bold, i18n.T("tui.you"), Reset, input)
 	
 	start := time.Now()
 	
 	// Show thinking indicator with live stats
 	done := make(chan bool, 1)
-	go s.showThinkingProgress(start, done)
+	if !s.quiet {
+		go s.showThinkingProgress(start, done)
+	}
 	
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.setRequestCancel(cancel)
+	defer s.setRequestCancel(nil)
 	var response string
 	var err error
 	var toolResults []ai.ToolResult
 
-	// Get the response (still non-streaming to avoid API complexity)
-	if s.provider.SupportsTools() {
+	// Get the response. Plain chat is streamed when enabled so the indicator
+	// can show throughput; the reply is still displayed once it is complete.
+	atomic.StoreInt64(&s.streamedChunks, 0)
+	atomic.StoreInt64(&s.firstChunkAt, 0)
+	if s.provider.SupportsTools() && !s.noTools {
 		response, toolResults, err = s.provider.GenerateResponseWithTools(ctx, input)
+	} else if s.config.EnableStreaming && s.provider.SupportsStreaming() {
+		response, err = s.provider.GenerateStreamingResponse(ctx, input, s.countChunk)
 	} else {
 		response, err = s.provider.GenerateResponse(ctx, input)
 	}
 
 	// Stop thinking indicator
 	done <- true
-	fmt.Print("\r\033[K") // Clear the thinking line
+	if !s.quiet {
+		fmt.Print("\r\033[K") // Clear the thinking line
+	}
 
-	// Handle errors
-	if err != nil {
-		fmt.Printf("%sError:%s %s\n\n", Red+Bold, Reset, err.Error())
+	// Handle errors. An interrupted reply is still shown as far as it got.
+	interrupted := errors.Is(err, context.Canceled)
+	if interrupted && strings.TrimSpace(response) == "" {
+		fmt.Printf("%s%s%s\n\n", Yellow, i18n.T("tui.interrupted"), Reset)
+		return
+	}
+	if err != nil && !interrupted {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, err.Error())
 		return
 	}
 
+	var thinking string
+	if s.config.HideThinking && !s.raw {
+		response, thinking = ai.SplitThinking(response)
+	}
+	// The truncation note is shown, but is not part of the reply
+	reply, truncated := ai.StripTruncationNote(response)
+	s.lastReply = reply
+	s.rememberTurn(input, reply)
+	ai.RememberToolResults(s.provider, toolResults)
+	s.recordTurn(input, reply)
+
 	// Display tool results if any
 	if len(toolResults) > 0 {
-		fmt.Printf("%sSystem:%s File operations executed:\n", Cyan+Bold, Reset)
+		fmt.Printf("%s%s%s %s\n", Cyan+Bold, i18n.T("tui.system"), Reset, i18n.T("tui.tools.executed"))
 		for _, result := range toolResults {
-			fmt.Printf("  %s✓%s %s: %s\n", Green, Reset, result.Name, result.Content)
+			mark, color := glyphs.Check, Green
+			if !result.Success {
+				mark, color = glyphs.Cross, Red
+			}
+			fmt.Printf("  %s%s%s %s: %s\n", color, mark, Reset, result.Name, result.Content)
 		}
 		fmt.Println()
 	}
 
-	// Display AI response with paragraph-based streaming simulation
-	fmt.Printf("%sAI:%s ", Magenta+Bold, Reset)
-	s.displayResponseByParagraphs(response)
+	// Hidden reasoning is still available in verbose mode
+	if s.verbose && thinking != "" {
+		fmt.Printf("%s%s%s\n%s%s%s\n\n", Dim+Bold, i18n.T("tui.reasoning"), Reset, Dim, s.wrapText(thinking, getTerminalWidth()), Reset)
+	}
+
+	if s.raw {
+		fmt.Print(response)
+		if !strings.HasSuffix(response, "\n") {
+			fmt.Println()
+		}
+	} else {
+		// Display AI response with paragraph-based streaming simulation
+		fmt.Printf("%s%s%s ", Magenta+Bold, i18n.T("tui.ai"), Reset)
+		s.displayResponseByParagraphs(reply)
+		if truncated {
+			s.showTruncated()
+		}
+	}
+	if interrupted {
+		fmt.Printf("%s%s%s\n", Yellow, i18n.T("tui.interrupted"), Reset)
+	}
 
 	// Update and display colorful stats
 	duration := time.Since(start)
 	s.totalRequests++
 	tokens := len(strings.Fields(response))
+	usage, measured := s.usageSince(start)
+	if measured {
+		tokens = usage.CompletionTokens
+	}
 	s.totalTokens += tokens
 	s.totalTime += duration
 
 	// Display colorful stats
-	fmt.Printf("%s[%sTokens:%s %s%d%s %s|%s %sTime:%s %s%s%s%s]%s\n\n", 
-		Dim, Reset+Cyan, Dim, Yellow, tokens, Dim, Reset+Dim, Dim, Reset+Cyan, Dim, 
-		Green, duration.Round(time.Millisecond), Dim, Reset+Dim, Reset)
+	if s.quiet {
+		fmt.Println()
+		return
+	}
+	s.printFooter(tokens, duration, usage, measured)
+}
+
+// usageSince returns the token usage the provider reported for a response
+// started at start; ok is false when it reported none since
+func (s *SimpleTUI) usageSince(start time.Time) (usage ai.Usage, ok bool) {
+	usage, ok = ai.LastUsage(s.provider)
+	if !ok || usage.At.Before(start) {
+		return ai.Usage{}, false
+	}
+	return usage, true
+}
+
+// printFooter shows the stats line under a reply; time to first token and
+// throughput are added when the provider reported real usage
+func (s *SimpleTUI) printFooter(tokens int, duration time.Duration, usage ai.Usage, measured bool) {
+	fmt.Printf("%s[%sTokens:%s %s%d%s %s|%s %sTime:%s %s%s%s",
+		Dim, Reset+Cyan, Dim, Yellow, tokens, Dim, Reset+Dim, Dim, Reset+Cyan, Dim,
+		Green, duration.Round(time.Millisecond), Dim)
+	if measured {
+		fmt.Printf(" %s|%s %sTTFT:%s %s%s%s", Reset+Dim, Dim, Reset+Cyan, Dim,
+			Green, usage.FirstToken.Round(time.Millisecond), Dim)
+		if rate := usage.TokensPerSecond(); rate > 0 {
+			fmt.Printf(" %s|%s %s%.1f%s %stok/s%s", Reset+Dim, Dim, Yellow, rate, Dim, Reset+Cyan, Dim)
+		}
+	}
+	fmt.Printf("%s]%s\n\n", Reset+Dim, Reset)
+}
+
+// setRequestCancel records how to stop the running request, nil when it is over
+func (s *SimpleTUI) setRequestCancel(cancel context.CancelFunc) {
+	s.cancelMu.Lock()
+	s.cancelRequest = cancel
+	s.cancelMu.Unlock()
+}
+
+// interruptRequest stops the running request and reports whether there was one
+func (s *SimpleTUI) interruptRequest() bool {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	if s.cancelRequest == nil {
+		return false
+	}
+	s.cancelRequest()
+	s.cancelRequest = nil
+	return true
+}
+
+// rememberTurn keeps the exchange as chat context for providers that support it
+func (s *SimpleTUI) rememberTurn(input, response string) {
+	ai.RememberTurn(s.provider, input, response)
+}
+
+// askingCommand returns how to run a slash command that asks the user
+// questions or makes requests Ctrl+C should stop, or nil for other input
+func (s *SimpleTUI) askingCommand(input string) func() {
+	if command, ok := shellCommand(input); ok {
+		return func() { s.runShell(command) }
+	}
+	if input == "/settings" {
+		return s.guidedSettings
+	}
+	if fields := strings.Fields(input); len(fields) > 0 {
+		switch fields[0] {
+		case "/compare":
+			return func() { s.compareModels(fields[1:]) }
+		case "/continue":
+			return s.continueResponse
+		}
+	}
+	return nil
+}
+
+// usesTerminal reports whether a command runs another program on the terminal,
+// during which the TUI must not read stdin
+func usesTerminal(input string) bool {
+	fields := strings.Fields(input)
+	return len(fields) > 0 && fields[0] == "/edit"
+}
+
+// systemCommands are the slash commands handled by the TUI itself, used for typo suggestions
+var systemCommands = []string{
+	"/help", "/clear", "/stats", "/config", "/persona", "/profile", "/compare", "/sessions", "/resume", "/fork", "/rate", "/ratings", "/continue", "/context", "/stop", "/tools", "/trash",
+	"/undo", "/tx", "/verbose", "/raw", "/notools", "/statusline", "/edit", "/paste", "/shell", "/set", "/settings", "/exit", "/quit",
+}
+
+// suggestSlashCommand returns the closest known command when command is not one,
+// checking the given system commands and the file operation commands
+func suggestSlashCommand(command string, system []string) string {
+	name := strings.TrimPrefix(command, "/")
+	if _, ok := fileops.GetCommands()[name]; ok {
+		return ""
+	}
+
+	candidates := fileops.CommandNames()
+	for _, cmd := range system {
+		if cmd == command {
+			return ""
+		}
+		candidates = append(candidates, strings.TrimPrefix(cmd, "/"))
+	}
+	if suggestion := fileops.SuggestCommand(name, candidates...); suggestion != "" {
+		return "/" + suggestion
+	}
+	return ""
 }
 
 // handleSlashCommand processes slash commands
@@ -225,42 +558,147 @@ func (s *SimpleTUI) handleSlashCommand(cmd string) {
 		s.showStats()
 	case "/config":
 		s.showConfig()
+	case "/persona":
+		s.handlePersona(parts[1:])
+	case "/sessions":
+		s.handleSessions(parts[1:])
+	case "/resume":
+		s.resumeSession(parts[1:])
+	case "/fork":
+		s.forkSession(parts[1:])
+	case "/rate":
+		s.rateResponse(parts[1:])
+	case "/ratings":
+		s.showRatings()
+	case "/profile":
+		s.handleProfile(parts[1:])
+	case "/context":
+		s.handleContext(parts[1:])
+	case "/stop":
+		s.handleStop(parts[1:])
+	case "/tools":
+		s.showTools(parts[1:])
+	case "/trash":
+		s.handleTrash(parts[1:])
+	case "/undo":
+		s.undo()
+	case "/tx":
+		s.handleTransaction(parts[1:])
+	case "/paste":
+		s.multiline.start(pasteSentinel)
+		fmt.Printf("%s%s%s\n%s... %s", Dim, i18n.Tf("paste.start", pasteSentinel), Reset, Dim, Reset)
+	case "/edit":
+		s.editFile(parts[1:])
+	case "/verbose":
+		s.verbose = !s.verbose
+		if s.verbose {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("verbose.on"))
+		} else {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("verbose.off"))
+		}
+	case "/statusline":
+		if s.status.active() {
+			s.status.disable()
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("statusline.off"))
+			break
+		}
+		s.status.enable()
+		if s.status.active() {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("statusline.on"))
+		} else {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.T("statusline.unavailable"))
+		}
+	case "/raw":
+		s.raw = !s.raw
+		if s.raw {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("raw.on"))
+		} else {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("raw.off"))
+		}
+	case "/set":
+		s.handleSet(cmd)
+	case "/notools":
+		s.noTools = !s.noTools
+		if s.noTools {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("notools.on"))
+		} else {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("notools.off"))
+		}
 	case "/exit", "/quit":
-		fmt.Printf("%sGoodbye!%s\n", Green+Bold, Reset)
+		fmt.Printf("%s%s%s\n", Green+Bold, i18n.T("common.goodbye"), Reset)
+		restoreTerminal()
 		os.Exit(0)
 	default:
+		if suggestion := suggestSlashCommand(command, systemCommands); suggestion != "" {
+			fmt.Printf("%s%s%s %s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset,
+				i18n.Tf("command.unknown", command), i18n.Tf("command.suggest", suggestion))
+			return
+		}
+
 		// Try file operation
 		result := fileops.ExecuteCommand(cmd)
 		if strings.Contains(result.Message, "✓") || strings.Contains(result.Message, "success") {
-			fmt.Printf("%sSystem:%s %s\n\n", Green+Bold, Reset, result.Message)
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, i18n.T("tui.system"), Reset, result.Message)
 		} else {
-			fmt.Printf("%sSystem:%s %s\n\n", Red+Bold, Reset, result.Message)
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.system"), Reset, result.Message)
 		}
 	}
 }
 
 // showHelp displays help information
 func (s *SimpleTUI) showHelp() {
-	fmt.Printf("%sAvailable Commands:%s\n\n", Cyan+Bold, Reset)
+	fmt.Printf("%s%s%s\n\n", Cyan+Bold, i18n.T("help.title"), Reset)
 	
-	fmt.Printf("%sSystem Commands:%s\n", Yellow+Bold, Reset)
-	fmt.Printf("  %s/clear%s           Clear screen and reset session\n", Green, Reset)
-	fmt.Printf("  %s/stats%s           Show session statistics\n", Green, Reset)
-	fmt.Printf("  %s/config%s          Show current configuration\n", Green, Reset)
-	fmt.Printf("  %s/help%s            Show this help message\n", Green, Reset)
-	fmt.Printf("  %s/exit, /quit%s     Exit application\n\n", Green, Reset)
+	fmt.Printf("%s%s%s\n", Yellow+Bold, i18n.T("help.system"), Reset)
+	printHelpLine("/clear", "help.clear")
+	printHelpLine("/stats", "help.stats")
+	printHelpLine("/config", "help.config")
+	printHelpLine("/set <key> <value>", "help.set")
+	printHelpLine("/settings", "help.settings")
+	printHelpLine("/persona [name]", "help.persona")
+	printHelpLine("/profile [use name]", "help.profile")
+	printHelpLine("/compare <targets> <prompt>", "help.compare")
+	printHelpLine("/sessions [list]", "help.sessions")
+	printHelpLine("/resume [id]", "help.resume")
+	printHelpLine("/fork [turns]", "help.fork")
+	printHelpLine("/continue", "help.continue")
+	printHelpLine("/context [add|remove|clear]", "help.context")
+	printHelpLine("/stop [clear|seq...]", "help.stop")
+	printHelpLine("/rate up|down [note]", "help.rate")
+	printHelpLine("/ratings", "help.ratings")
+	printHelpLine("/tools [name]", "help.tools")
+	printHelpLine("/notools", "help.notools")
+	printHelpLine("/verbose", "help.verbose")
+	printHelpLine("/raw", "help.raw")
+	printHelpLine("/statusline", "help.statusline")
+	printHelpLine("/trash [list|restore [id]]", "help.trash")
+	printHelpLine("/undo", "help.undo")
+	printHelpLine("/tx [begin|commit|rollback]", "help.tx")
+	printHelpLine("/edit <file>", "help.edit")
+	printHelpLine("/paste", "help.paste")
+	printHelpLine("/shell <cmd>, /!", "help.shell")
+	printHelpLine("/help", "help.help")
+	printHelpLine("/exit, /quit", "help.exit")
+	fmt.Println()
 	
-	fmt.Printf("%sFile Operations:%s\n", Yellow+Bold, Reset)
-	fmt.Printf("  %s/ls [path]%s       List files and directories\n", Green, Reset)
-	fmt.Printf("  %s/cat <file>%s      Display file content\n", Green, Reset)
-	fmt.Printf("  %s/pwd%s             Show current directory\n", Green, Reset)
-	fmt.Printf("  %s/cd <path>%s       Change directory\n", Green, Reset)
-	fmt.Printf("  %s/create <file>%s   Create new file\n", Green, Reset)
-	fmt.Printf("  %s/mkdir <dir>%s     Create directory\n\n", Green, Reset)
+	fmt.Printf("%s%s%s\n", Yellow+Bold, i18n.T("help.files"), Reset)
+	printHelpLine("/ls [path]", "help.ls")
+	printHelpLine("/cat <file>", "help.cat")
+	printHelpLine("/pwd", "help.pwd")
+	printHelpLine("/cd <path>", "help.cd")
+	printHelpLine("/create <file>", "help.create")
+	printHelpLine("/mkdir <dir>", "help.mkdir")
+	fmt.Println()
 	
-	fmt.Printf("%sKeyboard Shortcuts:%s\n", Yellow+Bold, Reset)
-	fmt.Printf("  %sCtrl+C%s           Exit application\n", Green, Reset)
-	fmt.Printf("  %sEnter%s            Send message\n\n", Green, Reset)
+	fmt.Printf("%s%s%s\n", Yellow+Bold, i18n.T("help.shortcuts"), Reset)
+	printHelpLine("Ctrl+C", "help.key.exit")
+	printHelpLine("Enter", "help.key.enter")
+	fmt.Println()
+}
+
+// printHelpLine prints an aligned help entry with a translated description
+func printHelpLine(command, descriptionKey string) {
+	fmt.Printf("  %s%-17s%s%s\n", Green, command, Reset, i18n.T(descriptionKey))
 }
 
 // clearScreen clears the terminal
@@ -269,41 +707,285 @@ func (s *SimpleTUI) clearScreen() {
 	s.totalTokens = 0
 	s.totalRequests = 0
 	s.totalTime = 0
+	if ollama, ok := ai.UnwrapProvider(s.provider).(*ai.OllamaProvider); ok {
+		ollama.History = nil
+	}
+	s.newSession()
 	
-	fmt.Printf("%s🗣️ Tala - Terminal AI Language Assistant%s\n", Bold+Cyan, Reset)
-	fmt.Printf("%sProvider:%s %s%s%s %s|%s %sModel:%s %s%s%s\n", 
-		Dim, Reset, Green, s.provider.GetName(), Reset,
-		Dim, Reset, Dim, Reset, Yellow, s.config.Model, Reset)
-	fmt.Printf("%sType '%s/help%s' for commands or chat normally with AI%s\n\n", 
-		Gray, Cyan, Gray, Reset)
+	fmt.Printf("%s%s%s\n", Bold+Cyan, glyphs.Text(i18n.T("app.title")), Reset)
+	fmt.Printf("%s%s%s %s%s%s %s|%s %s%s%s %s%s%s\n", 
+		Dim, i18n.T("tui.provider"), Reset, Green, s.provider.GetName(), Reset,
+		Dim, Reset, Dim, i18n.T("tui.model"), Reset, Yellow, s.config.Model, Reset)
+	fmt.Printf("%s%s%s\n\n", Gray, i18n.Tf("tui.banner.commands", Cyan+"/help"+Gray), Reset)
 }
 
 // showStats displays session statistics
 func (s *SimpleTUI) showStats() {
 	if s.totalRequests > 0 {
 		avgTime := s.totalTime / time.Duration(s.totalRequests)
-		fmt.Printf("%sSession Stats:%s %s%d%s requests, %s%d%s tokens, avg %s%s%s\n\n", 
-			Cyan+Bold, Reset, Green, s.totalRequests, Reset, 
+		fmt.Printf("%s%s%s %s%d%s requests, %s%d%s tokens, avg %s%s%s\n\n", 
+			Cyan+Bold, i18n.T("stats.title"), Reset, Green, s.totalRequests, Reset, 
 			Green, s.totalTokens, Reset, Yellow, avgTime.Round(time.Millisecond), Reset)
 	} else {
-		fmt.Printf("%sNo requests made yet%s\n\n", Dim, Reset)
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("stats.none"), Reset)
 	}
+
+	usage := ai.CurrentToolUsage()
+	fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("stats.tools", usage.Turn, toolLimit(usage.TurnLimit), usage.Minute, toolLimit(usage.MinuteLimit)), Reset)
+}
+
+// toolLimit shows a tool call limit, where 0 means there is none
+func toolLimit(limit int) string {
+	if limit == 0 {
+		return glyphs.Infinity
+	}
+	return strconv.Itoa(limit)
+}
+
+// handlePersona lists personas or switches the active one for this session
+func (s *SimpleTUI) handlePersona(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%sPersonas:%s\n", Cyan+Bold, Reset)
+		for _, name := range s.config.ListPersonas() {
+			marker := " "
+			if name == s.config.Persona {
+				marker = "*"
+			}
+			fmt.Printf("  %s%s %s%s\n", Green, marker, name, Reset)
+		}
+		fmt.Printf("%sUse /persona <name> to switch, /persona none to reset%s\n\n", Dim, Reset)
+		return
+	}
+	
+	name := args[0]
+	if name == "none" || name == "default" {
+		name = ""
+	} else if _, exists := s.config.GetPersona(name); !exists {
+		fmt.Printf("%s%s%s Unknown persona: %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, name)
+		return
+	}
+	
+	previous := s.config.Persona
+	s.config.Persona = name
+	provider, err := ai.CreateProviderFromConfig(s.config)
+	if err != nil {
+		s.config.Persona = previous
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	s.provider = provider
+	
+	if name == "" {
+		fmt.Printf("%s%s%s Persona reset to the default system prompt\n\n", Green+Bold, i18n.T("tui.system"), Reset)
+	} else {
+		fmt.Printf("%s%s%s Persona switched to %s\n\n", Green+Bold, i18n.T("tui.system"), Reset, name)
+	}
+}
+
+// handleProfile lists provider profiles or switches to one for this session
+func (s *SimpleTUI) handleProfile(args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		names := s.config.ListProfiles()
+		if len(names) == 0 {
+			fmt.Printf("%s%s%s\n\n", Dim, i18n.T("profile.none"), Reset)
+			return
+		}
+		fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("profile.title"), Reset)
+		for _, name := range names {
+			marker := " "
+			if name == s.config.ActiveProfile {
+				marker = "*"
+			}
+			profile := s.config.Profiles[name]
+			fmt.Printf("  %s%s %s%s %s%s %s%s\n", Green, marker, name, Reset, Dim, profile.Provider, profile.Model, Reset)
+		}
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("profile.hint", "/profile use <name>", "/profile use none"), Reset)
+		return
+	}
+
+	if args[0] != "use" || len(args) < 2 {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("profile.usage", "/profile [list|use <name>]"))
+		return
+	}
+
+	previous := s.config.ActiveProfile
+	err := s.config.UseProfile(args[1])
+	if err == nil {
+		err = s.config.Validate()
+	}
+	var provider ai.Provider
+	if err == nil {
+		provider, err = ai.CreateProviderFromConfig(s.config)
+	}
+	if err != nil {
+		_ = s.config.UseProfile(previous)
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	s.provider = provider
+
+	message := i18n.Tf("profile.reset", provider.GetName(), s.config.Model)
+	if s.config.ActiveProfile != "" {
+		message = i18n.Tf("profile.switched", s.config.ActiveProfile, provider.GetName(), s.config.Model)
+	}
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, i18n.T("tui.system"), Reset, message)
+}
+
+// showTools lists the tools the AI can call, or the parameters of one tool
+func (s *SimpleTUI) showTools(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("tools.title"), Reset)
+		for _, tool := range ai.GetAvailableTools() {
+			fmt.Printf("  %s%-24s%s%s\n", Yellow, tool.Name, Reset, tool.Description)
+		}
+		fmt.Printf("\n%s%s%s\n\n", Dim, i18n.Tf("tools.hint", "/tools <name>"), Reset)
+		return
+	}
+
+	tool, ok := ai.FindTool(args[0])
+	if !ok {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("tools.unknown", args[0]))
+		return
+	}
+
+	fmt.Printf("%s%s%s\n  %s\n\n", Cyan+Bold, tool.Name, Reset, tool.Description)
+	params := tool.ParameterList()
+	if len(params) == 0 {
+		fmt.Printf("  %s%s%s\n\n", Dim, i18n.T("tools.none"), Reset)
+		return
+	}
+	fmt.Printf("%s%s%s\n", Yellow+Bold, i18n.T("tools.params"), Reset)
+	for _, param := range params {
+		required := ""
+		if param.Required {
+			required = ", " + i18n.T("tools.required")
+		}
+		fmt.Printf("  %s%s%s %s(%s%s)%s %s\n", Green, param.Name, Reset, Dim, param.Type, required, Reset, param.Description)
+	}
+	fmt.Println()
+}
+
+// undo reverses the most recent file change made by a tool
+func (s *SimpleTUI) undo() {
+	description, err := ai.Undo()
+	if errors.Is(err, ai.ErrNothingToUndo) {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("undo.empty"), Reset)
+		return
+	}
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("undo.done", description))
+}
+
+// handleTransaction begins, commits or rolls back a transaction, or lists staged operations
+func (s *SimpleTUI) handleTransaction(args []string) {
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	var err error
+	switch action {
+	case "begin":
+		if err = ai.BeginTransaction(); err == nil {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("tx.begun"))
+		}
+	case "commit":
+		var count int
+		if count, err = ai.CommitTransaction(); err == nil {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("tx.committed", count))
+		}
+	case "rollback":
+		var count int
+		if count, err = ai.RollbackTransaction(); err == nil {
+			fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("tx.rolled_back", count))
+		}
+	case "":
+		if !ai.TransactionActive() {
+			fmt.Printf("%s%s%s\n\n", Dim, i18n.T("tx.none"), Reset)
+			return
+		}
+		fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("tx.staged"), Reset)
+		for i, op := range ai.StagedOperations() {
+			fmt.Printf("  %s%d.%s %s\n", Yellow, i+1, Reset, op)
+		}
+		fmt.Println()
+	default:
+		err = fmt.Errorf(i18n.T("tx.usage"), "/tx [begin|commit|rollback]")
+	}
+
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+	}
+}
+
+// handleTrash lists trashed items or restores one
+func (s *SimpleTUI) handleTrash(args []string) {
+	if len(args) > 0 && args[0] == "restore" {
+		id := ""
+		if len(args) > 1 {
+			id = args[1]
+		}
+		result := fileops.RestoreFromTrash(id)
+		if !result.Success {
+			fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, result.Message)
+			return
+		}
+		fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, result.Message)
+		return
+	}
+	if len(args) > 0 && args[0] != "list" {
+		fmt.Printf("%s%s%s %s\n\n", Red+Bold, i18n.T("tui.error"), Reset, i18n.Tf("trash.usage", "/trash [list|restore [id]]"))
+		return
+	}
+
+	items, err := fileops.ListTrash()
+	if err != nil {
+		fmt.Printf("%s%s%s %v\n\n", Red+Bold, i18n.T("tui.error"), Reset, err)
+		return
+	}
+	if len(items) == 0 {
+		fmt.Printf("%s%s%s\n\n", Dim, i18n.T("trash.empty"), Reset)
+		return
+	}
+
+	fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("trash.title"), Reset)
+	for _, item := range items {
+		fmt.Printf("  %s%s%s %s %s(%s)%s\n", Yellow, item.ID, Reset, item.OriginalPath, Dim, item.DeletedAt.Format("2006-01-02 15:04"), Reset)
+	}
+	fmt.Printf("\n%s%s%s\n\n", Dim, i18n.Tf("trash.hint", "/trash restore <id>"), Reset)
 }
 
 // showConfig displays current configuration
 func (s *SimpleTUI) showConfig() {
-	fmt.Printf("%sCurrent Configuration:%s\n", Cyan+Bold, Reset)
-	fmt.Printf("  %sProvider:%s %s%s%s\n", Yellow, Reset, Green, s.config.Provider, Reset)
-	fmt.Printf("  %sModel:%s %s%s%s\n", Yellow, Reset, Green, s.config.Model, Reset)
-	fmt.Printf("  %sTemperature:%s %s%.1f%s\n", Yellow, Reset, Green, s.config.Temperature, Reset)
-	fmt.Printf("  %sMax Tokens:%s %s%d%s\n", Yellow, Reset, Green, s.config.MaxTokens, Reset)
-	fmt.Printf("  %sTools:%s %s%v%s\n\n", Yellow, Reset, Green, s.provider.SupportsTools(), Reset)
+	fmt.Printf("%s%s%s\n", Cyan+Bold, i18n.T("config.title"), Reset)
+	fmt.Printf("  %s%s%s %s%s%s\n", Yellow, i18n.T("config.provider"), Reset, Green, s.config.Provider, Reset)
+	fmt.Printf("  %s%s%s %s%s%s\n", Yellow, i18n.T("config.model"), Reset, Green, s.config.Model, Reset)
+	fmt.Printf("  %s%s%s %s%.1f%s\n", Yellow, i18n.T("config.temperature"), Reset, Green, s.config.Temperature, Reset)
+	fmt.Printf("  %s%s%s %s%d%s\n", Yellow, i18n.T("config.max_tokens"), Reset, Green, s.config.MaxTokens, Reset)
+	fmt.Printf("  %s%s%s %s%v%s\n\n", Yellow, i18n.T("config.tools"), Reset, Green, s.provider.SupportsTools() && !s.noTools, Reset)
 }
 
 // displayResponseByParagraphs displays AI response paragraph by paragraph with natural timing
 func (s *SimpleTUI) displayResponseByParagraphs(response string) {
 	// Split response into paragraphs (double newlines or single newlines)
 	paragraphs := strings.Split(response, "\n")
+
+	// Nobody reads along when output goes to a pipe or file
+	delay := s.config.GetTypingDelay()
+	if !isTerminal(os.Stdout) {
+		delay = 0
+	}
+
+	// The typewriter sets its own pace, so it replaces the paragraph delay
+	tw := s.newTypewriter()
+	if tw != nil {
+		delay = 0
+		atomic.StoreInt32(&s.typing, 1)
+		defer atomic.StoreInt32(&s.typing, 0)
+	}
 	
 	// Process each paragraph
 	for i, paragraph := range paragraphs {
@@ -319,7 +1001,7 @@ func (s *SimpleTUI) displayResponseByParagraphs(response string) {
 		wrappedParagraph := s.wrapText(paragraph, getTerminalWidth())
 		
 		// Display the paragraph
-		fmt.Print(wrappedParagraph)
+		tw.write(wrappedParagraph)
 		
 		// Add newline after paragraph (except for the last one)
 		if i < len(paragraphs)-1 {
@@ -328,8 +1010,8 @@ func (s *SimpleTUI) displayResponseByParagraphs(response string) {
 		
 		// Add a slight delay between paragraphs for natural reading flow
 		// (but not too long to avoid feeling slow)
-		if i < len(paragraphs)-1 && paragraph != "" {
-			time.Sleep(200 * time.Millisecond)
+		if i < len(paragraphs)-1 && paragraph != "" && delay > 0 {
+			time.Sleep(delay)
 		}
 	}
 	
@@ -377,6 +1059,148 @@ func (s *SimpleTUI) wrapText(text string, width int) string {
 }
 
 
+// askClarification prompts the user for a tool parameter the AI could not determine
+func (s *SimpleTUI) askClarification(tool, param, description string) (string, bool) {
+	question := i18n.T("clarify." + param)
+	if question == "clarify."+param {
+		question = i18n.Tf("clarify.generic", param, description)
+	}
+
+	answer := s.ask(question + " " + Dim + i18n.T("clarify.hint") + Reset)
+	return answer, answer != ""
+}
+
+// confirmModelPull asks before downloading a missing Ollama model
+func (s *SimpleTUI) confirmModelPull(model string) bool {
+	return isYes(s.ask(i18n.Tf("pull.confirm", model)))
+}
+
+// confirmCommand asks before execute_command runs a risky command
+func (s *SimpleTUI) confirmCommand(command, reason string) bool {
+	return isYes(s.ask(i18n.Tf("command.confirm", Yellow+command+Reset, reason)))
+}
+
+// keepWaiting asks whether to give a timed-out command more time instead of killing it
+func (s *SimpleTUI) keepWaiting(command string, elapsed time.Duration) bool {
+	return isYes(s.ask(i18n.Tf("command.keep_waiting", Yellow+command+Reset, elapsed)))
+}
+
+// confirmOutsidePath asks before a tool acts outside the current directory
+func (s *SimpleTUI) confirmOutsidePath(tool, path string) bool {
+	return isYes(s.ask(i18n.Tf("path.confirm_outside", tool, Yellow+path+Reset)))
+}
+
+// confirmRenames previews a bulk rename and asks before applying it
+func (s *SimpleTUI) confirmRenames(dir string, renames []fileops.Rename) bool {
+	return isYes(s.ask(i18n.Tf("rename.confirm", len(renames), Yellow+dir+Reset, fileops.FormatRenames(renames))))
+}
+
+// isYes reports whether an answer to a yes/no question agreed, in the words
+// the current language accepts
+func isYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, yes := range strings.Split(i18n.T("answer.yes"), ",") {
+		if answer == yes {
+			return true
+		}
+	}
+	return false
+}
+
+// showPullProgress renders model download progress on a single line
+func (s *SimpleTUI) showPullProgress(model, status string, completed, total int64) {
+	atomic.StoreInt32(&s.paused, 1)
+	fmt.Print("\r\033[K")
+	if status == "success" {
+		fmt.Printf("%s%s%s %s\n", Cyan+Bold, i18n.T("tui.system"), Reset, i18n.Tf("pull.done", model))
+		atomic.StoreInt32(&s.paused, 0)
+		return
+	}
+	if total > 0 {
+		fmt.Printf("%s%s %s%d%%%s", Dim, i18n.Tf("pull.progress", model, status), Yellow, completed*100/total, Reset)
+	} else {
+		fmt.Printf("%s%s%s", Dim, i18n.Tf("pull.progress", model, status), Reset)
+	}
+}
+
+// showIntent prints a detected intent in verbose mode, before its tool runs
+func (s *SimpleTUI) showIntent(intent ai.Intent, accepted bool) {
+	if !s.verbose {
+		return
+	}
+
+	verdict := Green + i18n.Tf("intent.run", intent.Confidence, ai.IntentConfidenceThreshold)
+	if !accepted {
+		verdict = Yellow + i18n.Tf("intent.skip", intent.Confidence, ai.IntentConfidenceThreshold)
+	}
+	params, _ := json.Marshal(intent.Parameters)
+
+	fmt.Print("\r\033[K")
+	fmt.Printf("%s%s%s %s%s%s %s %s(%s)%s\n", Cyan+Bold, i18n.T("intent.detected"), Reset, Yellow, intent.Tool, Reset, params, verdict, intent.Action, Reset)
+}
+
+// ask prints a question while the AI is busy and waits for the next input line.
+// It runs on the AI goroutine; the answer arrives through the main input loop.
+func (s *SimpleTUI) ask(question string) string {
+	// Requests running side by side, like /compare targets, ask one at a time
+	s.askMu.Lock()
+	defer s.askMu.Unlock()
+	atomic.StoreInt32(&s.paused, 1)
+	defer atomic.StoreInt32(&s.paused, 0)
+
+	fmt.Print("\r\033[K")
+	fmt.Printf("%s%s%s %s\n", Cyan+Bold, i18n.T("tui.system"), Reset, question)
+	fmt.Printf("%s> %s", Blue+Bold, Reset)
+
+	atomic.StoreInt32(&s.awaiting, 1)
+	answer := <-s.answers
+	atomic.StoreInt32(&s.awaiting, 0)
+	return strings.TrimSpace(answer)
+}
+
+// showFileProgress keeps the latest progress of a long file operation for the
+// thinking indicator, until the operation ends
+func (s *SimpleTUI) showFileProgress(operation string, done, total int) {
+	switch {
+	case total > 0 && done >= total:
+		s.fileProgress.Store("")
+	case total > 0:
+		s.fileProgress.Store(i18n.Tf("tui.files_done", operation, done, total))
+	default:
+		s.fileProgress.Store(i18n.Tf("tui.files_counted", operation, done))
+	}
+}
+
+// countChunk records a streamed chunk for the thinking indicator
+func (s *SimpleTUI) countChunk(string) {
+	atomic.CompareAndSwapInt64(&s.firstChunkAt, 0, time.Now().UnixNano())
+	atomic.AddInt64(&s.streamedChunks, 1)
+}
+
+// progressEstimate describes how far along a response is: the progress of a
+// long file operation a tool is running, its throughput once streamed chunks
+// arrive, otherwise the time left against the session average
+func (s *SimpleTUI) progressEstimate(elapsed time.Duration) string {
+	if text, _ := s.fileProgress.Load().(string); text != "" {
+		return text
+	}
+	if chunks := atomic.LoadInt64(&s.streamedChunks); chunks > 0 {
+		streaming := time.Since(time.Unix(0, atomic.LoadInt64(&s.firstChunkAt))).Seconds()
+		if streaming < 0.1 {
+			return i18n.Tf("tui.streamed", chunks)
+		}
+		return i18n.Tf("tui.throughput", chunks, float64(chunks)/streaming)
+	}
+	if s.totalRequests == 0 {
+		return ""
+	}
+	avg := s.totalTime / time.Duration(s.totalRequests)
+	if elapsed < avg {
+		return i18n.Tf("tui.eta", (avg - elapsed).Seconds(), avg.Seconds())
+	}
+	return i18n.Tf("tui.overdue", (elapsed - avg).Seconds(), avg.Seconds())
+}
+
 // showThinkingProgress displays clean thinking progress with stats
 func (s *SimpleTUI) showThinkingProgress(start time.Time, done chan bool) {
 	ticker := time.NewTicker(400 * time.Millisecond)
@@ -387,13 +1211,20 @@ func (s *SimpleTUI) showThinkingProgress(start time.Time, done chan bool) {
 		case <-done:
 			return
 		case <-ticker.C:
+			if atomic.LoadInt32(&s.paused) == 1 {
+				continue
+			}
 			elapsed := time.Since(start)
 			
 			// Format elapsed time with consistent width (always shows as X.Xs format)
 			elapsedSeconds := elapsed.Seconds()
 			timeStr := fmt.Sprintf("%4.1fs", elapsedSeconds)
 			
-			// Always show session stats with consistent formatting
+			// The estimate comes first, then the session stats
+			estimate := ""
+			if text := s.progressEstimate(elapsed); text != "" {
+				estimate = fmt.Sprintf(" %s|%s %s%s%s", Dim, Reset, Yellow, text, Reset)
+			}
 			var avgSeconds float64
 			if s.totalRequests > 0 {
 				avgTime := s.totalTime / time.Duration(s.totalRequests)
@@ -401,14 +1232,17 @@ func (s *SimpleTUI) showThinkingProgress(start time.Time, done chan bool) {
 			}
 			
 			// Create complete progress line with consistent formatting
-			progressText := fmt.Sprintf("%s🤔 AI is thinking...%s %s(%s)%s %s|%s %sSession:%s %s%3d%s req, %s%5d%s tokens, avg %s%4.1fs%s", 
-				Yellow, Reset, Dim, timeStr, Reset,
-				Dim, Reset, Cyan, Reset, Green, s.totalRequests, Reset,
+			progressText := fmt.Sprintf("%s%s%s %s(%s)%s%s %s|%s %s%s%s %s%3d%s req, %s%5d%s tokens, avg %s%4.1fs%s", 
+				Yellow, glyphs.Text(i18n.T("tui.thinking")), Reset, Dim, timeStr, Reset, estimate,
+				Dim, Reset, Cyan, i18n.T("tui.session"), Reset, Green, s.totalRequests, Reset,
 				Green, s.totalTokens, Reset, Yellow, avgSeconds, Reset)
 			
 			// Clear the line completely and write the new progress
 			fmt.Print("\r\033[K")  // Clear entire line
 			fmt.Print(progressText)
+
+			// Keep the status line live too, as a tool may change directory
+			s.updateStatusLine()
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tala/internal/i18n"
+)
+
+// statusLine pins session details to the bottom row of the terminal. A scroll
+// region keeps the rest of the output moving above it.
+type statusLine struct {
+	mu      sync.Mutex
+	enabled bool
+	rows    int
+	cols    int
+	text    string
+}
+
+// getTerminalRows returns the terminal height, or 0 when it cannot be determined
+func getTerminalRows() int {
+	cmd := exec.Command("tput", "lines")
+	cmd.Stderr = os.Stderr
+	if output, err := cmd.Output(); err == nil {
+		if rows, err := strconv.Atoi(strings.TrimSpace(string(output))); err == nil && rows > 0 {
+			return rows
+		}
+	}
+	return 0
+}
+
+// enable reserves the bottom row for the status line. It does nothing when stdout
+// is not a terminal or the terminal is too small to spare a row.
+func (l *statusLine) enable() {
+	if !isTerminal(os.Stdout) {
+		return
+	}
+	rows := getTerminalRows()
+	if rows < 3 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enabled {
+		return
+	}
+	l.enabled = true
+	l.rows, l.cols = rows, getTerminalWidth()
+
+	// Make sure the cursor is above the last row before fencing it off;
+	// setting the scroll region moves the cursor, so save and restore it
+	fmt.Printf("\n\x1b[1A\x1b7\x1b[1;%dr\x1b8", rows-1)
+	onRestore(l.disable)
+}
+
+// disable gives the bottom row back to normal output
+func (l *statusLine) disable() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled {
+		return
+	}
+	l.enabled = false
+	fmt.Printf("\x1b7\x1b[r\x1b[%d;1H\x1b[2K\x1b8", l.rows)
+}
+
+// active reports whether the status line is shown
+func (l *statusLine) active() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enabled
+}
+
+// layout sets the scroll region again for the current terminal size and redraws.
+// Call it after a resize or after another program (/edit) had the terminal.
+func (l *statusLine) layout() {
+	rows := getTerminalRows()
+	cols := getTerminalWidth()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled || rows < 3 {
+		return
+	}
+	l.rows, l.cols = rows, cols
+	fmt.Printf("\x1b7\x1b[1;%dr\x1b8", rows-1)
+	l.drawLocked()
+}
+
+// draw shows text on the status line, cut to the terminal width
+func (l *statusLine) draw(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.text = text
+	if l.enabled {
+		l.drawLocked()
+	}
+}
+
+func (l *statusLine) drawLocked() {
+	text := l.text
+	if runes := []rune(text); len(runes) > l.cols {
+		text = string(runes[:l.cols])
+	}
+	fmt.Printf("\x1b7\x1b[%d;1H\x1b[2K%s%s%s\x1b8", l.rows, Dim, text, Reset)
+}
+
+// statusText describes the session for the status line: provider, model,
+// working directory and tokens used so far
+func (s *SimpleTUI) statusText() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "?"
+	} else if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rel, err := filepath.Rel(home, cwd); err == nil && !strings.HasPrefix(rel, "..") {
+			cwd = filepath.Join("~", rel)
+		}
+	}
+	return fmt.Sprintf(" %s | %s | %s | %s", s.provider.GetName(), s.config.Model, cwd,
+		i18n.Tf("status.tokens", s.totalTokens))
+}
+
+// updateStatusLine redraws the status line with the current session details
+func (s *SimpleTUI) updateStatusLine() {
+	if s.status.active() {
+		s.status.draw(s.statusText())
+	}
+}
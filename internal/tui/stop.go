@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tala/internal/ai"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+)
+
+// handleStop shows or sets the stop sequences for the rest of the run; \n and
+// \t in a sequence stand for a newline and a tab. /stop [clear|<seq>...]
+func (s *SimpleTUI) handleStop(args []string) {
+	switch {
+	case len(args) == 0:
+		if len(s.config.StopSequences) == 0 {
+			fmt.Printf("%s%s%s\n\n", Dim, i18n.Tf("stop.none", "/stop <sequence>"), Reset)
+			return
+		}
+		fmt.Printf("%s%s%s %s\n\n", Cyan+Bold, i18n.T("stop.title"), Reset, quoteAll(s.config.StopSequences))
+		return
+	case len(args) == 1 && args[0] == "clear":
+		s.config.StopSequences = nil
+		ai.SetStopSequences(s.provider, nil)
+		fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.T("stop.cleared"))
+		return
+	}
+
+	stop := make([]string, len(args))
+	for i, arg := range args {
+		stop[i] = unescapeStop(arg)
+	}
+	s.config.StopSequences = stop
+	ai.SetStopSequences(s.provider, stop)
+	fmt.Printf("%s%s%s %s\n\n", Green+Bold, glyphs.Check, Reset, i18n.Tf("stop.set", quoteAll(stop)))
+}
+
+// unescapeStop turns \n, \t and \\ into the characters they stand for
+func unescapeStop(seq string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(seq)
+}
+
+// quoteAll shows strings as Go literals so whitespace is visible
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"sync"
+)
+
+// Terminal changes made by the TUI register an undo step here, so the terminal is
+// put back however the process ends: normal return, signal, /exit or a panic.
+var (
+	restoreMu sync.Mutex
+	restorers []func()
+)
+
+// onRestore registers f to run when the terminal is restored
+func onRestore(f func()) {
+	restoreMu.Lock()
+	defer restoreMu.Unlock()
+	restorers = append(restorers, f)
+}
+
+// restoreTerminal undoes every registered terminal change, most recent first.
+// It is safe to call more than once.
+func restoreTerminal() {
+	restoreMu.Lock()
+	defer restoreMu.Unlock()
+	for i := len(restorers) - 1; i >= 0; i-- {
+		restorers[i]()
+	}
+	restorers = nil
+}
+
+// restoreOnPanic restores the terminal before a panic ends the process. Defer it
+// at the top of every goroutine the TUI starts.
+func restoreOnPanic() {
+	if r := recover(); r != nil {
+		restoreTerminal()
+		panic(r)
+	}
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// typewriter reveals a reply a character at a time. Once skipped (Ctrl+C), the
+// rest of the reply is printed at once.
+type typewriter struct {
+	interval time.Duration
+	skip     <-chan struct{}
+	skipped  bool
+}
+
+// newTypewriter returns a typewriter printing cps characters per second, or nil
+// when the effect is off and text should be printed directly
+func (s *SimpleTUI) newTypewriter() *typewriter {
+	if !s.config.TypewriterEffect || s.raw || !isTerminal(os.Stdout) {
+		return nil
+	}
+	// Drop a skip left over from a previous reply
+	select {
+	case <-s.skipTyping:
+	default:
+	}
+	return &typewriter{
+		interval: time.Second / time.Duration(s.config.GetTypewriterCPS()),
+		skip:     s.skipTyping,
+	}
+}
+
+// write prints text, one character per interval until skipped. A nil typewriter
+// prints text immediately.
+func (t *typewriter) write(text string) {
+	if t == nil || t.skipped {
+		fmt.Print(text)
+		return
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	runes := []rune(text)
+	for i, r := range runes {
+		fmt.Print(string(r))
+		select {
+		case <-t.skip:
+			t.skipped = true
+			fmt.Print(string(runes[i+1:]))
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,63 +5,258 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"tala/internal/ai"
+	"tala/internal/audit"
 	"tala/internal/config"
+	"tala/internal/glyphs"
+	"tala/internal/i18n"
+	"tala/internal/logging"
+	"tala/internal/prompt"
+	"tala/internal/retrieval"
+	"tala/internal/session"
 	"tala/internal/tui"
 )
 
 var version = "1.0.6" // Version can be overridden at build time
 
+// defaultPromptTimeout bounds a headless request so scripts never hang forever
+const defaultPromptTimeout = 2 * time.Minute
+
 func main() {
 	// Parse command line flags
+	var templateVars stringList
+	flag.Var(&templateVars, "var", "Set a prompt template variable as name=value (repeatable)")
 	var (
-		prompt = flag.String("p", "", "Direct prompt mode - execute prompt and exit")
+		promptFlag = flag.String("p", "", "Direct prompt mode - execute prompt and exit")
+		promptFile = flag.String("prompt-file", "", "Read the prompt from a file (piped stdin is appended) and exit")
 		model = flag.String("model", "", "Override model for this session")
 		provider = flag.String("provider", "", "Override provider for this session")
+		format = flag.String("format", "", "Response format for this session (\"json\" forces valid JSON output)")
+		jsonSchema = flag.String("json-schema", "", "Validate headless JSON output against this JSON Schema file (implies --format json)")
+		timeout = flag.Duration("timeout", defaultPromptTimeout, "Maximum time to wait for a headless response (0 = no limit)")
+		mode = flag.String("mode", "", "Launch mode without a prompt: tui or headless (default from config)")
+		noTools = flag.Bool("no-tools", false, "Plain chat: skip intent detection and never run tools")
+		verbose = flag.Bool("verbose", false, "Show detected intents and their confidence before tools run, and the progress of long file operations")
+		raw = flag.Bool("raw", false, "Print responses verbatim: no wrapping, coloring, delays or thinking removal")
+		stream = flag.Bool("stream", false, "Print a headless reply as it is generated (plain chat, no tools)")
+		output = flag.String("output", "", "Also write the headless reply to this file")
+		logLevel = flag.String("log-level", "", "Diagnostics to show on stderr: debug, info, warn or error")
+		logFormat = flag.String("log-format", "", "Diagnostic log format: text or json")
+		quiet = flag.Bool("quiet", false, "Suppress decorative output and print only the response")
+		persona = flag.String("persona", "", "Persona preset to use for this session")
+		compare = flag.String("compare", "", "Ask the prompt to several comma-separated provider:model targets or profiles at once")
+		profile = flag.String("profile", "", "Named provider profile from the config to use for this session")
+		metricsAddr = flag.String("metrics-addr", "", "Serve Prometheus metrics at this address under /metrics")
+		resume = flag.String("resume", "", "Resume a saved session by ID (or \"last\") in the TUI")
+		temperature = flag.Float64("temperature", -1, "Override temperature (0.0-2.0) for this session")
+		maxTokens = flag.Int("max-tokens", -1, "Override max tokens (0 = unlimited) for this session")
+		seed = flag.Int("seed", 0, "Sampling seed for repeatable replies (best effort)")
+		topP = flag.Float64("top-p", 0, "Override nucleus sampling top_p (0.0-1.0) for this session")
+		presencePenalty = flag.Float64("presence-penalty", 0, "Override presence penalty (-2.0-2.0) for this session")
+		frequencyPenalty = flag.Float64("frequency-penalty", 0, "Override frequency penalty (-2.0-2.0) for this session")
+		listProviders = flag.Bool("list-providers", false, "List supported providers and exit")
+		listTools = flag.Bool("list-tools", false, "List available tools and exit")
+		jsonOutput = flag.Bool("json", false, "Print --list-providers/--list-tools output as JSON")
 		help = flag.Bool("help", false, "Show help message")
 		versionFlag = flag.Bool("version", false, "Show version information")
 	)
 	flag.Parse()
 
+	// Flags set up logging at once so early errors are logged consistently;
+	// the config's settings apply once it is loaded
+	if err := logging.Setup(*logLevel, *logFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging flags: %v\n", err)
+		os.Exit(1)
+	}
+
 	if *help {
 		showHelp()
 		return
 	}
 
+	if err := config.ValidateMode(*mode); err != nil {
+		logging.Fatal("invalid --mode", "error", err)
+	}
+
 	if *versionFlag {
 		showVersion()
 		return
 	}
 
+	if *listProviders {
+		showProviders(*jsonOutput)
+		return
+	}
+
+	if *listTools {
+		showTools(*jsonOutput)
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("loading config", "error", err)
+	}
+	i18n.SetLanguage(i18n.Detect(cfg.Language))
+	glyphs.SetUnicode(glyphs.Detect(cfg.Unicode))
+	if *logLevel != "" {
+		cfg.LogLevel = *logLevel
+	}
+	if *logFormat != "" {
+		cfg.LogFormat = *logFormat
+	}
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		logging.Fatal(i18n.Tf("config.error", err))
 	}
 
-	// Apply command-line overrides
+	// Subcommands work on stored data and need no provider
+	if args := flag.Args(); len(args) > 0 && args[0] == "export-finetune" {
+		os.Exit(runExportFinetune(args[1:], cfg))
+	}
+	if args := flag.Args(); len(args) > 0 && args[0] == "audit" {
+		os.Exit(runAudit(args[1:]))
+	}
+	if args := flag.Args(); len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfig(args[1:]))
+	}
+
+	// Apply command-line overrides; a profile comes first so --model etc. refine it
+	if *profile != "" {
+		if err := cfg.UseProfile(*profile); err != nil {
+			logging.Fatal("invalid --profile", "error", err)
+		}
+	}
 	if *model != "" {
 		cfg.Model = *model
 	}
 	if *provider != "" {
 		cfg.Provider = *provider
 	}
+	if *persona != "" {
+		cfg.Persona = *persona
+	}
+	if *format != "" {
+		cfg.ResponseFormat = *format
+	}
+	var schema map[string]interface{}
+	if *jsonSchema != "" {
+		var err error
+		if schema, err = loadJSONSchema(*jsonSchema); err != nil {
+			logging.Fatal("invalid --json-schema", "error", err)
+		}
+		cfg.ResponseFormat = ai.ResponseFormatJSON
+	}
+	if isFlagSet("temperature") {
+		if err := config.ValidateTemperature(*temperature); err != nil {
+			logging.Fatal("invalid --temperature", "error", err)
+		}
+		cfg.Temperature = *temperature
+	}
+	if isFlagSet("max-tokens") {
+		if err := config.ValidateMaxTokens(*maxTokens); err != nil {
+			logging.Fatal("invalid --max-tokens", "error", err)
+		}
+		cfg.MaxTokens = *maxTokens
+	}
+	if isFlagSet("seed") {
+		cfg.Seed = seed
+	}
+	if isFlagSet("top-p") {
+		if err := config.ValidateTopP(*topP); err != nil {
+			logging.Fatal("invalid --top-p", "error", err)
+		}
+		cfg.TopP = topP
+	}
+	if isFlagSet("presence-penalty") {
+		if err := config.ValidatePenalty("presence_penalty", *presencePenalty); err != nil {
+			logging.Fatal("invalid --presence-penalty", "error", err)
+		}
+		cfg.PresencePenalty = presencePenalty
+	}
+	if isFlagSet("frequency-penalty") {
+		if err := config.ValidatePenalty("frequency_penalty", *frequencyPenalty); err != nil {
+			logging.Fatal("invalid --frequency-penalty", "error", err)
+		}
+		cfg.FrequencyPenalty = frequencyPenalty
+	}
 
 	if err := cfg.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		fmt.Fprintf(os.Stderr, "Please set your API key and other configuration options.\n")
-		fmt.Fprintf(os.Stderr, "Configuration file location: ~/.config/tala/config.json\n")
+		slog.Error(i18n.Tf("config.error", err))
+		if !*quiet {
+			fmt.Fprintln(os.Stderr, i18n.T("config.hint.settings"))
+			fmt.Fprintln(os.Stderr, i18n.T("config.hint.location"))
+		}
 		os.Exit(1)
 	}
 
+	if err := ai.ConfigureHTTP(cfg); err != nil {
+		slog.Error(i18n.Tf("config.error", err))
+		os.Exit(1)
+	}
+	ai.ConfigureTools(cfg)
+	ai.ConfigureContext(cfg)
+
+	// These subcommands use the chosen provider, so they run after the overrides
+	if args := flag.Args(); len(args) > 0 && args[0] == "index" {
+		os.Exit(runIndex(args[1:], cfg))
+	}
+	if args := flag.Args(); len(args) > 0 && args[0] == "replay" {
+		os.Exit(runReplay(args[1:], cfg, *timeout, *raw))
+	}
+	ai.ConfigureRetrieval(cfg)
+	ai.ConfigureCache(cfg)
+	if *metricsAddr != "" {
+		cfg.MetricsAddr = *metricsAddr
+	}
+	if cfg.MetricsAddr != "" {
+		ai.StartMetrics(cfg.MetricsAddr)
+	}
+	if *verbose {
+		// The TUI installs its own reporters; these cover headless runs
+		ai.ReportIntent = reportIntent
+		ai.FileProgress = reportProgress
+	}
+
+	vars, err := prompt.ParseVars(templateVars)
+	if err != nil {
+		logging.Fatal("invalid --var", "error", err)
+	}
+
+	// Headless prompts go to one provider, or to several with --compare
+	run := func(text string) {
+		if *compare != "" {
+			os.Exit(runCompare(text, cfg, strings.Split(*compare, ","), *timeout, *raw))
+		}
+		runDirectPrompt(text, cfg, *timeout, schema, *noTools, *verbose, *raw, *stream, *output)
+	}
+
+	// A prompt file replaces -p; piped input such as a diff is added after it
+	if *promptFile != "" {
+		if *promptFlag != "" || len(flag.Args()) > 0 {
+			logging.Fatal("--prompt-file cannot be combined with -p or a prompt argument")
+		}
+		promptText, err := readPromptFile(*promptFile, vars)
+		if err != nil {
+			logging.Fatal("invalid --prompt-file", "error", err)
+		}
+		run(promptText)
+		return
+	}
+
 	// Handle direct prompt mode (headless)
-	if *prompt != "" {
-		runDirectPrompt(*prompt, cfg)
+	if *promptFlag != "" {
+		run(renderPrompt(*promptFlag, vars))
 		return
 	}
 
@@ -69,49 +264,663 @@ func main() {
 	args := flag.Args()
 	if len(args) > 0 {
 		promptText := strings.Join(args, " ")
-		runDirectPrompt(promptText, cfg)
+		run(renderPrompt(promptText, vars))
+		return
+	}
+
+	// Without a prompt, --mode or the config's default_mode picks what to launch
+	launchMode := cfg.DefaultMode
+	if *compare != "" && launchMode != "headless" && *mode != "headless" {
+		logging.Fatal("--compare needs a prompt; in the TUI use /compare instead")
+	}
+	if *mode != "" {
+		launchMode = *mode
+	}
+	switch launchMode {
+	case "headless":
+		promptText, err := readStdinPrompt()
+		if err != nil {
+			logging.Fatal(err.Error())
+		}
+		run(promptText)
 		return
+	case "gui":
+		// The GUI is only compiled in with -tags gui, in which case this main is not used
+		if *mode != "" {
+			logging.Fatal("this build of tala has no GUI; rebuild with -tags gui")
+		}
+		if !*quiet {
+			slog.Warn("default_mode is \"gui\" but this build has no GUI; starting the TUI")
+		}
 	}
 
-	// Default TUI mode
+	// TUI mode
 	simpleTUI, err := tui.NewSimpleTUI(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("starting the TUI", "error", err)
+	}
+	simpleTUI.SetQuiet(*quiet)
+	simpleTUI.SetNoTools(*noTools)
+	simpleTUI.SetVerbose(*verbose)
+	simpleTUI.SetRaw(*raw)
+	if *resume != "" {
+		if err := simpleTUI.Resume(*resume); err != nil {
+			logging.Fatal("invalid --resume", "error", err)
+		}
 	}
 
 	if err := simpleTUI.Run(); err != nil {
-		log.Fatal(err)
+		logging.Fatal("TUI stopped", "error", err)
 	}
 }
 
+// stringList collects the values of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// renderPrompt fills a prompt's {{.name}} placeholders when --var was given,
+// exiting if one is unset. Without --var the prompt is sent as typed, so
+// braces in it are never expanded, nor environment variables read into it.
+func renderPrompt(text string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return text
+	}
+	rendered, err := prompt.Render(text, vars)
+	if err != nil {
+		logging.Fatal("invalid prompt", "error", err)
+	}
+	return rendered
+}
+
+// isFlagSet reports whether a flag was passed explicitly on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// reportIntent prints a detected intent to stderr for --verbose headless runs
+func reportIntent(intent ai.Intent, accepted bool) {
+	verdict := "running"
+	if !accepted {
+		verdict = "skipped"
+	}
+	params, _ := json.Marshal(intent.Parameters)
+	fmt.Fprintf(os.Stderr, "intent: %s %s confidence %.2f (threshold %.2f), %s\n",
+		intent.Tool, params, intent.Confidence, ai.IntentConfidenceThreshold, verdict)
+}
+
+// reportProgress prints a line on stderr about a long file operation a tool is running
+func reportProgress(operation string, done, total int) {
+	if total > 0 {
+		fmt.Fprintf(os.Stderr, "progress: %s, %d/%d files\n", operation, done, total)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "progress: %s, %d files\n", operation, done)
+}
+
+// readStdinPrompt reads a headless prompt piped on standard input
+func readStdinPrompt() (string, error) {
+	prompt, err := readPipedStdin()
+	if err != nil {
+		return "", err
+	}
+	if prompt == "" {
+		return "", errors.New("headless mode needs a prompt: pass -p, --prompt-file, arguments, or pipe it on stdin")
+	}
+	return prompt, nil
+}
+
+// readPipedStdin returns what is piped on standard input, or "" when it is a terminal
+func readPipedStdin() (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return "", nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading prompt from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readPromptFile reads a --prompt-file prompt, fills its template variables and
+// appends any piped stdin, which is never treated as a template
+func readPromptFile(path string, vars map[string]string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	text, err = prompt.Render(text, vars)
+	if err != nil {
+		return "", err
+	}
+
+	piped, err := readPipedStdin()
+	if err != nil {
+		return "", err
+	}
+	if piped != "" {
+		text += "\n\n" + piped
+	}
+	return text, nil
+}
+
 // runDirectPrompt executes a single prompt and exits (headless mode)
-func runDirectPrompt(prompt string, cfg *config.Config) {
-	provider, err := ai.CreateProvider(cfg.Provider, cfg.APIKey, cfg.Model, cfg.Temperature, cfg.MaxTokens)
+func runDirectPrompt(prompt string, cfg *config.Config, timeout time.Duration, schema map[string]interface{}, noTools, verbose, raw, stream bool, output string) {
+	provider, err := ai.CreateProviderFromConfig(cfg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating provider: %v\n", err)
-		os.Exit(1)
+		logging.Fatal("creating provider", "error", err)
 	}
 
+	// The reply goes to stdout and, with --output, to a file as well
+	sinks := []io.Writer{os.Stdout}
+	var outputFile *os.File
+	if output != "" {
+		outputFile, err = os.Create(output)
+		if err != nil {
+			logging.Fatal("invalid --output", "error", err)
+		}
+		sinks = append(sinks, outputFile)
+	}
+	tee := ai.NewTee(sinks...)
+	// finish flushes whatever part of the reply was written, also before exiting on an error
+	finish := func() {
+		if err := tee.Close(); err != nil {
+			slog.Warn("writing the reply failed", "error", err)
+		}
+		if outputFile != nil {
+			outputFile.Close()
+		}
+	}
+	// Streaming prints the reply as it arrives; structured output is validated whole
+	streamed := stream && provider.SupportsStreaming() && cfg.ResponseFormat != ai.ResponseFormatJSON
+
 	ctx := context.Background()
-	var response string
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	// Use tools if available
-	if provider.SupportsTools() {
-		response, _, err = provider.GenerateResponseWithTools(ctx, prompt)
-	} else {
-		response, err = provider.GenerateResponse(ctx, prompt)
+	type outcome struct {
+		response    string
+		toolResults []ai.ToolResult
+		err         error
 	}
+	done := make(chan outcome, 1)
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	slog.Debug("sending prompt", "provider", provider.GetName(), "model", cfg.Model,
+		"format", cfg.ResponseFormat, "tools", provider.SupportsTools() && !noTools, "timeout", timeout)
+	start := time.Now()
+	go func() {
+		var out outcome
+		// Structured output skips tools so nothing but the JSON reaches stdout
+		if cfg.ResponseFormat == ai.ResponseFormatJSON {
+			out.response, out.err = ai.GenerateJSON(ctx, provider, prompt, schema)
+		} else if streamed {
+			out.response, out.err = provider.GenerateStreamingResponse(ctx, prompt, tee.Chunk)
+		} else if provider.SupportsTools() && !noTools {
+			out.response, out.toolResults, out.err = provider.GenerateResponseWithTools(ctx, prompt)
+		} else {
+			out.response, out.err = provider.GenerateResponse(ctx, prompt)
+		}
+		done <- out
+	}()
+
+	// Providers that ignore the context must not keep the CLI alive past the deadline
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	if out.err != nil {
+		// Keep what was streamed so far, ending the line on the terminal
+		finish()
+		if streamed && tee.Written() > 0 {
+			fmt.Println()
+		}
+	}
+	if errors.Is(out.err, context.DeadlineExceeded) {
+		logging.Fatal("request timed out (use --timeout to change the limit)", "timeout", timeout)
+	}
+	if out.err != nil {
+		logging.Fatal("request failed", "provider", provider.GetName(), "error", out.err)
+	}
+	slog.Debug("response received", "duration", time.Since(start), "tool_calls", len(out.toolResults))
+	response, toolResults := out.response, out.toolResults
+
+	// There is nobody to ask for missing tool parameters in headless mode
+	for _, result := range toolResults {
+		if len(result.MissingParams) > 0 {
+			logging.Fatal("tool parameters missing; please include them in the prompt",
+				"tool", result.Name, "missing", strings.Join(result.MissingParams, ", "))
+		}
+	}
+
+	// Reasoning goes to stderr in verbose mode so stdout stays the answer alone;
+	// a streamed reply was printed before it could be split
+	if cfg.HideThinking && !raw && !streamed {
+		var thinking string
+		response, thinking = ai.SplitThinking(response)
+		if verbose && thinking != "" {
+			fmt.Fprintf(os.Stderr, "thinking: %s\n", thinking)
+		}
 	}
 
 	// Output response directly to stdout (Unix-philosophy)
+	if !streamed {
+		tee.Chunk(response)
+	}
+	if !raw && !strings.HasSuffix(response, "\n") {
+		tee.Chunk("\n")
+	}
+	finish()
+}
+
+// runCompare asks several providers the same prompt at once and prints each
+// answer under a header with its timing. It returns 1 if any target failed.
+func runCompare(prompt string, cfg *config.Config, names []string, timeout time.Duration, raw bool) int {
+	var targets []ai.CompareTarget
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		targetCfg, err := cfg.ForTarget(name)
+		if err != nil {
+			logging.Fatal("invalid --compare target", "target", name, "error", err)
+		}
+		provider, err := ai.CreateProviderFromConfig(targetCfg)
+		if err != nil {
+			logging.Fatal("creating provider", "target", name, "error", err)
+		}
+		targets = append(targets, ai.CompareTarget{Name: name, Provider: provider})
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	status := 0
+	for i, result := range ai.Compare(ctx, targets, prompt) {
+		if i > 0 {
+			fmt.Println()
+		}
+		if result.Err != nil {
+			fmt.Printf("== %s (%s) ==\n", result.Target, result.Duration.Round(time.Millisecond))
+			slog.Error("request failed", "target", result.Target, "error", result.Err)
+			status = 1
+			continue
+		}
+		printCompared(fmt.Sprintf("%s (%s)", result.Target, result.Duration.Round(time.Millisecond)), result.Response, cfg.HideThinking && !raw)
+	}
+	return status
+}
+
+// replayPrompt sends one replayed prompt. Replays never run tools, so files are
+// not changed a second time.
+func replayPrompt(provider ai.Provider, prompt string, timeout time.Duration) (string, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return provider.GenerateResponse(ctx, prompt)
+}
+
+// printCompared prints one of several answers to the same prompt under a header
+func printCompared(header, response string, hideThinking bool) {
+	fmt.Printf("== %s ==\n", header)
+	if hideThinking {
+		response, _ = ai.SplitThinking(response)
+		response = strings.TrimSpace(response)
+	}
 	fmt.Print(response)
 	if !strings.HasSuffix(response, "\n") {
-		fmt.Print("\n")
+		fmt.Println()
+	}
+}
+
+// runReplay sends each prompt of a saved session to another model, printing the
+// original and new answers together and saving the new conversation as a
+// session branched from the original:
+// tala replay <session> [--model m] [--provider p] [--profile name]
+func runReplay(args []string, cfg *config.Config, timeout time.Duration, raw bool) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	model := fs.String("model", "", "Model to replay the session against")
+	providerName := fs.String("provider", "", "Provider to replay the session against")
+	profile := fs.String("profile", "", "Provider profile to replay the session against")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: tala replay <session> [--model m] [--provider p] [--profile name]")
+		fs.PrintDefaults()
+	}
+
+	// The session usually comes first: tala replay <session> --model m
+	id := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if id == "" && fs.NArg() == 1 {
+		id = fs.Arg(0)
+	} else if id == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	original, err := session.Load(id)
+	if err != nil {
+		slog.Error("loading the session", "error", err)
+		return 1
+	}
+	if *profile != "" {
+		if err := cfg.UseProfile(*profile); err != nil {
+			slog.Error("invalid --profile", "error", err)
+			return 2
+		}
+	}
+	if *providerName != "" {
+		cfg.Provider = *providerName
+	}
+	if *model != "" {
+		cfg.Model = *model
+	}
+	provider, err := ai.CreateProviderFromConfig(cfg)
+	if err != nil {
+		slog.Error("creating provider", "error", err)
+		return 1
+	}
+
+	replay := original.Branch()
+	turns := 0
+	for i, message := range original.Messages {
+		if message.Role != "user" {
+			continue
+		}
+		if turns > 0 {
+			fmt.Println()
+		}
+		turns++
+		fmt.Printf("## %d. %s\n", turns, session.Preview(message.Content))
+		if i+1 < len(original.Messages) && original.Messages[i+1].Role == "assistant" {
+			answer := original.Messages[i+1]
+			name := answer.Model
+			if name == "" {
+				name = "original"
+			}
+			printCompared(name+" (original)", answer.Content, cfg.HideThinking && !raw)
+		}
+
+		start := time.Now()
+		response, err := replayPrompt(provider, message.Content, timeout)
+		if err != nil {
+			slog.Error("replaying the session", "turn", turns, "error", err)
+			return 1
+		}
+		printCompared(fmt.Sprintf("%s (%s)", cfg.Model, time.Since(start).Round(time.Millisecond)), response, cfg.HideThinking && !raw)
+
+		ai.RememberTurn(provider, message.Content, response)
+		if err := replay.Append("user", message.Content); err == nil {
+			err = replay.Add(session.Message{Role: "assistant", Content: response, Model: cfg.Model})
+		}
+		if err != nil {
+			slog.Warn("saving the replay", "error", err)
+		}
+	}
+	if turns == 0 {
+		slog.Error("the session has no prompts to replay", "session", original.ID)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "\nReplayed %d prompts with %s; saved as session %s (tala --resume %s)\n", turns, cfg.Model, replay.ID, replay.ID)
+	return 0
+}
+
+// runExportFinetune writes the saved sessions as OpenAI fine-tuning JSONL:
+// tala export-finetune [--rated] [--no-system] <file|->
+func runExportFinetune(args []string, cfg *config.Config) int {
+	fs := flag.NewFlagSet("export-finetune", flag.ContinueOnError)
+	rated := fs.Bool("rated", false, "Only export up-rated responses, each with the conversation before it")
+	noSystem := fs.Bool("no-system", false, "Leave out the configured system prompt")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: tala export-finetune [--rated] [--no-system] <file|->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := session.FinetuneOptions{UpRatedOnly: *rated}
+	if !*noSystem {
+		opts.SystemPrompt = cfg.GetSystemPrompt()
+	}
+
+	out := io.Writer(os.Stdout)
+	if path := fs.Arg(0); path != "-" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			slog.Error("creating export file", "error", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	stats, err := session.ExportFinetune(out, opts)
+	if err != nil {
+		slog.Error("exporting sessions", "error", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d examples from %d sessions", stats.Examples, stats.Sessions)
+	if stats.Skipped > 0 {
+		fmt.Fprintf(os.Stderr, " (%d invalid examples skipped)", stats.Skipped)
+	}
+	fmt.Fprintln(os.Stderr)
+	return 0
+}
+
+// runConfig reads and changes the config file:
+// tala config list | tala config get <key> | tala config set <key> <value>
+func runConfig(args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "Usage: tala config list | tala config get <key> | tala config set <key> <value>")
+		fmt.Fprintln(os.Stderr, "Keys are the names in config.json; use dots for map entries, e.g. aliases.deploy or profiles.work.model")
+		return 2
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	// Read the file again so command-line overrides are never saved
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("loading config", "error", err)
+		return 1
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		for _, setting := range cfg.Settings() {
+			fmt.Printf("%s = %s\n", setting.Key, setting.Value)
+		}
+	case args[0] == "get" && len(args) == 2:
+		value, err := cfg.Get(args[1])
+		if err != nil {
+			slog.Error("reading a setting", "error", err)
+			return 1
+		}
+		fmt.Println(value)
+	case args[0] == "set" && len(args) == 3:
+		if err := cfg.Set(args[1], args[2]); err != nil {
+			slog.Error("changing a setting", "error", err)
+			return 1
+		}
+		if err := cfg.Save(); err != nil {
+			slog.Error("saving config", "error", err)
+			return 1
+		}
+		value, _ := cfg.Get(args[1])
+		fmt.Fprintf(os.Stderr, "Set %s to %q\n", args[1], value)
+	default:
+		return usage()
 	}
+	return 0
+}
+
+// runAudit prints the most recent entries of the audit log:
+// tala audit [-n count] [--follow] [--json]
+func runAudit(args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	count := fs.Int("n", 20, "Number of recent entries to show (0 for all)")
+	follow := fs.Bool("follow", false, "Keep printing entries as they are written")
+	fs.BoolVar(follow, "f", false, "Short for --follow")
+	asJSON := fs.Bool("json", false, "Print entries as JSON lines")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: tala audit [-n count] [--follow] [--json]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	show := func(entries []audit.Entry) {
+		for _, entry := range entries {
+			if *asJSON {
+				line, _ := json.Marshal(entry)
+				fmt.Println(string(line))
+			} else {
+				printAuditEntry(entry)
+			}
+		}
+	}
+
+	entries, offset, err := audit.Tail(*count)
+	if err != nil {
+		slog.Error("reading the audit log", "error", err)
+		return 1
+	}
+	if len(entries) == 0 && !*follow {
+		path, _ := audit.Path()
+		fmt.Fprintf(os.Stderr, "No operations recorded yet in %s\n", path)
+		return 0
+	}
+	show(entries)
+
+	for *follow {
+		time.Sleep(time.Second)
+		entries, offset, err = audit.ReadFrom(offset)
+		if err != nil {
+			slog.Error("reading the audit log", "error", err)
+			return 1
+		}
+		show(entries)
+	}
+	return 0
+}
+
+// printAuditEntry shows an audit entry as a line with its parameters, followed
+// by the prompt that led to it and any error
+func printAuditEntry(entry audit.Entry) {
+	mark := glyphs.Check
+	if !entry.Success {
+		mark = glyphs.Cross
+	}
+	keys := make([]string, 0, len(entry.Params))
+	for key := range entry.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := []rune(fmt.Sprint(entry.Params[key]))
+		if len(value) > 60 {
+			value = append(value[:57], []rune("...")...)
+		}
+		params = append(params, fmt.Sprintf("%s=%q", key, string(value)))
+	}
+
+	fmt.Printf("%s %s %s %s\n", entry.Time.Local().Format("2006-01-02 15:04:05"), mark, entry.Tool, strings.Join(params, " "))
+	if entry.Prompt != "" {
+		fmt.Printf("    prompt: %s\n", strings.Join(strings.Fields(entry.Prompt), " "))
+	}
+	if entry.Error != "" {
+		fmt.Printf("    error: %s\n", strings.Join(strings.Fields(entry.Error), " "))
+	}
+}
+
+// runIndex builds the document index that retrieval adds to prompts:
+// tala index <dir>, or tala index --clear to remove it
+func runIndex(args []string, cfg *config.Config) int {
+	fs := flag.NewFlagSet("index", flag.ContinueOnError)
+	clearIndex := fs.Bool("clear", false, "Remove the index, turning retrieval off")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: tala index <dir> | tala index --clear")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *clearIndex {
+		if err := retrieval.Remove(); err != nil && !errors.Is(err, retrieval.ErrNoIndex) {
+			slog.Error("removing the index", "error", err)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "Removed the document index")
+		return 0
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	model := ai.EmbeddingModelFor(cfg.Provider, cfg.EmbeddingModel)
+	embedder, err := ai.NewEmbedderFromConfig(cfg, model)
+	if err != nil {
+		slog.Error("indexing documents", "error", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Indexing %s with %s %s...\n", fs.Arg(0), cfg.Provider, model)
+	index, err := retrieval.Build(context.Background(), fs.Arg(0), embedder, func(file string) {
+		slog.Debug("indexing file", "file", file)
+	})
+	if err != nil {
+		slog.Error("indexing documents", "error", err)
+		return 1
+	}
+	index.Provider, index.Model = cfg.Provider, model
+	if err := index.Save(); err != nil {
+		slog.Error("saving the index", "error", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Indexed %d chunks from %d files; prompts now include the most relevant ones\n", len(index.Chunks), index.Files)
+	return 0
 }
 
 // showHelp displays usage information
@@ -120,11 +929,47 @@ func showHelp() {
 
 Usage:
   tala [flags] [prompt...]
+  tala export-finetune [--rated] [--no-system] <file|->
+  tala audit [-n count] [--follow] [--json]
+  tala config list | get <key> | set <key> <value>
+  tala index <dir> | tala index --clear
+  tala replay <session> [--model m] [--provider p] [--profile name]
 
 Flags:
   -p, --prompt string     Direct prompt mode - execute prompt and exit
+  --prompt-file file      Read the prompt from a file; piped stdin is appended to it
+  --var name=value        Fill {{.name}} in the prompt (repeatable; the environment is also used)
   --model string          Override model for this session
   --provider string       Override provider for this session
+  --temperature float     Override temperature (0.0-2.0) for this session
+  --max-tokens int        Override max tokens (0 = unlimited) for this session
+  --seed int              Sampling seed so the same prompt gives the same reply (best effort)
+  --top-p float           Override nucleus sampling top_p (0.0-1.0) for this session
+  --presence-penalty f    Override presence penalty (-2.0-2.0); positive favors new topics
+  --frequency-penalty f   Override frequency penalty (-2.0-2.0); positive discourages repetition
+  --persona string        Persona preset (concise, teacher, code-reviewer, or custom)
+  --compare targets       Ask several comma-separated provider:model targets or profiles at once
+  --profile string        Named provider profile from the config's "profiles"
+  --log-level level       Diagnostics on stderr: debug, info, warn (default) or error
+  --log-format format     Diagnostic log format: text (default) or json
+  --metrics-addr addr     Serve Prometheus metrics at addr under /metrics
+  --resume id             Resume a saved session in the TUI ("last" for the latest)
+  --format string         Response format; "json" forces valid JSON output
+  --json-schema file      Validate JSON output against a schema, re-prompting on failure
+  --timeout duration      Maximum time to wait for a headless response (default 2m, 0 = no limit)
+  --mode string           Launch mode without a prompt: tui or headless (reads stdin);
+                          defaults to default_mode from the config
+  --no-tools              Plain chat: skip intent detection and never run tools
+  --verbose               Show detected intents and their confidence before tools run,
+                          and the progress of long file operations
+  --raw                   Print responses verbatim (no wrapping, colors, delays or
+                          thinking removal)
+  --stream                Print a headless reply as it is generated (plain chat, no tools)
+  --output file           Also write the headless reply to file, as it streams with --stream
+  --quiet                 Suppress banner, spinner and stats; print only the response
+  --list-providers        List supported providers and exit
+  --list-tools            List available tools and exit
+  --json                  Use JSON output for --list-providers/--list-tools
   --help                  Show this help message
   --version               Show version information
 
@@ -134,12 +979,40 @@ Examples:
   tala -p "Explain Go channels"  # Direct prompt with flag
   tala --model gpt-4 "Help me"   # Override model
   tala --provider openai -p "Hi" # Override provider
+  tala --profile work            # Switch provider, model and key together
+  tala --compare ollama:llama3.2,openai:gpt-4o -p "Explain CRDTs"  # Side by side
+  tala --temperature 0 -p "2+2?" # Deterministic query
+  tala --seed 42 -p "Name a color"  # Same answer each run
+  tala --quiet -p "Summarize" > out.txt  # Scripting-friendly output
+  tala --stream --output story.md -p "Write a story"  # Watch it and keep a copy
+  tala --json-schema person.json -p "Extract the author"  # Structured extraction
+  git diff | tala --mode headless  # Prompt from stdin
+  git diff | tala --prompt-file review.txt  # Reusable prompt plus piped input
+  tala --prompt-file review.txt --var file=main.go  # Prompt template
+  tala export-finetune --rated out.jsonl  # Up-rated responses as fine-tuning data
+  tala audit -f                  # Watch what the AI does to your system
+  tala config set temperature 0.2  # Change a setting without editing JSON
+  tala index ~/notes             # Answer from your documents
+  tala replay last --model qwen2.5  # Re-ask the latest session's prompts
 
 Interactive Commands:
   /help                   Show available commands
   /clear                  Clear screen and reset session
+  /persona [name]         List personas or switch the active one
+  /profile [use name]     List provider profiles or switch to one
+  /set <key> <value>      Change a setting now and in the config file
+  /settings               Walk through provider, model, API key and sampling settings
+  /sessions               List saved sessions
+  /resume [id]            Resume a saved session (default: the latest)
+  /fork [turns]           Continue in a copy of the session, optionally cut after turns
+  /rate up|down [note]    Rate the latest response
+  /ratings                Summarize ratings per model
+  /tools [name]           List available tools or describe one
+  /notools                Toggle tool use off and on
+  /verbose                Show detected intents and their confidence
+  /raw                    Toggle verbatim response output
   /ls, /cat, /pwd, etc.   File operations
-  Ctrl+C                  Exit
+  Ctrl+C                  Stop the current reply, or exit
   Ctrl+L                  Clear screen
 
 Configuration:
@@ -149,6 +1022,73 @@ For more information, visit: https://github.com/domykasas/tala
 `)
 }
 
+// loadJSONSchema reads a JSON Schema document from path
+func loadJSONSchema(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON object: %w", path, err)
+	}
+	return schema, nil
+}
+
+// showProviders lists the supported providers and whether they need an API key
+func showProviders(asJSON bool) {
+	providers := ai.SupportedProviders()
+	if asJSON {
+		printJSON(providers)
+		return
+	}
+
+	for _, p := range providers {
+		key := "no API key needed"
+		if p.RequiresAPIKey {
+			key = "requires API key"
+		}
+		model := "default model " + p.DefaultModel
+		if p.DefaultModel == "" {
+			model = "no default model"
+		}
+		fmt.Printf("%-18s %-32s (%s, %s)\n", p.Name, p.Description, key, model)
+	}
+}
+
+// showTools lists the tools available to the AI with their parameters
+func showTools(asJSON bool) {
+	tools := ai.GetAvailableTools()
+	if asJSON {
+		printJSON(tools)
+		return
+	}
+
+	for i, tool := range tools {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s\n  %s\n", tool.Name, tool.Description)
+
+		for _, param := range tool.ParameterList() {
+			marker := ""
+			if param.Required {
+				marker = ", required"
+			}
+			fmt.Printf("  - %s (%s%s): %s\n", param.Name, param.Type, marker, param.Description)
+		}
+	}
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		logging.Fatal("encoding JSON", "error", err)
+	}
+	fmt.Println(string(data))
+}
+
 // showVersion displays version information
 func showVersion() {
 	fmt.Printf("Tala v%s\n", version)
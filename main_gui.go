@@ -5,29 +5,48 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"tala/internal/ai"
 	"tala/internal/config"
+	"tala/internal/i18n"
 	"tala/internal/gui"
+	"tala/internal/logging"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("loading config", "error", err)
+	}
+	i18n.SetLanguage(i18n.Detect(cfg.Language))
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		logging.Fatal(i18n.Tf("config.error", err))
 	}
 
 	if err := cfg.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		fmt.Fprintf(os.Stderr, "Please set your API key and other configuration options.\n")
-		fmt.Fprintf(os.Stderr, "Configuration file location: ~/.config/tala/config.json\n")
+		slog.Error(i18n.Tf("config.error", err))
+		fmt.Fprintln(os.Stderr, i18n.T("config.hint.settings"))
+		fmt.Fprintln(os.Stderr, i18n.T("config.hint.location"))
 		os.Exit(1)
 	}
 
+	if err := ai.ConfigureHTTP(cfg); err != nil {
+		slog.Error(i18n.Tf("config.error", err))
+		os.Exit(1)
+	}
+	ai.ConfigureTools(cfg)
+	ai.ConfigureContext(cfg)
+	ai.ConfigureRetrieval(cfg)
+	ai.ConfigureCache(cfg)
+	if cfg.MetricsAddr != "" {
+		ai.StartMetrics(cfg.MetricsAddr)
+	}
+
 	app, err := gui.NewApp(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("starting the GUI", "error", err)
 	}
 
 	app.Run()